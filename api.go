@@ -0,0 +1,677 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxAPIBody is the maximum size of the body of the API requests.
+const maxAPIBody = 1 << 20
+
+// maxJobs is the maximum number of asynchronous generations kept by
+// the server. When it is reached, the oldest finished one is
+// discarded.
+const maxJobs = 64
+
+// A graphRequest is the body of the POST /graphs requests.
+type graphRequest struct {
+	// Parameters maps generate flag names to their values.
+	Parameters map[string]any `json:"parameters"`
+
+	// Async makes the graph be generated in the background.
+	Async bool `json:"async"`
+}
+
+// Statuses of the asynchronous generations.
+const (
+	jobQueued   = "queued"
+	jobRunning  = "running"
+	jobDone     = "done"
+	jobFailed   = "failed"
+	jobCanceled = "canceled"
+)
+
+// A graphJob is an asynchronous generation. Its output is written to
+// a file, so it does not need to fit in memory and can be downloaded
+// in ranges.
+type graphJob struct {
+	id          string
+	contentType string
+	cancel      context.CancelFunc
+
+	// tenant is the tenant that started the job. It is nil if the
+	// server has no tenants.
+	tenant *tenant
+
+	// exceeded reports whether the generation exceeded the quotas
+	// of the tenant.
+	exceeded func() error
+
+	// name is the name of the output file.
+	name string
+
+	// written is the number of bytes of output written so far.
+	written atomic.Int64
+
+	mu       sync.Mutex
+	status   string
+	err      error
+	finished time.Time
+	vertices int64
+	edges    int64
+}
+
+// counting returns a writer that writes to w and counts the bytes
+// written to the output of the job.
+func (job *graphJob) counting(w io.Writer) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		n, err := w.Write(p)
+		job.written.Add(int64(n))
+		return n, err
+	})
+}
+
+// A writerFunc is a function that implements [io.Writer].
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// acquire waits for a slot in sem until ctx is done. It reports
+// whether the slot was acquired. If sem is nil, the number of slots is
+// not limited.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if sem == nil {
+		return true
+	}
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release releases a slot acquired in sem.
+func release(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
+
+// run generates the graph of the job once a slot of its tenant and a
+// slot in sem are available. The slot of the tenant is acquired first,
+// so that jobs waiting for it do not hold slots needed by the jobs of
+// other tenants.
+func (job *graphJob) run(ctx context.Context, gen *generation, sem chan struct{}) {
+	slots := job.tenant.jobSlots()
+	if !acquire(ctx, slots) {
+		job.finish(ctx, nil, nil)
+		return
+	}
+	defer release(slots)
+	if !acquire(ctx, sem) {
+		job.finish(ctx, nil, nil)
+		return
+	}
+	defer release(sem)
+
+	job.mu.Lock()
+	job.status = jobRunning
+	job.mu.Unlock()
+
+	// The output is written to a temporary file, which is renamed
+	// once the generation is done, so only complete graphs can be
+	// downloaded.
+	tmp := job.name + ".part"
+	f, err := os.Create(tmp)
+	if err != nil {
+		job.finish(ctx, nil, err)
+		return
+	}
+	bw := bufio.NewWriter(job.counting(f))
+	gen.write(ctx, bw, func() { bw.Flush() })
+	err = errors.Join(bw.Flush(), f.Close())
+	if err == nil {
+		err = job.exceeded()
+	}
+	if err == nil {
+		err = gen.verify(ctx.Err() == nil)
+	}
+	if err == nil && ctx.Err() == nil {
+		err = os.Rename(tmp, job.name)
+	}
+	if err != nil || ctx.Err() != nil {
+		os.Remove(tmp)
+	}
+	job.finish(ctx, gen, err)
+}
+
+// finish records the result of the job.
+func (job *graphJob) finish(ctx context.Context, gen *generation, err error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	switch {
+	case ctx.Err() != nil:
+		job.status = jobCanceled
+	case err != nil:
+		job.status, job.err = jobFailed, err
+	default:
+		job.status = jobDone
+	}
+	job.finished = time.Now()
+	if gen != nil {
+		job.vertices, job.edges = gen.counter.vertices, gen.counter.edges
+	}
+}
+
+// A jobStatus is the JSON representation of a graphJob.
+type jobStatus struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Vertices int64  `json:"vertices"`
+	Edges    int64  `json:"edges"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// jsonStatus returns the JSON representation of the job.
+func (job *graphJob) jsonStatus() jobStatus {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	st := jobStatus{ID: job.id, Status: job.status, Vertices: job.vertices, Edges: job.edges, Bytes: job.written.Load()}
+	if job.err != nil {
+		st.Error = job.err.Error()
+	}
+	return st
+}
+
+// A graphAPI serves the JSON API of the server.
+type graphAPI struct {
+	// dir is the directory of the outputs of the jobs.
+	dir string
+
+	// sem limits the number of jobs running at the same time.
+	sem chan struct{}
+
+	// wg waits for the jobs to finish.
+	wg sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*graphJob
+
+	// order are the IDs of the jobs from oldest to newest.
+	order []string
+}
+
+// newGraphAPI returns a graphAPI without jobs that writes their
+// outputs to dir and runs up to concurrency of them at the same time.
+func newGraphAPI(dir string, concurrency int) *graphAPI {
+	return &graphAPI{
+		dir:  dir,
+		sem:  make(chan struct{}, concurrency),
+		jobs: make(map[string]*graphJob),
+	}
+}
+
+// register registers the handlers of the API in mux.
+func (api *graphAPI) register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /graphs", api.handleCreate)
+	mux.HandleFunc("GET /graphs/{id}", api.handleStatus)
+	mux.HandleFunc("GET /graphs/{id}/output", api.handleOutput)
+	mux.HandleFunc("DELETE /graphs/{id}", api.handleDelete)
+	mux.HandleFunc("GET /openapi.json", handleOpenAPI)
+}
+
+// handleCreate generates the graph described by the request body. It
+// is streamed unless the request is asynchronous.
+func (api *graphAPI) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req graphRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, usageError{fmt.Errorf("invalid request: %w", err)})
+		return
+	}
+
+	params, err := jsonParams(req.Parameters)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	c, gen, err := serverGeneration(params)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	t := tenantFrom(r.Context())
+	exceeded, err := t.limit(c, gen)
+	if err != nil {
+		writeAPIError(w, http.StatusForbidden, err)
+		return
+	}
+
+	if !req.Async {
+		writeGraph(r.Context(), w, c, gen, exceeded)
+		return
+	}
+	if c.infinite {
+		writeAPIError(w, http.StatusBadRequest, usageError{errors.New("-infinite cannot be asynchronous")})
+		return
+	}
+
+	job, err := api.start(c, gen, t, exceeded)
+	if errors.Is(err, errJobQuota) {
+		w.Header().Set("Retry-After", "1")
+		writeAPIError(w, http.StatusTooManyRequests, err)
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.Header().Set("Location", "/graphs/"+job.id)
+	writeJSON(w, http.StatusAccepted, job.jsonStatus())
+}
+
+// errJobQuota is returned when a tenant has too many queued or
+// running jobs.
+var errJobQuota = errors.New("too many queued generations")
+
+// start starts generating gen in the background for tenant t and
+// returns its job. exceeded reports whether gen exceeded the quotas of
+// t.
+func (api *graphAPI) start(c *genConfig, gen *generation, t *tenant, exceeded func() error) (*graphJob, error) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if t != nil && t.MaxQueued > 0 && api.pending(t) >= t.MaxQueued {
+		return nil, fmt.Errorf("%w: %v of tenant %v", errJobQuota, t.MaxQueued, t.Name)
+	}
+	if len(api.jobs) >= maxJobs && !api.evict() {
+		return nil, errors.New("too many running generations")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	id := rand.Text()
+	job := &graphJob{
+		id:          id,
+		contentType: graphContentType(c),
+		cancel:      cancel,
+		tenant:      t,
+		exceeded:    exceeded,
+		name:        filepath.Join(api.dir, id),
+		status:      jobQueued,
+	}
+	api.jobs[job.id] = job
+	api.order = append(api.order, job.id)
+
+	api.wg.Go(func() {
+		defer cancel()
+		job.run(ctx, gen, api.sem)
+	})
+	return job, nil
+}
+
+// close cancels the jobs that are queued or running and waits for
+// them to finish.
+func (api *graphAPI) close() {
+	api.mu.Lock()
+	for _, job := range api.jobs {
+		job.cancel()
+	}
+	api.mu.Unlock()
+	api.wg.Wait()
+}
+
+// pending returns the number of jobs of tenant t that are queued or
+// running. api.mu must be held.
+func (api *graphAPI) pending(t *tenant) int {
+	n := 0
+	for _, job := range api.jobs {
+		if job.tenant != t {
+			continue
+		}
+		if st := job.jsonStatus().Status; st == jobQueued || st == jobRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// evict discards the oldest finished job. It reports whether a job
+// was discarded. api.mu must be held.
+func (api *graphAPI) evict() bool {
+	for i, id := range api.order {
+		if st := api.jobs[id].jsonStatus().Status; st != jobQueued && st != jobRunning {
+			os.Remove(api.jobs[id].name)
+			delete(api.jobs, id)
+			api.order = slices.Delete(api.order, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// job returns the job with the ID in the request path. If it does not
+// exist or was started by another tenant, it writes an error and
+// returns nil.
+func (api *graphAPI) job(w http.ResponseWriter, r *http.Request) *graphJob {
+	api.mu.Lock()
+	job := api.jobs[r.PathValue("id")]
+	api.mu.Unlock()
+	if job != nil && job.tenant != tenantFrom(r.Context()) {
+		job = nil
+	}
+	if job == nil {
+		writeAPIError(w, http.StatusNotFound, errors.New("unknown graph"))
+	}
+	return job
+}
+
+// handleStatus writes the status of a job.
+func (api *graphAPI) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if job := api.job(w, r); job != nil {
+		writeJSON(w, http.StatusOK, job.jsonStatus())
+	}
+}
+
+// handleOutput writes the graph generated by a finished job. Range
+// requests are supported, so interrupted downloads can be resumed.
+func (api *graphAPI) handleOutput(w http.ResponseWriter, r *http.Request) {
+	job := api.job(w, r)
+	if job == nil {
+		return
+	}
+
+	job.mu.Lock()
+	status, finished := job.status, job.finished
+	job.mu.Unlock()
+	if status != jobDone {
+		writeAPIError(w, http.StatusConflict, fmt.Errorf("graph is %v", status))
+		return
+	}
+
+	f, err := os.Open(job.name)
+	if err != nil {
+		// The job may have been discarded meanwhile.
+		writeAPIError(w, http.StatusNotFound, err)
+		return
+	}
+	defer f.Close()
+
+	// The output does not change once the job is done, so its ID
+	// identifies it.
+	w.Header().Set("Content-Type", job.contentType)
+	w.Header().Set("ETag", `"`+job.id+`"`)
+	http.ServeContent(w, r, "", finished, f)
+}
+
+// handleDelete cancels a job, if it is running, and discards it.
+func (api *graphAPI) handleDelete(w http.ResponseWriter, r *http.Request) {
+	job := api.job(w, r)
+	if job == nil {
+		return
+	}
+	job.cancel()
+
+	api.mu.Lock()
+	os.Remove(job.name)
+	delete(api.jobs, job.id)
+	api.order = slices.DeleteFunc(api.order, func(id string) bool { return id == job.id })
+	api.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// jsonParams converts the parameters of a JSON request into the
+// values of the corresponding flags.
+func jsonParams(params map[string]any) (url.Values, error) {
+	values := make(url.Values)
+	for name, v := range params {
+		switch v := v.(type) {
+		case string:
+			values.Set(name, v)
+		case json.Number:
+			values.Set(name, v.String())
+		case bool:
+			values.Set(name, fmt.Sprint(v))
+		default:
+			return nil, usageError{fmt.Errorf("invalid value of parameter %q: must be a string, number or boolean", name)}
+		}
+	}
+	return values, nil
+}
+
+// writeJSON writes v as a JSON response with the provided status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("serve: %v", err)
+	}
+}
+
+// writeAPIError writes err as a JSON error record with the provided
+// HTTP status.
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, newErrorRecord(exitStatus(err), err))
+}
+
+// handleOpenAPI writes the OpenAPI description of the server.
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, openAPISpec())
+}
+
+// openAPISpec returns the OpenAPI description of the server. The
+// parameters of the generations are described from the generate
+// flags, so they are always up to date.
+func openAPISpec() map[string]any {
+	var c genConfig
+	params := make(map[string]any)
+	c.flagSet("generate").VisitAll(func(f *flag.Flag) {
+		if !serverFlags[f.Name] {
+			return
+		}
+		_, usage := flag.UnquoteUsage(f)
+		typ := "string"
+		var def any = f.DefValue
+		if g, ok := f.Value.(flag.Getter); ok {
+			switch v := g.Get().(type) {
+			case bool:
+				typ, def = "boolean", v
+			case int, int64, uint64:
+				typ, def = "integer", v
+			case float64:
+				typ, def = "number", v
+			}
+		}
+		params[f.Name] = map[string]any{"type": typ, "description": usage, "default": def}
+	})
+
+	ref := func(name string) map[string]any {
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	content := func(schema any) map[string]any {
+		return map[string]any{"application/json": map[string]any{"schema": schema}}
+	}
+	graphContent := map[string]any{
+		"text/plain":        map[string]any{"schema": map[string]any{"type": "string"}},
+		"text/vnd.graphviz": map[string]any{"schema": map[string]any{"type": "string"}},
+	}
+	errResp := map[string]any{"description": "Error", "content": content(ref("Error"))}
+	idParam := []any{map[string]any{"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "mkdigraph",
+			"version": version(),
+		},
+		// API keys are only required if the server has tenants.
+		"security": []map[string]any{{"apiKey": []string{}}, {}},
+		"paths": map[string]any{
+			"/graphs": map[string]any{
+				"post": map[string]any{
+					"operationId": "createGraph",
+					"summary":     "Generate a graph",
+					"requestBody": map[string]any{"required": true, "content": content(ref("GraphRequest"))},
+					"responses": map[string]any{
+						"200": map[string]any{"description": "The generated graph", "content": graphContent},
+						"202": map[string]any{"description": "The asynchronous generation", "content": content(ref("Job"))},
+						"400": errResp,
+						"403": errResp,
+						"429": errResp,
+						"503": errResp,
+					},
+				},
+			},
+			"/graphs/{id}": map[string]any{
+				"get": map[string]any{
+					"operationId": "getGraph",
+					"summary":     "Get the status of an asynchronous generation",
+					"parameters":  idParam,
+					"responses": map[string]any{
+						"200": map[string]any{"description": "The generation", "content": content(ref("Job"))},
+						"404": errResp,
+					},
+				},
+				"delete": map[string]any{
+					"operationId": "deleteGraph",
+					"summary":     "Cancel and discard an asynchronous generation",
+					"parameters":  idParam,
+					"responses": map[string]any{
+						"204": map[string]any{"description": "Discarded"},
+						"404": errResp,
+					},
+				},
+			},
+			"/graphs/{id}/output": map[string]any{
+				"get": map[string]any{
+					"operationId": "getGraphOutput",
+					"summary":     "Get the graph of a finished asynchronous generation",
+					"parameters":  idParam,
+					"responses": map[string]any{
+						"200": map[string]any{"description": "The generated graph", "content": graphContent},
+						"206": map[string]any{"description": "A range of the generated graph", "content": graphContent},
+						"404": errResp,
+						"409": errResp,
+						"416": map[string]any{"description": "Invalid range"},
+					},
+				},
+			},
+		},
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"apiKey": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+			"schemas": map[string]any{
+				"GraphRequest": map[string]any{
+					"type":     "object",
+					"required": []string{"parameters"},
+					"properties": map[string]any{
+						"parameters": map[string]any{"type": "object", "properties": params, "additionalProperties": false},
+						"async":      map[string]any{"type": "boolean", "default": false},
+					},
+				},
+				"Job": map[string]any{
+					"type":     "object",
+					"required": []string{"id", "status", "vertices", "edges", "bytes"},
+					"properties": map[string]any{
+						"id":       map[string]any{"type": "string"},
+						"status":   map[string]any{"type": "string", "enum": []string{jobQueued, jobRunning, jobDone, jobFailed, jobCanceled}},
+						"error":    map[string]any{"type": "string"},
+						"vertices": map[string]any{"type": "integer"},
+						"edges":    map[string]any{"type": "integer"},
+						"bytes":    map[string]any{"type": "integer"},
+					},
+				},
+				"Error": map[string]any{
+					"type":     "object",
+					"required": []string{"error", "kind", "status"},
+					"properties": map[string]any{
+						"error":  map[string]any{"type": "string"},
+						"kind":   map[string]any{"type": "string"},
+						"status": map[string]any{"type": "integer"},
+						"op":     map[string]any{"type": "string"},
+						"path":   map[string]any{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// serverGeneration returns the generation with the provided generate
+// flags, which are rejected if they are not allowed in the server. See
+// [queryArgs].
+func serverGeneration(params url.Values) (*genConfig, *generation, error) {
+	args, err := queryArgs(params)
+	if err != nil {
+		return nil, nil, usageError{err}
+	}
+	c, fs, err := parseGenerate(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, gen, nil
+}
+
+// graphContentType returns the content type of the output of c.
+func graphContentType(c *genConfig) string {
+	if c.emitDOT {
+		return "text/vnd.graphviz; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// writeGraph streams the graph of gen to w until ctx is done. If
+// exceeded reports that gen exceeded the quotas of the tenant, the
+// graph has already been partially written with status 200, so the
+// error is written as a comment at the end of the graph, if the format
+// has comments, and sent in the Mkdigraph-Error trailer.
+func writeGraph(ctx context.Context, w http.ResponseWriter, c *genConfig, gen *generation, exceeded func() error) {
+	w.Header().Set("Content-Type", graphContentType(c))
+	w.Header().Set("Trailer", "Mkdigraph-Error")
+
+	bw := bufio.NewWriter(w)
+	rc := http.NewResponseController(w)
+	gen.write(ctx, bw, func() {
+		if bw.Flush() == nil {
+			rc.Flush()
+		}
+	})
+	if err := bw.Flush(); err != nil {
+		log.Printf("serve: %v", err)
+	}
+	if err := exceeded(); err != nil {
+		log.Printf("serve: %v", err)
+		gen.p.writeComment(w, c.emitDOT, "error: "+err.Error())
+		w.Header().Set("Mkdigraph-Error", err.Error())
+	}
+}
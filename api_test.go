@@ -0,0 +1,205 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAPIServer returns a test server serving the JSON API.
+func newAPIServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	newGraphAPI(t.TempDir(), 2).register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAPICreate(t *testing.T) {
+	srv := newAPIServer(t)
+
+	tests := []struct {
+		body       string
+		wantStatus int
+	}{
+		{body: `{"parameters": {"n": 5, "trials": 2, "meta": false}}`, wantStatus: http.StatusOK},
+		{body: `{"parameters": {"n": 5, "dot": true, "meta": false}}`, wantStatus: http.StatusOK},
+		{body: `{"parameters": {"n": -1}}`, wantStatus: http.StatusBadRequest},
+		{body: `{"parameters": {"words": "/etc/passwd"}}`, wantStatus: http.StatusBadRequest},
+		{body: `{"parameters": {"n": [1, 2]}}`, wantStatus: http.StatusBadRequest},
+		{body: `{"parameters": {}, "unknown": 1}`, wantStatus: http.StatusBadRequest},
+		{body: `{"parameters": {"infinite": true}, "async": true}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Post(srv.URL+"/graphs", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%v: unexpected status: got: %v want: %v", tt.body, resp.StatusCode, tt.wantStatus)
+			continue
+		}
+		if tt.wantStatus == http.StatusOK {
+			if !validSimpleOutput.Match(body) && !validDOTOutput.Match(body) {
+				t.Errorf("%v: malformed output:\n%s", tt.body, body)
+			}
+			continue
+		}
+		var rec errorRecord
+		if err := json.Unmarshal(body, &rec); err != nil || rec.Error == "" {
+			t.Errorf("%v: invalid error record: %s", tt.body, body)
+		}
+	}
+}
+
+func TestAPIAsync(t *testing.T) {
+	srv := newAPIServer(t)
+
+	resp, err := http.Post(srv.URL+"/graphs", "application/json", strings.NewReader(`{"parameters": {"n": 10, "seed": 1, "meta": false}, "async": true}`))
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	var job jobStatus
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("invalid job: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "/graphs/"+job.ID {
+		t.Fatalf("unexpected response: %v %v", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	for job.Status == jobQueued || job.Status == jobRunning {
+		time.Sleep(10 * time.Millisecond)
+		resp, err := http.Get(srv.URL + "/graphs/" + job.ID)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("invalid job: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if job.Status != jobDone || job.Vertices != 10 || job.Bytes == 0 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	resp, err = http.Get(srv.URL + "/graphs/" + job.ID + "/output")
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !validSimpleOutput.Match(body) || int64(strings.Count(string(body), "E: ")) != job.Edges {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+	if int64(len(body)) != job.Bytes {
+		t.Errorf("unexpected output size: got: %v want: %v", len(body), job.Bytes)
+	}
+
+	// Resume the download from the middle of the output.
+	req, _ := http.NewRequest("GET", srv.URL+"/graphs/"+job.ID+"/output", nil)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%v-", len(body)/2))
+	req.Header.Set("If-Range", resp.Header.Get("ETag"))
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	rest, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(rest) != string(body[len(body)/2:]) {
+		t.Errorf("unexpected range response: %v:\n%s", resp.StatusCode, rest)
+	}
+
+	req, _ = http.NewRequest("DELETE", srv.URL+"/graphs/"+job.ID, nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unexpected delete status: %v", resp.StatusCode)
+	}
+
+	if resp, err = http.Get(srv.URL + "/graphs/" + job.ID); err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status after delete: %v", resp.StatusCode)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	srv := newAPIServer(t)
+
+	resp, err := http.Get(srv.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var spec struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas struct {
+				GraphRequest struct {
+					Properties struct {
+						Parameters struct {
+							Properties map[string]struct {
+								Type string `json:"type"`
+							} `json:"properties"`
+						} `json:"parameters"`
+					} `json:"properties"`
+				} `json:"GraphRequest"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+
+	params := spec.Components.Schemas.GraphRequest.Properties.Parameters.Properties
+	for name, want := range map[string]string{"n": "integer", "prob": "number", "loops": "boolean", "vertex-types": "string"} {
+		if got := params[name].Type; got != want {
+			t.Errorf("unexpected type of %v: got: %q want: %q", name, got, want)
+		}
+	}
+	if _, ok := params["words"]; ok {
+		t.Error("denied flag in the spec: words")
+	}
+}
+
+func TestAPIClose(t *testing.T) {
+	api := newGraphAPI(t.TempDir(), 1)
+	var jobs []*graphJob
+	for range 2 {
+		c, gen, err := serverGeneration(url.Values{"n": {"1000"}, "prob": {"1"}, "rate": {"1"}})
+		if err != nil {
+			t.Fatalf("generation error: %v", err)
+		}
+		exceeded, _ := (*tenant)(nil).limit(c, gen)
+		job, err := api.start(c, gen, nil, exceeded)
+		if err != nil {
+			t.Fatalf("start error: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	api.close()
+	for _, job := range jobs {
+		if st := job.jsonStatus(); st.Status != jobCanceled {
+			t.Errorf("unexpected job: %+v", st)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxASCII is the default maximum number of vertices of the
+// graphs drawn as text by the render command.
+const defaultMaxASCII = 30
+
+// writeASCII draws d as text and writes it to w. The vertices are
+// drawn as boxes stacked in the order of ids. Every edge leaves the
+// right side of the box of its tail through a row of its own and
+// enters the box of its head, marked with "<", through a vertical lane
+// to the right of the boxes. Shorter edges are given the inner lanes,
+// so edges are nested when possible. Where two edges cross, the
+// horizontal one is drawn on top.
+//
+// For instance, the graph with the edges 0 -> 1, 1 -> 2 and 0 -> 2 is
+// drawn as:
+//
+//	+---+
+//	| 0 |---+
+//	|   |-+ |
+//	+---+ | |
+//	+---+ | |
+//	| 1 |<+ |
+//	|   |-+ |
+//	+---+ | |
+//	+---+ | |
+//	| 2 |<+ |
+//	|   |<--+
+//	+---+
+func writeASCII(w io.Writer, d *graphData, ids []int64) error {
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = strconv.FormatInt(id, 10)
+	}
+	for _, v := range d.vertices {
+		if v.label != "" {
+			labels[index[v.id]] = v.label
+		}
+	}
+	boxWidth := 0
+	for _, l := range labels {
+		boxWidth = max(boxWidth, utf8.RuneCountInString(l)+4)
+	}
+
+	// Every edge endpoint takes a row of the box of its vertex. The
+	// edges to the vertices above leave from the top of the box and
+	// the edges to the vertices below from the bottom, with the
+	// nearest vertices closer to the middle, so that they are nested.
+	type endpoint struct {
+		edge  int
+		other int
+		out   bool
+	}
+	ends := make([][]endpoint, len(ids))
+	for i, e := range d.edges {
+		t, h := index[e.tail], index[e.head]
+		ends[t] = append(ends[t], endpoint{edge: i, other: h, out: true})
+		ends[h] = append(ends[h], endpoint{edge: i, other: t, out: false})
+	}
+	tailRows := make([]int, len(d.edges))
+	headRows := make([]int, len(d.edges))
+	top := make([]int, len(ids))
+	row := 0
+	for i := range ids {
+		slices.SortStableFunc(ends[i], func(a, b endpoint) int {
+			if c := cmp.Compare(cmp.Compare(a.other, i), cmp.Compare(b.other, i)); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(b.other, a.other); c != 0 {
+				return c
+			}
+			// Loops leave the box before entering it.
+			switch {
+			case a.out && !b.out:
+				return -1
+			case !a.out && b.out:
+				return 1
+			}
+			return 0
+		})
+		top[i] = row
+		for j, end := range ends[i] {
+			if end.out {
+				tailRows[end.edge] = row + 1 + j
+			} else {
+				headRows[end.edge] = row + 1 + j
+			}
+		}
+		row += max(len(ends[i]), 1) + 2
+	}
+	height := row
+
+	// Edges are assigned the innermost lane that is free along
+	// their span, from the shortest to the longest one.
+	order := make([]int, len(d.edges))
+	for i := range order {
+		order[i] = i
+	}
+	span := func(i int) (lo, hi int) {
+		return min(tailRows[i], headRows[i]), max(tailRows[i], headRows[i])
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		loA, hiA := span(a)
+		loB, hiB := span(b)
+		return cmp.Compare(hiA-loA, hiB-loB)
+	})
+	lanes := make([]int, len(d.edges))
+	var busy [][]bool
+	for _, i := range order {
+		lo, hi := span(i)
+		lane := slices.IndexFunc(busy, func(rows []bool) bool {
+			return !slices.Contains(rows[lo:hi+1], true)
+		})
+		if lane < 0 {
+			lane = len(busy)
+			busy = append(busy, make([]bool, height))
+		}
+		for r := lo; r <= hi; r++ {
+			busy[lane][r] = true
+		}
+		lanes[i] = lane
+	}
+
+	canvas := make([][]rune, height)
+	for r := range canvas {
+		canvas[r] = []rune(strings.Repeat(" ", boxWidth+1+2*len(busy)))
+	}
+	set := func(r, c int, s string) {
+		copy(canvas[r][c:], []rune(s))
+	}
+	for i, l := range labels {
+		border := "+" + strings.Repeat("-", boxWidth-2) + "+"
+		inner := "|" + strings.Repeat(" ", boxWidth-2) + "|"
+		bottom := top[i] + max(len(ends[i]), 1) + 1
+		set(top[i], 0, border)
+		for r := top[i] + 1; r < bottom; r++ {
+			set(r, 0, inner)
+		}
+		set(top[i]+1, 2, l)
+		set(bottom, 0, border)
+	}
+
+	// Vertical segments are drawn first, so that horizontal ones
+	// are drawn on top of them.
+	laneCol := func(lane int) int { return boxWidth + 1 + 2*lane }
+	for i := range d.edges {
+		lo, hi := span(i)
+		for r := lo + 1; r < hi; r++ {
+			canvas[r][laneCol(lanes[i])] = '|'
+		}
+	}
+	for i := range d.edges {
+		c := laneCol(lanes[i])
+		set(tailRows[i], boxWidth, strings.Repeat("-", c-boxWidth)+"+")
+		set(headRows[i], boxWidth, "<"+strings.Repeat("-", c-boxWidth-1)+"+")
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, line := range canvas {
+		bw.WriteString(strings.TrimRight(string(line), " "))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteASCII(t *testing.T) {
+	const input = "V: 3 alpha\nE: 0 1\nE: 1 0\nE: 2 2\nE: 0 3\n"
+	const want = `+-------+
+| 0     |-----+
+|       |---+ |
+|       |<+ | |
++-------+ | | |
++-------+ | | |
+| 1     |-+ | |
+|       |<--+ |
++-------+     |
++-------+     |
+| 2     |-+   |
+|       |<+   |
++-------+     |
++-------+     |
+| alpha |<----+
++-------+
+`
+
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := writeASCII(&b, d, d.vertexIDs()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("unexpected drawing:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"text/tabwriter"
+	"time"
+)
+
+// benchFormats are the output formats measured by -bench.
+var benchFormats = []string{"simple", "dot"}
+
+// A benchResult is the throughput of writing a graph in an output
+// format.
+type benchResult struct {
+	Format     string  `json:"format"`
+	Vertices   int64   `json:"vertices"`
+	Edges      int64   `json:"edges"`
+	Bytes      int64   `json:"bytes"`
+	Seconds    float64 `json:"seconds"`
+	VerticesPS float64 `json:"vertices_per_sec"`
+	EdgesPS    float64 `json:"edges_per_sec"`
+	BytesPS    float64 `json:"bytes_per_sec"`
+	Allocs     uint64  `json:"allocs"`
+	AllocBytes uint64  `json:"alloc_bytes"`
+}
+
+// bench writes the graph to [io.Discard] and measures the throughput
+// and the allocations. It stops early if ctx is done.
+func (gen *generation) bench(ctx context.Context, format string) benchResult {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	cw := &countingWriter{w: io.Discard}
+	bw := bufio.NewWriter(cw)
+	start := time.Now()
+	gen.write(ctx, bw, func() { bw.Flush() })
+	bw.Flush()
+	elapsed := time.Since(start).Seconds()
+
+	runtime.ReadMemStats(&after)
+
+	res := benchResult{
+		Format:     format,
+		Vertices:   gen.counter.vertices,
+		Edges:      gen.counter.edges,
+		Bytes:      cw.n,
+		Seconds:    elapsed,
+		Allocs:     after.Mallocs - before.Mallocs,
+		AllocBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+	if elapsed > 0 {
+		res.VerticesPS = float64(res.Vertices) / elapsed
+		res.EdgesPS = float64(res.Edges) / elapsed
+		res.BytesPS = float64(res.Bytes) / elapsed
+	}
+	return res
+}
+
+// runBench generates the graph described by c once per output format
+// and writes the results to w. fs is the flag set used to parse c. If
+// ctx is done, the formats not measured yet are skipped.
+func runBench(ctx context.Context, w io.Writer, c genConfig, fs *flag.FlagSet) error {
+	var results []benchResult
+	for _, format := range benchFormats {
+		if ctx.Err() != nil {
+			break
+		}
+		c.emitDOT = format == "dot"
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			return err
+		}
+		results = append(results, gen.bench(ctx, format))
+	}
+
+	if c.benchJSON {
+		enc := json.NewEncoder(w)
+		for _, res := range results {
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "format\tvertices\tedges\tbytes\tseconds\tvertices/s\tedges/s\tbytes/s\tallocs\talloc-bytes")
+	for _, res := range results {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%.3f\t%.0f\t%.0f\t%.0f\t%v\t%v\n",
+			res.Format, res.Vertices, res.Edges, res.Bytes, res.Seconds,
+			res.VerticesPS, res.EdgesPS, res.BytesPS, res.Allocs, res.AllocBytes)
+	}
+	return tw.Flush()
+}
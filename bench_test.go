@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRunBench(t *testing.T) {
+	var c genConfig
+	fs := c.flagSet("generate")
+	if err := fs.Parse([]string{"-n=100", "-seed=1", "-bench", "-bench-json"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := runBench(context.Background(), &buf, c, fs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dec := json.NewDecoder(&buf)
+	var results []benchResult
+	for {
+		var res benchResult
+		if err := dec.Decode(&res); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			t.Fatalf("unexpected error: %v", err)
+		}
+		results = append(results, res)
+	}
+
+	if len(results) != len(benchFormats) {
+		t.Fatalf("unexpected number of results: got: %v, want: %v", len(results), len(benchFormats))
+	}
+	for i, res := range results {
+		if res.Format != benchFormats[i] {
+			t.Errorf("unexpected format: got: %v, want: %v", res.Format, benchFormats[i])
+		}
+		if res.Vertices != 100 {
+			t.Errorf("%v: unexpected number of vertices: got: %v, want: 100", res.Format, res.Vertices)
+		}
+		if res.Edges != results[0].Edges {
+			t.Errorf("%v: the graph depends on the format: got: %v edges, want: %v", res.Format, res.Edges, results[0].Edges)
+		}
+		if res.Bytes == 0 {
+			t.Errorf("%v: no bytes written", res.Format)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A blockModel is a directed stochastic block model. The vertices are
+// split in consecutive blocks, and every ordered pair of vertices is
+// connected with the probability given for their blocks.
+type blockModel struct {
+	// names and sizes are the name and number of vertices of
+	// every block.
+	names []string
+	sizes []int64
+
+	// probs[i][j] is the probability of an edge from a vertex of
+	// block i to a vertex of block j.
+	probs [][]float64
+
+	// theta is the propensity of every vertex if the model is
+	// degree-corrected, or nil otherwise.
+	theta []float64
+
+	// order holds the vertices of every block sorted by decreasing
+	// propensity.
+	order [][]int64
+}
+
+// readBlocks reads a blocks file. Every line describes a block with
+// its name, its number of vertices and the probabilities of an edge
+// from one of its vertices to a vertex of every block, in the order
+// of the file, separated by whitespace. Empty lines and lines
+// starting with "#" are ignored.
+func readBlocks(name string) (*blockModel, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	m := &blockModel{}
+	seen := make(map[string]bool)
+	var total int64
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || (len(m.probs) > 0 && len(fields) != len(m.probs[0])+2) {
+			return nil, fmt.Errorf("%v:%v: malformed line: %q", name, lineno, line)
+		}
+		if seen[fields[0]] {
+			return nil, fmt.Errorf("%v:%v: duplicated block: %v", name, lineno, fields[0])
+		}
+		seen[fields[0]] = true
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("%v:%v: invalid block size: %q", name, lineno, fields[1])
+		}
+		if total += size; total < 0 {
+			return nil, fmt.Errorf("%v:%v: too many vertices", name, lineno)
+		}
+		var probs []float64
+		for _, field := range fields[2:] {
+			p, err := strconv.ParseFloat(field, 64)
+			if err != nil || !(p >= 0 && p <= 1) {
+				return nil, fmt.Errorf("%v:%v: invalid probability: %q", name, lineno, field)
+			}
+			probs = append(probs, p)
+		}
+		m.names = append(m.names, fields[0])
+		m.sizes = append(m.sizes, size)
+		m.probs = append(m.probs, probs)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(m.probs) == 0 {
+		return nil, fmt.Errorf("%v: no blocks", name)
+	}
+	if k := len(m.probs[0]); k != len(m.probs) {
+		return nil, fmt.Errorf("%v: %v blocks but %v probabilities per block", name, len(m.probs), k)
+	}
+	return m, nil
+}
+
+// vertices returns the number of vertices of the graph.
+func (m *blockModel) vertices() int64 {
+	var n int64
+	for _, size := range m.sizes {
+		n += size
+	}
+	return n
+}
+
+// correct turns the model into a degree-corrected stochastic block
+// model. The propensity of every vertex is drawn from dist and scaled
+// so the mean propensity of every block is 1. Then, the probability
+// of an edge u -> v is the probability given for their blocks
+// multiplied by the propensities of u and v, capped at 1, so the
+// expected number of edges between blocks is preserved unless
+// probabilities are capped, and degrees follow the shape of dist. r
+// is the source of randomness.
+func (m *blockModel) correct(dist degreeDist, r *rand.Rand) {
+	m.theta = make([]float64, m.vertices())
+	m.order = make([][]int64, len(m.sizes))
+	first := int64(0)
+	for i, size := range m.sizes {
+		var sum float64
+		for id := first; id < first+size; id++ {
+			m.theta[id] = float64(dist(r))
+			sum += m.theta[id]
+		}
+		if sum > 0 {
+			for id := first; id < first+size; id++ {
+				m.theta[id] *= float64(size) / sum
+			}
+		}
+		order := make([]int64, size)
+		for k := range order {
+			order[k] = first + int64(k)
+		}
+		slices.SortStableFunc(order, func(a, b int64) int {
+			return cmp.Compare(m.theta[b], m.theta[a])
+		})
+		m.order[i] = order
+		first += size
+	}
+}
+
+// graph returns a graph following the block model. The vertices of
+// every block have consecutive IDs, in the order of the blocks, and
+// the name of their block as community. Loops are discarded unless
+// allowed, and multiple edges cannot happen. r is the source of
+// randomness.
+//
+// The heads of every vertex are drawn block by block skipping over
+// the vertices that are not connected, so the time is proportional
+// to the number of vertices times the number of blocks plus the
+// number of edges. If the model is degree-corrected, the heads are
+// visited by decreasing propensity, and the skips are drawn with the
+// probability of the next head, which bounds the probability of the
+// following ones, and every reached head is accepted with the ratio
+// between its probability and the bound. So heads are not sorted by
+// ID.
+func (m *blockModel) graph(loops bool, r *rand.Rand, vlabel func(id int64) string) graph {
+	// first[i] is the ID of the first vertex of block i.
+	first := make([]int64, len(m.sizes)+1)
+	for i, size := range m.sizes {
+		first[i+1] = first[i] + size
+	}
+	vertices := func(yield func(vertex) bool) {
+		for i, name := range m.names {
+			for id := first[i]; id < first[i+1]; id++ {
+				if !yield(vertex{id: id, label: vlabel(id), community: name}) {
+					return
+				}
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for i := range m.names {
+			for tail := first[i]; tail < first[i+1]; tail++ {
+				for j, p := range m.probs[i] {
+					if p == 0 {
+						continue
+					}
+					heads := skipSample(first[j], first[j+1], p, r)
+					if m.theta != nil {
+						heads = m.correctedHeads(j, p*m.theta[tail], r)
+					}
+					for head := range heads {
+						if head == tail && !loops {
+							continue
+						}
+						if !yield(edge{tail: tail, head: head}) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// correctedHeads returns the vertices of block j chosen
+// independently with probability min(1, w*θ), where θ is their
+// propensity.
+func (m *blockModel) correctedHeads(j int, w float64, r *rand.Rand) iter.Seq[int64] {
+	order := m.order[j]
+	return func(yield func(int64) bool) {
+		for k := 0; k < len(order); k++ {
+			// The propensities are decreasing, so q bounds the
+			// probabilities of the remaining heads.
+			q := min(1, w*m.theta[order[k]])
+			if q == 0 {
+				return
+			}
+			if q < 1 {
+				skip := math.Floor(math.Log(1-r.Float64()) / math.Log1p(-q))
+				if skip >= float64(len(order)-k) {
+					return
+				}
+				k += int(skip)
+			}
+			head := order[k]
+			if p := min(1, w*m.theta[head]); p < q && r.Float64() >= p/q {
+				continue
+			}
+			if !yield(head) {
+				return
+			}
+		}
+	}
+}
+
+// skipSample returns the integers in [lo, hi) chosen independently
+// with probability p, in increasing order. The gaps between them are
+// drawn from a geometric distribution, so only the chosen integers
+// are visited.
+func skipSample(lo, hi int64, p float64, r *rand.Rand) iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		if p == 0 {
+			return
+		}
+		logq := math.Log1p(-p)
+		for x := lo - 1; ; {
+			skip := 0.0
+			if p < 1 {
+				skip = math.Floor(math.Log(1-r.Float64()) / logq)
+			}
+			if skip >= float64(hi-x-1) {
+				return
+			}
+			x += 1 + int64(skip)
+			if !yield(x) {
+				return
+			}
+		}
+	}
+}
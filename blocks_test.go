@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestReadBlocks(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "blocks")
+	data := "# name size a b\na 10 0.5 1\n\nb\t5 0 0\n"
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := readBlocks(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.vertices() != 15 {
+		t.Errorf("unexpected number of vertices: got: %v want: 15", m.vertices())
+	}
+	if len(m.names) != 2 || m.names[1] != "b" || m.probs[0][1] != 1 {
+		t.Errorf("unexpected blocks: %+v", m)
+	}
+}
+
+func TestReadBlocksInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"a 10\n",
+		"a 10 0.5 0.5\n",
+		"a 10 0.5\nb 10 0.5\n",
+		"a 10 0.5 0.5\nb 10 0.5\n",
+		"a x 0.5\n",
+		"a -1 0.5\n",
+		"a 10 x\n",
+		"a 10 1.5\n",
+		"a 10 NaN\n",
+		"a 10 0 0\na 10 0 0\n",
+	}
+	for _, input := range inputs {
+		name := filepath.Join(t.TempDir(), "blocks")
+		if err := os.WriteFile(name, []byte(input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readBlocks(name); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestBlockGraph(t *testing.T) {
+	m := &blockModel{
+		names: []string{"a", "b", "c"},
+		sizes: []int64{200, 100, 0},
+		probs: [][]float64{
+			{0.1, 1, 0.5},
+			{0, 0.3, 1},
+			{1, 1, 1},
+		},
+	}
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	g := m.graph(false, newRNG(rngPCG, 1), vlabel)
+
+	comm := make(map[int64]string)
+	for v := range g.vertices {
+		comm[v.id] = v.community
+	}
+	if len(comm) != 300 || comm[0] != "a" || comm[199] != "a" || comm[200] != "b" || comm[299] != "b" {
+		t.Fatalf("unexpected vertices: %v", len(comm))
+	}
+
+	count := make(map[[2]string]int)
+	seen := make(map[edge]bool)
+	for e := range g.edges {
+		if e.tail == e.head {
+			t.Errorf("unexpected loop: %v", e)
+		}
+		if seen[e] {
+			t.Errorf("multiple edge: %v", e)
+		}
+		seen[e] = true
+		count[[2]string{comm[e.tail], comm[e.head]}]++
+	}
+
+	tests := []struct {
+		from, to string
+		want     float64
+	}{
+		{"a", "a", 0.1 * 200 * 199},
+		{"a", "b", 200 * 100},
+		{"b", "a", 0},
+		{"b", "b", 0.3 * 100 * 99},
+	}
+	for _, tt := range tests {
+		got := float64(count[[2]string{tt.from, tt.to}])
+		if math.Abs(got-tt.want) > 0.05*tt.want {
+			t.Errorf("unexpected number of edges from %v to %v: got: %v want: %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestBlockGraphLoops(t *testing.T) {
+	m := &blockModel{names: []string{"a"}, sizes: []int64{10}, probs: [][]float64{{1}}}
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	n := 0
+	for range m.graph(true, newRNG(rngPCG, 1), vlabel).edges {
+		n++
+	}
+	if n != 100 {
+		t.Errorf("unexpected number of edges: got: %v want: 100", n)
+	}
+}
+
+func TestBlockGraphCorrected(t *testing.T) {
+	const n = 2000
+
+	m := &blockModel{
+		names: []string{"a", "b"},
+		sizes: []int64{n, n},
+		probs: [][]float64{{0.005, 0.001}, {0, 0.005}},
+	}
+	dist, err := parseDegreeDist("powerlaw:2.5,1,100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRNG(rngPCG, 1)
+	m.correct(dist, r)
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	for i := range m.sizes {
+		var sum float64
+		for _, id := range m.order[i] {
+			sum += m.theta[id]
+		}
+		if math.Abs(sum-n) > 1e-6*n {
+			t.Errorf("block %v: unexpected propensity sum: %v", i, sum)
+		}
+	}
+
+	deg := make([]int, 2*n)
+	count := make(map[[2]int64]int)
+	seen := make(map[edge]bool)
+	for e := range m.graph(false, r, vlabel).edges {
+		if e.tail == e.head {
+			t.Errorf("unexpected loop: %v", e)
+		}
+		if seen[e] {
+			t.Errorf("multiple edge: %v", e)
+		}
+		seen[e] = true
+		deg[e.tail]++
+		deg[e.head]++
+		count[[2]int64{e.tail / n, e.head / n}]++
+	}
+
+	if got := count[[2]int64{1, 0}]; got != 0 {
+		t.Errorf("unexpected edges from b to a: %v", got)
+	}
+	// Capped probabilities lower the number of edges.
+	if got, want := float64(count[[2]int64{0, 0}]), 0.005*n*n; got < 0.7*want || got > 1.05*want {
+		t.Errorf("unexpected number of edges inside a: got: %v want: %v", got, want)
+	}
+
+	// The degrees are heavy-tailed, unlike the ones of the plain
+	// model, whose maximum is close to the mean.
+	var total, maxDeg int
+	for _, d := range deg[:n] {
+		total += d
+		maxDeg = max(maxDeg, d)
+	}
+	if mean := float64(total) / n; float64(maxDeg) < 5*mean {
+		t.Errorf("degrees are not heavy-tailed: max: %v mean: %v", maxDeg, mean)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"iter"
+	"math"
+)
+
+// A bloomFilter is a set of edges that may report false positives
+// but never false negatives.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    int
+}
+
+// newBloomFilter returns a Bloom filter sized to hold n edges with a
+// false positive rate of fp.
+func newBloomFilter(n int64, fp float64) *bloomFilter {
+	m, k := bloomParams(n, fp)
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// bloomParams returns the number of bits and hash functions of a
+// Bloom filter sized to hold n edges with a false positive rate of
+// fp.
+func bloomParams(n int64, fp float64) (m uint64, k int) {
+	n = max(n, 1)
+	m = uint64(math.Ceil(-float64(n) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k = int(math.Round(float64(m) / float64(n) * math.Ln2))
+	k = max(k, 1)
+	return m, k
+}
+
+// add adds e to the filter. It reports whether e was possibly
+// already in the filter.
+func (f *bloomFilter) add(e edge) bool {
+	h1 := mix64(mix64(uint64(e.tail)) ^ uint64(e.head))
+	h2 := mix64(h1) | 1
+
+	found := true
+	for i := range f.k {
+		bit := (h1 + uint64(i)*h2) % f.m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			found = false
+			f.bits[word] |= mask
+		}
+	}
+	return found
+}
+
+// mix64 is the splitmix64 finalizer.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// A bloomDedup removes duplicated edges from an edge stream using a
+// Bloom filter. Due to false positives, some unique edges may be
+// suppressed too.
+type bloomDedup struct {
+	filter     *bloomFilter
+	suppressed int
+}
+
+// edges returns the edges of seq that have not been seen before.
+func (d *bloomDedup) edges(seq iter.Seq[edge]) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		for e := range seq {
+			if d.filter.add(e) {
+				d.suppressed++
+				continue
+			}
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const (
+		n  int64 = 10000
+		fp       = 0.01
+	)
+
+	f := newBloomFilter(n, fp)
+	for i := range n {
+		f.add(edge{tail: i, head: i + 1})
+	}
+
+	for i := range n {
+		if !f.add(edge{tail: i, head: i + 1}) {
+			t.Fatalf("false negative: %v -> %v", i, i+1)
+		}
+	}
+
+	// Every probe is added to the filter, so keep the number of
+	// probes small compared to its capacity.
+	const probes = n / 10
+
+	fps := 0
+	for i := range probes {
+		if f.add(edge{tail: i + 1, head: i}) {
+			fps++
+		}
+	}
+	if rate := float64(fps) / float64(probes); rate > 2*fp {
+		t.Errorf("false positive rate too high: got: %v want: <= %v", rate, 2*fp)
+	}
+}
+
+func TestBloomDedup(t *testing.T) {
+	edges := []edge{{tail: 0, head: 1}, {tail: 1, head: 2}, {tail: 0, head: 1}, {tail: 2, head: 0}, {tail: 1, head: 2}}
+	want := []edge{{tail: 0, head: 1}, {tail: 1, head: 2}, {tail: 2, head: 0}}
+
+	d := &bloomDedup{filter: newBloomFilter(int64(len(edges)), 0.001)}
+	got := slices.Collect(d.edges(slices.Values(edges)))
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected edges: got: %v want: %v", got, want)
+	}
+	if d.suppressed != 2 {
+		t.Errorf("unexpected number of suppressed edges: got: %v want: 2", d.suppressed)
+	}
+}
+
+func TestBloomDedupModels(t *testing.T) {
+	generate := func(args ...string) *generation {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return gen
+	}
+
+	// Models that remove multiple edges exactly reject it.
+	for _, args := range [][]string{
+		{"-n=200", "-seed=1"},
+		{"-n=200", "-seed=1", "-out-degree=poisson:5"},
+		{"-n=200", "-seed=1", "-triad-formation=0.5"},
+	} {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(append(args, "-dedup=bloom")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := c.newGeneration(fs); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+
+	// Models that keep multiple edges are deduplicated.
+	gen := generate("-profile=graph500", "-scale=6", "-edgefactor=16", "-seed=1", "-dedup=bloom")
+	if gen.dedup == nil {
+		t.Fatal("missing Bloom filter")
+	}
+	seen := make(map[edge]bool)
+	for e := range gen.g.edges {
+		if seen[e] {
+			t.Errorf("multiple edge: %v", e)
+		}
+		seen[e] = true
+	}
+	if gen.dedup.suppressed == 0 {
+		t.Error("no suppressed edges")
+	}
+}
@@ -0,0 +1,370 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"slices"
+)
+
+// defaultMaxCanon is the default maximum number of vertices of the
+// graphs put in exact canonical form by the canon command.
+const defaultMaxCanon = 500
+
+// sortedOrder returns the new IDs of the vertices of d, which number
+// the vertices in order of increasing out-degree, then in-degree and
+// then label. Vertices with the same degrees and label are kept in
+// ID order.
+func sortedOrder(d *graphData) map[int64]int64 {
+	ids := d.vertexIDs()
+	labels := make(map[int64]string, len(d.vertices))
+	for _, v := range d.vertices {
+		labels[v.id] = v.label
+	}
+	degrees := make(map[int64][2]int, len(ids))
+	for _, e := range d.edges {
+		dt := degrees[e.tail]
+		dt[0]++
+		degrees[e.tail] = dt
+		dh := degrees[e.head]
+		dh[1]++
+		degrees[e.head] = dh
+	}
+	slices.SortStableFunc(ids, func(a, b int64) int {
+		if c := cmp.Compare(degrees[a][0], degrees[b][0]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(degrees[a][1], degrees[b][1]); c != 0 {
+			return c
+		}
+		return cmp.Compare(labels[a], labels[b])
+	})
+	order := make(map[int64]int64, len(ids))
+	for i, id := range ids {
+		order[id] = int64(i)
+	}
+	return order
+}
+
+// A canonizer computes the canonical form of a small graph by
+// individualization and refinement. Vertices are indexes in [0, n).
+type canonizer struct {
+	n       int
+	out, in [][]int
+	edges   [][2]int
+	gens    [][]int // automorphisms found so far
+
+	// first and best are the labelings of the first leaf and of
+	// the best leaf found so far, along with their encodings and
+	// the individualized vertices that lead to them.
+	first, best         []int
+	firstEnc, bestEnc   [][2]int
+	firstPath, bestPath []int
+}
+
+// canonicalOrder returns the new IDs of the vertices of d that put its
+// structure in canonical form: two graphs are isomorphic if and only
+// if they have the same edges once renumbered. Labels and attributes
+// are ignored.
+func canonicalOrder(d *graphData) map[int64]int64 {
+	ids := d.vertexIDs()
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	c := &canonizer{n: len(ids), out: make([][]int, len(ids)), in: make([][]int, len(ids))}
+	for _, e := range d.edges {
+		t, h := index[e.tail], index[e.head]
+		c.out[t] = append(c.out[t], h)
+		c.in[h] = append(c.in[h], t)
+		c.edges = append(c.edges, [2]int{t, h})
+	}
+
+	c.search(c.refine(make([]int, c.n)), nil)
+
+	order := make(map[int64]int64, len(ids))
+	for i, id := range ids {
+		order[id] = int64(c.best[i])
+	}
+	return order
+}
+
+// refine returns the coarsest equitable refinement of the coloring
+// colors. Colors are numbered from 0 in an order that only depends on
+// the structure of the graph, so isomorphic vertices get the same
+// color.
+func (c *canonizer) refine(colors []int) []int {
+	type signature struct {
+		v       int
+		color   int
+		out, in []int
+	}
+	ncolors := -1
+	for {
+		sigs := make([]signature, c.n)
+		for v := range c.n {
+			sig := signature{v: v, color: colors[v]}
+			for _, w := range c.out[v] {
+				sig.out = append(sig.out, colors[w])
+			}
+			for _, w := range c.in[v] {
+				sig.in = append(sig.in, colors[w])
+			}
+			slices.Sort(sig.out)
+			slices.Sort(sig.in)
+			sigs[v] = sig
+		}
+		compare := func(a, b signature) int {
+			if c := cmp.Compare(a.color, b.color); c != 0 {
+				return c
+			}
+			if c := slices.Compare(a.out, b.out); c != 0 {
+				return c
+			}
+			return slices.Compare(a.in, b.in)
+		}
+		slices.SortFunc(sigs, compare)
+
+		next := make([]int, c.n)
+		color := 0
+		for i, sig := range sigs {
+			if i > 0 && compare(sigs[i-1], sig) != 0 {
+				color++
+			}
+			next[sig.v] = color
+		}
+		colors = next
+		if color == ncolors {
+			return colors
+		}
+		ncolors = color
+	}
+}
+
+// search explores the search tree rooted at the equitable coloring
+// colors, reached by individualizing the vertices in prefix. If a leaf
+// equivalent to the first or the best leaf is found, the subtrees
+// below their common ancestor are equivalent, so search returns the
+// depth of the ancestor to resume the search there. Otherwise, it
+// returns len(prefix).
+func (c *canonizer) search(colors []int, prefix []int) int {
+	// The target cell is the first color shared by several
+	// vertices.
+	size := make([]int, c.n)
+	for _, color := range colors {
+		size[color]++
+	}
+	target := slices.IndexFunc(size, func(s int) bool { return s > 1 })
+	if target < 0 {
+		return c.leaf(colors, prefix)
+	}
+
+	// Children in the same orbit of an explored child, under the
+	// automorphisms that fix prefix, are skipped. The orbits are
+	// updated when new automorphisms are found.
+	var (
+		explored []int
+		orbit    []int
+		ngens    = -1
+	)
+	for v := range c.n {
+		if colors[v] != target {
+			continue
+		}
+		if len(explored) > 0 {
+			if ngens != len(c.gens) {
+				orbit, ngens = c.orbits(prefix), len(c.gens)
+			}
+			if slices.ContainsFunc(explored, func(w int) bool { return orbit[w] == orbit[v] }) {
+				continue
+			}
+		}
+		explored = append(explored, v)
+
+		// Individualize v, giving it a color of its own that
+		// precedes the rest of its cell.
+		next := make([]int, c.n)
+		for w, color := range colors {
+			next[w] = color
+			if color > target || color == target && w != v {
+				next[w]++
+			}
+		}
+		if depth := c.search(c.refine(next), append(prefix, v)); depth < len(prefix) {
+			return depth
+		}
+	}
+	return len(prefix)
+}
+
+// orbits returns the representative of the orbit of every vertex
+// under the automorphisms found so far that fix every vertex of
+// prefix.
+func (c *canonizer) orbits(prefix []int) []int {
+	parent := make([]int, c.n)
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	for _, g := range c.gens {
+		if slices.ContainsFunc(prefix, func(p int) bool { return g[p] != p }) {
+			continue
+		}
+		for x, y := range g {
+			parent[find(x)] = find(y)
+		}
+	}
+	for x := range parent {
+		parent[x] = find(x)
+	}
+	return parent
+}
+
+// leaf processes the labeling given by the discrete coloring colors,
+// reached by individualizing the vertices in path. It returns the
+// depth at which the search must resume, like search.
+func (c *canonizer) leaf(colors []int, path []int) int {
+	enc := make([][2]int, len(c.edges))
+	for i, e := range c.edges {
+		enc[i] = [2]int{colors[e[0]], colors[e[1]]}
+	}
+	slices.SortFunc(enc, func(a, b [2]int) int { return slices.Compare(a[:], b[:]) })
+	compareEnc := func(a, b [][2]int) int {
+		return slices.CompareFunc(a, b, func(x, y [2]int) int { return slices.Compare(x[:], y[:]) })
+	}
+
+	// common returns the length of the common prefix of path and
+	// p.
+	common := func(p []int) int {
+		i := 0
+		for i < len(path) && i < len(p) && path[i] == p[i] {
+			i++
+		}
+		return i
+	}
+
+	path = slices.Clone(path)
+	switch {
+	case c.first == nil:
+		c.first, c.firstEnc, c.firstPath = colors, enc, path
+		c.best, c.bestEnc, c.bestPath = colors, enc, path
+	case compareEnc(enc, c.firstEnc) == 0:
+		c.gens = append(c.gens, automorphism(c.first, colors))
+		return common(c.firstPath)
+	case compareEnc(enc, c.bestEnc) == 0:
+		c.gens = append(c.gens, automorphism(c.best, colors))
+		return common(c.bestPath)
+	case compareEnc(enc, c.bestEnc) < 0:
+		c.best, c.bestEnc, c.bestPath = colors, enc, path
+	}
+	return len(path)
+}
+
+// automorphism returns the automorphism that maps every vertex
+// labeled by the labeling a to the vertex with the same label in the
+// labeling b.
+func automorphism(a, b []int) []int {
+	inv := make([]int, len(b))
+	for v, l := range b {
+		inv[l] = v
+	}
+	g := make([]int, len(a))
+	for v, l := range a {
+		g[v] = inv[l]
+	}
+	return g
+}
+
+// renumber returns the elements of d with their vertices renumbered
+// following order, the vertices sorted by new ID and the edges by new
+// tail and head. If bare is true, vertices are labeled with their new
+// IDs, attributes are dropped and every vertex is declared.
+func renumber(d *graphData, order map[int64]int64, bare bool, width int) graph {
+	var vertices []vertex
+	if bare {
+		for _, id := range order {
+			vertices = append(vertices, vertex{id: id, label: label(nil, id, width)})
+		}
+	} else {
+		for _, v := range d.vertices {
+			v.id = order[v.id]
+			vertices = append(vertices, v)
+		}
+	}
+	slices.SortStableFunc(vertices, func(a, b vertex) int { return cmp.Compare(a.id, b.id) })
+
+	edges := make([]edge, 0, len(d.edges))
+	for _, e := range d.edges {
+		ne := edge{tail: order[e.tail], head: order[e.head]}
+		if !bare {
+			ne.etype, ne.time = e.etype, e.time
+		}
+		edges = append(edges, ne)
+	}
+	slices.SortStableFunc(edges, compareEdges)
+	return graph{vertices: slices.Values(vertices), edges: slices.Values(edges)}
+}
+
+// compareEdges orders edges by tail, head, type and time.
+func compareEdges(a, b edge) int {
+	if c := cmp.Compare(a.tail, b.tail); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.head, b.head); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.etype, b.etype); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.time, b.time)
+}
+
+func runCanon(args []string) {
+	fs := flag.NewFlagSet("canon", flag.ExitOnError)
+	exact := fs.Bool("exact", false, "compute the exact canonical form of the structure")
+	maxVertices := fs.Int("max-vertices", defaultMaxCanon, "maximum number of `vertices` of the input graph with -exact")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "canon [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	d, err := loadGraph(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+
+	var order map[int64]int64
+	if *exact {
+		if n := len(d.vertexIDs()); n > *maxVertices {
+			fatal(fmt.Errorf("graph too large: %v vertices (max %v)", n, *maxVertices))
+		}
+		order = canonicalOrder(d)
+	} else {
+		order = sortedOrder(d)
+	}
+
+	g := renumber(d, order, *exact, of.idWidth)
+	err = of.write(func(enc encoder) error {
+		for v := range g.vertices {
+			enc.vertex(v)
+		}
+		for e := range g.edges {
+			enc.edge(e)
+		}
+		return nil
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
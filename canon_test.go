@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// canonEdges returns the edges of the graph in input once renumbered
+// with order.
+func canonEdges(t *testing.T, input string, exact bool) []edge {
+	t.Helper()
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := sortedOrder(d)
+	if exact {
+		order = canonicalOrder(d)
+	}
+	return slices.Collect(renumber(d, order, exact, 0).edges)
+}
+
+func TestSortedOrder(t *testing.T) {
+	const input = "V: 0 c\nV: 1 b\nV: 2 a\nE: 0 1\nE: 0 2\nE: 1 2\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sortedOrder(d)
+	want := map[int64]int64{2: 0, 1: 1, 0: 2}
+	for id, n := range want {
+		if got[id] != n {
+			t.Errorf("vertex %v: got: %v want: %v", id, got[id], n)
+		}
+	}
+}
+
+func TestCanonicalOrder(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	graphs := []string{
+		// Empty graph, many automorphisms.
+		"V: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\nV: 4 E\nV: 5 F\nV: 6 G\nV: 7 H\n",
+		// Directed cycle.
+		"E: 0 1\nE: 1 2\nE: 2 3\nE: 3 4\nE: 4 5\nE: 5 0\n",
+		// Two disjoint 2-cycles and a loop.
+		"E: 0 1\nE: 1 0\nE: 2 3\nE: 3 2\nE: 4 4\nE: 0 4\n",
+	}
+	var b strings.Builder
+	for range 40 {
+		fmt.Fprintf(&b, "E: %v %v\n", r.IntN(12), r.IntN(12))
+	}
+	graphs = append(graphs, b.String())
+
+	for _, input := range graphs {
+		want := canonEdges(t, input, true)
+
+		// Shuffle the vertex IDs and the order of the records.
+		perm := r.Perm(12)
+		lines := strings.Split(strings.TrimSpace(input), "\n")
+		for i, line := range lines {
+			var kind string
+			var x, y int
+			if n, _ := fmt.Sscanf(line, "E: %d %d", &x, &y); n == 2 {
+				lines[i] = fmt.Sprintf("E: %v %v", perm[x], perm[y])
+				continue
+			}
+			fmt.Sscanf(line, "V: %d %s", &x, &kind)
+			lines[i] = fmt.Sprintf("V: %v %v", perm[x], kind)
+		}
+		r.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+		shuffled := strings.Join(lines, "\n") + "\n"
+
+		if got := canonEdges(t, shuffled, true); !slices.Equal(got, want) {
+			t.Errorf("%q: canonical forms differ:\ngot:  %v\nwant: %v", input, got, want)
+		}
+	}
+
+	// Non-isomorphic graphs with the same degrees.
+	a := canonEdges(t, "E: 0 1\nE: 1 0\nE: 2 3\nE: 3 2\n", true)
+	c := canonEdges(t, "E: 0 1\nE: 1 2\nE: 2 3\nE: 3 0\n", true)
+	if slices.Equal(a, c) {
+		t.Errorf("non-isomorphic graphs have the same canonical form: %v", a)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"time"
+)
+
+// A checkpoint is the state of an interrupted generation.
+type checkpoint struct {
+	// Meta is the metadata of the generation. It is used to check
+	// that the generation is resumed with the same parameters.
+	Meta string `json:"meta"`
+
+	// Tail is the generation ID of the first tail vertex whose
+	// edges have not been written.
+	Tail int64 `json:"tail"`
+
+	// Offset is the size of the output up to the edges of Tail.
+	Offset int64 `json:"offset"`
+
+	// Vertices and Edges are the number of vertices and edges
+	// written.
+	Vertices int64 `json:"vertices"`
+	Edges    int64 `json:"edges"`
+}
+
+// readCheckpoint reads the named checkpoint file.
+func readCheckpoint(name string) (*checkpoint, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %v: %w", name, err)
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint writes cp to the named file. The file is replaced
+// atomically, so it always holds a complete checkpoint.
+func writeCheckpoint(name string, cp *checkpoint) error {
+	out, err := createOutput(name, false, true)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(out).Encode(cp); err != nil {
+		out.abort()
+		return err
+	}
+	return out.commit()
+}
+
+// A checkpointer periodically writes checkpoints while the edges of a
+// graph are generated in tail order.
+type checkpointer struct {
+	name  string
+	every time.Duration
+	meta  string
+
+	// counter counts the written vertices and edges.
+	counter *counter
+
+	// sync flushes the output and returns its size. Checkpoints
+	// are not written if it is nil.
+	sync func() (int64, error)
+
+	last time.Time
+}
+
+// edges returns a copy of seq that writes a checkpoint before the
+// edges of a tail vertex if the last checkpoint is older than the
+// checkpoint interval. The edges of seq must be sorted by tail and
+// identified by generation IDs.
+func (cp *checkpointer) edges(seq iter.Seq[edge]) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		tail := int64(-1)
+		for e := range seq {
+			if e.tail != tail {
+				tail = e.tail
+				cp.mark(tail)
+			}
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// mark writes a checkpoint, if due, before the edges of tail are
+// written. Errors are reported as warnings, so generation goes on.
+func (cp *checkpointer) mark(tail int64) {
+	if cp.sync == nil || time.Since(cp.last) < cp.every {
+		return
+	}
+	cp.last = time.Now()
+
+	offset, err := cp.sync()
+	if err != nil {
+		warn("checkpoint", "error", err)
+		return
+	}
+	state := &checkpoint{
+		Meta:     cp.meta,
+		Tail:     tail,
+		Offset:   offset,
+		Vertices: cp.counter.vertices,
+		Edges:    cp.counter.edges,
+	}
+	if err := writeCheckpoint(cp.name, state); err != nil {
+		warn("checkpoint", "error", err)
+	}
+}
+
+// A countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
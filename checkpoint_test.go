@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheckpointFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "checkpoint")
+	want := checkpoint{Meta: "mkdigraph -n=10", Tail: 3, Offset: 42, Vertices: 10, Edges: 7}
+
+	if err := writeCheckpoint(name, &want); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	got, err := readCheckpoint(name)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if *got != want {
+		t.Errorf("unexpected checkpoint: got: %+v want: %+v", *got, want)
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "checkpoint")
+	edges := []edge{{tail: 0, head: 1}, {tail: 0, head: 2}, {tail: 2, head: 0}, {tail: 3, head: 1}}
+
+	cnt := &counter{}
+	var offset int64
+	cp := &checkpointer{
+		name:    name,
+		meta:    "meta",
+		counter: cnt,
+		sync:    func() (int64, error) { return offset, nil },
+	}
+
+	var tails []int64
+	for e := range cp.edges(slices.Values(edges)) {
+		got, err := readCheckpoint(name)
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if got.Tail == e.tail && !slices.Contains(tails, e.tail) {
+			tails = append(tails, e.tail)
+			if got.Edges != cnt.edges || got.Offset != offset {
+				t.Errorf("unexpected checkpoint before edge %v: %+v", e, got)
+			}
+		}
+		cnt.edges++
+		offset += 7
+	}
+	if want := []int64{0, 2, 3}; !slices.Equal(tails, want) {
+		t.Errorf("unexpected checkpointed tails: got: %v want: %v", tails, want)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"iter"
+	"math/rand/v2"
+	"slices"
+)
+
+// A triadCloser adds triangle-closing edges to an edge stream. After
+// every edge u -> v, with probability p, it adds the edge u -> w,
+// where w is a random out-neighbor of v.
+//
+// A triadCloser keeps the adjacency list of the graph in memory.
+type triadCloser struct {
+	p          float64
+	loops      bool
+	multiedges bool
+	out        map[int64][]int64
+	rng        *rand.Rand
+}
+
+// newTriadCloser returns a triadCloser that closes triangles with
+// probability p, using r as the source of randomness. loops and
+// multiedges control whether the added edges can be loops or duplicate
+// existing edges.
+func newTriadCloser(p float64, loops, multiedges bool, r *rand.Rand) *triadCloser {
+	return &triadCloser{
+		p:          p,
+		loops:      loops,
+		multiedges: multiedges,
+		out:        make(map[int64][]int64),
+		rng:        r,
+	}
+}
+
+// edges returns seq interleaved with the triangle-closing edges.
+func (c *triadCloser) edges(seq iter.Seq[edge]) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		for e := range seq {
+			// The edges of seq may duplicate a previously added
+			// triangle-closing edge.
+			if !c.multiedges && slices.Contains(c.out[e.tail], e.head) {
+				continue
+			}
+			c.out[e.tail] = append(c.out[e.tail], e.head)
+			if !yield(e) {
+				return
+			}
+
+			if c.rng.Float64() >= c.p {
+				continue
+			}
+			e, ok := c.close(e)
+			if !ok {
+				continue
+			}
+			c.out[e.tail] = append(c.out[e.tail], e.head)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// close returns an edge that closes a triangle with e. It returns
+// false if there is no valid candidate.
+func (c *triadCloser) close(e edge) (edge, bool) {
+	nbs := c.out[e.head]
+	if len(nbs) == 0 {
+		return edge{}, false
+	}
+	w := nbs[c.rng.IntN(len(nbs))]
+	if w == e.tail && !c.loops {
+		return edge{}, false
+	}
+	if !c.multiedges && slices.Contains(c.out[e.tail], w) {
+		return edge{}, false
+	}
+	return edge{tail: e.tail, head: w}, true
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTriadCloser(t *testing.T) {
+	edges := []edge{{tail: 1, head: 0}, {tail: 2, head: 1}, {tail: 3, head: 1}}
+	want := []edge{{tail: 1, head: 0}, {tail: 2, head: 1}, {tail: 2, head: 0}, {tail: 3, head: 1}, {tail: 3, head: 0}}
+
+	c := newTriadCloser(1, false, false, newRNG(rngPCG, 0))
+	got := slices.Collect(c.edges(slices.Values(edges)))
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected edges: got: %v want: %v", got, want)
+	}
+}
+
+func TestTriadCloserLoops(t *testing.T) {
+	edges := []edge{{tail: 0, head: 1}, {tail: 1, head: 0}}
+
+	tests := []struct {
+		loops bool
+		want  []edge
+	}{
+		{
+			loops: false,
+			want:  []edge{{tail: 0, head: 1}, {tail: 1, head: 0}},
+		},
+		{
+			loops: true,
+			want:  []edge{{tail: 0, head: 1}, {tail: 1, head: 0}, {tail: 1, head: 1}},
+		},
+	}
+	for _, tt := range tests {
+		c := newTriadCloser(1, tt.loops, false, newRNG(rngPCG, 0))
+		got := slices.Collect(c.edges(slices.Values(edges)))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("unexpected edges (loops=%v): got: %v want: %v", tt.loops, got, tt.want)
+		}
+	}
+}
+
+func TestTriadCloserDuplicates(t *testing.T) {
+	// The edge 2 -> 0 closes a triangle before the stream
+	// duplicates it.
+	edges := []edge{{tail: 1, head: 0}, {tail: 2, head: 1}, {tail: 2, head: 0}}
+	want := []edge{{tail: 1, head: 0}, {tail: 2, head: 1}, {tail: 2, head: 0}}
+
+	c := newTriadCloser(1, false, false, newRNG(rngPCG, 0))
+	got := slices.Collect(c.edges(slices.Values(edges)))
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected edges: got: %v want: %v", got, want)
+	}
+}
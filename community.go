@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+)
+
+// readCommunities reads a communities file. Every line contains a
+// vertex ID and a community label separated by whitespace. Empty
+// lines and lines starting with "#" are ignored.
+func readCommunities(name string) (map[int64]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	comms := make(map[int64]string)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%v:%v: malformed line: %q", name, n, line)
+		}
+		id, err := parseID(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", name, n, err)
+		}
+		if _, ok := comms[id]; ok {
+			return nil, fmt.Errorf("%v:%v: duplicated vertex: %v", name, n, id)
+		}
+		comms[id] = fields[1]
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return comms, nil
+}
+
+// withCommunities returns a copy of g where every vertex is assigned
+// its community in comms and edges between vertices of different
+// communities are kept with probability mixing. Vertices missing from
+// comms do not belong to any community. r is the source of
+// randomness.
+func withCommunities(g graph, comms map[int64]string, mixing float64, r *rand.Rand) graph {
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			v.community = comms[v.id]
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			ct, ok := comms[e.tail]
+			if ch := comms[e.head]; !ok || ct != ch {
+				if r.Float64() >= mixing {
+					continue
+				}
+			}
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
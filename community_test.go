@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadCommunities(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "communities")
+	data := "# vertex community\n0 a\n1\ta\n\n2 b\n"
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readCommunities(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[int64]string{0: "a", 1: "a", 2: "b"}
+	if !maps.Equal(got, want) {
+		t.Errorf("unexpected communities: got: %v want: %v", got, want)
+	}
+}
+
+func TestReadCommunitiesInvalid(t *testing.T) {
+	inputs := []string{
+		"0\n",
+		"0 a b\n",
+		"x a\n",
+		"-1 a\n",
+		"0 a\n0 b\n",
+	}
+	for _, input := range inputs {
+		name := filepath.Join(t.TempDir(), "communities")
+		if err := os.WriteFile(name, []byte(input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readCommunities(name); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestWithCommunities(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}, {id: 2, label: "C"}, {id: 3, label: "D"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}, {tail: 1, head: 2}, {tail: 2, head: 2}, {tail: 3, head: 0}}),
+	}
+	comms := map[int64]string{0: "a", 1: "a", 2: "b"}
+
+	g = withCommunities(g, comms, 0, newRNG(rngPCG, 0))
+
+	gotVertices := slices.Collect(g.vertices)
+	wantVertices := []vertex{
+		{id: 0, label: "A", community: "a"},
+		{id: 1, label: "B", community: "a"},
+		{id: 2, label: "C", community: "b"},
+		{id: 3, label: "D"},
+	}
+	if !slices.Equal(gotVertices, wantVertices) {
+		t.Errorf("unexpected vertices: got: %v want: %v", gotVertices, wantVertices)
+	}
+
+	gotEdges := slices.Collect(g.edges)
+	wantEdges := []edge{{tail: 0, head: 1}, {tail: 2, head: 2}}
+	if !slices.Equal(gotEdges, wantEdges) {
+		t.Errorf("unexpected edges: got: %v want: %v", gotEdges, wantEdges)
+	}
+}
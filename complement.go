@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+)
+
+// defaultMaxComplement is the default maximum number of vertices of
+// the graphs complemented by the complement command. The complement
+// of a sparse graph with n vertices has about n^2 edges.
+const defaultMaxComplement = 10000
+
+// complement returns the directed complement of d, which has the same
+// vertices and the edges between the vertices in ids that are not in
+// d. ids must be sorted. Loops are only added if loops is true. The
+// edges are sorted by tail and head.
+func complement(d *graphData, ids []int64, loops bool) graph {
+	seen := make(map[edge]struct{}, len(d.edges))
+	for _, e := range d.edges {
+		seen[edge{tail: e.tail, head: e.head}] = struct{}{}
+	}
+
+	edges := func(yield func(edge) bool) {
+		for _, tail := range ids {
+			for _, head := range ids {
+				if tail == head && !loops {
+					continue
+				}
+				e := edge{tail: tail, head: head}
+				if _, ok := seen[e]; ok {
+					continue
+				}
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+	return graph{vertices: slices.Values(d.vertices), edges: edges}
+}
+
+func runComplement(args []string) {
+	fs := flag.NewFlagSet("complement", flag.ExitOnError)
+	loops := fs.Bool("loops", false, "add the missing loops")
+	maxVertices := fs.Int("max-vertices", defaultMaxComplement, "maximum number of `vertices` of the input graph")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "complement [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	d, err := loadGraph(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+	ids := d.vertexIDs()
+	if len(ids) > *maxVertices {
+		fatal(fmt.Errorf("graph too large: %v vertices (max %v)", len(ids), *maxVertices))
+	}
+
+	g := complement(d, ids, *loops)
+	err = of.write(func(enc encoder) error {
+		for v := range g.vertices {
+			enc.vertex(v)
+		}
+		for e := range g.edges {
+			enc.edge(e)
+		}
+		return nil
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
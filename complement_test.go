@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestComplement(t *testing.T) {
+	const input = "V: 0 A\nV: 1 B\nE: 0 1\nE: 1 2\nE: 1 1\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := d.vertexIDs()
+	if want := []int64{0, 1, 2}; !slices.Equal(ids, want) {
+		t.Fatalf("unexpected vertex IDs: got: %v want: %v", ids, want)
+	}
+
+	tests := []struct {
+		loops bool
+		want  []edge
+	}{
+		{
+			loops: false,
+			want:  []edge{{tail: 0, head: 2}, {tail: 1, head: 0}, {tail: 2, head: 0}, {tail: 2, head: 1}},
+		},
+		{
+			loops: true,
+			want: []edge{
+				{tail: 0, head: 0}, {tail: 0, head: 2}, {tail: 1, head: 0},
+				{tail: 2, head: 0}, {tail: 2, head: 1}, {tail: 2, head: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		g := complement(d, ids, tt.loops)
+		if got := slices.Collect(g.edges); !slices.Equal(got, tt.want) {
+			t.Errorf("loops=%v: unexpected edges: got: %v want: %v", tt.loops, got, tt.want)
+		}
+		if got := slices.Collect(g.vertices); !slices.Equal(got, d.vertices) {
+			t.Errorf("loops=%v: unexpected vertices: got: %v want: %v", tt.loops, got, d.vertices)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// flagValues are the values suggested by shell completion for the
+// flags that accept a fixed set of values.
+var flagValues = map[string][]string{
+	"dedup":           {"none", "bloom"},
+	"emit":            {"vertices", "edges", "both"},
+	"log":             {"text", "json"},
+	"words-encoding":  {"utf-8", "latin1"},
+	"out-degree":      {"const:", "uniform:", "binomial:", "poisson:", "powerlaw:"},
+	"in-degree":       {"const:", "uniform:", "binomial:", "poisson:", "powerlaw:"},
+	"community-sizes": {"const:", "uniform:", "binomial:", "poisson:", "powerlaw:"},
+	"propensity":      {"const:", "uniform:", "binomial:", "poisson:", "powerlaw:"},
+	"locality":        {"geometric:", "powerlaw:"},
+	"rng":             {"pcg", "chacha8", "mt19937"},
+	"checksum":        {"sha256"},
+	"profile":         {"graph500", "ldbc"},
+}
+
+// fileFlags are the flags whose values are file paths.
+var fileFlags = map[string]bool{
+	"o":            true,
+	"words":        true,
+	"communities":  true,
+	"trials-file":  true,
+	"blocks":       true,
+	"snapshot-dir": true,
+	"checkpoint":   true,
+	"cpuprofile":   true,
+	"memprofile":   true,
+	"manifest":     true,
+}
+
+// shells are the shells supported by the completion command.
+var shells = []string{"bash", "zsh", "fish"}
+
+// writeCompletion writes the completion script for the provided
+// shell. The script completes the flags of fs and the names of cmds.
+func writeCompletion(w io.Writer, shell string, fs *flag.FlagSet, cmds []string) error {
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, f)
+	})
+
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, flags, cmds)
+	case "zsh":
+		writeZshCompletion(w, flags, cmds)
+	case "fish":
+		writeFishCompletion(w, flags, cmds)
+	default:
+		return fmt.Errorf("unsupported shell: %q", shell)
+	}
+	return nil
+}
+
+func writeBashCompletion(w io.Writer, flags []*flag.Flag, cmds []string) {
+	var names, bools []string
+	for _, f := range flags {
+		names = append(names, "-"+f.Name)
+		if isBoolFlag(f) {
+			bools = append(bools, "-"+f.Name)
+		}
+	}
+
+	fmt.Fprintln(w, "# bash completion for mkdigraph")
+	fmt.Fprintln(w, "_mkdigraph() {")
+	fmt.Fprintln(w, "\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\"")
+	fmt.Fprintln(w, "\tlocal prev=\"${COMP_WORDS[COMP_CWORD-1]}\"")
+	fmt.Fprintln(w, "\tif [[ $COMP_CWORD -eq 2 && $prev == completion ]]; then")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(shells, " "))
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "\tcase $prev in")
+	for _, f := range flags {
+		switch {
+		case fileFlags[f.Name]:
+			fmt.Fprintf(w, "\t-%v)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\treturn\n\t\t;;\n", f.Name)
+		case flagValues[f.Name] != nil:
+			values := strings.Join(flagValues[f.Name], " ")
+			fmt.Fprintf(w, "\t-%v)\n\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\treturn\n\t\t;;\n", f.Name, values)
+		case !isBoolFlag(f):
+			fmt.Fprintf(w, "\t-%v)\n\t\treturn\n\t\t;;\n", f.Name)
+		}
+	}
+	fmt.Fprintln(w, "\tesac")
+	if len(cmds) > 0 {
+		fmt.Fprintln(w, "\tif [[ $COMP_CWORD -eq 1 ]]; then")
+		fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(append(names, cmds...), " "))
+		fmt.Fprintln(w, "\t\treturn")
+		fmt.Fprintln(w, "\tfi")
+	}
+	fmt.Fprintf(w, "\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _mkdigraph mkdigraph")
+}
+
+func writeZshCompletion(w io.Writer, flags []*flag.Flag, cmds []string) {
+	fmt.Fprintln(w, "#compdef mkdigraph")
+	fmt.Fprintln(w, "_arguments \\")
+	for _, f := range flags {
+		name, usage := flag.UnquoteUsage(f)
+		spec := fmt.Sprintf("-%v[%v]", f.Name, zshEscape(usage))
+		switch {
+		case fileFlags[f.Name]:
+			spec += ":file:_files"
+		case flagValues[f.Name] != nil:
+			spec += ":" + zshEscape(name) + ":(" + strings.Join(flagValues[f.Name], " ") + ")"
+		case !isBoolFlag(f):
+			spec += ":" + zshEscape(name) + ":"
+		}
+		fmt.Fprintf(w, "\t'%v' \\\n", spec)
+	}
+	fmt.Fprintf(w, "\t'1::command:(%v)' \\\n", strings.Join(cmds, " "))
+	fmt.Fprintf(w, "\t'2::shell:(%v)'\n", strings.Join(shells, " "))
+}
+
+// zshEscape escapes s so it can be used in a single-quoted
+// _arguments specification.
+func zshEscape(s string) string {
+	r := strings.NewReplacer(
+		"'", `'\''`,
+		"[", `\[`,
+		"]", `\]`,
+		":", `\:`,
+	)
+	return r.Replace(s)
+}
+
+func writeFishCompletion(w io.Writer, flags []*flag.Flag, cmds []string) {
+	fmt.Fprintln(w, "# fish completion for mkdigraph")
+	for _, f := range flags {
+		_, usage := flag.UnquoteUsage(f)
+		line := fmt.Sprintf("complete -c mkdigraph -o %v -d %v", f.Name, fishQuote(usage))
+		switch {
+		case fileFlags[f.Name]:
+			line += " -r -F"
+		case flagValues[f.Name] != nil:
+			line += " -x -a " + fishQuote(strings.Join(flagValues[f.Name], " "))
+		case !isBoolFlag(f):
+			line += " -x"
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "complete -c mkdigraph -n '__fish_use_subcommand' -x -a %v\n", fishQuote(strings.Join(cmds, " ")))
+	fmt.Fprintf(w, "complete -c mkdigraph -n '__fish_seen_subcommand_from completion' -x -a %v\n", fishQuote(strings.Join(shells, " ")))
+}
+
+// fishQuote returns s as a single-quoted fish string.
+func fishQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "'", `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+// isBoolFlag reports whether f is a boolean flag.
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+func runCompletion(args []string) {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "completion bash|zsh|fish")
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var cmds []string
+	for _, cmd := range commands {
+		cmds = append(cmds, cmd.name)
+	}
+
+	var c genConfig
+	if err := writeCompletion(os.Stdout, fs.Arg(0), c.flagSet("generate"), cmds); err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestWriteCompletion(t *testing.T) {
+	fs := flag.NewFlagSet("mkdigraph", flag.ContinueOnError)
+	fs.Int("n", 25, "number of vertices")
+	fs.Bool("loops", false, "allow loops")
+	fs.String("dedup", "none", "edge deduplication `mode`")
+	fs.String("o", "", "output file")
+	cmds := []string{"generate", "completion"}
+
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{
+			shell: "bash",
+			want: []string{
+				"complete -F _mkdigraph mkdigraph",
+				`COMPREPLY=($(compgen -W "none bloom" -- "$cur"))`,
+				`COMPREPLY=($(compgen -W "-dedup -loops -n -o generate completion" -- "$cur"))`,
+				`COMPREPLY=($(compgen -W "-dedup -loops -n -o" -- "$cur"))`,
+			},
+		},
+		{
+			shell: "zsh",
+			want: []string{
+				"#compdef mkdigraph",
+				"'-dedup[edge deduplication mode]:mode:(none bloom)'",
+				"'-loops[allow loops]'",
+				"'-o[output file]:file:_files'",
+				"'1::command:(generate completion)'",
+			},
+		},
+		{
+			shell: "fish",
+			want: []string{
+				"complete -c mkdigraph -o dedup -d 'edge deduplication mode' -x -a 'none bloom'",
+				"complete -c mkdigraph -o loops -d 'allow loops'\n",
+				"complete -c mkdigraph -o o -d 'output file' -r -F",
+				"complete -c mkdigraph -n '__fish_use_subcommand' -x -a 'generate completion'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		if err := writeCompletion(buf, tt.shell, fs, cmds); err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.shell, err)
+		}
+		out := buf.String()
+		for _, s := range tt.want {
+			if !strings.Contains(out, s) {
+				t.Errorf("%v: missing %q in:\n%v", tt.shell, s, out)
+			}
+		}
+	}
+
+	if err := writeCompletion(&bytes.Buffer{}, "tcsh", fs, cmds); err == nil {
+		t.Error("expected error for unsupported shell")
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"flag"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+)
+
+// weakComponents are the weakly connected components of a graph.
+// Components are numbered from 0 by decreasing number of vertices,
+// and components of the same size by their lowest vertex ID.
+type weakComponents struct {
+	// elems are the elements of the graph in input order.
+	elems []element
+
+	// comp is the component of every vertex, including the
+	// undeclared edge endpoints.
+	comp map[int64]int
+
+	// vertices and edges are the number of vertices and edges of
+	// every component.
+	vertices, edges []int64
+}
+
+// findWeakComponents returns the weakly connected components of the
+// graph read from seq. The whole graph is kept in memory.
+func findWeakComponents(seq iter.Seq2[element, error]) (weakComponents, error) {
+	var wc weakComponents
+
+	// parent and size form a disjoint-set forest of the vertices.
+	parent := make(map[int64]int64)
+	size := make(map[int64]int64)
+	find := func(id int64) int64 {
+		if _, ok := parent[id]; !ok {
+			parent[id] = id
+			size[id] = 1
+		}
+		for parent[id] != id {
+			parent[id] = parent[parent[id]]
+			id = parent[id]
+		}
+		return id
+	}
+	for elem, err := range seq {
+		if err != nil {
+			return weakComponents{}, err
+		}
+		wc.elems = append(wc.elems, elem)
+		if !elem.isEdge {
+			find(elem.v.id)
+			continue
+		}
+		a, b := find(elem.e.tail), find(elem.e.head)
+		if a == b {
+			continue
+		}
+		if size[a] < size[b] {
+			a, b = b, a
+		}
+		parent[b] = a
+		size[a] += size[b]
+		delete(size, b)
+	}
+
+	// low is the lowest vertex ID of every component.
+	low := make(map[int64]int64)
+	for id := range parent {
+		root := find(id)
+		if l, ok := low[root]; !ok || id < l {
+			low[root] = id
+		}
+	}
+	roots := make([]int64, 0, len(size))
+	for root := range size {
+		roots = append(roots, root)
+	}
+	slices.SortFunc(roots, func(a, b int64) int {
+		if c := cmp.Compare(size[b], size[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(low[a], low[b])
+	})
+
+	index := make(map[int64]int, len(roots))
+	wc.vertices = make([]int64, len(roots))
+	wc.edges = make([]int64, len(roots))
+	for i, root := range roots {
+		index[root] = i
+		wc.vertices[i] = size[root]
+	}
+	wc.comp = make(map[int64]int, len(parent))
+	for id := range parent {
+		wc.comp[id] = index[find(id)]
+	}
+	for _, elem := range wc.elems {
+		if elem.isEdge {
+			wc.edges[wc.comp[elem.e.tail]]++
+		}
+	}
+	return wc, nil
+}
+
+// elements returns an iterator over the elements of the i-th
+// component, in input order.
+func (wc weakComponents) elements(i int) iter.Seq[element] {
+	return func(yield func(element) bool) {
+		for _, elem := range wc.elems {
+			id := elem.v.id
+			if elem.isEdge {
+				id = elem.e.tail
+			}
+			if wc.comp[id] != i {
+				continue
+			}
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// componentName returns the name of the file of the i-th of k
+// components in dir. Components are numbered from 1.
+func componentName(dir string, i, k int, dot bool) string {
+	ext := ".txt"
+	if dot {
+		ext = ".dot"
+	}
+	width := max(3, len(strconv.Itoa(k)))
+	return filepath.Join(dir, fmt.Sprintf("component-%0*d%v", width, i, ext))
+}
+
+func runComponents(args []string) {
+	fs := flag.NewFlagSet("components", flag.ExitOnError)
+	dir := fs.String("dir", ".", "`directory` where components are written")
+	minSize := fs.Int64("min-size", 1, "drop components with less than `n` vertices")
+	statsOnly := fs.Bool("stats-only", false, "print the size of every component instead of writing it")
+	emitDOT := fs.Bool("dot", false, "emit DOT output")
+	dotStrict := fs.Bool("dot-strict", false, "declare the DOT graph strict")
+	dotName := fs.String("dot-name", "", "identifier of the DOT graph")
+	dotTooltip := fs.String("dot-tooltip", "", "`template` of the tooltips of the DOT nodes")
+	dotURL := fs.String("dot-url", "", "`template` of the URLs of the DOT nodes")
+	nul := fs.Bool("z", false, "terminate records with NUL instead of newline")
+	crlf := fs.Bool("crlf", false, "terminate lines with CRLF instead of LF")
+	idWidth := fs.Int("id-width", 0, "minimum `width` of vertex IDs")
+	fs.Usage = commandUsage(fs, "components [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *nul && *emitDOT {
+		fatal(fmt.Errorf("-z cannot be combined with -dot"))
+	}
+	if *nul && *crlf {
+		fatal(fmt.Errorf("-z cannot be combined with -crlf"))
+	}
+	if (*dotStrict || *dotName != "" || *dotTooltip != "" || *dotURL != "") && !*emitDOT {
+		fatal(fmt.Errorf("-dot-strict, -dot-name, -dot-tooltip and -dot-url require -dot"))
+	}
+	tooltipTmpl, urlTmpl, err := parseNodeTemplates(*dotTooltip, *dotURL)
+	if err != nil {
+		fatal(err)
+	}
+	if *idWidth < 0 || *idWidth > maxIDWidth {
+		fatal(fmt.Errorf("invalid ID width: %v", *idWidth))
+	}
+
+	wc, err := findWeakComponents(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+	k := 0
+	for k < len(wc.vertices) && wc.vertices[k] >= *minSize {
+		k++
+	}
+
+	if *statsOnly {
+		bw := bufio.NewWriter(os.Stdout)
+		for i := range k {
+			fmt.Fprintf(bw, "%v %v %v\n", i+1, wc.vertices[i], wc.edges[i])
+		}
+		if err := bw.Flush(); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	p := printer{idWidth: *idWidth, nul: *nul, crlf: *crlf, dotStrict: *dotStrict, dotName: *dotName, dotTooltip: tooltipTmpl, dotURL: urlTmpl}
+	for i := range k {
+		out, err := createOutput(componentName(*dir, i+1, k, *emitDOT), false, false)
+		if err != nil {
+			fatal(err)
+		}
+		bw := bufio.NewWriter(out)
+		enc := p.newEncoder(bw, *emitDOT)
+		enc.begin()
+		for elem := range wc.elements(i) {
+			if elem.isEdge {
+				enc.edge(elem.e)
+			} else {
+				enc.vertex(elem.v)
+			}
+		}
+		enc.end()
+		if err := bw.Flush(); err != nil {
+			out.abort()
+			fatal(err)
+		}
+		if err := out.commit(); err != nil {
+			fatal(err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindWeakComponents(t *testing.T) {
+	const input = "V: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\nV: 4 E\nV: 5 F\nE: 5 4\nE: 1 0\nE: 2 1\nE: 4 6\nE: 3 3\n"
+	wc, err := findWeakComponents(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []int64{3, 3, 1}; !slices.Equal(wc.vertices, want) {
+		t.Errorf("unexpected vertices: got: %v want: %v", wc.vertices, want)
+	}
+	if want := []int64{2, 2, 1}; !slices.Equal(wc.edges, want) {
+		t.Errorf("unexpected edges: got: %v want: %v", wc.edges, want)
+	}
+
+	var got []string
+	for elem := range wc.elements(1) {
+		if elem.isEdge {
+			got = append(got, "E")
+		} else {
+			got = append(got, "V")
+		}
+	}
+	if want := []string{"V", "V", "E", "E"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected elements: got: %v want: %v", got, want)
+	}
+}
+
+func TestComponentName(t *testing.T) {
+	tests := []struct {
+		i, k int
+		dot  bool
+		want string
+	}{
+		{i: 1, k: 5, dot: false, want: "out/component-001.txt"},
+		{i: 12, k: 1000, dot: true, want: "out/component-0012.dot"},
+	}
+	for _, tt := range tests {
+		if got := componentName("out", tt.i, tt.k, tt.dot); got != tt.want {
+			t.Errorf("componentName(%v, %v, %v): got: %v want: %v", tt.i, tt.k, tt.dot, got, tt.want)
+		}
+	}
+}
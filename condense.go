@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+)
+
+// A condensation is the graph of the strongly connected components of
+// a graph. Components are numbered in topological order, so every
+// edge of the condensation goes from a lower to a higher component.
+type condensation struct {
+	// members are the vertices of every component, sorted by ID.
+	members [][]int64
+
+	// out are the sorted out-neighbors of every component.
+	out [][]int64
+}
+
+// condense returns the condensation of the graph read from seq. The
+// components are computed with Tarjan's algorithm, visiting the
+// vertices in order of appearance and their out-neighbors in edge
+// order, so the result only depends on the input. The adjacency lists
+// of the graph are kept in memory.
+func condense(seq iter.Seq2[element, error]) (condensation, error) {
+	order, out, err := readAdjacency(seq)
+	if err != nil {
+		return condensation{}, err
+	}
+
+	var (
+		index   = make(map[int64]int, len(out))
+		low     = make(map[int64]int, len(out))
+		onStack = make(map[int64]bool, len(out))
+		stack   []int64
+		comps   [][]int64
+	)
+
+	// frame is a vertex on the search path and the index of its
+	// next out-neighbor.
+	type frame struct {
+		id   int64
+		next int
+	}
+
+	var path []frame
+	visit := func(id int64) {
+		index[id] = len(index)
+		low[id] = index[id]
+		onStack[id] = true
+		stack = append(stack, id)
+		path = append(path, frame{id: id})
+	}
+	for _, root := range order {
+		if _, ok := index[root]; ok {
+			continue
+		}
+		visit(root)
+		for len(path) > 0 {
+			f := &path[len(path)-1]
+			if f.next < len(out[f.id]) {
+				head := out[f.id][f.next]
+				f.next++
+				if _, ok := index[head]; !ok {
+					visit(head)
+				} else if onStack[head] {
+					low[f.id] = min(low[f.id], index[head])
+				}
+				continue
+			}
+
+			id := f.id
+			path = path[:len(path)-1]
+			if len(path) > 0 {
+				parent := path[len(path)-1].id
+				low[parent] = min(low[parent], low[id])
+			}
+			if low[id] != index[id] {
+				continue
+			}
+			i := len(stack) - 1
+			for stack[i] != id {
+				i--
+			}
+			comp := slices.Clone(stack[i:])
+			for _, v := range comp {
+				onStack[v] = false
+			}
+			stack = stack[:i]
+			slices.Sort(comp)
+			comps = append(comps, comp)
+		}
+	}
+
+	// Tarjan's algorithm finds the components in reverse
+	// topological order.
+	slices.Reverse(comps)
+	comp := make(map[int64]int, len(out))
+	for i, members := range comps {
+		for _, v := range members {
+			comp[v] = i
+		}
+	}
+	c := condensation{members: comps, out: make([][]int64, len(comps))}
+	for tail, heads := range out {
+		ct := comp[tail]
+		for _, head := range heads {
+			if ch := comp[head]; ch != ct {
+				c.out[ct] = append(c.out[ct], int64(ch))
+			}
+		}
+	}
+	for i := range c.out {
+		slices.Sort(c.out[i])
+		c.out[i] = slices.Compact(c.out[i])
+	}
+	return c, nil
+}
+
+// encode writes the condensation using enc. Components are labeled
+// with their IDs.
+func (c condensation) encode(enc encoder, width int) {
+	for id := range c.members {
+		enc.vertex(vertex{id: int64(id), label: label(nil, int64(id), width)})
+	}
+	for tail, heads := range c.out {
+		for _, head := range heads {
+			enc.edge(edge{tail: int64(tail), head: head})
+		}
+	}
+}
+
+// writeMembers writes the component of every vertex to w, one
+// "vertex component" pair per line, sorted by vertex ID.
+func (c condensation) writeMembers(w io.Writer) error {
+	type member struct{ id, comp int64 }
+	var members []member
+	for comp, ids := range c.members {
+		for _, id := range ids {
+			members = append(members, member{id: id, comp: int64(comp)})
+		}
+	}
+	slices.SortFunc(members, func(a, b member) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, m := range members {
+		fmt.Fprintln(bw, m.id, m.comp)
+	}
+	return bw.Flush()
+}
+
+func runCondense(args []string) {
+	fs := flag.NewFlagSet("condense", flag.ExitOnError)
+	membersFile := fs.String("members", "", "write the component of every vertex to `file`")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "condense [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	c, err := condense(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+
+	err = of.write(func(enc encoder) error {
+		c.encode(enc, of.idWidth)
+		return nil
+	})
+	if err != nil {
+		fatal(err)
+	}
+
+	if *membersFile == "" {
+		return
+	}
+	out, err := createOutput(*membersFile, false, false)
+	if err != nil {
+		fatal(err)
+	}
+	if err := c.writeMembers(out); err != nil {
+		out.abort()
+		fatal(err)
+	}
+	if err := out.commit(); err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCondense(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantMembers [][]int64
+		wantOut     [][]int64
+	}{
+		{
+			input:       "V: 0 A\nV: 1 B\nV: 2 C\nE: 0 1\nE: 1 2\n",
+			wantMembers: [][]int64{{0}, {1}, {2}},
+			wantOut:     [][]int64{{1}, {2}, nil},
+		},
+		{
+			input:       "V: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\nV: 4 E\nE: 3 4\nE: 0 1\nE: 1 0\nE: 1 2\nE: 2 3\nE: 3 2\nE: 0 3\nE: 2 2\n",
+			wantMembers: [][]int64{{0, 1}, {2, 3}, {4}},
+			wantOut:     [][]int64{{1}, {2}, nil},
+		},
+		{
+			input:       "V: 5 A\nV: 4 B\nE: 4 5\n",
+			wantMembers: [][]int64{{4}, {5}},
+			wantOut:     [][]int64{{1}, nil},
+		},
+	}
+	for _, tt := range tests {
+		c, err := condense(decodeSimple(strings.NewReader(tt.input)))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.members, tt.wantMembers) {
+			t.Errorf("%q: unexpected members: got: %v want: %v", tt.input, c.members, tt.wantMembers)
+		}
+		if !reflect.DeepEqual(c.out, tt.wantOut) {
+			t.Errorf("%q: unexpected edges: got: %v want: %v", tt.input, c.out, tt.wantOut)
+		}
+	}
+}
+
+func TestCondensationWriteMembers(t *testing.T) {
+	c := condensation{members: [][]int64{{3}, {0, 2}, {1}}}
+	var buf bytes.Buffer
+	if err := c.writeMembers(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := buf.String(), "0 1\n1 2\n2 1\n3 0\n"; got != want {
+		t.Errorf("unexpected members: got: %q want: %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+)
+
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	xlsx := fs.Bool("xlsx", false, "write an Excel workbook")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "convert [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *xlsx {
+		if of.emitDOT || of.nul {
+			fatal(errors.New("-xlsx cannot be combined with -dot or -z"))
+		}
+		d, err := loadGraph(decodeGraph(in))
+		if err != nil {
+			fatal(err)
+		}
+		out, err := createOutput(of.outFile, false, false)
+		if err != nil {
+			fatal(err)
+		}
+		bw := bufio.NewWriter(out)
+		if err := writeXLSX(bw, d); err != nil {
+			out.abort()
+			fatal(err)
+		}
+		if err := bw.Flush(); err != nil {
+			out.abort()
+			fatal(err)
+		}
+		if err := out.commit(); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	err := of.write(func(enc encoder) error {
+		return encodeElements(enc, decodeGraph(in))
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A bbox is a geographic bounding box in degrees.
+type bbox struct {
+	minLat, minLon float64
+	maxLat, maxLon float64
+}
+
+// parseBBox parses the value of the -coords flag, which has the form
+// "minlat,minlon,maxlat,maxlon".
+func parseBBox(s string) (bbox, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return bbox{}, fmt.Errorf("malformed bounding box: %q", s)
+	}
+	var v [4]float64
+	for i, f := range fields {
+		x, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return bbox{}, fmt.Errorf("malformed bounding box: %q", s)
+		}
+		v[i] = x
+	}
+	b := bbox{minLat: v[0], minLon: v[1], maxLat: v[2], maxLon: v[3]}
+	if b.minLat < -90 || b.maxLat > 90 || b.minLat >= b.maxLat ||
+		b.minLon < -180 || b.maxLon > 180 || b.minLon >= b.maxLon {
+		return bbox{}, fmt.Errorf("invalid bounding box: %q", s)
+	}
+	return b, nil
+}
+
+// coords returns random coordinates within b for the vertex with the
+// provided ID. They only depend on seed and id. Coordinates are
+// uniformly distributed over the area of the bounding box, so
+// latitudes near the poles are less likely.
+func (b bbox) coords(seed uint64, id int64) (lat, lon float64) {
+	h := vertexHash(seed, id, saltCoords)
+	u, w := unitFloat(h), unitFloat(mix64(h))
+
+	rad := math.Pi / 180
+	s0, s1 := math.Sin(b.minLat*rad), math.Sin(b.maxLat*rad)
+	lat = math.Asin(s0+u*(s1-s0)) / rad
+	lon = b.minLon + w*(b.maxLon-b.minLon)
+	return lat, lon
+}
+
+// unitFloat returns a float64 in [0, 1) made of the most significant
+// bits of x.
+func unitFloat(x uint64) float64 {
+	return float64(x>>11) / (1 << 53)
+}
+
+// withCoords returns a copy of g where every vertex has random
+// coordinates within b. See [bbox.coords].
+func withCoords(g graph, b bbox, seed uint64) graph {
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			v.lat, v.lon = b.coords(seed, v.id)
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: g.edges}
+}
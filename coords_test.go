@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestParseBBox(t *testing.T) {
+	b, err := parseBBox("40.4,-3.8,40.5,-3.6")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (bbox{minLat: 40.4, minLon: -3.8, maxLat: 40.5, maxLon: -3.6}); b != want {
+		t.Errorf("unexpected bounding box: got: %+v, want: %+v", b, want)
+	}
+
+	invalid := []string{
+		"",
+		"1,2,3",
+		"a,2,3,4",
+		"10,0,5,1",
+		"0,10,1,5",
+		"-91,0,0,1",
+		"0,0,1,181",
+	}
+	for _, s := range invalid {
+		if _, err := parseBBox(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestBBoxCoords(t *testing.T) {
+	b := bbox{minLat: -60, minLon: 10, maxLat: 75, maxLon: 20}
+
+	var north int
+	for id := range int64(10000) {
+		lat, lon := b.coords(1, id)
+		if lat < b.minLat || lat > b.maxLat || lon < b.minLon || lon > b.maxLon {
+			t.Fatalf("coordinates out of the bounding box: %v, %v", lat, lon)
+		}
+		if lat2, lon2 := b.coords(1, id); lat2 != lat || lon2 != lon {
+			t.Fatalf("coordinates are not deterministic")
+		}
+		if lat > 0 {
+			north++
+		}
+	}
+
+	// The fraction of the area of the bounding box in the northern
+	// hemisphere is sin(75°) / (sin(75°) + sin(60°)) ≈ 0.527.
+	if north < 5000 || north > 5500 {
+		t.Errorf("unexpected number of coordinates in the northern hemisphere: %v", north)
+	}
+}
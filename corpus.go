@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A corpusModel is a model of the graphs of a corpus.
+type corpusModel struct {
+	name string
+
+	// minN is the minimum number of vertices of the model.
+	minN int64
+
+	// args returns the generation flags of a graph of the model
+	// with n vertices.
+	args func(r *rand.Rand, n int64) []string
+}
+
+// corpusModels are the models of the graphs of a corpus.
+var corpusModels = []corpusModel{
+	{name: "binomial", minN: 1, args: func(r *rand.Rand, n int64) []string {
+		args := []string{fmt.Sprintf("-n=%v", n), fmt.Sprintf("-trials=%v", r.IntN(5)), fmt.Sprintf("-prob=%.2f", r.Float64())}
+		if r.IntN(4) == 0 {
+			args = append(args, "-loops")
+		}
+		if r.IntN(4) == 0 {
+			args = append(args, "-multiedges")
+		}
+		return args
+	}},
+	{name: "closure", minN: 2, args: func(r *rand.Rand, n int64) []string {
+		return []string{fmt.Sprintf("-n=%v", n), fmt.Sprintf("-trials=%v", 1+r.IntN(3)), fmt.Sprintf("-prob=%.2f", r.Float64()), fmt.Sprintf("-closure=%.2f", r.Float64())}
+	}},
+	{name: "locality", minN: 2, args: func(r *rand.Rand, n int64) []string {
+		decay := fmt.Sprintf("geometric:%.2f", r.Float64())
+		if r.IntN(2) == 0 {
+			decay = fmt.Sprintf("powerlaw:%.2f", 3*r.Float64())
+		}
+		return []string{fmt.Sprintf("-n=%v", n), fmt.Sprintf("-trials=%v", 1+r.IntN(4)), fmt.Sprintf("-prob=%.2f", r.Float64()), "-locality=" + decay}
+	}},
+	{name: "holmekim", minN: 2, args: func(r *rand.Rand, n int64) []string {
+		return []string{fmt.Sprintf("-n=%v", n), fmt.Sprintf("-trials=%v", 1+r.IntN(3)), fmt.Sprintf("-prob=%.2f", 0.5+r.Float64()/2), fmt.Sprintf("-triad-formation=%.2f", 0.05+0.95*r.Float64())}
+	}},
+	{name: "degrees", minN: 1, args: func(r *rand.Rand, n int64) []string {
+		return []string{fmt.Sprintf("-n=%v", n), "-out-degree=" + corpusDegree(r, n), "-in-degree=" + corpusDegree(r, n)}
+	}},
+	{name: "lfr", minN: 4, args: func(r *rand.Rand, n int64) []string {
+		return []string{
+			fmt.Sprintf("-n=%v", n),
+			fmt.Sprintf("-out-degree=poisson:%.2f", 0.5+r.Float64()),
+			fmt.Sprintf("-in-degree=poisson:%.2f", 0.5+r.Float64()),
+			fmt.Sprintf("-community-sizes=uniform:2,%v", max(2, n/2)),
+			fmt.Sprintf("-mu=%.2f", r.Float64()/2),
+		}
+	}},
+	{name: "graph500", minN: 2, args: func(r *rand.Rand, n int64) []string {
+		return []string{"-profile=graph500", fmt.Sprintf("-scale=%v", bits.Len64(uint64(n))-1), fmt.Sprintf("-edgefactor=%v", 1+r.IntN(4))}
+	}},
+}
+
+// corpusDegree returns a random degree distribution of a graph with n
+// vertices.
+func corpusDegree(r *rand.Rand, n int64) string {
+	switch r.IntN(3) {
+	case 0:
+		return fmt.Sprintf("const:%v", r.Int64N(min(n, 4)))
+	case 1:
+		return fmt.Sprintf("powerlaw:%.2f,1,%v", 1.5+r.Float64(), max(1, n/2))
+	}
+	return fmt.Sprintf("poisson:%.2f", 3*r.Float64())
+}
+
+// corpusArgs returns the model and the generation flags of the graph
+// with the provided index of a corpus of graphs with up to maxN
+// vertices. They only depend on seed, index and maxN, so growing a
+// corpus does not change its existing graphs.
+func corpusArgs(seed uint64, index int64, maxN int64) (model string, args []string) {
+	r := vertexRNG(rngPCG, seed, index)
+
+	var models []corpusModel
+	for _, m := range corpusModels {
+		if m.minN <= maxN {
+			models = append(models, m)
+		}
+	}
+	m := models[r.IntN(len(models))]
+	n := m.minN + r.Int64N(maxN-m.minN+1)
+	args = append(m.args(r, n), fmt.Sprintf("-seed=%v", r.Uint64()|1), "-meta=false")
+
+	if r.IntN(4) == 0 {
+		args = append(args, "-vertex-types=a:2,b:1")
+	}
+	if r.IntN(4) == 0 {
+		args = append(args, "-edge-types=x:2,y:1")
+	}
+	if r.IntN(5) == 0 {
+		args = append(args, "-vertex-time=2024-01-01T00:00:00Z,1m")
+	}
+	if r.IntN(5) == 0 {
+		args = append(args, "-coords=40,-4,41,-3")
+	}
+	if r.IntN(5) == 0 {
+		args = append(args, fmt.Sprintf("-id-start=%v", r.IntN(1000)))
+	}
+	if r.IntN(4) == 0 {
+		args = append(args, "-dot")
+	}
+	return m.name, args
+}
+
+// writeCorpus writes count graphs with up to maxN vertices to dir. See
+// [corpusArgs].
+func writeCorpus(ctx context.Context, dir string, count, maxN int64, seed uint64) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i := range count {
+		if ctx.Err() != nil {
+			break
+		}
+
+		model, args := corpusArgs(seed, i, maxN)
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(args); err != nil {
+			return fmt.Errorf("%v: %w", strings.Join(args, " "), err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			return fmt.Errorf("%v: %w", strings.Join(args, " "), err)
+		}
+
+		ext := "txt"
+		if c.emitDOT {
+			ext = "dot"
+		}
+		name := filepath.Join(dir, fmt.Sprintf("%06d-%v.%v", i, model, ext))
+		if err := gen.writeFile(ctx, name, false, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCorpus(args []string) {
+	fs := flag.NewFlagSet("corpus", flag.ExitOnError)
+	dir := fs.String("out", "", "output `directory`")
+	count := fs.Int64("count", 100, "number of graphs")
+	maxN := fs.Int64("max-n", 16, "maximum number of vertices of every graph")
+	seed := fs.Uint64("seed", 1, "seed of the corpus (0 means random)")
+	fs.Usage = commandUsage(fs, "corpus -out dir [-count k] [-max-n m] [-seed s]")
+	parseFlags(fs, args)
+	if fs.NArg() != 0 || *dir == "" {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	switch {
+	case *count < 0:
+		fatal(usageError{fmt.Errorf("invalid number of graphs: %v", *count)})
+	case *maxN < 1:
+		fatal(usageError{errors.New("invalid maximum number of vertices: must be at least 1")})
+	}
+	if *seed == 0 {
+		*seed = rand.Uint64()
+	}
+
+	ctx, stop := notifyInterrupt()
+	defer stop()
+
+	if err := writeCorpus(ctx, *dir, *count, *maxN, *seed); err != nil {
+		fatal(err)
+	}
+}
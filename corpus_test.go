@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCorpusArgs(t *testing.T) {
+	for i := range int64(20) {
+		model1, args1 := corpusArgs(1, i, 16)
+		model2, args2 := corpusArgs(1, i, 16)
+		if model1 != model2 || !slices.Equal(args1, args2) {
+			t.Errorf("graph %v: non-deterministic args: %v %v, %v %v", i, model1, args1, model2, args2)
+		}
+	}
+
+	for _, maxN := range []int64{1, 2, 3} {
+		for i := range int64(50) {
+			if model, _ := corpusArgs(1, i, maxN); model == "lfr" {
+				t.Errorf("max-n %v: unexpected model: %v", maxN, model)
+			}
+		}
+	}
+}
+
+func TestWriteCorpus(t *testing.T) {
+	for _, maxN := range []int64{1, 4, 32} {
+		dir := t.TempDir()
+		if err := writeCorpus(context.Background(), dir, 100, maxN, 1); err != nil {
+			t.Fatalf("max-n %v: error writing corpus: %v", maxN, err)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("error reading corpus: %v", err)
+		}
+		if len(entries) != 100 {
+			t.Errorf("max-n %v: unexpected number of graphs: %v", maxN, len(entries))
+		}
+	}
+
+	dir1, dir2 := t.TempDir(), t.TempDir()
+	if err := writeCorpus(context.Background(), dir1, 10, 16, 1); err != nil {
+		t.Fatalf("error writing corpus: %v", err)
+	}
+	if err := writeCorpus(context.Background(), dir2, 20, 16, 1); err != nil {
+		t.Fatalf("error writing corpus: %v", err)
+	}
+	entries, err := os.ReadDir(dir1)
+	if err != nil {
+		t.Fatalf("error reading corpus: %v", err)
+	}
+	for _, e := range entries {
+		b1, err := os.ReadFile(filepath.Join(dir1, e.Name()))
+		if err != nil {
+			t.Fatalf("error reading graph: %v", err)
+		}
+		b2, err := os.ReadFile(filepath.Join(dir2, e.Name()))
+		if err != nil {
+			t.Fatalf("error reading graph: %v", err)
+		}
+		if string(b1) != string(b2) {
+			t.Errorf("graph %v changed when growing the corpus", e.Name())
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+//go:build cshared
+
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+
+typedef struct {
+	int64_t id;
+	const char *label;
+	const char *type;
+	const char *community;
+	int64_t time;
+	double lat;
+	double lon;
+} mkdigraph_vertex;
+
+typedef struct {
+	int64_t tail;
+	int64_t head;
+	const char *type;
+	int64_t time;
+	int removed;
+} mkdigraph_edge;
+
+typedef int (*mkdigraph_vertex_func)(const mkdigraph_vertex *v, void *data);
+typedef int (*mkdigraph_edge_func)(const mkdigraph_edge *e, void *data);
+
+static int call_vertex_func(mkdigraph_vertex_func fn, const mkdigraph_vertex *v, void *data) {
+	return fn(v, data);
+}
+
+static int call_edge_func(mkdigraph_edge_func fn, const mkdigraph_edge *e, void *data) {
+	return fn(e, data);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"unsafe"
+)
+
+// goArgs returns the argc C strings in argv as Go strings.
+func goArgs(argc C.int, argv **C.char) []string {
+	var args []string
+	for _, arg := range unsafe.Slice(argv, int(argc)) {
+		args = append(args, C.GoString(arg))
+	}
+	return args
+}
+
+// setCError copies the message of err into the C buffer errbuf of
+// size errlen, truncating it if needed, and returns the exit status
+// corresponding to err.
+func setCError(err error, errbuf *C.char, errlen C.size_t) C.int {
+	if errbuf != nil && errlen > 0 {
+		msg := C.CString(err.Error())
+		defer C.free(unsafe.Pointer(msg))
+		C.strncpy(errbuf, msg, errlen-1)
+		*(*C.char)(unsafe.Add(unsafe.Pointer(errbuf), errlen-1)) = 0
+	}
+	return C.int(exitStatus(err))
+}
+
+// cString returns s as a C string, or NULL if s is empty. It must be
+// freed with C.free.
+func cString(s string) *C.char {
+	if s == "" {
+		return nil
+	}
+	return C.CString(s)
+}
+
+// mkdigraph_generate generates a graph with the generate flags in
+// argv and calls vfn with every vertex and efn with every edge, in the
+// order they would be written, passing them data. The strings of the
+// vertices and edges are only valid during the call. If a callback
+// returns nonzero, the generation stops. Callbacks can be NULL.
+//
+// It returns 0 on success and, on failure, the exit status of the
+// command line, writing the error message to errbuf, which has size
+// errlen.
+//
+//export mkdigraph_generate
+func mkdigraph_generate(argc C.int, argv **C.char, vfn C.mkdigraph_vertex_func, efn C.mkdigraph_edge_func, data unsafe.Pointer, errbuf *C.char, errlen C.size_t) C.int {
+	c, fs, err := parseGenerate(goArgs(argc, argv))
+	if err != nil {
+		return setCError(err, errbuf, errlen)
+	}
+	// The graph is only passed to the callbacks, so the flags that
+	// write files or check the written graph need mkdigraph_write.
+	if c.outFile != "" || len(c.tees) > 0 || c.snapshots > 0 || c.checkpoint != "" || c.manifest != "" || c.checksum != "" || c.verify {
+		return setCError(usageError{errors.New("-o, -tee, -snapshots, -checkpoint, -manifest, -checksum and -verify require mkdigraph_write")}, errbuf, errlen)
+	}
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		return setCError(err, errbuf, errlen)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	vertexFunc := func(v vertex) {
+		if vfn == nil {
+			return
+		}
+		cv := C.mkdigraph_vertex{
+			id:        C.int64_t(v.id),
+			label:     cString(v.label),
+			_type:     cString(v.vtype),
+			community: cString(v.community),
+			time:      C.int64_t(v.time),
+			lat:       C.double(v.lat),
+			lon:       C.double(v.lon),
+		}
+		defer C.free(unsafe.Pointer(cv.label))
+		defer C.free(unsafe.Pointer(cv._type))
+		defer C.free(unsafe.Pointer(cv.community))
+		if C.call_vertex_func(vfn, &cv, data) != 0 {
+			cancel()
+		}
+	}
+	edgeFunc := func(e edge) {
+		if efn == nil {
+			return
+		}
+		ce := C.mkdigraph_edge{
+			tail:  C.int64_t(e.tail),
+			head:  C.int64_t(e.head),
+			_type: cString(e.etype),
+			time:  C.int64_t(e.time),
+		}
+		if e.removed {
+			ce.removed = 1
+		}
+		defer C.free(unsafe.Pointer(ce._type))
+		if C.call_edge_func(efn, &ce, data) != 0 {
+			cancel()
+		}
+	}
+	if err := gen.walk(ctx, vertexFunc, edgeFunc); err != nil {
+		return setCError(err, errbuf, errlen)
+	}
+	return 0
+}
+
+// mkdigraph_write generates a graph with the generate flags in argv
+// and writes it like the command line does, to the file set by -o or
+// to the standard output, or the snapshots set by -snapshots.
+//
+// It returns 0 on success and, on failure, the exit status of the
+// command line, writing the error message to errbuf, which has size
+// errlen.
+//
+//export mkdigraph_write
+func mkdigraph_write(argc C.int, argv **C.char, errbuf *C.char, errlen C.size_t) C.int {
+	c, fs, err := parseGenerate(goArgs(argc, argv))
+	if err != nil {
+		return setCError(err, errbuf, errlen)
+	}
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		return setCError(err, errbuf, errlen)
+	}
+	if c.snapshots > 0 {
+		err = gen.writeSnapshots(context.Background(), c.snapDir, c.atomic)
+	} else {
+		err = gen.writeFile(context.Background(), c.outFile, c.appendOut, c.atomic)
+	}
+	if err == nil {
+		err = gen.verify(true)
+	}
+	if err != nil {
+		return setCError(err, errbuf, errlen)
+	}
+	return 0
+}
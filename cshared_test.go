@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+//go:build cshared
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCGenerate(t *testing.T) {
+	vertices, edges, status, msg := cGenerate([]string{"-n=10", "-prob=0.5", "-seed=1"}, 0, 64)
+	if status != 0 || msg != "" {
+		t.Fatalf("unexpected error: %v: %q", status, msg)
+	}
+	if vertices != 10 || edges == 0 {
+		t.Errorf("unexpected graph: %v vertices, %v edges", vertices, edges)
+	}
+}
+
+func TestCGenerateStop(t *testing.T) {
+	vertices, edges, status, msg := cGenerate([]string{"-n=10", "-prob=0.5", "-seed=1"}, 12, 64)
+	if status != 0 || msg != "" {
+		t.Fatalf("unexpected error: %v: %q", status, msg)
+	}
+	if vertices+edges != 12 {
+		t.Errorf("generation not stopped: %v vertices, %v edges", vertices, edges)
+	}
+}
+
+func TestCGenerateErrbuf(t *testing.T) {
+	args := []string{"-n=x"}
+	_, _, status, want := cGenerate(args, 0, 1024)
+	if status != 2 || want == "" {
+		t.Fatalf("unexpected error: %v: %q", status, want)
+	}
+
+	tests := []struct {
+		errlen int
+		want   string
+	}{
+		{errlen: 0, want: ""},
+		{errlen: 1, want: ""},
+		{errlen: 8, want: want[:7]},
+		{errlen: len(want) + 1, want: want},
+	}
+	for _, tt := range tests {
+		_, _, status, msg := cGenerate(args, 0, tt.errlen)
+		if status != 2 || msg != tt.want {
+			t.Errorf("errlen %v: unexpected error: %v: got: %q want: %q", tt.errlen, status, msg, tt.want)
+		}
+	}
+}
+
+func TestCGenerateUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	for _, arg := range []string{"-o=" + dir + "/out", "-tee=dot:" + dir + "/tee", "-checkpoint=" + dir + "/cp", "-verify"} {
+		vertices, edges, status, msg := cGenerate([]string{"-n=10", arg}, 0, 256)
+		if status != 2 || !strings.Contains(msg, "require mkdigraph_write") {
+			t.Errorf("%v: unexpected error: %v: %q", arg, status, msg)
+		}
+		if vertices != 0 || edges != 0 {
+			t.Errorf("%v: graph generated: %v vertices, %v edges", arg, vertices, edges)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+//go:build cshared
+
+package main
+
+// cgo cannot be used in test files, so the C callbacks used by the
+// tests of the C library are defined here.
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct {
+	int64_t id;
+	const char *label;
+	const char *type;
+	const char *community;
+	int64_t time;
+	double lat;
+	double lon;
+} mkdigraph_vertex;
+
+typedef struct {
+	int64_t tail;
+	int64_t head;
+	const char *type;
+	int64_t time;
+	int removed;
+} mkdigraph_edge;
+
+typedef int (*mkdigraph_vertex_func)(const mkdigraph_vertex *v, void *data);
+typedef int (*mkdigraph_edge_func)(const mkdigraph_edge *e, void *data);
+
+typedef struct {
+	int64_t vertices;
+	int64_t edges;
+	int64_t stop;
+} test_counter;
+
+int test_count_vertex(const mkdigraph_vertex *v, void *data) {
+	test_counter *c = data;
+	c->vertices++;
+	return c->stop > 0 && c->vertices + c->edges >= c->stop;
+}
+
+int test_count_edge(const mkdigraph_edge *e, void *data) {
+	test_counter *c = data;
+	c->edges++;
+	return c->stop > 0 && c->vertices + c->edges >= c->stop;
+}
+*/
+import "C"
+
+import "unsafe"
+
+// cGenerate calls mkdigraph_generate with the flags in args and an
+// error buffer of size errlen. Its callbacks count the vertices and
+// edges, and stop the generation after stop calls if stop is
+// positive. It returns the counts, the returned status and the
+// message written to the error buffer.
+func cGenerate(args []string, stop int64, errlen int) (vertices, edges int64, status int, msg string) {
+	argv := make([]*C.char, len(args)+1)
+	for i, arg := range args {
+		argv[i] = C.CString(arg)
+		defer C.free(unsafe.Pointer(argv[i]))
+	}
+
+	counter := (*C.test_counter)(C.calloc(1, C.sizeof_test_counter))
+	defer C.free(unsafe.Pointer(counter))
+	counter.stop = C.int64_t(stop)
+
+	var errbuf *C.char
+	if errlen > 0 {
+		errbuf = (*C.char)(C.calloc(C.size_t(errlen), 1))
+		defer C.free(unsafe.Pointer(errbuf))
+	}
+
+	ret := mkdigraph_generate(C.int(len(args)), &argv[0],
+		C.mkdigraph_vertex_func(C.test_count_vertex), C.mkdigraph_edge_func(C.test_count_edge),
+		unsafe.Pointer(counter), errbuf, C.size_t(errlen))
+	if errbuf != nil {
+		msg = C.GoString(errbuf)
+	}
+	return int64(counter.vertices), int64(counter.edges), int(ret), msg
+}
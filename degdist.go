@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// A degreeBin holds the number of vertices with a given out-degree
+// and the number of vertices with the same in-degree.
+type degreeBin struct {
+	Degree int64 `json:"degree"`
+	Out    int64 `json:"out"`
+	In     int64 `json:"in"`
+}
+
+// degreeHistogram returns the out-degree and in-degree histograms of
+// the graph read from seq, in a single pass. Only the degrees of at
+// least one vertex are returned, in increasing order. The vertices
+// include the undeclared edge endpoints. The degrees of every vertex
+// are kept in memory.
+func degreeHistogram(seq iter.Seq2[element, error]) ([]degreeBin, error) {
+	degrees := make(map[int64]*[2]int64)
+	degree := func(id int64) *[2]int64 {
+		d, ok := degrees[id]
+		if !ok {
+			d = &[2]int64{}
+			degrees[id] = d
+		}
+		return d
+	}
+	for elem, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		if !elem.isEdge {
+			degree(elem.v.id)
+			continue
+		}
+		degree(elem.e.tail)[0]++
+		degree(elem.e.head)[1]++
+	}
+
+	var maxDegree int64
+	for _, d := range degrees {
+		maxDegree = max(maxDegree, d[0], d[1])
+	}
+	bins := make([]degreeBin, maxDegree+1)
+	for i := range bins {
+		bins[i].Degree = int64(i)
+	}
+	for _, d := range degrees {
+		bins[d[0]].Out++
+		bins[d[1]].In++
+	}
+
+	var hist []degreeBin
+	for _, b := range bins {
+		if b.Out > 0 || b.In > 0 {
+			hist = append(hist, b)
+		}
+	}
+	return hist, nil
+}
+
+// writeDegreeCSV writes hist to w as CSV with a header.
+func writeDegreeCSV(w io.Writer, hist []degreeBin) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "degree,out,in")
+	for _, b := range hist {
+		fmt.Fprintf(bw, "%v,%v,%v\n", b.Degree, b.Out, b.In)
+	}
+	return bw.Flush()
+}
+
+// writeDegreeGnuplot writes a gnuplot script that plots hist on
+// logarithmic axes to w. The data is embedded in the script.
+func writeDegreeGnuplot(w io.Writer, hist []degreeBin) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "$degrees << EOD")
+	for _, b := range hist {
+		fmt.Fprintf(bw, "%v %v %v\n", b.Degree, b.Out, b.In)
+	}
+	fmt.Fprintln(bw, "EOD")
+	fmt.Fprintln(bw, "set logscale xy")
+	fmt.Fprintln(bw, `set xlabel "degree"`)
+	fmt.Fprintln(bw, `set ylabel "vertices"`)
+	fmt.Fprintln(bw, `plot $degrees using 1:2 with points title "out-degree", \`)
+	fmt.Fprintln(bw, `     $degrees using 1:3 with points title "in-degree"`)
+	return bw.Flush()
+}
+
+// writeDegreeVegaLite writes a Vega-Lite specification that plots
+// hist on symmetric logarithmic axes to w. The data is embedded in
+// the specification.
+func writeDegreeVegaLite(w io.Writer, hist []degreeBin) error {
+	values := hist
+	if values == nil {
+		values = []degreeBin{}
+	}
+	axis := func(field string) map[string]any {
+		return map[string]any{
+			"field": field,
+			"type":  "quantitative",
+			"scale": map[string]any{"type": "symlog"},
+		}
+	}
+	spec := map[string]any{
+		"$schema": "https://vega.github.io/schema/vega-lite/v5.json",
+		"data":    map[string]any{"values": values},
+		"transform": []any{
+			map[string]any{"fold": []string{"out", "in"}, "as": []string{"direction", "vertices"}},
+			map[string]any{"filter": "datum.vertices > 0"},
+		},
+		"mark": "point",
+		"encoding": map[string]any{
+			"x":     axis("degree"),
+			"y":     axis("vertices"),
+			"color": map[string]any{"field": "direction", "type": "nominal"},
+		},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(spec)
+}
+
+func runDegdist(args []string) {
+	fs := flag.NewFlagSet("degdist", flag.ExitOnError)
+	plot := fs.String("plot", "", "write a plot of the histograms in `format` (gnuplot, vega-lite) instead of CSV")
+	outFile := fs.String("o", "", "output file")
+	fs.Usage = commandUsage(fs, "degdist [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	var write func(io.Writer, []degreeBin) error
+	switch *plot {
+	case "":
+		write = writeDegreeCSV
+	case "gnuplot":
+		write = writeDegreeGnuplot
+	case "vega-lite":
+		write = writeDegreeVegaLite
+	default:
+		fatal(fmt.Errorf("unknown plot format: %v", *plot))
+	}
+
+	hist, err := degreeHistogram(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+
+	out, err := createOutput(*outFile, false, false)
+	if err != nil {
+		fatal(err)
+	}
+	if err := write(out, hist); err != nil {
+		out.abort()
+		fatal(err)
+	}
+	if err := out.commit(); err != nil {
+		fatal(err)
+	}
+}
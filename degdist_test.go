@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDegreeHistogram(t *testing.T) {
+	const input = "V: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\nE: 0 1\nE: 0 2\nE: 0 3\nE: 1 2\nE: 4 2\n"
+	hist, err := degreeHistogram(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []degreeBin{
+		{Degree: 0, Out: 2, In: 2},
+		{Degree: 1, Out: 2, In: 2},
+		{Degree: 3, Out: 1, In: 1},
+	}
+	if !slices.Equal(hist, want) {
+		t.Errorf("unexpected histogram: got: %v want: %v", hist, want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeDegreeCSV(&buf, hist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := buf.String(), "degree,out,in\n0,2,2\n1,2,2\n3,1,1\n"; got != want {
+		t.Errorf("unexpected CSV: got: %q want: %q", got, want)
+	}
+}
+
+func TestWriteDegreeVegaLite(t *testing.T) {
+	for _, hist := range [][]degreeBin{nil, {{Degree: 1, Out: 2, In: 3}}} {
+		var buf bytes.Buffer
+		if err := writeDegreeVegaLite(&buf, hist); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var spec struct {
+			Data struct {
+				Values []degreeBin `json:"values"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &spec); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if spec.Data.Values == nil || !slices.Equal(spec.Data.Values, hist) {
+			t.Errorf("unexpected values: got: %v want: %v", spec.Data.Values, hist)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A degreeDist returns random vertex degrees following a discrete
+// probability distribution, using r as the source of randomness.
+type degreeDist func(r *rand.Rand) int
+
+// parseDegreeDist parses a degree distribution specification of the
+// form "name:param,...". The supported distributions are:
+//
+//	const:k                constant degree k
+//	uniform:min,max        uniform in [min, max]
+//	binomial:n,p           binomial with n trials and probability p
+//	poisson:mean           Poisson with the given mean
+//	powerlaw:gamma,min,max power law with exponent gamma in [min, max]
+func parseDegreeDist(s string) (degreeDist, error) {
+	name, params, _ := strings.Cut(s, ":")
+	var args []float64
+	if params != "" {
+		for p := range strings.SplitSeq(params, ",") {
+			f, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid degree distribution %q: %w", s, err)
+			}
+			args = append(args, f)
+		}
+	}
+
+	var (
+		dist degreeDist
+		err  error
+	)
+	switch name {
+	case "const":
+		dist, err = constDist(args)
+	case "uniform":
+		dist, err = uniformDist(args)
+	case "binomial":
+		dist, err = binomialDist(args)
+	case "poisson":
+		dist, err = poissonDist(args)
+	case "powerlaw":
+		dist, err = powerLawDist(args)
+	default:
+		err = errors.New("unknown distribution")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid degree distribution %q: %w", s, err)
+	}
+	return dist, nil
+}
+
+// mustParseDegreeDist is like [parseDegreeDist] but panics if s
+// cannot be parsed. It simplifies the initialization of the degree
+// distributions of constant specifications.
+func mustParseDegreeDist(s string) degreeDist {
+	dist, err := parseDegreeDist(s)
+	if err != nil {
+		panic(err)
+	}
+	return dist
+}
+
+// degreeBound returns the maximum degree drawn from the degree
+// distribution specification s, which must be valid. ok is false if
+// the distribution is not bounded.
+func degreeBound(s string) (max int, ok bool) {
+	name, params, _ := strings.Cut(s, ":")
+	args := strings.Split(params, ",")
+	var arg string
+	switch name {
+	case "const", "binomial":
+		arg = args[0]
+	case "uniform":
+		arg = args[1]
+	case "powerlaw":
+		arg = args[2]
+	default:
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func constDist(args []float64) (degreeDist, error) {
+	if len(args) != 1 {
+		return nil, errors.New("want 1 parameter")
+	}
+	k, err := degree(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return func(*rand.Rand) int { return k }, nil
+}
+
+func uniformDist(args []float64) (degreeDist, error) {
+	if len(args) != 2 {
+		return nil, errors.New("want 2 parameters")
+	}
+	lo, err := degree(args[0])
+	if err != nil {
+		return nil, err
+	}
+	hi, err := degree(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if lo > hi {
+		return nil, errors.New("min is greater than max")
+	}
+	return func(r *rand.Rand) int { return lo + r.IntN(hi-lo+1) }, nil
+}
+
+func binomialDist(args []float64) (degreeDist, error) {
+	if len(args) != 2 {
+		return nil, errors.New("want 2 parameters")
+	}
+	n, err := degree(args[0])
+	if err != nil {
+		return nil, err
+	}
+	p := args[1]
+	if p < 0 || p > 1 {
+		return nil, errors.New("probability out of range")
+	}
+	return func(r *rand.Rand) int {
+		k := 0
+		for range n {
+			if r.Float64() < p {
+				k++
+			}
+		}
+		return k
+	}, nil
+}
+
+func poissonDist(args []float64) (degreeDist, error) {
+	if len(args) != 1 {
+		return nil, errors.New("want 1 parameter")
+	}
+	mean := args[0]
+	if mean < 0 {
+		return nil, errors.New("negative mean")
+	}
+	if mean > 500 {
+		// Use the normal approximation, exp(-mean) underflows
+		// for large means.
+		return func(r *rand.Rand) int {
+			return max(0, int(math.Round(mean+math.Sqrt(mean)*r.NormFloat64())))
+		}, nil
+	}
+	l := math.Exp(-mean)
+	return func(r *rand.Rand) int {
+		k, p := 0, r.Float64()
+		for p > l {
+			k++
+			p *= r.Float64()
+		}
+		return k
+	}, nil
+}
+
+func powerLawDist(args []float64) (degreeDist, error) {
+	if len(args) != 3 {
+		return nil, errors.New("want 3 parameters")
+	}
+	gamma := args[0]
+	if gamma <= 1 {
+		return nil, errors.New("exponent must be greater than 1")
+	}
+	lo, err := degree(args[1])
+	if err != nil {
+		return nil, err
+	}
+	hi, err := degree(args[2])
+	if err != nil {
+		return nil, err
+	}
+	if lo < 1 || lo > hi {
+		return nil, errors.New("invalid range")
+	}
+
+	// Inverse transform sampling of the continuous power law in
+	// [lo, hi+1), rounded down.
+	e := 1 - gamma
+	a := math.Pow(float64(lo), e)
+	b := math.Pow(float64(hi+1), e)
+	return func(r *rand.Rand) int {
+		x := math.Pow(a+(b-a)*r.Float64(), 1/e)
+		return min(hi, int(x))
+	}, nil
+}
+
+// degree converts f into a degree.
+func degree(f float64) (int, error) {
+	if f < 0 || f != math.Trunc(f) || f > math.MaxInt32 {
+		return 0, fmt.Errorf("invalid degree: %v", f)
+	}
+	return int(f), nil
+}
+
+// stubMatching returns a graph with n vertices whose out-degrees and
+// in-degrees are drawn from out and in respectively. Every vertex
+// gets as many out-stubs and in-stubs as its degrees, and the edges
+// are created by randomly pairing out-stubs with in-stubs. If the
+// number of out-stubs and in-stubs differ, random stubs are discarded
+// from the larger set. Loops and multiple edges are discarded unless
+// allowed. r is the source of randomness.
+//
+// assort biases the pairing towards connecting tails of similar
+// out-degree and heads of similar in-degree, if positive, or of
+// opposite degree, if negative. It must be in [-1, 1]. See
+// [assortativeOrder].
+//
+// Unlike the binomial model, stub matching keeps all the stubs in
+// memory.
+func stubMatching(n int64, out, in degreeDist, assort float64, loops, multiedges bool, r *rand.Rand, vlabel func(id int64) string) graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := range n {
+			if !yield(vertex{id: id, label: vlabel(id)}) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		tails, heads := stubs(n, out, r), stubs(n, in, r)
+		if len(tails) > len(heads) {
+			r.Shuffle(len(tails), func(i, j int) {
+				tails[i], tails[j] = tails[j], tails[i]
+			})
+			tails = tails[:len(heads)]
+			slices.Sort(tails)
+		}
+		r.Shuffle(len(heads), func(i, j int) {
+			heads[i], heads[j] = heads[j], heads[i]
+		})
+		heads = heads[:len(tails)]
+		if assort != 0 {
+			tails, heads = assortativeOrder(tails, heads, assort, r)
+		}
+
+		// Tails are sorted, so duplicated edges can only happen
+		// within a run of equal tails.
+		seen := make(map[int64]struct{})
+		for i, tail := range tails {
+			head := heads[i]
+			if i > 0 && tails[i-1] != tail {
+				clear(seen)
+			}
+			if tail == head && !loops {
+				continue
+			}
+			if !multiedges {
+				if _, ok := seen[head]; ok {
+					continue
+				}
+				seen[head] = struct{}{}
+			}
+			if !yield(edge{tail: tail, head: head}) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// assortativeOrder returns the pairs of tails and heads reordered
+// so that the out-degree of the tail and the in-degree of the head of
+// every pair are correlated with sign assort. The returned tails are
+// sorted.
+//
+// Every stub is ranked by the degree of its vertex within its list,
+// and sorted by a key that mixes its normalized rank, with weight
+// |assort|, and uniform noise, with weight 1-|assort|. Pairing the
+// sorted tails with the heads sorted in the same order, or in the
+// opposite order if assort is negative, pairs stubs of similar ranks.
+// If assort is 1 or -1, the pairing is deterministic.
+func assortativeOrder(tails, heads []int64, assort float64, r *rand.Rand) ([]int64, []int64) {
+	a := math.Abs(assort)
+	keys := func(stubs []int64, reverse bool) []float64 {
+		sorted := slices.Clone(stubs)
+		slices.Sort(sorted)
+		deg := make(map[int64]int)
+		for _, id := range sorted {
+			deg[id]++
+		}
+		order := make([]int, len(stubs))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(i, j int) int {
+			return deg[stubs[i]] - deg[stubs[j]]
+		})
+		k := make([]float64, len(stubs))
+		for rank, i := range order {
+			q := (float64(rank) + 0.5) / float64(len(stubs))
+			if reverse {
+				q = 1 - q
+			}
+			k[i] = a*q + (1-a)*r.Float64()
+		}
+		return k
+	}
+	byKey := func(stubs []int64, k []float64) []int64 {
+		idx := make([]int, len(stubs))
+		for i := range idx {
+			idx[i] = i
+		}
+		slices.SortFunc(idx, func(i, j int) int {
+			return cmp.Compare(k[i], k[j])
+		})
+		s := make([]int64, len(stubs))
+		for i, j := range idx {
+			s[i] = stubs[j]
+		}
+		return s
+	}
+
+	tails = byKey(tails, keys(tails, false))
+	heads = byKey(heads, keys(heads, assort < 0))
+
+	pairs := make([][2]int64, len(tails))
+	for i := range pairs {
+		pairs[i] = [2]int64{tails[i], heads[i]}
+	}
+	slices.SortFunc(pairs, func(p, q [2]int64) int {
+		if c := cmp.Compare(p[0], q[0]); c != 0 {
+			return c
+		}
+		return cmp.Compare(p[1], q[1])
+	})
+	for i, p := range pairs {
+		tails[i], heads[i] = p[0], p[1]
+	}
+	return tails, heads
+}
+
+// stubs returns the sorted list of stubs of n vertices whose degrees
+// are drawn from dist using r.
+func stubs(n int64, dist degreeDist, r *rand.Rand) []int64 {
+	var s []int64
+	for id := range n {
+		for range dist(r) {
+			s = append(s, id)
+		}
+	}
+	return s
+}
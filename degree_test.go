@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDegreeDist(t *testing.T) {
+	tests := []struct {
+		spec   string
+		lo, hi int
+	}{
+		{spec: "const:3", lo: 3, hi: 3},
+		{spec: "uniform:2,4", lo: 2, hi: 4},
+		{spec: "binomial:5,0.5", lo: 0, hi: 5},
+		{spec: "poisson:0", lo: 0, hi: 0},
+		{spec: "powerlaw:2.5,1,10", lo: 1, hi: 10},
+	}
+	r := newRNG(rngPCG, 1)
+	for _, tt := range tests {
+		dist, err := parseDegreeDist(tt.spec)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.spec, err)
+			continue
+		}
+		for range 1000 {
+			if k := dist(r); k < tt.lo || k > tt.hi {
+				t.Errorf("%v: degree out of range: got: %v want: [%v, %v]", tt.spec, k, tt.lo, tt.hi)
+				break
+			}
+		}
+	}
+}
+
+func TestDegreeBound(t *testing.T) {
+	tests := []struct {
+		spec string
+		max  int
+		ok   bool
+	}{
+		{spec: "const:3", max: 3, ok: true},
+		{spec: "uniform:2,4", max: 4, ok: true},
+		{spec: "binomial:5,0.5", max: 5, ok: true},
+		{spec: "poisson:2", ok: false},
+		{spec: "powerlaw:2.5,1,10", max: 10, ok: true},
+	}
+	for _, tt := range tests {
+		if max, ok := degreeBound(tt.spec); max != tt.max || ok != tt.ok {
+			t.Errorf("%v: unexpected bound: got: %v, %v want: %v, %v", tt.spec, max, ok, tt.max, tt.ok)
+		}
+	}
+}
+
+func TestParseDegreeDistInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"const",
+		"const:-1",
+		"const:1.5",
+		"uniform:4,2",
+		"binomial:5,2",
+		"poisson:-1",
+		"powerlaw:1,1,10",
+		"powerlaw:2,0,10",
+		"zipf:2",
+	}
+	for _, spec := range specs {
+		if _, err := parseDegreeDist(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestStubMatching(t *testing.T) {
+	const n = 10
+
+	dist, err := parseDegreeDist("const:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vlabel := func(id int64) string { return label(nil, id, 0) }
+	g := stubMatching(n, dist, dist, 0, true, true, newRNG(rngPCG, 0), vlabel)
+
+	nv := 0
+	for range g.vertices {
+		nv++
+	}
+	if nv != n {
+		t.Errorf("unexpected number of vertices: got: %v want: %v", nv, n)
+	}
+
+	outdeg := make(map[int64]int)
+	indeg := make(map[int64]int)
+	for e := range g.edges {
+		outdeg[e.tail]++
+		indeg[e.head]++
+	}
+	for id := range int64(n) {
+		if outdeg[id] != 2 || indeg[id] != 2 {
+			t.Errorf("unexpected degrees of vertex %v: got: (%v, %v) want: (2, 2)", id, outdeg[id], indeg[id])
+		}
+	}
+}
+
+func TestStubMatchingAssortativity(t *testing.T) {
+	const n = 2000
+
+	dist, err := parseDegreeDist("powerlaw:2,1,50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vlabel := func(id int64) string { return label(nil, id, 0) }
+
+	// corr returns the Pearson correlation between the out-degree
+	// of the tails and the in-degree of the heads of the edges.
+	corr := func(assort float64) float64 {
+		g := stubMatching(n, dist, dist, assort, true, true, newRNG(rngPCG, 1), vlabel)
+		var edges []edge
+		outdeg := make(map[int64]float64)
+		indeg := make(map[int64]float64)
+		for e := range g.edges {
+			edges = append(edges, e)
+			outdeg[e.tail]++
+			indeg[e.head]++
+		}
+		var sx, sy, sxx, syy, sxy float64
+		for _, e := range edges {
+			x, y := outdeg[e.tail], indeg[e.head]
+			sx += x
+			sy += y
+			sxx += x * x
+			syy += y * y
+			sxy += x * y
+		}
+		m := float64(len(edges))
+		return (sxy/m - sx/m*sy/m) / math.Sqrt((sxx/m-sx/m*sx/m)*(syy/m-sy/m*sy/m))
+	}
+
+	neg, zero, pos := corr(-0.9), corr(0), corr(0.9)
+	if !(neg < -0.3 && math.Abs(zero) < 0.1 && pos > 0.3) {
+		t.Errorf("unexpected correlations: -0.9: %v, 0: %v, 0.9: %v", neg, zero, pos)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// jsonLog is the logger used to write diagnostics as JSON records. If
+// it is nil, diagnostics are written as plain text lines using the
+// log package.
+var jsonLog *slog.Logger
+
+// setLogFormat sets the format of the diagnostics written to the
+// standard error. The supported formats are "text" and "json".
+func setLogFormat(format string) error {
+	switch format {
+	case "text":
+		jsonLog = nil
+	case "json":
+		jsonLog = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	default:
+		return fmt.Errorf("unknown log format: %q", format)
+	}
+	return nil
+}
+
+// Exit statuses of mkdigraph.
+const (
+	exitError   = 1 // generation and other errors
+	exitUsage   = 2 // invalid command line
+	exitIO      = 3 // input and output errors
+	exitInvalid = 4 // invalid input graph
+)
+
+// statusKinds are the names of the exit statuses in the JSON errors.
+var statusKinds = map[int]string{
+	exitError:   "error",
+	exitUsage:   "usage",
+	exitIO:      "io",
+	exitInvalid: "invalid",
+}
+
+// errorFormat is the format of the errors written by [fatal] and
+// [reportError]: "text" or "json". It is set by the -errors flag.
+var errorFormat = "text"
+
+// An inputError is an error in the input graph.
+type inputError struct {
+	err error
+}
+
+func (e inputError) Error() string { return e.err.Error() }
+
+func (e inputError) Unwrap() error { return e.err }
+
+// exitStatus returns the exit status corresponding to err.
+func exitStatus(err error) int {
+	switch {
+	case errors.As(err, new(usageError)):
+		return exitUsage
+	case errors.As(err, new(*fs.PathError)), errors.As(err, new(*os.LinkError)), errors.As(err, new(*os.SyscallError)):
+		return exitIO
+	case errors.As(err, new(inputError)):
+		return exitInvalid
+	}
+	return exitError
+}
+
+// fatal reports an error and exits. If the only argument is an error,
+// the exit status depends on it, see [exitStatus]. Otherwise, it is 1
+// and the arguments are handled in the manner of [fmt.Print].
+func fatal(v ...any) {
+	if len(v) == 1 {
+		if err, ok := v[0].(error); ok {
+			exit(exitStatus(err), err)
+		}
+	}
+	exit(exitError, errors.New(fmt.Sprint(v...)))
+}
+
+// fatalf is like [fatal], but the error is built in the manner of
+// [fmt.Errorf].
+func fatalf(format string, v ...any) {
+	err := fmt.Errorf(format, v...)
+	exit(exitStatus(err), err)
+}
+
+// exit reports err, removes the temporary files of pending atomic
+// outputs and exits with the provided status.
+func exit(status int, err error) {
+	reportError(status, err)
+	tracing.abort(err)
+	removePendingOutputs()
+	os.Exit(status)
+}
+
+// An errorRecord is the JSON representation of an error.
+type errorRecord struct {
+	Error  string `json:"error"`
+	Kind   string `json:"kind"`
+	Status int    `json:"status"`
+	Op     string `json:"op,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// newErrorRecord returns the JSON representation of err, which leads
+// to the provided exit status.
+func newErrorRecord(status int, err error) errorRecord {
+	rec := errorRecord{Error: err.Error(), Kind: statusKinds[status], Status: status}
+	var perr *fs.PathError
+	if errors.As(err, &perr) {
+		rec.Op, rec.Path = perr.Op, perr.Path
+	}
+	return rec
+}
+
+// reportError reports err, which leads to the provided exit status. With
+// -errors=json, it is written to the standard error as a JSON object
+// with the error message, the kind and the value of the status and, for
+// errors on files, the operation and the path.
+func reportError(status int, err error) {
+	if errorFormat == "json" {
+		json.NewEncoder(os.Stderr).Encode(newErrorRecord(status, err))
+		return
+	}
+	if jsonLog != nil {
+		jsonLog.Error(err.Error())
+		return
+	}
+	log.Print(err)
+}
+
+// warn reports a warning with the provided attributes as
+// alternating keys and values.
+func warn(msg string, args ...any) {
+	if jsonLog == nil {
+		log.Print(textRecord("warning: "+msg, args))
+		return
+	}
+	jsonLog.Warn(msg, args...)
+}
+
+// info reports an informational message with the provided attributes
+// as alternating keys and values.
+func info(msg string, args ...any) {
+	if jsonLog == nil {
+		log.Print(textRecord(msg, args))
+		return
+	}
+	jsonLog.Info(msg, args...)
+}
+
+// textRecord formats a diagnostic as a plain text line.
+func textRecord(msg string, args []any) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	sep := ": "
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, "%v%v=%v", sep, args[i], args[i+1])
+		sep = " "
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTextRecord(t *testing.T) {
+	tests := []struct {
+		msg  string
+		args []any
+		want string
+	}{
+		{msg: "done", args: nil, want: "done"},
+		{msg: "dedup", args: []any{"suppressed", 3}, want: "dedup: suppressed=3"},
+		{msg: "summary", args: []any{"vertices", 5, "edges", 7}, want: "summary: vertices=5 edges=7"},
+	}
+	for _, tt := range tests {
+		got := textRecord(tt.msg, tt.args)
+		if got != tt.want {
+			t.Errorf("unexpected record: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	_, ioErr := os.Open(filepath.Join(t.TempDir(), "missing"))
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: errors.New("failed"), want: exitError},
+		{err: usageError{errors.New("bad flag")}, want: exitUsage},
+		{err: ioErr, want: exitIO},
+		{err: fmt.Errorf("writing: %w", ioErr), want: exitIO},
+		{err: inputError{errors.New("malformed record")}, want: exitInvalid},
+		{err: inputError{ioErr}, want: exitIO},
+	}
+	for _, tt := range tests {
+		if got := exitStatus(tt.err); got != tt.want {
+			t.Errorf("%v: unexpected status: got: %v, want: %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// nodeTemplateFields are the vertex fields that can be referenced by
+// node templates.
+var nodeTemplateFields = []string{"id", "label", "type", "community", "time"}
+
+// A nodeTemplate is the template of a DOT node attribute. It is made
+// of literal text and references to the fields of the vertices, like
+// "{label}".
+type nodeTemplate []templatePart
+
+// A templatePart is either literal text or, if field is not empty, a
+// reference to a vertex field.
+type templatePart struct {
+	text  string
+	field string
+}
+
+// parseNodeTemplate parses the node template s. Fields are referenced
+// by their names enclosed in braces. Unknown fields and unbalanced
+// braces are rejected.
+func parseNodeTemplate(s string) (nodeTemplate, error) {
+	var t nodeTemplate
+	for s != "" {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			t = append(t, templatePart{text: s})
+			break
+		}
+		if s[i] == '}' {
+			return nil, fmt.Errorf("unexpected } in template")
+		}
+		if i > 0 {
+			t = append(t, templatePart{text: s[:i]})
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			return nil, fmt.Errorf("unterminated field in template")
+		}
+		field := s[i+1 : i+j]
+		if !slices.Contains(nodeTemplateFields, field) {
+			return nil, fmt.Errorf("unknown template field: %q", field)
+		}
+		t = append(t, templatePart{field: field})
+		s = s[i+j+1:]
+	}
+	return t, nil
+}
+
+// expand appends t expanded for v to b. IDs are padded up to width.
+// If escape is true, the fields are escaped to be used in URLs.
+func (t nodeTemplate) expand(b []byte, v vertex, width int, escape bool) []byte {
+	for _, part := range t {
+		var s string
+		switch part.field {
+		case "":
+			b = append(b, part.text...)
+			continue
+		case "id":
+			s = formatID(v.id, width)
+		case "label":
+			s = v.label
+		case "type":
+			s = v.vtype
+		case "community":
+			s = v.community
+		case "time":
+			s = strconv.FormatInt(v.time, 10)
+		}
+		if escape {
+			s = url.PathEscape(s)
+		}
+		b = append(b, s...)
+	}
+	return b
+}
+
+// parseNodeTemplates parses the tooltip and URL templates of the DOT
+// nodes. Empty templates are returned as nil.
+func parseNodeTemplates(tooltip, href string) (tooltipTmpl, urlTmpl nodeTemplate, err error) {
+	if tooltip != "" {
+		if tooltipTmpl, err = parseNodeTemplate(tooltip); err != nil {
+			return nil, nil, fmt.Errorf("invalid tooltip: %w", err)
+		}
+	}
+	if href != "" {
+		if urlTmpl, err = parseNodeTemplate(href); err != nil {
+			return nil, nil, fmt.Errorf("invalid URL: %w", err)
+		}
+	}
+	return tooltipTmpl, urlTmpl, nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestNodeTemplate(t *testing.T) {
+	v := vertex{id: 7, label: "a b/c", vtype: "user", community: "x", time: 42}
+	tests := []struct {
+		tmpl   string
+		escape bool
+		want   string
+	}{
+		{tmpl: "plain", want: "plain"},
+		{tmpl: "{label} ({type})", want: "a b/c (user)"},
+		{tmpl: "{id}:{community}@{time}", want: "007:x@42"},
+		{tmpl: "https://example.com/v/{label}?id={id}", escape: true, want: "https://example.com/v/a%20b%2Fc?id=007"},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseNodeTemplate(tt.tmpl)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.tmpl, err)
+			continue
+		}
+		if got := string(tmpl.expand(nil, v, 3, tt.escape)); got != tt.want {
+			t.Errorf("%q: unexpected expansion: got: %q want: %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestNodeTemplateErrors(t *testing.T) {
+	for _, s := range []string{"{lat}", "{label", "label}", "{}"} {
+		if _, err := parseNodeTemplate(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// egoVertices returns the vertices of d at most hops edges away from
+// root, including root. If out is true, edges are followed from tail
+// to head, and if in is true, from head to tail.
+func egoVertices(d *graphData, root int64, hops int, out, in bool) map[int64]struct{} {
+	adj := make(map[int64][]int64)
+	for _, e := range d.edges {
+		if out {
+			adj[e.tail] = append(adj[e.tail], e.head)
+		}
+		if in {
+			adj[e.head] = append(adj[e.head], e.tail)
+		}
+	}
+
+	ego := map[int64]struct{}{root: {}}
+	frontier := []int64{root}
+	for range hops {
+		var next []int64
+		for _, u := range frontier {
+			for _, v := range adj[u] {
+				if _, ok := ego[v]; !ok {
+					ego[v] = struct{}{}
+					next = append(next, v)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		frontier = next
+	}
+	return ego
+}
+
+func runEgo(args []string) {
+	fs := flag.NewFlagSet("ego", flag.ExitOnError)
+	root := fs.Int64("root", -1, "`ID` of the root vertex")
+	hops := fs.Int("hops", 1, "maximum `distance` from the root")
+	direction := fs.String("direction", "out", "`direction` of the edges followed from the root (out, in, both)")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "ego -root id [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *root < 0 {
+		fatal(fmt.Errorf("-root is required"))
+	}
+	if *hops < 0 {
+		fatal(fmt.Errorf("invalid number of hops: %v", *hops))
+	}
+	var followOut, followIn bool
+	switch *direction {
+	case "out":
+		followOut = true
+	case "in":
+		followIn = true
+	case "both":
+		followOut, followIn = true, true
+	default:
+		fatal(fmt.Errorf("unknown direction: %v", *direction))
+	}
+
+	d, err := loadGraph(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+	if !d.hasVertex(*root) {
+		fatal(fmt.Errorf("unknown vertex: %v", *root))
+	}
+	ego := egoVertices(d, *root, *hops, followOut, followIn)
+	keep := func(id int64) bool {
+		_, ok := ego[id]
+		return ok
+	}
+
+	err = of.write(func(enc encoder) error {
+		for _, v := range d.vertices {
+			if keep(v.id) {
+				enc.vertex(v)
+			}
+		}
+		for _, e := range d.edges {
+			if keep(e.tail) && keep(e.head) {
+				enc.edge(e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
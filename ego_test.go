@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEgoVertices(t *testing.T) {
+	const input = "E: 0 1\nE: 1 2\nE: 2 3\nE: 4 0\nE: 5 4\nE: 1 0\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		hops    int
+		out, in bool
+		want    []int64
+	}{
+		{hops: 0, out: true, in: false, want: []int64{0}},
+		{hops: 1, out: true, in: false, want: []int64{0, 1}},
+		{hops: 2, out: true, in: false, want: []int64{0, 1, 2}},
+		{hops: 10, out: true, in: false, want: []int64{0, 1, 2, 3}},
+		{hops: 2, out: false, in: true, want: []int64{0, 1, 4, 5}},
+		{hops: 1, out: true, in: true, want: []int64{0, 1, 4}},
+	}
+	for _, tt := range tests {
+		got := slices.Sorted(maps.Keys(egoVertices(d, 0, tt.hops, tt.out, tt.in)))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("hops=%v out=%v in=%v: got: %v want: %v", tt.hops, tt.out, tt.in, got, tt.want)
+		}
+	}
+}
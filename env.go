@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix is the prefix of the environment variables that set flag
+// defaults.
+const envPrefix = "MKDIGRAPH_"
+
+// envName returns the name of the environment variable that sets the
+// default value of the named flag. For instance, "MKDIGRAPH_ID_START"
+// for "id-start".
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// An envValue is the value of a flag set from the environment. It
+// records whether the flag is set again on the command line.
+type envValue struct {
+	flag.Value
+	cmdline bool
+}
+
+// Set sets the value from the command line.
+func (v *envValue) Set(s string) error {
+	v.cmdline = true
+	return v.Value.Set(s)
+}
+
+// IsBoolFlag reports whether the underlying value is a boolean flag.
+func (v *envValue) IsBoolFlag() bool {
+	bf, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// Get returns the underlying value, or its string representation if
+// it does not implement [flag.Getter].
+func (v *envValue) Get() any {
+	if g, ok := v.Value.(flag.Getter); ok {
+		return g.Get()
+	}
+	return v.String()
+}
+
+// setOnCommandLine reports whether the flag f, visited by
+// [flag.FlagSet.Visit], was set on the command line and not only from
+// the environment.
+func setOnCommandLine(f *flag.Flag) bool {
+	v, ok := f.Value.(*envValue)
+	return !ok || v.cmdline
+}
+
+// setFromEnv sets the flags of fs that have a corresponding
+// environment variable. The flags are recorded as set, so they are
+// visited by [flag.FlagSet.Visit], but [setOnCommandLine] tells them
+// apart.
+func setFromEnv(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		v, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if serr := fs.Set(f.Name, v); serr != nil {
+			err = fmt.Errorf("invalid value %q for %v: %w", v, envName(f.Name), serr)
+			return
+		}
+		f.Value = &envValue{Value: f.Value}
+	})
+	return err
+}
+
+// parseFlags defines the -errors flag, which is shared by all the
+// commands, and parses args into fs after setting the flags of fs from
+// the environment, so command line flags take precedence over
+// environment variables.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.StringVar(&errorFormat, "errors", "text", "error `format` (text, json)")
+	if err := setFromEnv(fs); err != nil {
+		fmt.Fprintln(fs.Output(), err)
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	fs.Parse(args)
+	if errorFormat != "text" && errorFormat != "json" {
+		format := errorFormat
+		errorFormat = "text"
+		fatal(usageError{fmt.Errorf("unknown error format: %q", format)})
+	}
+}
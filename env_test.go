@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "n", want: "MKDIGRAPH_N"},
+		{name: "id-start", want: "MKDIGRAPH_ID_START"},
+		{name: "dedup-fp", want: "MKDIGRAPH_DEDUP_FP"},
+	}
+	for _, tt := range tests {
+		if got := envName(tt.name); got != tt.want {
+			t.Errorf("unexpected name for %q: got: %q, want: %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetFromEnv(t *testing.T) {
+	t.Setenv("MKDIGRAPH_N", "100")
+	t.Setenv("MKDIGRAPH_PROB", "0.25")
+	t.Setenv("MKDIGRAPH_ID_START", "7")
+
+	var c genConfig
+	fs := c.flagSet("generate")
+	if err := setFromEnv(fs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"-n=10"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.vertices != 10 {
+		t.Errorf("flag does not take precedence: got: %v, want: 10", c.vertices)
+	}
+	if c.prob != 0.25 {
+		t.Errorf("unexpected probability: got: %v, want: 0.25", c.prob)
+	}
+	if c.idStart != 7 {
+		t.Errorf("unexpected ID start: got: %v, want: 7", c.idStart)
+	}
+	if c.trials != 5 {
+		t.Errorf("unexpected trials: got: %v, want: 5", c.trials)
+	}
+}
+
+func TestSetFromEnvBool(t *testing.T) {
+	t.Setenv("MKDIGRAPH_LOOPS", "false")
+
+	var c genConfig
+	fs := c.flagSet("generate")
+	if err := setFromEnv(fs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fs.Parse([]string{"-loops"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.loops {
+		t.Error("flag does not take precedence")
+	}
+}
+
+func TestSetFromEnvInvalid(t *testing.T) {
+	t.Setenv("MKDIGRAPH_LOOPS", "maybe")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("loops", false, "allow loops")
+	if err := setFromEnv(fs); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// Kinds of filter expression tokens other than operators.
+const (
+	exprEOF = iota
+	exprIdent
+	exprNumber
+	exprString
+	exprOp
+)
+
+// An exprToken is a token of a filter expression.
+type exprToken struct {
+	kind int
+	text string
+}
+
+// lexExpr splits the filter expression s into tokens.
+func lexExpr(s string) ([]exprToken, error) {
+	var toks []exprToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || 'a' <= s[j] && s[j] <= 'z' || 'A' <= s[j] && s[j] <= 'Z' || '0' <= s[j] && s[j] <= '9') {
+				j++
+			}
+			toks = append(toks, exprToken{kind: exprIdent, text: s[i:j]})
+			i = j
+		case '0' <= c && c <= '9' || c == '-' && i+1 < len(s) && '0' <= s[i+1] && s[i+1] <= '9':
+			j := i + 1
+			for j < len(s) && '0' <= s[j] && s[j] <= '9' {
+				j++
+			}
+			toks = append(toks, exprToken{kind: exprNumber, text: s[i:j]})
+			i = j
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, errors.New("unterminated string")
+			}
+			toks = append(toks, exprToken{kind: exprString, text: s[i : j+1]})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")"} {
+				if strings.HasPrefix(s[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character: %q", c)
+			}
+			toks = append(toks, exprToken{kind: exprOp, text: op})
+			i += len(op)
+		}
+	}
+	return append(toks, exprToken{kind: exprEOF}), nil
+}
+
+// An exprParser parses filter expressions into predicates.
+type exprParser struct {
+	toks []exprToken
+	pos  int
+}
+
+// parseEdgeFilter parses the filter expression s and returns the
+// corresponding edge predicate.
+//
+// Expressions compare the attributes of the edges, combined with the
+// && (and), || (or) and ! (not) operators and parentheses. The
+// comparison operators are ==, !=, <, <=, > and >=. The attributes
+// are tail, head and time, which are integers, and type, which is a
+// string. Both sides of a comparison must be of the same kind, and
+// strings are double-quoted like in Go. For instance:
+//
+//	type == "knows" && tail != head
+func parseEdgeFilter(s string) (func(e edge) bool, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	pred, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != exprEOF {
+		return nil, fmt.Errorf("unexpected token: %q", tok.text)
+	}
+	return pred, nil
+}
+
+// peek returns the next token without consuming it.
+func (p *exprParser) peek() exprToken {
+	return p.toks[p.pos]
+}
+
+// accept consumes the next token if it is the operator op.
+func (p *exprParser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == exprOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// or parses a disjunction.
+func (p *exprParser) or() (func(e edge) bool, error) {
+	x, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		y, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		x = func(x, y func(edge) bool) func(edge) bool {
+			return func(e edge) bool { return x(e) || y(e) }
+		}(x, y)
+	}
+	return x, nil
+}
+
+// and parses a conjunction.
+func (p *exprParser) and() (func(e edge) bool, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = func(x, y func(edge) bool) func(edge) bool {
+			return func(e edge) bool { return x(e) && y(e) }
+		}(x, y)
+	}
+	return x, nil
+}
+
+// unary parses a negation, a parenthesized expression or a
+// comparison.
+func (p *exprParser) unary() (func(e edge) bool, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e edge) bool { return !x(e) }, nil
+	}
+	if p.accept("(") {
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, errors.New("missing )")
+		}
+		return x, nil
+	}
+	return p.comparison()
+}
+
+// An exprOperand is an operand of a comparison. Exactly one of its
+// fields is set, depending on its kind.
+type exprOperand struct {
+	num func(e edge) int64
+	str func(e edge) string
+}
+
+// operand parses an attribute or a literal.
+func (p *exprParser) operand() (exprOperand, error) {
+	tok := p.peek()
+	p.pos++
+	switch tok.kind {
+	case exprIdent:
+		switch tok.text {
+		case "tail":
+			return exprOperand{num: func(e edge) int64 { return e.tail }}, nil
+		case "head":
+			return exprOperand{num: func(e edge) int64 { return e.head }}, nil
+		case "time":
+			return exprOperand{num: func(e edge) int64 { return e.time }}, nil
+		case "type":
+			return exprOperand{str: func(e edge) string { return e.etype }}, nil
+		}
+		return exprOperand{}, fmt.Errorf("unknown attribute: %q", tok.text)
+	case exprNumber:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return exprOperand{}, fmt.Errorf("invalid number: %q", tok.text)
+		}
+		return exprOperand{num: func(edge) int64 { return n }}, nil
+	case exprString:
+		s, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return exprOperand{}, fmt.Errorf("invalid string: %v", tok.text)
+		}
+		return exprOperand{str: func(edge) string { return s }}, nil
+	case exprEOF:
+		return exprOperand{}, errors.New("unexpected end of expression")
+	}
+	return exprOperand{}, fmt.Errorf("unexpected token: %q", tok.text)
+}
+
+// comparison parses a comparison.
+func (p *exprParser) comparison() (func(e edge) bool, error) {
+	x, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	var test func(c int) bool
+	switch tok.text {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	}
+	if tok.kind != exprOp || test == nil {
+		return nil, errors.New("missing comparison operator")
+	}
+	p.pos++
+	y, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case x.num != nil && y.num != nil:
+		return func(e edge) bool { return test(cmp.Compare(x.num(e), y.num(e))) }, nil
+	case x.str != nil && y.str != nil:
+		return func(e edge) bool { return test(cmp.Compare(x.str(e), y.str(e))) }, nil
+	}
+	return nil, errors.New("mismatched comparison operands")
+}
+
+// filterElements returns the elements of seq without the edges for
+// which keep returns false.
+func filterElements(seq iter.Seq2[element, error], keep func(e edge) bool) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		for elem, err := range seq {
+			if err != nil {
+				yield(elem, err)
+				return
+			}
+			if elem.isEdge && !keep(elem.e) {
+				continue
+			}
+			if !yield(elem, nil) {
+				return
+			}
+		}
+	}
+}
+
+func runFilter(args []string) {
+	fs := flag.NewFlagSet("filter", flag.ExitOnError)
+	expr := fs.String("expr", "", "keep the edges matching `expression`")
+	prune := fs.Bool("prune", false, "remove the vertices without edges")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "filter -expr expression [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *expr == "" {
+		fatal(errors.New("-expr is required"))
+	}
+	keep, err := parseEdgeFilter(*expr)
+	if err != nil {
+		fatal(fmt.Errorf("invalid expression: %w", err))
+	}
+
+	seq := filterElements(decodeGraph(in), keep)
+	if *prune {
+		// The vertices can precede their edges, so the graph
+		// is read into memory.
+		d, err := loadGraph(seq)
+		if err != nil {
+			fatal(err)
+		}
+		used := make(map[int64]struct{})
+		for _, e := range d.edges {
+			used[e.tail] = struct{}{}
+			used[e.head] = struct{}{}
+		}
+		seq = inducedElements(d.elements(), func(id int64) bool {
+			_, ok := used[id]
+			return ok
+		})
+	}
+
+	err = of.write(func(enc encoder) error {
+		return encodeElements(enc, seq)
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
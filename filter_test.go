@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEdgeFilter(t *testing.T) {
+	edges := []edge{
+		{tail: 0, head: 0, etype: "knows"},
+		{tail: 0, head: 1, etype: "knows", time: 10},
+		{tail: 1, head: 2, etype: "likes", time: 20},
+		{tail: 2, head: 0},
+	}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "tail != head", want: "0111"},
+		{expr: `type == "knows" && tail != head`, want: "0100"},
+		{expr: `type == "likes" || time < 5`, want: "1011"},
+		{expr: `!(time >= 10) && head >= 0`, want: "1001"},
+		{expr: `type != "" && !(tail == 0)`, want: "0010"},
+		{expr: "time > -1 && (head == 1 || head == 2)", want: "0110"},
+		{expr: `type < "l"`, want: "1101"},
+	}
+	for _, tt := range tests {
+		keep, err := parseEdgeFilter(tt.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.expr, err)
+			continue
+		}
+		var b strings.Builder
+		for _, e := range edges {
+			if keep(e) {
+				b.WriteByte('1')
+			} else {
+				b.WriteByte('0')
+			}
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%q: got: %v want: %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseEdgeFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "weight > 0", wantErr: `unknown attribute: "weight"`},
+		{expr: `type == 1`, wantErr: "mismatched comparison operands"},
+		{expr: "tail", wantErr: "missing comparison operator"},
+		{expr: "tail ==", wantErr: "unexpected end of expression"},
+		{expr: "(tail == 1", wantErr: "missing )"},
+		{expr: "tail == 1 head", wantErr: `unexpected token: "head"`},
+		{expr: `type == "knows`, wantErr: "unterminated string"},
+		{expr: "tail = 1", wantErr: `unexpected character: '='`},
+	}
+	for _, tt := range tests {
+		_, err := parseEdgeFilter(tt.expr)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%q: unexpected error: got: %v want: %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// fitModel returns the generate flags of the provided model that
+// reproduce the gross statistics of the graph summarized by st. comms
+// holds the community of every vertex that belongs to one. The models
+// are:
+//
+//   - gnp: the binomial model with the same mean out-degree.
+//   - ba: stub matching with the constant out-degree and the power
+//     law in-degree of the Barabási-Albert model, with the exponent
+//     estimated by maximum likelihood.
+//   - sbm: an LFR benchmark, which plants communities like a
+//     stochastic block model, with the community sizes and the
+//     fraction of edges between communities of the graph.
+func fitModel(model string, st *graphStats, comms map[int64]string) ([]string, error) {
+	n := int64(len(st.degrees))
+	if n == 0 {
+		return nil, errors.New("empty graph")
+	}
+	mean := float64(st.edges) / float64(n)
+	args := []string{"-n", strconv.FormatInt(n, 10)}
+
+	switch model {
+	case "gnp":
+		trials := max(1, int(math.Ceil(2*mean)))
+		args = append(args,
+			"-trials", strconv.Itoa(trials),
+			"-prob", formatParam(mean/float64(trials)),
+		)
+	case "ba":
+		var k, sum float64
+		var maxIn int64
+		for _, d := range st.degrees {
+			if d[1] == 0 {
+				continue
+			}
+			k++
+			sum += math.Log(float64(d[1]) + 0.5)
+			maxIn = max(maxIn, d[1])
+		}
+		if k == 0 || sum == 0 {
+			return nil, errors.New("cannot fit the in-degree power law")
+		}
+		m := max(1, int(math.Round(mean)))
+		args = append(args,
+			"-out-degree", fmt.Sprintf("const:%v", m),
+			"-in-degree", fmt.Sprintf("powerlaw:%v,1,%v", formatParam(1+k/sum), maxIn),
+		)
+	case "sbm":
+		sizes := make(map[string]int64)
+		for _, c := range comms {
+			sizes[c]++
+		}
+		if len(sizes) == 0 {
+			return nil, errors.New("the sbm model requires community attributes")
+		}
+		minSize, maxSize := int64(math.MaxInt64), int64(0)
+		for _, size := range sizes {
+			minSize = min(minSize, size)
+			maxSize = max(maxSize, size)
+		}
+		var inter, total int64
+		for e := range st.seen {
+			ct, ok1 := comms[e.tail]
+			ch, ok2 := comms[e.head]
+			if !ok1 || !ok2 {
+				continue
+			}
+			total++
+			if ct != ch {
+				inter++
+			}
+		}
+		var mu float64
+		if total > 0 {
+			mu = float64(inter) / float64(total)
+		}
+		args = append(args,
+			"-out-degree", "poisson:"+formatParam(mean),
+			"-in-degree", "poisson:"+formatParam(mean),
+			"-community-sizes", fmt.Sprintf("uniform:%v,%v", minSize, maxSize),
+			"-mu", formatParam(mu),
+		)
+	default:
+		return nil, fmt.Errorf("unknown model: %v", model)
+	}
+
+	if st.loops > 0 {
+		args = append(args, "-loops")
+	}
+	if st.multiedges > 0 {
+		args = append(args, "-multiedges")
+	}
+	return args, nil
+}
+
+// formatParam formats a model parameter with 4 significant digits.
+func formatParam(f float64) string {
+	return strconv.FormatFloat(f, 'g', 4, 64)
+}
+
+func runFit(args []string) {
+	fs := flag.NewFlagSet("fit", flag.ExitOnError)
+	model := fs.String("model", "gnp", "`model` to fit (gnp, ba, sbm)")
+	fs.Usage = commandUsage(fs, "fit [-model model] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	switch *model {
+	case "gnp", "ba", "sbm":
+	default:
+		fatal(fmt.Errorf("unknown model: %v", *model))
+	}
+
+	st := newGraphStats()
+	comms := make(map[int64]string)
+	for elem, err := range decodeGraph(in) {
+		if err != nil {
+			fatal(err)
+		}
+		st.add(elem)
+		if !elem.isEdge && elem.v.community != "" {
+			comms[elem.v.id] = elem.v.community
+		}
+	}
+
+	genArgs, err := fitModel(*model, st, comms)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Println("mkdigraph " + strings.Join(genArgs, " "))
+}
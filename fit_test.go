@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFitModel(t *testing.T) {
+	const input = "V: 0 A community=x\nV: 1 B community=x\nV: 2 C community=y\nV: 3 D\n" +
+		"E: 0 1\nE: 1 0\nE: 1 2\nE: 2 2\nE: 3 0\nE: 3 0\n"
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "gnp", want: "-n 4 -trials 3 -prob 0.5 -loops -multiedges"},
+		{model: "ba", want: "-n 4 -out-degree const:2 -in-degree powerlaw:2.165,1,3 -loops -multiedges"},
+		{model: "sbm", want: "-n 4 -out-degree poisson:1.5 -in-degree poisson:1.5 -community-sizes uniform:1,2 -mu 0.25 -loops -multiedges"},
+	}
+	for _, tt := range tests {
+		st := newGraphStats()
+		comms := make(map[int64]string)
+		for elem, err := range decodeSimple(strings.NewReader(input)) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			st.add(elem)
+			if !elem.isEdge && elem.v.community != "" {
+				comms[elem.v.id] = elem.v.community
+			}
+		}
+		args, err := fitModel(tt.model, st, comms)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.model, err)
+			continue
+		}
+		if got := strings.Join(args, " "); got != tt.want {
+			t.Errorf("%v: got: %q want: %q", tt.model, got, tt.want)
+		}
+	}
+
+	if _, err := fitModel("sbm", newGraphStats(), nil); err == nil {
+		t.Error("expected error fitting an empty graph")
+	}
+}
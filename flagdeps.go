@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// A usageError is an error in the command line, like an invalid flag
+// value. mkdigraph exits with status 2 on them, like it does on flags
+// that cannot be parsed.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+
+func (e usageError) Unwrap() error { return e.err }
+
+// checkRanges returns an error if the number of vertices, the number
+// of trials or the success probability are out of range.
+func (c *genConfig) checkRanges() error {
+	if c.profile == "" && !c.infinite && c.vertices < 1 {
+		return usageError{fmt.Errorf("invalid number of vertices: %v (must be at least 1)", c.vertices)}
+	}
+	if c.trials < 0 {
+		return usageError{fmt.Errorf("invalid number of trials: %v (must be at least 0)", c.trials)}
+	}
+	// NaN is rejected too.
+	if !(c.prob >= 0 && c.prob <= 1) {
+		return usageError{fmt.Errorf("invalid probability: %v (must be between 0 and 1)", c.prob)}
+	}
+	return nil
+}
+
+// usesBloomFilter reports whether -dedup=bloom removes the multiple
+// edges of the generation.
+func (c *genConfig) usesBloomFilter() bool {
+	return c.dedup == "bloom" && c.bloomModel()
+}
+
+// bloomModel reports whether the model of c can be deduplicated with
+// -dedup=bloom. The other models remove multiple edges exactly while
+// only keeping the heads of the current vertex in memory. The Bloom
+// filter replaces the set of edges of -community-sizes, and
+// deduplicates -profile=graph500 and -plugin, which keep multiple
+// edges.
+func (c *genConfig) bloomModel() bool {
+	return c.commSizes != "" || c.profile == "graph500" || c.plugin != ""
+}
+
+// binomialModel reports whether the graph is generated with the
+// binomial model, with or without -trials-file, which is the only
+// model supported by some flags.
+func (c *genConfig) binomialModel() bool {
+	return !c.infinite && c.snapshots == 0 && c.commSizes == "" && c.outDegree == "" && c.inDegree == "" && c.triad == 0 && c.profile == "" && c.plugin == "" && c.blocksFile == ""
+}
+
+// degenerate returns the reasons why the generation is guaranteed to
+// produce a degenerate graph, if any.
+func (c *genConfig) degenerate() []string {
+	var reasons []string
+	trialModel := (c.binomialModel() && c.trialsFile == "") || c.infinite || c.snapshots > 0 || c.triad > 0
+	if trialModel && (c.trials == 0 || c.prob == 0) {
+		reasons = append(reasons, "the expected number of edges is 0")
+	}
+	if c.profile == "" && c.plugin == "" && c.blocksFile == "" && !c.infinite && c.vertices == 1 && !c.loops {
+		reasons = append(reasons, "a single vertex without -loops cannot have edges")
+	}
+	if c.churn == 1 {
+		reasons = append(reasons, "-churn=1 removes every edge right after adding it")
+	}
+	return reasons
+}
+
+// checkFlagDeps returns an error if a flag explicitly set in fs has no
+// effect because of the other flags of c, so misconfigured generations
+// fail instead of silently ignoring the flag. Flags only set from the
+// environment are defaults, and are not checked.
+func (c *genConfig) checkFlagDeps(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		if setOnCommandLine(f) {
+			set[f.Name] = true
+		}
+	})
+
+	for _, dep := range c.flagDeps() {
+		if set[dep.name] && !dep.ok {
+			return usageError{errors.New(dep.err)}
+		}
+	}
+	return nil
+}
+
+// inert reports whether the flag with the provided name has no effect
+// because of the other flags of c.
+func (c *genConfig) inert(name string) bool {
+	for _, dep := range c.flagDeps() {
+		if dep.name == name && !dep.ok {
+			return true
+		}
+	}
+	return false
+}
+
+// A flagDep is a condition that must hold for a flag to have effect.
+type flagDep struct {
+	name string
+	ok   bool
+	err  string
+}
+
+// flagDeps returns the conditions of the flags that only have effect
+// in combination with other flags of c.
+func (c *genConfig) flagDeps() []flagDep {
+	words := c.wordsFile != "" || c.typeWords != "" || c.labelPools != ""
+	return []flagDep{
+		{"n", c.profile == "", "-n cannot be combined with -profile, use -scale or -scale-factor instead"},
+		{"n", c.blocksFile == "", "-n has no effect with -blocks, the number of vertices is the sum of the block sizes"},
+		{"trials", c.profile == "" && c.plugin == "" && c.blocksFile == "" && (c.outDegree == "" || c.inDegree == ""), "-trials has no effect with -profile, -plugin, -blocks or with both -out-degree and -in-degree"},
+		{"prob", c.profile == "" && c.plugin == "" && c.blocksFile == "" && (c.outDegree == "" || c.inDegree == ""), "-prob has no effect with -profile, -plugin, -blocks or with both -out-degree and -in-degree"},
+		{"scale", c.profile == "graph500", "-scale requires -profile=graph500"},
+		{"edgefactor", c.profile == "graph500", "-edgefactor requires -profile=graph500"},
+		{"scale-factor", c.profile == "ldbc", "-scale-factor requires -profile=ldbc"},
+		{"mixing", c.commsFile != "", "-mixing requires -communities"},
+		{"mu", c.commSizes != "", "-mu requires -community-sizes"},
+		{"propensity", c.blocksFile != "", "-propensity requires -blocks"},
+		{"dedup", c.dedup != "bloom" || c.bloomModel(), "-dedup=bloom has no effect unless -community-sizes, -profile=graph500 or -plugin is specified, the other models remove multiple edges exactly"},
+		{"dedup-fp", c.usesBloomFilter(), "-dedup-fp requires -dedup=bloom with -community-sizes, -profile=graph500 or -plugin"},
+		{"burst", c.rate > 0, "-burst requires -rate"},
+		{"overflow-buffer", c.dropOnOver, "-overflow-buffer requires -drop-on-overflow"},
+		{"verify", !c.bench, "-verify has no effect with -bench"},
+		{"checkpoint-interval", c.checkpoint != "", "-checkpoint-interval requires -checkpoint"},
+		{"transliterate", words, "-transliterate requires -words, -type-words or -label-pools"},
+		{"words-encoding", words, "-words-encoding requires -words, -type-words or -label-pools"},
+		{"label-case", words, "-label-case requires -words, -type-words or -label-pools"},
+		{"on-label-collision", words, "-on-label-collision requires -words, -type-words or -label-pools"},
+	}
+}
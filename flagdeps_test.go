@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckFlagDeps(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: nil, wantErr: false},
+		{args: []string{"-mixing=0.5"}, wantErr: true},
+		{args: []string{"-mixing=0.5", "-communities=comms.txt"}, wantErr: false},
+		{args: []string{"-profile=graph500", "-n=10"}, wantErr: true},
+		{args: []string{"-profile=graph500", "-scale=10"}, wantErr: false},
+		{args: []string{"-scale=10"}, wantErr: true},
+		{args: []string{"-out-degree=const:2", "-in-degree=const:2", "-prob=0.1"}, wantErr: true},
+		{args: []string{"-out-degree=const:2", "-prob=0.1"}, wantErr: false},
+		{args: []string{"-burst=10"}, wantErr: true},
+		{args: []string{"-burst=10", "-rate=100"}, wantErr: false},
+		{args: []string{"-verify", "-bench"}, wantErr: true},
+		{args: []string{"-dedup=bloom"}, wantErr: true},
+		{args: []string{"-dedup=bloom", "-triad-formation=0.5"}, wantErr: true},
+		{args: []string{"-dedup=bloom", "-profile=graph500"}, wantErr: false},
+		{args: []string{"-dedup=none"}, wantErr: false},
+		{args: []string{"-dedup=bloom", "-dedup-fp=0.1"}, wantErr: true},
+		{args: []string{"-dedup=bloom", "-dedup-fp=0.1", "-profile=graph500"}, wantErr: false},
+		{args: []string{"-label-case=lower"}, wantErr: true},
+		{args: []string{"-label-case=lower", "-words=words.txt"}, wantErr: false},
+		{args: []string{"-words-encoding=latin1"}, wantErr: true},
+		{args: []string{"-words-encoding=latin1", "-words=words.txt"}, wantErr: false},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.checkFlagDeps(fs); (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.args, err)
+		}
+	}
+}
+
+func TestCheckFlagDepsEnv(t *testing.T) {
+	t.Setenv("MKDIGRAPH_TRIALS", "3")
+
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: []string{"-profile=graph500", "-scale=2"}, wantErr: false},
+		{args: []string{"-profile=graph500", "-scale=2", "-trials=4"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := setFromEnv(fs); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.checkFlagDeps(fs); (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.args, err)
+		}
+	}
+}
+
+func TestCheckRanges(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: nil, wantErr: false},
+		{args: []string{"-n=0"}, wantErr: true},
+		{args: []string{"-n=0", "-infinite"}, wantErr: false},
+		{args: []string{"-trials=-1"}, wantErr: true},
+		{args: []string{"-prob=1.5"}, wantErr: true},
+		{args: []string{"-prob=NaN"}, wantErr: true},
+		{args: []string{"-prob=0"}, wantErr: false},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err := c.checkRanges()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.args, err)
+		}
+		if err != nil && !errors.As(err, new(usageError)) {
+			t.Errorf("%v: not a usage error: %v", tt.args, err)
+		}
+	}
+}
+
+func TestDegenerate(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{args: nil, want: 0},
+		{args: []string{"-prob=0"}, want: 1},
+		{args: []string{"-n=1"}, want: 1},
+		{args: []string{"-n=1", "-loops"}, want: 0},
+		{args: []string{"-n=1", "-trials=0"}, want: 2},
+		{args: []string{"-trials=0", "-out-degree=const:2"}, want: 0},
+		{args: []string{"-trials=0", "-triad-formation=0.5"}, want: 1},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := c.degenerate(); len(got) != tt.want {
+			t.Errorf("%v: unexpected reasons: got: %q, want %v reasons", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestBinomialModel(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: true},
+		{args: []string{"-trials-file=trials.txt"}, want: true},
+		{args: []string{"-infinite"}, want: false},
+		{args: []string{"-snapshots=2"}, want: false},
+		{args: []string{"-out-degree=const:2"}, want: false},
+		{args: []string{"-community-sizes=const:2"}, want: false},
+		{args: []string{"-triad-formation=0.5"}, want: false},
+		{args: []string{"-profile=graph500"}, want: false},
+		{args: []string{"-plugin=model.so"}, want: false},
+		{args: []string{"-blocks=blocks.txt"}, want: false},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := c.binomialModel(); got != tt.want {
+			t.Errorf("%v: unexpected result: got: %v want: %v", tt.args, got, tt.want)
+		}
+	}
+}
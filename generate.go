@@ -0,0 +1,1452 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// A genConfig holds the parameters of the generate command.
+type genConfig struct {
+	vertices   int64
+	seed       uint64
+	rng        string
+	workers    int
+	vertexTime string
+	coords     string
+	vtypes     string
+	typeEdges  string
+	typeWords  string
+	etypes     string
+	vrange     string
+	infinite   bool
+	churn      float64
+	trials     int
+	trialsFile string
+	prob       float64
+	loops      bool
+	multiedges bool
+	outDegree  string
+	inDegree   string
+	assort     float64
+	locality   string
+	closure    float64
+	triad      float64
+	plugin     string
+	profile    string
+	scale      int
+	edgefactor int
+	sf         float64
+	edgeTuples bool
+	commsFile  string
+	mixing     float64
+	commSizes  string
+	mu         float64
+	blocksFile string
+	propensity string
+	dedup      string
+	dedupFP    float64
+	idStart    int64
+	idStride   int64
+	idWidth    int
+	wordsFile  string
+	collisions string
+	strict     bool
+	verify     bool
+	golden     bool
+	labels     string
+	translit   bool
+	wordsEnc   string
+	labelCase  string
+	labelPools string
+	labelDepth int
+	emit       string
+	interleave bool
+	rate       float64
+	burst      int
+	dropOnOver bool
+	overBuffer byteSize
+	tees       teeFlag
+	nul        bool
+	crlf       bool
+	emitDOT    bool
+	dotStrict  bool
+	dotName    string
+	dotTooltip string
+	dotURL     string
+	outFile    string
+	snapshots  int
+	snapDir    string
+	deltas     bool
+	appendOut  bool
+	atomic     bool
+	meta       bool
+	logFormat  string
+	ipc        bool
+	version    bool
+	bench      bool
+	benchJSON  bool
+	cpuProfile string
+	memProfile string
+	manifest   string
+	checksum   string
+	maxMem     byteSize
+	checkpoint string
+	cpInterval time.Duration
+	resume     bool
+}
+
+// flagSet returns a flag set that parses the generate flags into c.
+func (c *genConfig) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Int64Var(&c.vertices, "n", 25, "number of vertices")
+	fs.Uint64Var(&c.seed, "seed", 0, "seed of the random number generator (0 means random)")
+	fs.StringVar(&c.rng, "rng", "pcg", "random number generator `algorithm` (pcg, chacha8, mt19937)")
+	fs.StringVar(&c.vertexTime, "vertex-time", "", "assign creation times to vertices and edges starting at `start,interval`")
+	fs.StringVar(&c.coords, "coords", "", "assign coordinates within the bounding `box` minlat,minlon,maxlat,maxlon to vertices")
+	fs.StringVar(&c.vtypes, "vertex-types", "", "assign vertex types from a `list` of type:weight pairs")
+	fs.StringVar(&c.typeEdges, "type-edges", "", "only keep edges between the types in a `list` of tail>head pairs")
+	fs.StringVar(&c.typeWords, "type-words", "", "choose the labels of every type from the words files in a `list` of type=file pairs")
+	fs.StringVar(&c.etypes, "edge-types", "", "assign edge types from a `list` of type:weight pairs")
+	fs.IntVar(&c.workers, "workers", 1, "number of `goroutines` generating edges")
+	fs.StringVar(&c.vrange, "vertex-range", "", "only generate the vertices in `a:b` and their edges")
+	fs.BoolVar(&c.infinite, "infinite", false, "generate a growing graph until interrupted")
+	fs.Float64Var(&c.churn, "churn", 0, "probability of removing an edge after adding one with -infinite")
+	fs.IntVar(&c.trials, "trials", 5, "number of edge creation trials per vertex")
+	fs.StringVar(&c.trialsFile, "trials-file", "", "read per-vertex trials from a `file`")
+	fs.Float64Var(&c.prob, "prob", 0.5, "success probability for each trial")
+	fs.BoolVar(&c.strict, "strict", false, "reject parameters that produce degenerate graphs")
+	fs.BoolVar(&c.verify, "verify", false, "check that the written graph satisfies the constraints of the generation")
+	fs.BoolVar(&c.loops, "loops", false, "allow loops")
+	fs.BoolVar(&c.multiedges, "multiedges", false, "allow multiple edges")
+	fs.StringVar(&c.outDegree, "out-degree", "", "out-degree `distribution`")
+	fs.StringVar(&c.inDegree, "in-degree", "", "in-degree `distribution`")
+	fs.Float64Var(&c.assort, "assortativity", 0, "degree assortativity `bias` of stub matching, between -1 and 1")
+	fs.StringVar(&c.locality, "locality", "", "edge locality `decay`")
+	fs.Float64Var(&c.closure, "closure", 0, "triangle closure probability")
+	fs.StringVar(&c.profile, "profile", "", "generate a graph following a benchmark `profile` (graph500, ldbc)")
+	fs.IntVar(&c.scale, "scale", 16, "base 2 logarithm of the number of vertices of the Graph500 profile")
+	fs.IntVar(&c.edgefactor, "edgefactor", 16, "ratio of edges to vertices of the Graph500 profile")
+	fs.Float64Var(&c.sf, "scale-factor", 1, "scale factor of the LDBC profile")
+	fs.BoolVar(&c.edgeTuples, "edge-tuples", false, "write edges as Graph500 binary edge tuples")
+	fs.Float64Var(&c.triad, "triad-formation", 0, "generate a Holme-Kim graph with triad formation probability `p`")
+	fs.StringVar(&c.plugin, "plugin", "", "generate the edges with the model exported by a Go plugin `file`")
+	fs.StringVar(&c.commsFile, "communities", "", "assign vertices to the communities in a `file`")
+	fs.Float64Var(&c.mixing, "mixing", 1, "probability of keeping edges between communities")
+	fs.StringVar(&c.commSizes, "community-sizes", "", "generate an LFR benchmark with community sizes drawn from `distribution`")
+	fs.Float64Var(&c.mu, "mu", 0.1, "fraction of edges between communities of the LFR benchmark")
+	fs.StringVar(&c.blocksFile, "blocks", "", "generate a stochastic block model with the blocks in a `file`")
+	fs.StringVar(&c.propensity, "propensity", "", "degree-correct the stochastic block model with vertex propensities drawn from `distribution`")
+	fs.StringVar(&c.dedup, "dedup", "none", "edge deduplication `mode` (none, bloom)")
+	fs.Float64Var(&c.dedupFP, "dedup-fp", 0.01, "false positive rate of the Bloom filter")
+	fs.Int64Var(&c.idStart, "id-start", 0, "ID of the first vertex")
+	fs.Int64Var(&c.idStride, "id-stride", 1, "difference between consecutive vertex IDs")
+	fs.IntVar(&c.idWidth, "id-width", 0, "minimum `width` of vertex IDs")
+	fs.StringVar(&c.wordsFile, "words", "", "choose vertex labels from a words file")
+	fs.StringVar(&c.labels, "labels", "id", "vertex label `mode` (id, hash)")
+	fs.BoolVar(&c.translit, "transliterate", false, "transliterate the accented letters of words files instead of removing them")
+	fs.StringVar(&c.wordsEnc, "words-encoding", "utf-8", "character `encoding` of words files (utf-8, latin1)")
+	fs.StringVar(&c.labelCase, "label-case", "keep", "`case` of word labels (keep, lower, upper, title)")
+	fs.StringVar(&c.labelPools, "label-pools", "", "build dotted labels from the words files in a `list`, one per level")
+	fs.IntVar(&c.labelDepth, "label-depth", 0, "number of `levels` of dotted labels (0 means one per pool)")
+	fs.StringVar(&c.collisions, "on-label-collision", "suffix", "`strategy` when word labels repeat (suffix, error, reuse)")
+	fs.StringVar(&c.emit, "emit", "both", "`records` to emit (vertices, edges, both)")
+	fs.BoolVar(&c.interleave, "interleave", false, "write edges as soon as their endpoints are written")
+	fs.Float64Var(&c.rate, "rate", 0, "maximum number of edges written per second")
+	fs.IntVar(&c.burst, "burst", 1, "maximum number of edges written at once with -rate")
+	fs.Var(&c.tees, "tee", "also write the graph to `format:file` (simple, dot, edge-tuples); can be repeated")
+	fs.BoolVar(&c.dropOnOver, "drop-on-overflow", false, "drop edges instead of waiting while the output buffer is full")
+	c.overBuffer = 1 << 20
+	fs.Var(&c.overBuffer, "overflow-buffer", "`size` of the output buffer of -drop-on-overflow")
+	fs.BoolVar(&c.nul, "z", false, "terminate records with NUL instead of newline")
+	fs.BoolVar(&c.crlf, "crlf", false, "terminate lines with CRLF instead of LF")
+	fs.BoolVar(&c.emitDOT, "dot", false, "emit DOT output")
+	fs.BoolVar(&c.dotStrict, "dot-strict", false, "declare the DOT graph strict")
+	fs.StringVar(&c.dotName, "dot-name", "", "identifier of the DOT graph")
+	fs.StringVar(&c.dotTooltip, "dot-tooltip", "", "`template` of the tooltips of the DOT nodes")
+	fs.StringVar(&c.dotURL, "dot-url", "", "`template` of the URLs of the DOT nodes")
+	fs.StringVar(&c.outFile, "o", "", "output file")
+	fs.IntVar(&c.snapshots, "snapshots", 0, "write `k` snapshots of a growing graph")
+	fs.StringVar(&c.snapDir, "snapshot-dir", ".", "`directory` of the snapshots")
+	fs.BoolVar(&c.deltas, "deltas", false, "write a delta file along with every snapshot")
+	fs.BoolVar(&c.appendOut, "append", false, "append to the output file")
+	fs.BoolVar(&c.atomic, "atomic", false, "replace the output file only on success")
+	fs.StringVar(&c.checkpoint, "checkpoint", "", "periodically write a checkpoint `file`")
+	fs.DurationVar(&c.cpInterval, "checkpoint-interval", time.Minute, "time between checkpoints")
+	fs.BoolVar(&c.resume, "resume", false, "resume the generation from the checkpoint file")
+	fs.StringVar(&c.checksum, "checksum", "", "write the checksums of the output files computed with `algorithm` (sha256)")
+	fs.StringVar(&c.manifest, "manifest", "", "write a manifest of the output files to `file`")
+	fs.BoolVar(&c.meta, "meta", true, "record the generation parameters in the output")
+	fs.BoolVar(&c.golden, "golden", false, "write byte-stable output for snapshot tests")
+	fs.StringVar(&c.logFormat, "log", "text", "diagnostics `format` (text, json)")
+	fs.BoolVar(&c.ipc, "ipc", false, "serve generation requests on the standard input and output")
+	fs.BoolVar(&c.bench, "bench", false, "report the generation throughput instead of writing the graph")
+	fs.BoolVar(&c.benchJSON, "bench-json", false, "report the benchmark results as JSON")
+	fs.Var(&c.maxMem, "max-mem", "maximum estimated memory `size` of the generation (0 means unlimited)")
+	fs.StringVar(&c.cpuProfile, "cpuprofile", "", "write a CPU profile to `file`")
+	fs.StringVar(&c.memProfile, "memprofile", "", "write a memory profile to `file`")
+	fs.BoolVar(&c.version, "version", false, "print version and exit")
+	return fs
+}
+
+// A generation is a graph ready to be written.
+type generation struct {
+	g       graph
+	p       printer
+	emitDOT bool
+
+	// snapshots are the number of vertices of every snapshot
+	// written by writeSnapshots.
+	snapshots []int64
+
+	// deltas reports whether writeSnapshots writes delta files.
+	deltas bool
+
+	// rate is the maximum number of edges written per second. If
+	// 0, the rate is not limited. burst is the maximum number of
+	// edges written at once.
+	rate  float64
+	burst int
+
+	// dedup is the Bloom filter deduplicator used by -dedup=bloom.
+	// It is nil for other modes.
+	dedup *bloomDedup
+
+	// counter counts the written vertices and edges.
+	counter *counter
+
+	// tees are the additional outputs written by writeFile.
+	tees []teeOutput
+
+	// teeEncs are the encoders of the additional outputs while
+	// they are written.
+	teeEncs []encoder
+
+	// overflow is the size of the output buffer of
+	// -drop-on-overflow. If 0, writing waits for the consumer.
+	overflow int64
+
+	// full reports whether the output buffer is full while
+	// writing with -drop-on-overflow. Otherwise, it is nil.
+	full func() bool
+
+	// dropped is the number of edges dropped because the output
+	// buffer was full.
+	dropped int64
+
+	// stages accumulates the time spent in the stages of the
+	// pipeline. It is nil if tracing is not configured.
+	stages *stageTimes
+
+	// verifier checks the written graph. It is nil if -verify is
+	// not specified.
+	verifier *verifier
+
+	// problems are the problems found by the verifier, and
+	// verified reports whether it has finished.
+	problems []error
+	verified bool
+
+	// checkpointer writes checkpoints. It is nil if -checkpoint
+	// is not specified.
+	checkpointer *checkpointer
+
+	// files are the output files written so far.
+	files []writtenFile
+
+	// checksum reports whether the checksums of the output files
+	// are computed as they are written.
+	checksum bool
+
+	// resumed is the checkpoint the generation is resumed from.
+	// It is nil if -resume is not specified.
+	resumed *checkpoint
+}
+
+// parseGenerate parses the generate flags in args into a new
+// configuration. Unlike the command line, it does not exit or print
+// anything on errors, so it can be used by the embedded interfaces of
+// mkdigraph.
+func parseGenerate(args []string) (*genConfig, *flag.FlagSet, error) {
+	var c genConfig
+	fs := c.flagSet("generate")
+	fs.Init("generate", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, usageError{err}
+	}
+	if fs.NArg() != 0 {
+		return nil, nil, usageError{fmt.Errorf("unexpected arguments: %q", fs.Args())}
+	}
+	return &c, fs, nil
+}
+
+// newGeneration validates c and returns the corresponding generation.
+// fs is the flag set used to parse c. It is recorded in the output
+// metadata.
+func (c *genConfig) newGeneration(fs *flag.FlagSet) (*generation, error) {
+	if err := c.checkFlagDeps(fs); err != nil {
+		return nil, err
+	}
+	if err := c.checkRanges(); err != nil {
+		return nil, err
+	}
+	if reasons := c.degenerate(); c.strict && len(reasons) > 0 {
+		return nil, usageError{fmt.Errorf("degenerate graph: %v", reasons[0])}
+	}
+
+	if c.golden {
+		if c.infinite || c.snapshots > 0 || c.interleave || c.checkpoint != "" {
+			return nil, usageError{errors.New("-golden cannot be combined with -infinite, -snapshots, -interleave or -checkpoint")}
+		}
+		if c.seed == 0 {
+			// The seed is set through fs, so it is recorded in
+			// the metadata.
+			fs.Set("seed", strconv.Itoa(goldenSeed))
+		}
+	}
+
+	if c.closure < 0 || c.closure > 1 {
+		return nil, fmt.Errorf("invalid triangle closure probability: %v", c.closure)
+	}
+
+	var net ldbcNetwork
+	if c.profile != "" {
+		if c.infinite || c.snapshots > 0 || c.commSizes != "" || c.outDegree != "" || c.inDegree != "" || c.trialsFile != "" || c.triad > 0 {
+			return nil, errors.New("-profile cannot be combined with -infinite, -snapshots, -out-degree, -in-degree, -community-sizes, -trials-file or -triad-formation")
+		}
+		switch c.profile {
+		case "graph500":
+			if err := checkGraph500(c.scale, c.edgefactor); err != nil {
+				return nil, err
+			}
+			c.vertices = 1 << c.scale
+		case "ldbc":
+			if c.vtypes != "" || c.etypes != "" || c.vertexTime != "" {
+				return nil, errors.New("-profile=ldbc cannot be combined with -vertex-types, -edge-types or -vertex-time")
+			}
+			var err error
+			if net, err = newLDBCNetwork(c.sf, 0, 0); err != nil {
+				return nil, err
+			}
+			c.vertices = net.vertices()
+		default:
+			return nil, fmt.Errorf("unknown profile: %q", c.profile)
+		}
+	}
+
+	if c.triad != 0 {
+		if c.triad < 0 || c.triad > 1 {
+			return nil, fmt.Errorf("invalid triad formation probability: %v", c.triad)
+		}
+		if c.infinite || c.snapshots > 0 || c.commSizes != "" || c.outDegree != "" || c.inDegree != "" || c.trialsFile != "" {
+			return nil, errors.New("-triad-formation cannot be combined with -infinite, -snapshots, -out-degree, -in-degree, -community-sizes or -trials-file")
+		}
+	}
+
+	var model modelFunc
+	if c.plugin != "" {
+		if c.infinite || c.snapshots > 0 || c.commSizes != "" || c.outDegree != "" || c.inDegree != "" || c.trialsFile != "" || c.triad > 0 || c.profile != "" {
+			return nil, errors.New("-plugin cannot be combined with -profile, -infinite, -snapshots, -out-degree, -in-degree, -community-sizes, -trials-file or -triad-formation")
+		}
+		var err error
+		if model, err = loadModel(c.plugin); err != nil {
+			return nil, err
+		}
+	}
+
+	var blocks *blockModel
+	if c.blocksFile != "" {
+		if c.infinite || c.snapshots > 0 || c.commSizes != "" || c.outDegree != "" || c.inDegree != "" || c.trialsFile != "" || c.triad > 0 || c.profile != "" || c.plugin != "" {
+			return nil, errors.New("-blocks cannot be combined with -profile, -plugin, -infinite, -snapshots, -out-degree, -in-degree, -community-sizes, -trials-file or -triad-formation")
+		}
+		if c.commsFile != "" {
+			return nil, errors.New("-blocks cannot be combined with -communities")
+		}
+		var err error
+		if blocks, err = readBlocks(c.blocksFile); err != nil {
+			return nil, err
+		}
+		c.vertices = blocks.vertices()
+	}
+
+	if c.assort != 0 {
+		if c.assort < -1 || c.assort > 1 {
+			return nil, fmt.Errorf("invalid assortativity: %v", c.assort)
+		}
+		if (c.outDegree == "" && c.inDegree == "") || c.commSizes != "" {
+			return nil, errors.New("-assortativity requires -out-degree or -in-degree without -community-sizes")
+		}
+	}
+
+	var decay decayFunc
+	if c.locality != "" {
+		if !c.binomialModel() {
+			return nil, errors.New("-locality only supports the binomial model")
+		}
+		var err error
+		if decay, err = parseDecay(c.locality); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.mixing < 0 || c.mixing > 1 {
+		return nil, fmt.Errorf("invalid mixing probability: %v", c.mixing)
+	}
+
+	if c.mu < 0 || c.mu > 1 {
+		return nil, fmt.Errorf("invalid mixing parameter: %v", c.mu)
+	}
+
+	if c.commSizes != "" && c.commsFile != "" {
+		return nil, errors.New("-community-sizes cannot be combined with -communities")
+	}
+
+	if c.snapshots < 0 {
+		return nil, fmt.Errorf("invalid number of snapshots: %v", c.snapshots)
+	}
+
+	// The growing graph is generated with -infinite and
+	// -snapshots.
+	growing := c.infinite || c.snapshots > 0
+	if growing {
+		name := "-infinite"
+		if c.snapshots > 0 {
+			name = "-snapshots"
+		}
+		if c.infinite && c.snapshots > 0 {
+			return nil, errors.New("-infinite cannot be combined with -snapshots")
+		}
+		if c.commSizes != "" || c.outDegree != "" || c.inDegree != "" || c.trialsFile != "" {
+			return nil, fmt.Errorf("%v cannot be combined with -out-degree, -in-degree, -community-sizes or -trials-file", name)
+		}
+		if c.closure > 0 || c.dedup != "none" {
+			return nil, fmt.Errorf("%v cannot be combined with -closure or -dedup", name)
+		}
+	}
+
+	if c.churn != 0 {
+		if c.churn < 0 || c.churn > 1 {
+			return nil, fmt.Errorf("invalid churn probability: %v", c.churn)
+		}
+		if !c.infinite {
+			return nil, errors.New("-churn requires -infinite")
+		}
+		if c.emitDOT || c.locality != "" || c.commsFile != "" {
+			return nil, errors.New("-churn cannot be combined with -dot, -locality or -communities")
+		}
+	}
+
+	if c.infinite {
+		if err := checkIDs(0, c.idStart, c.idStride); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := checkSize(c.vertices, c.trials); err != nil {
+			return nil, err
+		}
+		if err := checkIDs(c.vertices, c.idStart, c.idStride); err != nil {
+			return nil, err
+		}
+	}
+
+	if int64(c.snapshots) > c.vertices {
+		return nil, fmt.Errorf("more snapshots than vertices: %v", c.snapshots)
+	}
+
+	if c.deltas && c.snapshots == 0 {
+		return nil, errors.New("-deltas requires -snapshots")
+	}
+
+	if c.snapshots > 0 && (c.outFile != "" || c.appendOut) {
+		return nil, errors.New("-snapshots cannot be combined with -o or -append")
+	}
+
+	algo, err := parseRNG(c.rng)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.workers < 1 {
+		return nil, fmt.Errorf("invalid number of workers: %v", c.workers)
+	}
+
+	if c.workers > 1 && !c.binomialModel() {
+		return nil, errors.New("-workers only supports the binomial model")
+	}
+
+	if c.benchJSON && !c.bench {
+		return nil, errors.New("-bench-json requires -bench")
+	}
+
+	if c.bench {
+		switch {
+		case c.outFile != "" || c.appendOut || c.atomic:
+			return nil, errors.New("-bench cannot be combined with -o, -append or -atomic")
+		case c.infinite || c.snapshots > 0 || c.checkpoint != "":
+			return nil, errors.New("-bench cannot be combined with -infinite, -snapshots or -checkpoint")
+		}
+	}
+
+	start, end := int64(0), c.vertices
+	if c.vrange != "" {
+		switch {
+		case c.seed == 0:
+			return nil, errors.New("-vertex-range requires -seed")
+		case c.emit == "both":
+			return nil, errors.New("-vertex-range requires -emit=vertices or -emit=edges")
+		case !c.binomialModel():
+			return nil, errors.New("-vertex-range only supports the binomial model")
+		case c.locality != "" || c.closure > 0 || c.commsFile != "" || c.dedup != "none":
+			return nil, errors.New("-vertex-range cannot be combined with -locality, -closure, -communities or -dedup")
+		}
+		if start, end, err = parseVertexRange(c.vrange, c.vertices); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.checksum != "" {
+		switch {
+		case c.checksum != "sha256":
+			return nil, fmt.Errorf("unknown checksum algorithm: %q", c.checksum)
+		case c.outFile == "" && c.snapshots == 0:
+			return nil, errors.New("-checksum requires -o or -snapshots")
+		case c.appendOut:
+			return nil, errors.New("-checksum cannot be combined with -append")
+		}
+	}
+
+	if c.manifest != "" {
+		switch {
+		case c.outFile == "" && c.snapshots == 0:
+			return nil, errors.New("-manifest requires -o or -snapshots")
+		case c.appendOut:
+			return nil, errors.New("-manifest cannot be combined with -append")
+		}
+	}
+
+	if c.resume && c.checkpoint == "" {
+		return nil, errors.New("-resume requires -checkpoint")
+	}
+
+	if c.checkpoint != "" {
+		switch {
+		case c.seed == 0:
+			return nil, errors.New("-checkpoint requires -seed")
+		case c.outFile == "":
+			return nil, errors.New("-checkpoint requires -o")
+		case c.cpInterval <= 0:
+			return nil, fmt.Errorf("invalid checkpoint interval: %v", c.cpInterval)
+		case c.appendOut || c.atomic || c.interleave:
+			return nil, errors.New("-checkpoint cannot be combined with -append, -atomic or -interleave")
+		case !c.binomialModel():
+			return nil, errors.New("-checkpoint only supports the binomial model")
+		case c.locality != "" || c.closure > 0 || c.commsFile != "" || c.dedup != "none":
+			return nil, errors.New("-checkpoint cannot be combined with -locality, -closure, -communities or -dedup")
+		}
+	}
+
+	if c.vertexTime != "" && c.infinite {
+		return nil, errors.New("-vertex-time cannot be combined with -infinite")
+	}
+
+	if c.idWidth < 0 || c.idWidth > maxIDWidth {
+		return nil, fmt.Errorf("invalid ID width: %v", c.idWidth)
+	}
+
+	mode, err := parseEmitMode(c.emit)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.rate < 0 {
+		return nil, fmt.Errorf("invalid rate: %v", c.rate)
+	}
+
+	if c.burst < 1 {
+		return nil, fmt.Errorf("invalid burst: %v", c.burst)
+	}
+
+	if len(c.tees) > 0 {
+		if c.snapshots > 0 || c.checkpoint != "" || c.bench || c.churn > 0 {
+			return nil, errors.New("-tee cannot be combined with -snapshots, -checkpoint, -bench or -churn")
+		}
+		names := map[string]bool{c.outFile: true}
+		for _, to := range c.tees {
+			if names[to.name] {
+				return nil, fmt.Errorf("-tee: duplicated output file: %v", to.name)
+			}
+			names[to.name] = true
+		}
+	}
+
+	if c.dropOnOver {
+		switch {
+		case c.overBuffer < overflowChunk || c.overBuffer > maxOverflowBuffer:
+			return nil, fmt.Errorf("invalid overflow buffer size: %v (must be between %v and %v)", int64(c.overBuffer), overflowChunk, maxOverflowBuffer)
+		case c.snapshots > 0 || c.checkpoint != "" || c.bench:
+			return nil, errors.New("-drop-on-overflow cannot be combined with -snapshots, -checkpoint or -bench")
+		}
+	}
+
+	if c.nul && c.emitDOT {
+		return nil, errors.New("-z cannot be combined with -dot")
+	}
+
+	if (c.dotStrict || c.dotName != "" || c.dotTooltip != "" || c.dotURL != "") && !c.emitDOT {
+		return nil, errors.New("-dot-strict, -dot-name, -dot-tooltip and -dot-url require -dot")
+	}
+	dotTooltip, dotURL, err := parseNodeTemplates(c.dotTooltip, c.dotURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.edgeTuples && (c.emitDOT || c.nul || c.churn > 0) {
+		return nil, errors.New("-edge-tuples cannot be combined with -dot, -z or -churn")
+	}
+
+	if c.crlf && (c.nul || c.edgeTuples) {
+		return nil, errors.New("-crlf cannot be combined with -z or -edge-tuples")
+	}
+
+	switch c.dedup {
+	case "none":
+	case "bloom":
+		if c.multiedges {
+			return nil, errors.New("-dedup=bloom cannot be combined with -multiedges")
+		}
+		if c.dedupFP <= 0 || c.dedupFP >= 1 {
+			return nil, fmt.Errorf("invalid false positive rate: %v", c.dedupFP)
+		}
+	default:
+		return nil, fmt.Errorf("unknown dedup mode: %q", c.dedup)
+	}
+
+	collisions, err := parseCollisionMode(c.collisions)
+	if err != nil {
+		return nil, err
+	}
+	if collisions == collisionError && c.infinite {
+		return nil, errors.New("-on-label-collision=error cannot be combined with -infinite")
+	}
+
+	switch c.labels {
+	case "id":
+	case "hash":
+		if c.wordsFile != "" || c.labelPools != "" {
+			return nil, errors.New("-labels=hash cannot be combined with -words or -label-pools")
+		}
+	default:
+		return nil, fmt.Errorf("unknown label mode: %q", c.labels)
+	}
+
+	if _, err := parseLetterCase(c.labelCase); err != nil {
+		return nil, err
+	}
+	if _, err := parseWordsEncoding(c.wordsEnc); err != nil {
+		return nil, err
+	}
+
+	var words []string
+	if c.wordsFile != "" {
+		words, err = c.readWords(c.wordsFile)
+		if err != nil {
+			return nil, err
+		}
+		if collisions == collisionError && c.vertices > 0 {
+			if last := c.idStart + (c.vertices-1)*c.idStride; last >= int64(len(words)) {
+				return nil, fmt.Errorf("%v: label collision: %v words for vertex IDs up to %v", c.wordsFile, len(words), last)
+			}
+		}
+	}
+
+	var pools [][]string
+	if c.labelPools != "" {
+		if c.wordsFile != "" {
+			return nil, errors.New("-label-pools cannot be combined with -words")
+		}
+		if pools, err = c.readLabelPools(); err != nil {
+			return nil, err
+		}
+		if c.labelDepth == 0 {
+			c.labelDepth = len(pools)
+		}
+		if c.labelDepth < 1 {
+			return nil, fmt.Errorf("invalid label depth: %v", c.labelDepth)
+		}
+		if collisions == collisionError && c.vertices > 0 {
+			if last := c.idStart + (c.vertices-1)*c.idStride; last >= poolsCapacity(pools, c.labelDepth) {
+				return nil, fmt.Errorf("label collision: %v dotted labels for vertex IDs up to %v", poolsCapacity(pools, c.labelDepth), last)
+			}
+		}
+	} else if c.labelDepth != 0 {
+		return nil, errors.New("-label-depth requires -label-pools")
+	}
+
+	var trials map[int64]degreeDist
+	if c.trialsFile != "" {
+		if c.commSizes != "" || c.outDegree != "" || c.inDegree != "" {
+			return nil, errors.New("-trials-file cannot be combined with -out-degree, -in-degree or -community-sizes")
+		}
+		trials, err = readTrials(c.trialsFile, c.vertices, c.idStart, c.idStride)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var comms map[int64]string
+	if c.commsFile != "" {
+		comms, err = readCommunities(c.commsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.maxMem > 0 {
+		uses, err := c.memUses(trials, comms, slices.Concat(append(pools, words)...))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMem(uses, int64(c.maxMem)); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.seed == 0 {
+		// The seed is set through fs, so it is recorded in the
+		// metadata and the graph can be reproduced.
+		fs.Set("seed", strconv.FormatUint(max(rand.Uint64(), 1), 10))
+	}
+	seed := c.seed
+	r := newRNG(algo, seed)
+
+	shuffleWords(words, seed, 0)
+	for i, pool := range pools {
+		// The streams of the pools count down, so they are not
+		// shared with the other words files.
+		shuffleWords(pool, seed, ^uint64(i))
+	}
+
+	var tl timeline
+	if c.vertexTime != "" {
+		if tl, err = parseTimeline(c.vertexTime, seed); err != nil {
+			return nil, err
+		}
+		if err := tl.check(c.vertices); err != nil {
+			return nil, err
+		}
+	}
+
+	var box bbox
+	if c.coords != "" {
+		if box, err = parseBBox(c.coords); err != nil {
+			return nil, err
+		}
+	}
+
+	var ts typeSchema
+	if c.vtypes != "" {
+		if ts, err = c.typeSchema(seed, collisions); err != nil {
+			return nil, err
+		}
+	} else if c.typeEdges != "" || c.typeWords != "" {
+		return nil, errors.New("-type-edges and -type-words require -vertex-types")
+	}
+
+	var et edgeTypes
+	if c.etypes != "" {
+		if et, err = parseEdgeTypes(c.etypes, seed); err != nil {
+			return nil, err
+		}
+	}
+
+	var resumed *checkpoint
+	if c.resume {
+		resumed, err = readCheckpoint(c.checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if resumed.Meta != checkpointParams(fs) {
+			return nil, errors.New("the checkpoint was written by a generation with different parameters")
+		}
+		if resumed.Tail < start || resumed.Tail > end {
+			return nil, errors.New("the checkpoint was written by a generation with a different vertex range")
+		}
+	}
+
+	vlabel := func(id int64) string {
+		return wordLabel(words, c.idStart+id*c.idStride, c.idWidth, collisions)
+	}
+	switch {
+	case pools != nil:
+		vlabel = func(id int64) string {
+			return dottedLabel(pools, c.labelDepth, c.idStart+id*c.idStride, c.idWidth, collisions)
+		}
+	case c.labels == "hash":
+		vlabel = func(id int64) string {
+			return hashLabel(seed, c.idStart+id*c.idStride)
+		}
+	}
+
+	var g graph
+	switch {
+	case model != nil:
+		g = modelGraph(c.vertices, model, r, vlabel)
+	case blocks != nil:
+		if c.propensity != "" {
+			dist, err := parseDegreeDist(c.propensity)
+			if err != nil {
+				return nil, err
+			}
+			blocks.correct(dist, r)
+		}
+		g = blocks.graph(c.loops, r, vlabel)
+	case c.commSizes != "":
+		out, in, err := degreeDists(c.outDegree, c.inDegree, c.trials, c.prob)
+		if err != nil {
+			return nil, err
+		}
+		sizes, err := parseDegreeDist(c.commSizes)
+		if err != nil {
+			return nil, err
+		}
+		m, err := newLFR(c.vertices, out, in, sizes, c.mu, c.loops, c.multiedges || c.usesBloomFilter(), r, vlabel)
+		if err != nil {
+			return nil, err
+		}
+		g = m.graph()
+	case c.infinite:
+		// Generate as many vertices as possible without
+		// overflowing vertex IDs or the vertex count.
+		n := (math.MaxInt64 - c.idStart) / c.idStride
+		g = growingGraph(n, c.trials, c.prob, c.churn, c.loops, c.multiedges, r, vlabel)
+	case c.snapshots > 0:
+		g = growingGraph(c.vertices, c.trials, c.prob, 0, c.loops, c.multiedges, r, vlabel)
+	case c.profile == "graph500":
+		g = kronecker(c.scale, c.edgefactor, r, vlabel)
+	case c.profile == "ldbc":
+		net.algo, net.seed = algo, seed
+		g = net.graph(vlabel)
+	case c.triad > 0:
+		g = holmeKim(c.vertices, c.trials, c.prob, c.triad, c.multiedges, r, vlabel)
+	case c.outDegree != "" || c.inDegree != "":
+		out, in, err := degreeDists(c.outDegree, c.inDegree, c.trials, c.prob)
+		if err != nil {
+			return nil, err
+		}
+		g = stubMatching(c.vertices, out, in, c.assort, c.loops, c.multiedges, r, vlabel)
+	default:
+		first := start
+		if resumed != nil {
+			first = resumed.Tail
+		}
+		g = trialGraph(c.vertices, trials, c.trials, c.prob, c.loops, c.multiedges, algo, seed, first, end, c.workers, vlabel)
+	}
+
+	cnt := &counter{}
+	if resumed != nil {
+		cnt.vertices, cnt.edges = resumed.Vertices, resumed.Edges
+		g.vertices = func(func(vertex) bool) {}
+	}
+
+	var cp *checkpointer
+	if c.checkpoint != "" {
+		cp = &checkpointer{
+			name:    c.checkpoint,
+			every:   c.cpInterval,
+			meta:    checkpointParams(fs),
+			counter: cnt,
+		}
+		g.edges = cp.edges(g.edges)
+	}
+
+	if decay != nil {
+		g.edges = localEdges(g.edges, decay, c.vertices, c.multiedges, r)
+	}
+
+	if c.closure > 0 {
+		tc := newTriadCloser(c.closure, c.loops, c.multiedges, r)
+		g.edges = tc.edges(g.edges)
+	}
+
+	var bd *bloomDedup
+	if c.usesBloomFilter() {
+		expected, err := c.expectedEdges()
+		if err != nil {
+			return nil, err
+		}
+		bd = &bloomDedup{filter: newBloomFilter(int64(expected), c.dedupFP)}
+		g.edges = bd.edges(g.edges)
+	}
+
+	if c.vertexTime != "" {
+		g = withTimes(g, tl)
+	}
+
+	if c.coords != "" {
+		g = withCoords(g, box, seed)
+	}
+
+	if c.vtypes != "" {
+		g = withTypes(g, ts)
+	}
+
+	if c.etypes != "" {
+		g = withEdgeTypes(g, et)
+	}
+
+	if c.idStart != 0 || c.idStride != 1 {
+		g = remapIDs(g, c.idStart, c.idStride)
+	}
+
+	if comms != nil {
+		g = withCommunities(g, comms, c.mixing, r)
+	}
+
+	if c.golden {
+		g = sortedGraph(g)
+	}
+
+	p := printer{idWidth: c.idWidth, emit: mode, interleave: c.interleave || growing, nul: c.nul, crlf: c.crlf, temporal: c.churn > 0, times: c.vertexTime != "" || c.profile == "ldbc", coords: c.coords != "", edgeTuples: c.edgeTuples, dotStrict: c.dotStrict, dotName: c.dotName, dotTooltip: dotTooltip, dotURL: dotURL}
+	p.skipBegin = resumed != nil || start > 0
+	p.skipEnd = end < c.vertices
+	if c.meta && !c.edgeTuples {
+		p.meta = c.metadata(fs)
+	}
+
+	var vf *verifier
+	if c.verify {
+		vf = c.verifier(ts, mode == emitBoth && start == 0 && end == c.vertices && resumed == nil)
+	}
+
+	gen := &generation{
+		g:            g,
+		p:            p,
+		emitDOT:      c.emitDOT,
+		snapshots:    snapshotSizes(c.vertices, c.snapshots),
+		deltas:       c.deltas,
+		rate:         c.rate,
+		burst:        c.burst,
+		dedup:        bd,
+		counter:      cnt,
+		verifier:     vf,
+		checkpointer: cp,
+		resumed:      resumed,
+		checksum:     c.checksum != "" || c.manifest != "",
+	}
+	if c.dropOnOver {
+		gen.overflow = int64(c.overBuffer)
+	}
+	gen.tees = c.tees
+	return gen, nil
+}
+
+// typeSchema returns the type schema described by the -vertex-types,
+// -type-edges and -type-words flags. seed determines the types of the
+// vertices and collisions how the labels of every type repeat.
+func (c *genConfig) typeSchema(seed uint64, collisions collisionMode) (typeSchema, error) {
+	types, err := parseVertexTypes(c.vtypes)
+	if err != nil {
+		return typeSchema{}, err
+	}
+	ts := typeSchema{types: types, labels: make([]func(int64) string, len(types.names)), seed: seed}
+
+	if c.typeEdges != "" {
+		if ts.allowed, err = parseTypeEdges(c.typeEdges, types); err != nil {
+			return typeSchema{}, err
+		}
+	}
+
+	if c.typeWords != "" {
+		files, err := parseTypeWords(c.typeWords, types)
+		if err != nil {
+			return typeSchema{}, err
+		}
+		for i, file := range files {
+			words, err := c.readWords(file)
+			if err != nil {
+				return typeSchema{}, err
+			}
+			shuffleWords(words, seed, uint64(i)+1)
+			if collisions == collisionError {
+				// Only the vertices of the type are labeled
+				// with its words, so the last one is searched
+				// backwards.
+				for id := c.vertices - 1; id >= 0 && c.idStart+id*c.idStride >= int64(len(words)); id-- {
+					if ts.typeOf(id) == i {
+						return typeSchema{}, fmt.Errorf("%v: label collision: %v words for vertex IDs up to %v", file, len(words), c.idStart+id*c.idStride)
+					}
+				}
+			}
+			ts.labels[i] = func(id int64) string {
+				return wordLabel(words, c.idStart+id*c.idStride, c.idWidth, collisions)
+			}
+		}
+	}
+	return ts, nil
+}
+
+// verifier returns the verifier of the constraints of the generation.
+// ts is its type schema and endpoints reports whether the endpoints of
+// every edge are written.
+func (c *genConfig) verifier(ts typeSchema, endpoints bool) *verifier {
+	// The benchmark profiles allow loops and multiple edges. The
+	// Bloom filter removes multiple edges.
+	vf := newVerifier(c.loops || c.profile != "", c.multiedges || c.profile != "")
+	vf.endpoints = endpoints
+
+	if ts.allowed != nil {
+		vf.allowed = make(map[[2]string]bool)
+		for pair := range ts.allowed {
+			vf.allowed[[2]string{ts.types.names[pair[0]], ts.types.names[pair[1]]}] = true
+		}
+	}
+
+	// Only stub matching bounds the degrees by their
+	// distributions, and triangle closure adds edges.
+	stubs := c.commSizes == "" && !c.infinite && c.snapshots == 0 && c.profile == "" && c.triad == 0 && (c.outDegree != "" || c.inDegree != "")
+	if stubs && c.closure == 0 {
+		def := fmt.Sprintf("binomial:%v,%v", c.trials, c.prob)
+		if maxOut, ok := degreeBound(cmp.Or(c.outDegree, def)); ok {
+			vf.maxOut = maxOut
+		}
+		if maxIn, ok := degreeBound(cmp.Or(c.inDegree, def)); ok {
+			vf.maxIn = maxIn
+		}
+	}
+	return vf
+}
+
+// metadata returns the metadata of the generation, which is recorded
+// in the output. fs is the flag set used to parse c.
+func (c *genConfig) metadata(fs *flag.FlagSet) string {
+	return metadata(fs, c.inert)
+}
+
+// readWords reads the words file with the provided name, applying the
+// -words-encoding, -transliterate and -label-case flags.
+func (c *genConfig) readWords(name string) ([]string, error) {
+	lc, err := parseLetterCase(c.labelCase)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := parseWordsEncoding(c.wordsEnc)
+	if err != nil {
+		return nil, err
+	}
+	words, err := readWords(name, enc, c.translit)
+	if err != nil {
+		return nil, err
+	}
+	return lc.apply(words), nil
+}
+
+// format returns the name of the output format of the generation.
+func (gen *generation) format() string {
+	switch {
+	case gen.emitDOT:
+		return "dot"
+	case gen.p.edgeTuples:
+		return "edge-tuples"
+	case gen.p.temporal:
+		return "patch"
+	}
+	return "simple"
+}
+
+// write writes the graph to w. It stops early if ctx is done. If the
+// rate is limited, flush is called before waiting, so it must flush
+// any buffering between w and the consumer.
+func (gen *generation) write(ctx context.Context, w io.Writer, flush func()) {
+	if gen.overflow == 0 {
+		gen.p.write(gen.newEncoder(w), gen.stream(ctx, flush))
+		return
+	}
+
+	// w and flush are only used by the goroutine of the
+	// overflowWriter while the graph is written.
+	ow := newOverflowWriter(w, gen.overflow, flush)
+	bw := bufio.NewWriterSize(ow, overflowChunk)
+	gen.full = ow.full
+	gen.p.write(gen.newEncoder(bw), gen.stream(ctx, func() {
+		bw.Flush()
+		ow.flush()
+	}))
+	bw.Flush()
+	ow.close()
+	gen.full = nil
+}
+
+// newEncoder returns the encoder of the graph that writes to w and
+// the additional outputs, if any.
+func (gen *generation) newEncoder(w io.Writer) encoder {
+	enc := gen.p.newEncoder(w, gen.emitDOT)
+	if len(gen.teeEncs) == 0 {
+		return enc
+	}
+	return append(multiEncoder{enc}, gen.teeEncs...)
+}
+
+// stream returns the graph as it must be written. See
+// [generation.write].
+func (gen *generation) stream(ctx context.Context, flush func()) graph {
+	g := untilDone(ctx, gen.g)
+	if gen.stages != nil {
+		g = gen.stages.timeGraph(g)
+	}
+	if gen.rate > 0 {
+		g.edges = rateLimit(ctx, g.edges, gen.rate, gen.burst, flush)
+	}
+	if gen.full != nil {
+		g.edges = dropOnOverflow(g.edges, gen.full, &gen.dropped)
+	}
+	if gen.verifier != nil {
+		g = gen.verifier.check(g)
+	}
+	return gen.counter.count(g)
+}
+
+// walk calls vfn for every vertex and efn for every edge of the graph,
+// in the order they would be written, until ctx is done. It returns
+// the problems found by -verify, if any.
+func (gen *generation) walk(ctx context.Context, vfn func(vertex), efn func(edge)) error {
+	gen.p.walk(gen.stream(ctx, func() {}), vfn, efn)
+	return gen.verify(ctx.Err() == nil)
+}
+
+// verify returns the problems found by -verify, if any. complete
+// reports whether the whole graph has been written.
+func (gen *generation) verify(complete bool) error {
+	if problems := gen.verifyProblems(complete); len(problems) > 0 {
+		return fmt.Errorf("verify: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// verifyProblems finishes the verifier, if any, and returns the
+// problems it found. The verifier is only finished once, so the
+// problems can be checked before committing the output and reported
+// later.
+func (gen *generation) verifyProblems(complete bool) []error {
+	if gen.verifier == nil {
+		return nil
+	}
+	if !gen.verified {
+		gen.problems = gen.verifier.finish(complete)
+		gen.verified = true
+	}
+	return gen.problems
+}
+
+// writeFile writes the graph to the named output file. If ctx is done
+// before the graph is complete, the output written so far is
+// committed, unless atomic is true. If appendOut is true, the graph
+// is appended to the file. With -verify, the graph is verified before
+// committing the output and, if atomic is true, a graph with problems
+// does not replace the output file. The problems are returned by
+// [generation.verifyProblems].
+//
+// If the generation is resumed, the output file is truncated to the
+// size recorded in the checkpoint before appending the rest of the
+// graph.
+func (gen *generation) writeFile(ctx context.Context, name string, appendOut, atomic bool) error {
+	var offset int64
+	if gen.resumed != nil {
+		offset, appendOut = gen.resumed.Offset, true
+		if err := os.Truncate(name, offset); err != nil {
+			return err
+		}
+	}
+
+	var h hash.Hash
+	if gen.checksum {
+		h = sha256.New()
+		if offset > 0 {
+			if _, err := hashFileTo(h, name, offset); err != nil {
+				return err
+			}
+		}
+	}
+
+	out, err := createOutput(name, appendOut, atomic)
+	if err != nil {
+		return err
+	}
+	cw := &countingWriter{w: out, n: offset}
+	if h != nil {
+		cw.w = io.MultiWriter(out, h)
+	}
+	if gen.stages != nil {
+		cw.w = gen.stages.timeWriter(cw.w)
+	}
+	bw := bufio.NewWriter(cw)
+
+	tees, err := gen.openTees(gen.tees, appendOut, atomic)
+	if err != nil {
+		out.abort()
+		return err
+	}
+	gen.teeEncs = nil
+	for _, tf := range tees {
+		gen.teeEncs = append(gen.teeEncs, tf.enc)
+	}
+
+	if gen.checkpointer != nil {
+		gen.checkpointer.sync = func() (int64, error) {
+			if err := bw.Flush(); err != nil {
+				return 0, err
+			}
+			if err := out.f.Sync(); err != nil {
+				return 0, err
+			}
+			return cw.n, nil
+		}
+	}
+
+	gen.write(ctx, bw, func() { bw.Flush() })
+	gen.teeEncs = nil
+
+	if err := bw.Flush(); err != nil {
+		out.abort()
+		gen.closeTees(tees, false)
+		return err
+	}
+	if ctx.Err() != nil && atomic {
+		out.abort()
+		gen.closeTees(tees, false)
+		return nil
+	}
+	if len(gen.verifyProblems(ctx.Err() == nil)) > 0 && atomic {
+		out.abort()
+		gen.closeTees(tees, false)
+		return nil
+	}
+	if err := out.commit(); err != nil {
+		gen.closeTees(tees, false)
+		return err
+	}
+	if name != "" {
+		wf := writtenFile{
+			name:     name,
+			format:   gen.format(),
+			vertices: gen.counter.vertices,
+			edges:    gen.counter.edges,
+			size:     cw.n,
+		}
+		if h != nil {
+			wf.sum = hex.EncodeToString(h.Sum(nil))
+		}
+		gen.files = append(gen.files, wf)
+	}
+	return gen.closeTees(tees, true)
+}
+
+func runGenerate(args []string) {
+	start := time.Now()
+
+	var c genConfig
+	fs := c.flagSet("generate")
+	fs.Usage = func() {
+		usage()
+		fmt.Fprintf(os.Stderr, "\nThe generate flags are:\n\n")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := setLogFormat(c.logFormat); err != nil {
+		fatal(err)
+	}
+
+	if c.version {
+		fmt.Println(version())
+		return
+	}
+
+	var err error
+	if tracing, err = newTracer(); err != nil {
+		fatal(err)
+	}
+
+	if c.ipc {
+		ctx, stop := notifyInterrupt()
+		defer stop()
+
+		srv := newIPCServer(os.Stdin, os.Stdout, fs)
+		if err := srv.serve(ctx); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	root := tracing.start("generate", nil,
+		"mkdigraph.model", c.modelName(),
+		"mkdigraph.n", c.vertices,
+		"mkdigraph.seed", c.seed,
+	)
+	setup := tracing.start("setup", root)
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		fatal(err)
+	}
+	setup.finish(nil)
+	root.set("mkdigraph.output.format", gen.format())
+	for _, reason := range c.degenerate() {
+		warn("degenerate graph", "reason", reason)
+	}
+	if tracing != nil {
+		gen.stages = &stageTimes{}
+	}
+
+	if c.maxMem > 0 {
+		debug.SetMemoryLimit(int64(c.maxMem))
+	}
+
+	ctx, stop := notifyInterrupt()
+	defer stop()
+
+	var stopCPUProfile func() error
+	if c.cpuProfile != "" {
+		if stopCPUProfile, err = startCPUProfile(c.cpuProfile); err != nil {
+			fatal(err)
+		}
+	}
+
+	write := tracing.start("write", root)
+	switch {
+	case c.bench:
+		err = runBench(ctx, os.Stdout, c, fs)
+	case c.snapshots > 0:
+		err = gen.writeSnapshots(ctx, c.snapDir, c.atomic)
+	default:
+		err = gen.writeFile(ctx, c.outFile, c.appendOut, c.atomic)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if gen.dropped > 0 {
+		warn("output overflow", "dropped", gen.dropped)
+	}
+	if gen.stages != nil {
+		// The stages are interleaved, so their times are
+		// reported as attributes instead of spans. The rest of
+		// the time is spent formatting the graph.
+		elapsed := time.Since(write.start)
+		write.set(
+			"mkdigraph.vertices", gen.counter.vertices,
+			"mkdigraph.edges", gen.counter.edges,
+			"mkdigraph.model.seconds", gen.stages.model,
+			"mkdigraph.output.seconds", gen.stages.output,
+			"mkdigraph.format.seconds", elapsed-gen.stages.model-gen.stages.output,
+		)
+	}
+	write.finish(context.Cause(ctx))
+
+	// Profiles are written before handling interruptions, so they
+	// are not lost if the generation is interrupted.
+	if stopCPUProfile != nil {
+		if err := stopCPUProfile(); err != nil {
+			fatal(err)
+		}
+	}
+	if c.memProfile != "" {
+		if err := writeMemProfile(c.memProfile); err != nil {
+			fatal(err)
+		}
+	}
+
+	if gen.verifier != nil {
+		verify := tracing.start("verify", root)
+		problems := gen.verifyProblems(ctx.Err() == nil)
+		verify.finish(errors.Join(problems...))
+		for _, err := range problems {
+			reportError(exitError, fmt.Errorf("verify: %w", err))
+		}
+		if len(problems) > 0 {
+			tracing.abort(errors.Join(problems...))
+			os.Exit(exitError)
+		}
+	}
+
+	if c.checksum != "" {
+		checksum := tracing.start("checksum", root)
+		for _, wf := range gen.files {
+			if err := writeChecksumFile(wf); err != nil {
+				fatal(err)
+			}
+		}
+		checksum.finish(nil)
+	}
+
+	if c.manifest != "" {
+		m := manifest{
+			Version:    version(),
+			Parameters: c.metadata(fs),
+			Range:      c.vrange,
+			Complete:   ctx.Err() == nil,
+		}
+		if c.golden {
+			// The version holds the time of the VCS revision.
+			m.Version = ""
+		}
+		span := tracing.start("manifest", root)
+		if err := writeManifest(c.manifest, m, gen.files); err != nil {
+			fatal(err)
+		}
+		span.finish(nil)
+	}
+
+	root.set("mkdigraph.vertices", gen.counter.vertices, "mkdigraph.edges", gen.counter.edges)
+	root.finish(context.Cause(ctx))
+	tracing.flush()
+
+	var serr *signalError
+	if errors.As(context.Cause(ctx), &serr) {
+		warn("interrupted", "signal", serr.sig.String())
+		os.Exit(serr.exitCode())
+	}
+
+	if c.checkpoint != "" {
+		if err := os.Remove(c.checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			warn("checkpoint", "error", err)
+		}
+	}
+
+	if c.bench {
+		return
+	}
+
+	if gen.dedup != nil {
+		info("dedup", "suppressed", gen.dedup.suppressed)
+	}
+
+	if jsonLog != nil {
+		jsonLog.Info("summary",
+			"vertices", gen.counter.vertices,
+			"edges", gen.counter.edges,
+			"elapsed", time.Since(start).Seconds(),
+		)
+	}
+}
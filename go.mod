@@ -1,5 +1,3 @@
 module github.com/jroimartin/mkdigraph
 
 go 1.25.0
-
-require github.com/jroimartin/randgraph v0.2.3
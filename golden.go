@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+// goldenSeed is the seed of the golden generations that do not
+// specify one.
+const goldenSeed = 1
+
+// sortedGraph returns a copy of g with its vertices sorted by ID and
+// its edges sorted by tail, head, type and time. Every sequence is
+// collected in memory when it is iterated.
+func sortedGraph(g graph) graph {
+	return graph{
+		vertices: func(yield func(vertex) bool) {
+			vertices := slices.SortedStableFunc(g.vertices, func(a, b vertex) int { return cmp.Compare(a.id, b.id) })
+			for _, v := range vertices {
+				if !yield(v) {
+					return
+				}
+			}
+		},
+		edges: func(yield func(edge) bool) {
+			for _, e := range slices.SortedStableFunc(g.edges, compareEdges) {
+				if !yield(e) {
+					return
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortedGraph(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 2}, {id: 0}, {id: 1}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0}, {tail: 0, head: 2}, {tail: 0, head: 1, etype: "b"}, {tail: 0, head: 1, etype: "a"}}),
+	}
+	sg := sortedGraph(g)
+
+	var ids []int64
+	for v := range sg.vertices {
+		ids = append(ids, v.id)
+	}
+	if want := []int64{0, 1, 2}; !slices.Equal(ids, want) {
+		t.Errorf("unexpected vertices: got: %v, want: %v", ids, want)
+	}
+
+	want := []edge{{tail: 0, head: 1, etype: "a"}, {tail: 0, head: 1, etype: "b"}, {tail: 0, head: 2}, {tail: 1, head: 0}}
+	if got := slices.Collect(sg.edges); !slices.Equal(got, want) {
+		t.Errorf("unexpected edges: got: %v, want: %v", got, want)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	generate := func(args ...string) string {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(append([]string{"-golden", "-n=50"}, args...)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var buf bytes.Buffer
+		gen.write(context.Background(), &buf, func() {})
+		return buf.String()
+	}
+
+	out := generate()
+	if again := generate(); again != out {
+		t.Error("golden output is not stable")
+	}
+	if concurrent := generate("-workers=4"); concurrent != out {
+		t.Error("golden output depends on the number of workers")
+	}
+	if header, _, _ := strings.Cut(out, "\n"); header != "# mkdigraph -n=50 -seed=1" {
+		t.Errorf("unexpected metadata: %q", header)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"iter"
+)
+
+// A vertex is a vertex of a generated graph.
+type vertex struct {
+	id    int64
+	label string
+
+	// community is the community the vertex belongs to. It is
+	// empty if the vertex does not belong to any community.
+	community string
+
+	// vtype is the type of the vertex. It is empty if vertices
+	// are not typed.
+	vtype string
+
+	// time is the time the vertex is added at. It is only
+	// meaningful in temporal streams and timed graphs.
+	time int64
+
+	// lat and lon are the geographic coordinates of the vertex in
+	// degrees. They are only meaningful with -coords.
+	lat, lon float64
+}
+
+// An edge is a directed edge from the tail vertex to the head
+// vertex.
+type edge struct {
+	tail, head int64
+
+	// removed reports whether the edge is removed instead of
+	// added. It is only meaningful in temporal streams.
+	removed bool
+
+	// etype is the type of the edge. It is empty if edges are not
+	// typed.
+	etype string
+
+	// time is the time the edge is added or removed at. It is
+	// only meaningful in temporal streams and timed graphs.
+	time int64
+}
+
+// A graph is a generated graph. All the vertices are streamed before
+// the first edge.
+type graph struct {
+	vertices iter.Seq[vertex]
+	edges    iter.Seq[edge]
+}
+
+// remapIDs returns a copy of g where every vertex ID i is replaced
+// with start + i*stride.
+func remapIDs(g graph, start, stride int64) graph {
+	id := func(i int64) int64 {
+		return start + i*stride
+	}
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			v.id = id(v.id)
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			e.tail, e.head = id(e.tail), id(e.head)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// untilDone returns a copy of g whose streams end as soon as ctx is
+// done.
+func untilDone(ctx context.Context, g graph) graph {
+	done := ctx.Done()
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// A counter counts the vertices and edges of a graph as they are
+// streamed.
+type counter struct {
+	vertices, edges int64
+}
+
+// count returns a copy of g whose streams are counted by c.
+func (c *counter) count(g graph) graph {
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			c.vertices++
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			c.edges++
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
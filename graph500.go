@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Initiator probabilities of the Graph500 Kronecker generator. The
+// probability of the fourth quadrant is 1-a-b-c.
+const (
+	graph500A = 0.57
+	graph500B = 0.19
+	graph500C = 0.19
+)
+
+// maxGraph500Scale is the maximum supported Graph500 scale.
+const maxGraph500Scale = 40
+
+// checkGraph500 returns an error if scale and edgefactor are not
+// valid Graph500 parameters.
+func checkGraph500(scale, edgefactor int) error {
+	if scale < 1 || scale > maxGraph500Scale {
+		return fmt.Errorf("invalid Graph500 scale: %v", scale)
+	}
+	if edgefactor < 1 {
+		return fmt.Errorf("invalid Graph500 edge factor: %v", edgefactor)
+	}
+	return nil
+}
+
+// kronecker returns a Graph500 Kronecker graph with 2^scale vertices
+// and edgefactor*2^scale edges, like the reference generator does.
+// Every edge is placed by recursively choosing one of the quadrants
+// of the adjacency matrix with the initiator probabilities, and the
+// vertex IDs are randomly permuted so that they do not reveal the
+// degrees. Loops and multiple edges are kept. r is the source of
+// randomness.
+//
+// Unlike the reference generator, the edges are not shuffled, because
+// they are already independent of each other, and the permutation is
+// computed instead of stored, so the memory used does not depend on
+// the size of the graph.
+func kronecker(scale, edgefactor int, r *rand.Rand, vlabel func(id int64) string) graph {
+	n := int64(1) << scale
+	perm := newBitPerm(scale, r)
+	vertices := func(yield func(vertex) bool) {
+		for id := range n {
+			if !yield(vertex{id: id, label: vlabel(id)}) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		const (
+			ab    = graph500A + graph500B
+			cNorm = graph500C / (1 - ab)
+			aNorm = graph500A / ab
+		)
+		for range int64(edgefactor) * n {
+			var i, j int64
+			for bit := range scale {
+				ii := r.Float64() > ab
+				q := aNorm
+				if ii {
+					q = cNorm
+				}
+				jj := r.Float64() > q
+				if ii {
+					i |= 1 << bit
+				}
+				if jj {
+					j |= 1 << bit
+				}
+			}
+			if !yield(edge{tail: perm.apply(i), head: perm.apply(j)}) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// A bitPerm is a random permutation of the integers in [0, 2^bits).
+type bitPerm struct {
+	mul, add [2]uint64
+	shift    int
+	mask     uint64
+}
+
+// newBitPerm returns a random permutation of the integers in
+// [0, 2^bits), using r as the source of randomness.
+func newBitPerm(bits int, r *rand.Rand) bitPerm {
+	p := bitPerm{shift: (bits + 1) / 2, mask: 1<<bits - 1}
+	for i := range p.mul {
+		p.mul[i] = r.Uint64() | 1
+		p.add[i] = r.Uint64()
+	}
+	return p
+}
+
+// apply returns the image of x. Every round is a bijection modulo
+// 2^bits: multiplying by an odd number, adding a constant and
+// xor-shifting.
+func (p bitPerm) apply(x int64) int64 {
+	u := uint64(x)
+	for i := range p.mul {
+		u = (u*p.mul[i] + p.add[i]) & p.mask
+		u ^= u >> p.shift
+	}
+	return int64(u)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBitPerm(t *testing.T) {
+	for _, bits := range []int{1, 2, 7, 12} {
+		p := newBitPerm(bits, newRNG(rngPCG, 1))
+		n := int64(1) << bits
+		seen := make(map[int64]bool)
+		for x := range n {
+			y := p.apply(x)
+			if y < 0 || y >= n {
+				t.Fatalf("bits=%v: image out of range: %v -> %v", bits, x, y)
+			}
+			if seen[y] {
+				t.Fatalf("bits=%v: duplicated image: %v -> %v", bits, x, y)
+			}
+			seen[y] = true
+		}
+	}
+}
+
+func TestKronecker(t *testing.T) {
+	const (
+		scale      = 10
+		edgefactor = 8
+		n          = 1 << scale
+	)
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+	g := kronecker(scale, edgefactor, newRNG(rngPCG, 1), vlabel)
+
+	var nv int64
+	for range g.vertices {
+		nv++
+	}
+	if nv != n {
+		t.Errorf("unexpected number of vertices: got: %v, want: %v", nv, n)
+	}
+
+	var ne int64
+	outdeg := make(map[int64]int64)
+	for e := range g.edges {
+		if e.tail < 0 || e.tail >= n || e.head < 0 || e.head >= n {
+			t.Fatalf("endpoint out of range: %v", e)
+		}
+		outdeg[e.tail]++
+		ne++
+	}
+	if ne != edgefactor*n {
+		t.Errorf("unexpected number of edges: got: %v, want: %v", ne, edgefactor*n)
+	}
+
+	// The degree distribution of Kronecker graphs is skewed.
+	var maxOut int64
+	for _, d := range outdeg {
+		maxOut = max(maxOut, d)
+	}
+	if maxOut < 10*edgefactor {
+		t.Errorf("unexpected maximum out-degree: %v", maxOut)
+	}
+}
+
+func TestCheckGraph500(t *testing.T) {
+	if err := checkGraph500(20, 16); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	invalid := [][2]int{{0, 16}, {maxGraph500Scale + 1, 16}, {20, 0}}
+	for _, args := range invalid {
+		if err := checkGraph500(args[0], args[1]); err == nil {
+			t.Errorf("scale=%v edgefactor=%v: expected error", args[0], args[1])
+		}
+	}
+}
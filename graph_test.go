@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestRemapIDs(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}, {id: 2, label: "C"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}, {tail: 2, head: 0}}),
+	}
+	wantVertices := []vertex{{id: 10, label: "A"}, {id: 13, label: "B"}, {id: 16, label: "C"}}
+	wantEdges := []edge{{tail: 10, head: 13}, {tail: 16, head: 10}}
+
+	g = remapIDs(g, 10, 3)
+	if got := slices.Collect(g.vertices); !slices.Equal(got, wantVertices) {
+		t.Errorf("unexpected vertices: got: %v want: %v", got, wantVertices)
+	}
+	if got := slices.Collect(g.edges); !slices.Equal(got, wantEdges) {
+		t.Errorf("unexpected edges: got: %v want: %v", got, wantEdges)
+	}
+}
+
+func TestUntilDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	edges := func(yield func(edge) bool) {
+		for i := range int64(10) {
+			if i == 5 {
+				cancel()
+			}
+			if !yield(edge{tail: i, head: i}) {
+				return
+			}
+		}
+	}
+	g := untilDone(ctx, graph{vertices: slices.Values([]vertex{}), edges: edges})
+
+	if n := len(slices.Collect(g.edges)); n != 5 {
+		t.Errorf("unexpected number of edges: got: %v want: 5", n)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}, {id: 2, label: "C"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}, {tail: 2, head: 0}}),
+	}
+
+	c := &counter{}
+	g = c.count(g)
+	for range g.vertices {
+	}
+	for range g.edges {
+	}
+	if c.vertices != 3 || c.edges != 2 {
+		t.Errorf("unexpected counts: got: (%v, %v) want: (3, 2)", c.vertices, c.edges)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+)
+
+// growingGraph returns a graph that grows one vertex at a time up to
+// n vertices. Every new vertex performs trials edge creation trials
+// that succeed with probability p. Every successful trial connects
+// the new vertex with a random vertex already in the graph, in a
+// random direction. Loops and multiple edges are discarded unless
+// allowed. r is the source of randomness.
+//
+// Every added edge is followed with probability churn by the removal
+// of a random edge of the graph. The time of every vertex and edge is
+// the ID of the vertex being added. If churn is greater than 0, the
+// edges of the graph are kept in memory.
+//
+// The edges of every vertex follow the edges of the previous
+// vertices, so the graph can be streamed interleaving vertices and
+// edges even if n is too large to ever finish.
+func growingGraph(n int64, trials int, p, churn float64, loops, multiedges bool, r *rand.Rand, vlabel func(id int64) string) graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := range n {
+			if !yield(vertex{id: id, label: vlabel(id), time: id}) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		var live []edge
+		seen := make(map[edge]struct{})
+		for id := range n {
+			clear(seen)
+			for range trials {
+				if r.Float64() >= p {
+					continue
+				}
+				e := edge{tail: id, head: r.Int64N(id + 1), time: id}
+				if e.head == id && !loops {
+					continue
+				}
+				if r.IntN(2) == 0 {
+					e.tail, e.head = e.head, e.tail
+				}
+				if !multiedges {
+					if _, ok := seen[e]; ok {
+						continue
+					}
+					seen[e] = struct{}{}
+				}
+				if !yield(e) {
+					return
+				}
+				if churn == 0 {
+					continue
+				}
+				live = append(live, e)
+				if r.Float64() >= churn {
+					continue
+				}
+				i := r.IntN(len(live))
+				rm := live[i]
+				live[i] = live[len(live)-1]
+				live = live[:len(live)-1]
+				rm.removed, rm.time = true, id
+				if !yield(rm) {
+					return
+				}
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
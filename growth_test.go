@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestGrowingGraph(t *testing.T) {
+	const n = 100
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+	g := growingGraph(n, 5, 0.5, 0, false, false, newRNG(rngPCG, 0), vlabel)
+
+	last := int64(0)
+	for e := range g.edges {
+		if e.tail == e.head {
+			t.Errorf("unexpected loop: %v", e)
+		}
+		if e.tail >= n || e.head >= n {
+			t.Errorf("endpoint out of range: %v", e)
+		}
+		m := max(e.tail, e.head)
+		if m < last {
+			t.Errorf("edge precedes the edges of a previous vertex: %v", e)
+		}
+		last = m
+	}
+}
+
+func TestGrowingGraphUnbounded(t *testing.T) {
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+	g := growingGraph(math.MaxInt64, 2, 1, 0, false, false, newRNG(rngPCG, 0), vlabel)
+
+	n := 0
+	for e := range g.edges {
+		if max(e.tail, e.head) > 1000 {
+			t.Fatalf("unexpected endpoint: %v", e)
+		}
+		if n++; n == 1000 {
+			break
+		}
+	}
+}
+
+func TestGrowingGraphChurn(t *testing.T) {
+	const n = 200
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+	g := growingGraph(n, 3, 1, 0.5, false, false, newRNG(rngPCG, 0), vlabel)
+
+	live := make(map[edge]int)
+	removed := 0
+	last := int64(0)
+	for e := range g.edges {
+		if e.time < last {
+			t.Errorf("time goes backwards: %v", e)
+		}
+		last = e.time
+		if m := max(e.tail, e.head); m > e.time {
+			t.Errorf("edge after its time: %v", e)
+		}
+
+		key := edge{tail: e.tail, head: e.head}
+		if !e.removed {
+			live[key]++
+			continue
+		}
+		if live[key] == 0 {
+			t.Errorf("removed edge does not exist: %v", e)
+		}
+		live[key]--
+		removed++
+	}
+	if removed == 0 {
+		t.Error("no edge was removed")
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"iter"
+)
+
+// headElements returns the first k edges of seq and the vertices they
+// reference, so that the result is a valid graph. The vertices are
+// returned first, in input order. Since a vertex can be declared
+// before its first edge, the vertices read before the k-th edge are
+// kept in memory.
+func headElements(seq iter.Seq2[element, error], k int) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		var (
+			edges    []edge
+			vertices []vertex
+			ends     = make(map[int64]struct{})
+		)
+		for elem, err := range seq {
+			if err != nil {
+				yield(elem, err)
+				return
+			}
+			if elem.isEdge {
+				if len(edges) < k {
+					edges = append(edges, elem.e)
+					ends[elem.e.tail] = struct{}{}
+					ends[elem.e.head] = struct{}{}
+				}
+				continue
+			}
+			if len(edges) == k {
+				if _, ok := ends[elem.v.id]; !ok {
+					continue
+				}
+			}
+			vertices = append(vertices, elem.v)
+		}
+
+		for _, v := range vertices {
+			if _, ok := ends[v.id]; !ok {
+				continue
+			}
+			if !yield(element{v: v}, nil) {
+				return
+			}
+		}
+		for _, e := range edges {
+			if !yield(element{isEdge: true, e: e}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func runHead(args []string) {
+	fs := flag.NewFlagSet("head", flag.ExitOnError)
+	k := fs.Int("edges", 1000, "number of `edges` to keep")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "head [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *k < 0 {
+		fatal(fmt.Errorf("invalid number of edges: %v", *k))
+	}
+
+	err := of.write(func(enc encoder) error {
+		return encodeElements(enc, headElements(decodeGraph(in), *k))
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadElements(t *testing.T) {
+	const input = "V: 0 A\nV: 1 B\nV: 2 C\nE: 0 1\nE: 1 3\nE: 2 0\nV: 3 D\nV: 4 E\n"
+	tests := []struct {
+		k    int
+		want string
+	}{
+		{k: 0, want: ""},
+		{k: 1, want: "V: 0 A\nV: 1 B\nE: 0 1\n"},
+		{k: 2, want: "V: 0 A\nV: 1 B\nV: 3 D\nE: 0 1\nE: 1 3\n"},
+		{k: 10, want: "V: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\nE: 0 1\nE: 1 3\nE: 2 0\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		for elem, err := range headElements(decodeSimple(strings.NewReader(input)), tt.k) {
+			if err != nil {
+				t.Fatalf("k=%v: unexpected error: %v", tt.k, err)
+			}
+			if elem.isEdge {
+				b.WriteString("E: " + formatID(elem.e.tail, 0) + " " + formatID(elem.e.head, 0) + "\n")
+			} else {
+				b.WriteString("V: " + formatID(elem.v.id, 0) + " " + elem.v.label + "\n")
+			}
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("k=%v: got: %q want: %q", tt.k, got, tt.want)
+		}
+	}
+}
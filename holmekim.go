@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+)
+
+// holmeKim returns a directed Holme-Kim graph with n vertices. It
+// grows one vertex at a time. Every new vertex performs trials edge
+// creation trials that succeed with probability p, and every
+// successful trial adds an edge from the new vertex to a vertex
+// already in the graph.
+//
+// The first edge of every vertex is created by preferential
+// attachment: its head is chosen with probability proportional to its
+// in-degree plus one. Every following edge is created, with
+// probability pt, by triad formation, pointing to a random
+// out-neighbor of the head of the last edge created by preferential
+// attachment, which closes a transitive triangle, or by preferential
+// attachment otherwise. Multiple edges are discarded unless allowed.
+// Loops cannot happen. r is the source of randomness.
+//
+// The adjacency lists of the graph are kept in memory.
+func holmeKim(n int64, trials int, p, pt float64, multiedges bool, r *rand.Rand, vlabel func(id int64) string) graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := range n {
+			if !yield(vertex{id: id, label: vlabel(id)}) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		// targets holds every vertex once and the head of
+		// every edge, so a random element is a preferential
+		// attachment target.
+		var targets []int64
+		out := make(map[int64][]int64)
+		seen := make(map[int64]struct{})
+		for id := range n {
+			clear(seen)
+			last := int64(-1)
+			for range trials {
+				if id == 0 || r.Float64() >= p {
+					continue
+				}
+				var head int64
+				if nbs := out[last]; last >= 0 && len(nbs) > 0 && r.Float64() < pt {
+					head = nbs[r.IntN(len(nbs))]
+				} else {
+					head = targets[r.IntN(len(targets))]
+					last = head
+				}
+				if !multiedges {
+					if _, ok := seen[head]; ok {
+						continue
+					}
+					seen[head] = struct{}{}
+				}
+				out[id] = append(out[id], head)
+				targets = append(targets, head)
+				if !yield(edge{tail: id, head: head}) {
+					return
+				}
+			}
+			targets = append(targets, id)
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
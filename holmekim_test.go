@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHolmeKim(t *testing.T) {
+	const n = 2000
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	// clustering returns the clustering coefficient of a Holme-Kim
+	// graph with triad formation probability pt.
+	clustering := func(pt float64) float64 {
+		st := newGraphStats()
+		g := holmeKim(n, 4, 1, pt, false, newRNG(rngPCG, 1), vlabel)
+		for v := range g.vertices {
+			st.add(element{v: v})
+		}
+		seen := make(map[edge]bool)
+		for e := range g.edges {
+			if e.tail <= e.head {
+				t.Errorf("edge does not point to an older vertex: %v", e)
+			}
+			if seen[e] {
+				t.Errorf("multiple edge: %v", e)
+			}
+			seen[e] = true
+			st.add(element{isEdge: true, e: e})
+		}
+		if st.vertices != n || st.edges == 0 {
+			t.Fatalf("unexpected size: %v vertices, %v edges", st.vertices, st.edges)
+		}
+		return st.clustering()
+	}
+
+	if low, high := clustering(0), clustering(0.9); high < 2*low || high < 0.1 {
+		t.Errorf("unexpected clustering: pt=0: %v, pt=0.9: %v", low, high)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readVertexSet reads a file of vertex IDs separated by whitespace.
+// Empty lines and lines starting with "#" are ignored.
+func readVertexSet(name string) (map[int64]struct{}, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	ids := make(map[int64]struct{})
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			id, err := parseID(field)
+			if err != nil {
+				return nil, fmt.Errorf("%v:%v: %w", name, n, err)
+			}
+			ids[id] = struct{}{}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func runInduce(args []string) {
+	fs := flag.NewFlagSet("induce", flag.ExitOnError)
+	verticesFile := fs.String("vertices", "", "read the vertex IDs to keep from `file`")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "induce -vertices file [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *verticesFile == "" {
+		fatal(fmt.Errorf("-vertices is required"))
+	}
+	ids, err := readVertexSet(*verticesFile)
+	if err != nil {
+		fatal(err)
+	}
+	keep := func(id int64) bool {
+		_, ok := ids[id]
+		return ok
+	}
+
+	err = of.write(func(enc encoder) error {
+		return encodeElements(enc, inducedElements(decodeGraph(in), keep))
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadVertexSet(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    []int64
+		wantErr bool
+	}{
+		{data: "# ids\n3\n\n1 2\n3\n", want: []int64{1, 2, 3}},
+		{data: "1\nx\n", wantErr: true},
+		{data: "1\n-2\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		name := filepath.Join(t.TempDir(), "ids.txt")
+		if err := os.WriteFile(name, []byte(tt.data), 0o644); err != nil {
+			t.Fatalf("could not write file: %v", err)
+		}
+		ids, err := readVertexSet(name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.data, err)
+			continue
+		}
+		if got := slices.Sorted(maps.Keys(ids)); !tt.wantErr && !slices.Equal(got, tt.want) {
+			t.Errorf("%q: unexpected IDs: got: %v want: %v", tt.data, got, tt.want)
+		}
+	}
+}
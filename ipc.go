@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Types of the response frames of the IPC protocol.
+const (
+	ipcData  = 'D' // chunk of the output
+	ipcError = 'E' // failed request, followed by an error record
+	ipcDone  = 'S' // finished request, followed by a summary
+)
+
+// maxIPCRequest is the maximum size of an IPC request.
+const maxIPCRequest = 1 << 20
+
+// ipcChunk is the maximum size of the data frames.
+const ipcChunk = 64 << 10
+
+// An ipcServer serves generation requests with the IPC protocol.
+//
+// Every frame starts with its length as a 32-bit big-endian unsigned
+// integer. Requests hold the arguments of a generation, separated by
+// NUL characters. Responses are sequences of frames whose first byte
+// is their type: data frames with chunks of the output, which are
+// concatenated, followed by an error frame with an error record or a
+// done frame with the number of vertices and edges as JSON.
+type ipcServer struct {
+	r *bufio.Reader
+	w *bufio.Writer
+
+	// base are the arguments prepended to every request.
+	base []string
+}
+
+// newIPCServer returns an ipcServer that reads requests from r and
+// writes responses to w. The flags explicitly set in fs, except -ipc,
+// are the defaults of every request.
+func newIPCServer(r io.Reader, w io.Writer, fs *flag.FlagSet) *ipcServer {
+	var base []string
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "ipc" {
+			base = append(base, fmt.Sprintf("-%v=%v", f.Name, f.Value))
+		}
+	})
+	return &ipcServer{r: bufio.NewReader(r), w: bufio.NewWriter(w), base: base}
+}
+
+// serve serves requests until the input ends or ctx is done. It
+// returns an error if the requests cannot be read or the responses
+// cannot be written.
+func (srv *ipcServer) serve(ctx context.Context) error {
+	for ctx.Err() == nil {
+		args, err := srv.readRequest()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var (
+			typ  byte
+			body any
+		)
+		if n, err := srv.handle(ctx, args); err != nil {
+			typ, body = ipcError, newErrorRecord(exitStatus(err), err)
+		} else {
+			typ, body = ipcDone, n
+		}
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		if err := srv.writeFrame(typ, b); err != nil {
+			return err
+		}
+		if err := srv.w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRequest reads the arguments of the next request. It returns
+// [io.EOF] if the input ends before the request.
+func (srv *ipcServer) readRequest() ([]string, error) {
+	var size uint32
+	if err := binary.Read(srv.r, binary.BigEndian, &size); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, inputError{errors.New("truncated IPC request")}
+		}
+		return nil, err
+	}
+	if size > maxIPCRequest {
+		return nil, inputError{fmt.Errorf("IPC request too large: %v bytes", size)}
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(srv.r, buf); err != nil {
+		return nil, inputError{errors.New("truncated IPC request")}
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	var args []string
+	for arg := range bytes.SplitSeq(buf, []byte{0}) {
+		args = append(args, string(arg))
+	}
+	return args, nil
+}
+
+// writeFrame writes a response frame.
+func (srv *ipcServer) writeFrame(typ byte, body []byte) error {
+	if err := binary.Write(srv.w, binary.BigEndian, uint32(1+len(body))); err != nil {
+		return err
+	}
+	if err := srv.w.WriteByte(typ); err != nil {
+		return err
+	}
+	_, err := srv.w.Write(body)
+	return err
+}
+
+// Write writes p as data frames.
+func (srv *ipcServer) Write(p []byte) (int, error) {
+	for chunk := range slices.Chunk(p, ipcChunk) {
+		if err := srv.writeFrame(ipcData, chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// An ipcSummary is the body of the done frames.
+type ipcSummary struct {
+	Vertices int64 `json:"vertices"`
+	Edges    int64 `json:"edges"`
+}
+
+// handle runs the generation with the provided arguments. The output
+// is written as data frames unless it is written to files.
+func (srv *ipcServer) handle(ctx context.Context, args []string) (ipcSummary, error) {
+	c, fs, err := parseGenerate(append(slices.Clone(srv.base), args...))
+	if err != nil {
+		return ipcSummary{}, err
+	}
+	if c.ipc || c.version || c.bench || c.cpuProfile != "" || c.memProfile != "" || c.checksum != "" || c.manifest != "" || c.checkpoint != "" {
+		return ipcSummary{}, usageError{errors.New("-ipc, -version, -bench, -cpuprofile, -memprofile, -checksum, -manifest and -checkpoint are not supported in IPC requests")}
+	}
+
+	if len(c.tees) > 0 && c.outFile == "" {
+		return ipcSummary{}, usageError{errors.New("-tee requires -o in IPC requests")}
+	}
+
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		return ipcSummary{}, err
+	}
+	for _, reason := range c.degenerate() {
+		warn("degenerate graph", "reason", reason)
+	}
+
+	switch {
+	case c.snapshots > 0:
+		err = gen.writeSnapshots(ctx, c.snapDir, c.atomic)
+	case c.outFile != "":
+		err = gen.writeFile(ctx, c.outFile, c.appendOut, c.atomic)
+	default:
+		bw := bufio.NewWriterSize(srv, ipcChunk)
+		gen.write(ctx, bw, func() {
+			bw.Flush()
+			srv.w.Flush()
+		})
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = gen.verify(ctx.Err() == nil)
+	}
+	if err != nil {
+		return ipcSummary{}, err
+	}
+	return ipcSummary{Vertices: gen.counter.vertices, Edges: gen.counter.edges}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// ipcRequest returns the frame of a request with the provided
+// arguments.
+func ipcRequest(args ...string) []byte {
+	body := strings.Join(args, "\x00")
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(body))), body...)
+}
+
+// An ipcResponse is a response of the IPC protocol.
+type ipcResponse struct {
+	typ  byte
+	data string
+	body string
+}
+
+// readIPCResponses reads the responses in b.
+func readIPCResponses(t *testing.T, b []byte) []ipcResponse {
+	t.Helper()
+
+	var (
+		resps []ipcResponse
+		data  strings.Builder
+	)
+	r := bytes.NewReader(b)
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("error reading frame: %v", err)
+		}
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			t.Fatalf("error reading frame: %v", err)
+		}
+		if frame[0] == ipcData {
+			data.Write(frame[1:])
+			continue
+		}
+		resps = append(resps, ipcResponse{typ: frame[0], data: data.String(), body: string(frame[1:])})
+		data.Reset()
+	}
+	return resps
+}
+
+func TestIPCServer(t *testing.T) {
+	var c genConfig
+	fs := c.flagSet("generate")
+	if err := fs.Parse([]string{"-ipc", "-meta=false", "-seed=1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var in bytes.Buffer
+	in.Write(ipcRequest("-n=3"))
+	in.Write(ipcRequest("-n=-1"))
+	in.Write(ipcRequest("-n=3", "-emit=vertices"))
+	in.Write(ipcRequest("-bench"))
+
+	var out bytes.Buffer
+	srv := newIPCServer(&in, &out, fs)
+	if err := srv.serve(context.Background()); err != nil {
+		t.Fatalf("serve error: %v", err)
+	}
+
+	resps := readIPCResponses(t, out.Bytes())
+	if len(resps) != 4 {
+		t.Fatalf("unexpected number of responses: %v", len(resps))
+	}
+
+	first := resps[0]
+	if first.typ != ipcDone || !strings.HasPrefix(first.data, "V: 0 0\nV: 1 1\nV: 2 2\n") {
+		t.Errorf("unexpected first response: %+v", first)
+	}
+	var sum ipcSummary
+	if err := json.Unmarshal([]byte(first.body), &sum); err != nil {
+		t.Fatalf("invalid summary: %v", err)
+	}
+	if sum.Vertices != 3 || int(sum.Edges) != strings.Count(first.data, "E: ") {
+		t.Errorf("unexpected summary: %+v", sum)
+	}
+
+	var rec errorRecord
+	if err := json.Unmarshal([]byte(resps[1].body), &rec); err != nil {
+		t.Fatalf("invalid error record: %v", err)
+	}
+	if resps[1].typ != ipcError || rec.Status != exitUsage {
+		t.Errorf("unexpected second response: %+v", resps[1])
+	}
+
+	if want := "V: 0 0\nV: 1 1\nV: 2 2\n"; resps[2].typ != ipcDone || resps[2].data != want {
+		t.Errorf("unexpected third response: %+v", resps[2])
+	}
+
+	if resps[3].typ != ipcError {
+		t.Errorf("unexpected fourth response: %+v", resps[3])
+	}
+}
+
+func TestIPCServerTruncated(t *testing.T) {
+	var c genConfig
+	fs := c.flagSet("generate")
+
+	in := bytes.NewReader(ipcRequest("-n=3")[:5])
+	srv := newIPCServer(in, io.Discard, fs)
+	if err := srv.serve(context.Background()); exitStatus(err) != exitInvalid {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+// Package mkdigraph.js loads the WebAssembly build of mkdigraph and
+// exposes its generator. wasm_exec.js, from the lib/wasm directory of
+// the Go distribution that built mkdigraph.wasm, must be loaded first.
+//
+// Build mkdigraph.wasm with:
+//
+//	GOOS=js GOARCH=wasm go build -o mkdigraph.wasm github.com/jroimartin/mkdigraph
+//
+// For instance:
+//
+//	const mkdigraph = await load("mkdigraph.wasm");
+//	mkdigraph.generate({n: 10, seed: 1}, (v) => console.log(v), (e) => console.log(e));
+
+// load fetches and starts mkdigraph.wasm from url, and returns an
+// object with its generate function.
+export async function load(url = "mkdigraph.wasm") {
+	const go = new Go();
+	const {instance} = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+	go.run(instance);
+	const {generate} = globalThis.mkdigraph;
+	return {
+		// generate generates a graph. params maps the flags of the
+		// generate command to their values, like {n: 100, seed: 1,
+		// "vertex-types": "a:1,b:1"}. onVertex and onEdge are called
+		// with every vertex ({id, label, type, community, time, lat,
+		// lon}) and edge ({tail, head, type, time, removed}), only
+		// including the meaningful attributes, and stop the
+		// generation if they return false. It returns the number of
+		// vertices and edges generated ({vertices, edges}) and
+		// throws an error if the generation fails.
+		generate(params = {}, onVertex = null, onEdge = null) {
+			const result = generate(params, onVertex, onEdge);
+			if (result.error !== undefined) {
+				throw new Error(result.error);
+			}
+			return result;
+		},
+	};
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// readLabelPools reads the words files of the -label-pools flag, which
+// are the pools of the levels of the dotted labels.
+func (c *genConfig) readLabelPools() ([][]string, error) {
+	var pools [][]string
+	for file := range strings.SplitSeq(c.labelPools, ",") {
+		if file == "" {
+			return nil, fmt.Errorf("malformed label pools: %q", c.labelPools)
+		}
+		words, err := c.readWords(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("%v: no words", file)
+		}
+		pools = append(pools, words)
+	}
+	return pools, nil
+}
+
+// poolsCapacity returns the number of distinct dotted labels of the
+// provided depth, saturated at math.MaxInt64.
+func poolsCapacity(pools [][]string, depth int) int64 {
+	n := int64(1)
+	for i := range depth {
+		size := int64(len(pools[i%len(pools)]))
+		if n > math.MaxInt64/size {
+			return math.MaxInt64
+		}
+		n *= size
+	}
+	return n
+}
+
+// dottedLabel returns the dotted label of the vertex with the provided
+// ID, made of depth words separated by dots. The word of level i is
+// taken from pools[i % len(pools)]. IDs are written in mixed radix with
+// the sizes of the pools as bases, the last level being the least
+// significant one, so consecutive vertices share the upper levels. If
+// the labels are exhausted, they repeat and, unless mode is
+// collisionReuse, they are suffixed with the ID, padded up to width.
+func dottedLabel(pools [][]string, depth int, id int64, width int, mode collisionMode) string {
+	parts := make([]string, depth)
+	n := id
+	for i := depth - 1; i >= 0; i-- {
+		pool := pools[i%len(pools)]
+		parts[i] = pool[n%int64(len(pool))]
+		n /= int64(len(pool))
+	}
+	l := strings.Join(parts, ".")
+	if n > 0 && mode != collisionReuse {
+		l += formatID(id, width)
+	}
+	return l
+}
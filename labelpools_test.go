@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDottedLabel(t *testing.T) {
+	pools := [][]string{{"eu", "us"}, {"a", "b", "c"}}
+	tests := []struct {
+		depth int
+		id    int64
+		mode  collisionMode
+		want  string
+	}{
+		{depth: 2, id: 0, mode: collisionSuffix, want: "eu.a"},
+		{depth: 2, id: 1, mode: collisionSuffix, want: "eu.b"},
+		{depth: 2, id: 3, mode: collisionSuffix, want: "us.a"},
+		{depth: 2, id: 5, mode: collisionSuffix, want: "us.c"},
+		{depth: 2, id: 6, mode: collisionSuffix, want: "eu.a6"},
+		{depth: 2, id: 6, mode: collisionReuse, want: "eu.a"},
+		{depth: 3, id: 7, mode: collisionSuffix, want: "us.a.us"},
+		{depth: 1, id: 1, mode: collisionSuffix, want: "us"},
+	}
+	for _, tt := range tests {
+		if got := dottedLabel(pools, tt.depth, tt.id, 0, tt.mode); got != tt.want {
+			t.Errorf("depth %v, id %v: unexpected label: got: %q, want: %q", tt.depth, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestPoolsCapacity(t *testing.T) {
+	pools := [][]string{{"eu", "us"}, {"a", "b", "c"}}
+	if got := poolsCapacity(pools, 3); got != 12 {
+		t.Errorf("unexpected capacity: got: %v, want: 12", got)
+	}
+	if got := poolsCapacity(pools, 100); got != math.MaxInt64 {
+		t.Errorf("capacity does not saturate: got: %v", got)
+	}
+}
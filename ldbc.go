@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Parameters of the LDBC profile.
+const (
+	// ldbcPersons is the number of persons per unit of scale
+	// factor.
+	ldbcPersons = 10000
+
+	// ldbcPosts is the number of posts created by every person.
+	ldbcPosts = 10
+
+	// ldbcKnows is the distribution of the number of persons
+	// every person knows.
+	ldbcKnows = "powerlaw:2,3,1000"
+
+	// ldbcNearby is the probability of knowing a person with a
+	// nearby ID, which gives the graph its community structure,
+	// and ldbcWindow is the maximum distance between their IDs.
+	ldbcNearby = 0.8
+	ldbcWindow = 500
+
+	// ldbcLike is the probability of every acquaintance of a
+	// person liking a post of that person.
+	ldbcLike = 0.2
+
+	// ldbcStart is the creation time of the first person, and
+	// ldbcSpan the time span of person and post creation. Times
+	// are Unix times in seconds.
+	ldbcStart = 1262304000 // 2010-01-01T00:00:00Z
+	ldbcSpan  = 3 * 365 * 24 * 3600
+)
+
+// ldbcKnowsDist is the parsed ldbcKnows distribution.
+var ldbcKnowsDist = mustParseDegreeDist(ldbcKnows)
+
+// An ldbcNetwork is a social network that approximates the schema of
+// the LDBC Social Network Benchmark. It has Person and Post vertices,
+// and knows, hasCreator and likes edges, all of them with their
+// creation times.
+//
+// The persons have generation IDs in [0, persons), and the posts of
+// person i have IDs in [persons+i*ldbcPosts, persons+(i+1)*ldbcPosts).
+type ldbcNetwork struct {
+	persons int64
+	algo    rngAlgorithm
+	seed    uint64
+}
+
+// newLDBCNetwork returns the network of the provided scale factor.
+// seed determines the network.
+func newLDBCNetwork(sf float64, algo rngAlgorithm, seed uint64) (ldbcNetwork, error) {
+	persons := math.Round(sf * ldbcPersons)
+	if !(persons >= 2) || persons*(1+ldbcPosts) > math.MaxInt64/2 {
+		return ldbcNetwork{}, fmt.Errorf("invalid scale factor: %v", sf)
+	}
+	return ldbcNetwork{persons: int64(persons), algo: algo, seed: seed}, nil
+}
+
+// vertices returns the number of vertices of the network.
+func (net ldbcNetwork) vertices() int64 {
+	return net.persons * (1 + ldbcPosts)
+}
+
+// creator returns the ID of the creator of the post with the provided
+// ID.
+func (net ldbcNetwork) creator(post int64) int64 {
+	return (post - net.persons) / ldbcPosts
+}
+
+// created returns the creation time of the vertex with the provided
+// ID. Posts are created after their creators.
+func (net ldbcNetwork) created(id int64) int64 {
+	h := vertexHash(net.seed, id, saltTime)
+	if id < net.persons {
+		return ldbcStart + int64(h%ldbcSpan)
+	}
+	return net.created(net.creator(id)) + 1 + int64(h%ldbcSpan)
+}
+
+// graph returns the network as a graph.
+func (net ldbcNetwork) graph(vlabel func(id int64) string) graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := range net.vertices() {
+			vtype := "Post"
+			if id < net.persons {
+				vtype = "Person"
+			}
+			v := vertex{id: id, label: vlabel(id), vtype: vtype, time: net.created(id)}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+
+	edges := func(yield func(edge) bool) {
+		newEdge := func(tail, head int64, etype string) edge {
+			e := edge{tail: tail, head: head, etype: etype}
+			jitter := int64(mix64(vertexHash(net.seed, tail, saltTime)^uint64(head)) % (24 * 3600))
+			e.time = max(net.created(tail), net.created(head)) + 1 + jitter
+			return e
+		}
+
+		var friends []int64
+		seen := make(map[int64]struct{})
+		for person := range net.persons {
+			r := vertexRNG(net.algo, net.seed, person)
+
+			friends = friends[:0]
+			clear(seen)
+			for range ldbcKnowsDist(r) {
+				var friend int64
+				if r.Float64() < ldbcNearby {
+					friend = person + r.Int64N(2*ldbcWindow+1) - ldbcWindow
+					friend = (friend%net.persons + net.persons) % net.persons
+				} else {
+					friend = r.Int64N(net.persons)
+				}
+				if _, ok := seen[friend]; ok || friend == person {
+					continue
+				}
+				seen[friend] = struct{}{}
+				friends = append(friends, friend)
+				if !yield(newEdge(person, friend, "knows")) {
+					return
+				}
+			}
+
+			for i := range int64(ldbcPosts) {
+				post := net.persons + person*ldbcPosts + i
+				if !yield(newEdge(post, person, "hasCreator")) {
+					return
+				}
+				for _, friend := range friends {
+					if r.Float64() >= ldbcLike {
+						continue
+					}
+					if !yield(newEdge(friend, post, "likes")) {
+						return
+					}
+				}
+			}
+		}
+	}
+
+	return graph{vertices: vertices, edges: edges}
+}
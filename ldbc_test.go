@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestLDBCNetwork(t *testing.T) {
+	net, err := newLDBCNetwork(0.02, rngPCG, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if net.persons != 200 || net.vertices() != 200*(1+ldbcPosts) {
+		t.Fatalf("unexpected size: %v persons, %v vertices", net.persons, net.vertices())
+	}
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+	g := net.graph(vlabel)
+
+	created := make(map[int64]int64)
+	for v := range g.vertices {
+		want := "Post"
+		if v.id < net.persons {
+			want = "Person"
+		}
+		if v.vtype != want {
+			t.Errorf("vertex %v: unexpected type: got: %q, want: %q", v.id, v.vtype, want)
+		}
+		created[v.id] = v.time
+	}
+
+	counts := make(map[string]int)
+	creators := make(map[int64]int64)
+	for e := range g.edges {
+		counts[e.etype]++
+		if e.time <= created[e.tail] || e.time <= created[e.head] {
+			t.Errorf("edge %v -> %v is created before its endpoints", e.tail, e.head)
+		}
+		tailPerson, headPerson := e.tail < net.persons, e.head < net.persons
+		switch e.etype {
+		case "knows":
+			if !tailPerson || !headPerson || e.tail == e.head {
+				t.Errorf("invalid knows edge: %v -> %v", e.tail, e.head)
+			}
+		case "hasCreator":
+			if tailPerson || !headPerson || net.creator(e.tail) != e.head {
+				t.Errorf("invalid hasCreator edge: %v -> %v", e.tail, e.head)
+			}
+			if created[e.tail] <= created[e.head] {
+				t.Errorf("post %v is created before its creator", e.tail)
+			}
+			creators[e.tail] = e.head
+		case "likes":
+			if !tailPerson || headPerson {
+				t.Errorf("invalid likes edge: %v -> %v", e.tail, e.head)
+			}
+		default:
+			t.Errorf("unexpected edge type: %q", e.etype)
+		}
+	}
+	if int64(len(creators)) != net.persons*ldbcPosts {
+		t.Errorf("unexpected number of posts with creator: %v", len(creators))
+	}
+	if counts["knows"] == 0 || counts["likes"] == 0 {
+		t.Errorf("unexpected edge counts: %v", counts)
+	}
+
+	for _, sf := range []float64{0, -1, 0.0001} {
+		if _, err := newLDBCNetwork(sf, rngPCG, 1); err == nil {
+			t.Errorf("sf=%v: expected error", sf)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+)
+
+// maxEmptyCommunities is the maximum number of consecutive empty
+// communities drawn from the community size distribution before
+// giving up.
+const maxEmptyCommunities = 1000
+
+// An lfrModel is a directed LFR (Lancichinetti-Fortunato-Radicchi)
+// benchmark graph. Vertex degrees and community sizes are drawn from
+// the provided distributions, and a fraction mu of the edges of every
+// vertex point to other communities.
+type lfrModel struct {
+	mu         float64
+	loops      bool
+	multiedges bool
+	vlabel     func(id int64) string
+
+	// outDeg and inDeg are the degrees of every vertex.
+	outDeg, inDeg []int
+
+	// comm is the community of every vertex.
+	comm []int
+
+	// ncomms is the number of communities.
+	ncomms int
+
+	rng *rand.Rand
+}
+
+// newLFR draws the degrees of n vertices from out and in, the
+// community sizes from sizes, and assigns every vertex to a
+// community. r is the source of randomness.
+func newLFR(n int64, out, in, sizes degreeDist, mu float64, loops, multiedges bool, r *rand.Rand, vlabel func(id int64) string) (*lfrModel, error) {
+	m := &lfrModel{
+		mu:         mu,
+		loops:      loops,
+		multiedges: multiedges,
+		vlabel:     vlabel,
+		outDeg:     make([]int, n),
+		inDeg:      make([]int, n),
+		comm:       make([]int, n),
+		rng:        r,
+	}
+	for i := range n {
+		m.outDeg[i], m.inDeg[i] = out(r), in(r)
+	}
+
+	var caps []int64
+	empty := 0
+	for total := int64(0); total < n; {
+		s := int64(sizes(r))
+		if s == 0 {
+			if empty++; empty == maxEmptyCommunities {
+				return nil, errors.New("community size distribution only yields empty communities")
+			}
+			continue
+		}
+		empty = 0
+		s = min(s, n-total)
+		caps = append(caps, s)
+		total += s
+	}
+	m.ncomms = len(caps)
+	m.assign(caps)
+	return m, nil
+}
+
+// assign assigns every vertex to a community whose capacity is given
+// by caps. Vertices are assigned in decreasing order of internal
+// degree to a random community with room left that is large enough to
+// hold all their internal edges. If there is no such community, the
+// vertex is assigned to the community with the most room left.
+func (m *lfrModel) assign(caps []int64) {
+	size := slices.Clone(caps)
+	order := make([]int, len(m.comm))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return m.internal(max(m.outDeg[b], m.inDeg[b])) - m.internal(max(m.outDeg[a], m.inDeg[a]))
+	})
+
+	for _, v := range order {
+		k := int64(m.internal(max(m.outDeg[v], m.inDeg[v])))
+		if !m.loops {
+			k++
+		}
+		c := -1
+		for range 2 * len(caps) {
+			i := m.rng.IntN(len(caps))
+			if caps[i] > 0 && size[i] >= k {
+				c = i
+				break
+			}
+		}
+		if c < 0 {
+			for i := range caps {
+				if c < 0 || caps[i] > caps[c] {
+					c = i
+				}
+			}
+		}
+		m.comm[v] = c
+		caps[c]--
+	}
+}
+
+// internal returns the number of internal edges of a vertex of
+// degree k.
+func (m *lfrModel) internal(k int) int {
+	return int(math.Round((1 - m.mu) * float64(k)))
+}
+
+// graph returns the LFR benchmark graph. The edges inside every
+// community are created by stub matching among its vertices, and the
+// edges between communities by stub matching among all the remaining
+// stubs. External pairs that fall in the same community are
+// discarded, as well as loops and multiple edges unless allowed.
+func (m *lfrModel) graph() graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := range int64(len(m.comm)) {
+			v := vertex{id: id, label: m.vlabel(id), community: strconv.Itoa(m.comm[id])}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		tails := make([][]int64, m.ncomms+1)
+		heads := make([][]int64, m.ncomms+1)
+		for id, c := range m.comm {
+			kout, kin := m.internal(m.outDeg[id]), m.internal(m.inDeg[id])
+			for range kout {
+				tails[c] = append(tails[c], int64(id))
+			}
+			for range kin {
+				heads[c] = append(heads[c], int64(id))
+			}
+			for range m.outDeg[id] - kout {
+				tails[m.ncomms] = append(tails[m.ncomms], int64(id))
+			}
+			for range m.inDeg[id] - kin {
+				heads[m.ncomms] = append(heads[m.ncomms], int64(id))
+			}
+		}
+
+		seen := make(map[edge]struct{})
+		for c := range tails {
+			ts, hs := tails[c], heads[c]
+			m.rng.Shuffle(len(ts), func(i, j int) { ts[i], ts[j] = ts[j], ts[i] })
+			m.rng.Shuffle(len(hs), func(i, j int) { hs[i], hs[j] = hs[j], hs[i] })
+			for i := range min(len(ts), len(hs)) {
+				e := edge{tail: ts[i], head: hs[i]}
+				if c == m.ncomms && m.comm[e.tail] == m.comm[e.head] {
+					continue
+				}
+				if e.tail == e.head && !m.loops {
+					continue
+				}
+				if !m.multiedges {
+					if _, ok := seen[e]; ok {
+						continue
+					}
+					seen[e] = struct{}{}
+				}
+				if !yield(e) {
+					return
+				}
+			}
+			tails[c], heads[c] = nil, nil
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
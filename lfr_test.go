@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"testing"
+)
+
+func TestLFR(t *testing.T) {
+	const (
+		n  = 200
+		mu = 0.2
+	)
+
+	k := func(*rand.Rand) int { return 10 }
+	size := func(*rand.Rand) int { return 20 }
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	m, err := newLFR(n, k, k, size, mu, false, false, newRNG(rngPCG, 0), vlabel)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := m.graph()
+
+	comm := make(map[int64]string)
+	members := make(map[string]int)
+	for v := range g.vertices {
+		comm[v.id] = v.community
+		members[v.community]++
+	}
+	if len(comm) != n {
+		t.Fatalf("unexpected number of vertices: got: %v want: %v", len(comm), n)
+	}
+	if len(members) != n/20 {
+		t.Errorf("unexpected number of communities: got: %v want: %v", len(members), n/20)
+	}
+	for c, size := range members {
+		if size != 20 {
+			t.Errorf("unexpected size of community %v: got: %v want: 20", c, size)
+		}
+	}
+
+	seen := make(map[edge]bool)
+	var total, external int
+	for e := range g.edges {
+		if e.tail == e.head {
+			t.Errorf("unexpected loop: %v", e)
+		}
+		if seen[e] {
+			t.Errorf("unexpected multiple edge: %v", e)
+		}
+		seen[e] = true
+		total++
+		if comm[e.tail] != comm[e.head] {
+			external++
+		}
+	}
+	if frac := float64(external) / float64(total); frac > mu+0.05 {
+		t.Errorf("too many external edges: got: %v want: <= %v", frac, mu)
+	}
+	if total < n*10*8/10 {
+		t.Errorf("too few edges: %v", total)
+	}
+}
+
+func TestLFREmptyCommunities(t *testing.T) {
+	k := func(*rand.Rand) int { return 1 }
+	size := func(*rand.Rand) int { return 0 }
+	vlabel := func(id int64) string { return "" }
+
+	if _, err := newLFR(10, k, k, size, 0.1, false, false, newRNG(rngPCG, 0), vlabel); err == nil {
+		t.Error("expected error")
+	}
+}
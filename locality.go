@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// maxLocalAttempts is the maximum number of heads drawn for an edge
+// before falling back to the nearest vertex that does not duplicate
+// another edge.
+const maxLocalAttempts = 100
+
+// A decayFunc returns a random distance between 1 and limit, with
+// probability proportional to a weight that decays with the distance.
+// r is the source of randomness.
+type decayFunc func(r *rand.Rand, limit int64) int64
+
+// parseDecay parses a locality decay specification of the form
+// "name:param". The supported decays are:
+//
+//	geometric:r  weight r^(d-1)
+//	powerlaw:a   weight d^-a
+func parseDecay(s string) (decayFunc, error) {
+	name, param, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid locality decay %q: missing parameter", s)
+	}
+	x, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid locality decay %q: %w", s, err)
+	}
+
+	switch name {
+	case "geometric":
+		if !(x > 0 && x <= 1) {
+			return nil, fmt.Errorf("invalid locality decay %q: ratio out of range", s)
+		}
+		return func(r *rand.Rand, limit int64) int64 {
+			return geometricDistance(x, limit, r)
+		}, nil
+	case "powerlaw":
+		if !(x >= 0) {
+			return nil, fmt.Errorf("invalid locality decay %q: negative exponent", s)
+		}
+		return func(r *rand.Rand, limit int64) int64 {
+			return powerLawDistance(x, limit, r)
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid locality decay %q: %w", s, errors.New("unknown decay"))
+}
+
+// geometricDistance returns a random distance between 1 and limit with
+// probability proportional to x^(d-1), using the inverse of its
+// cumulative distribution function.
+func geometricDistance(x float64, limit int64, r *rand.Rand) int64 {
+	if x == 1 {
+		return 1 + r.Int64N(limit)
+	}
+	logx := math.Log(x)
+	// total is 1-x^limit, the normalization constant of the
+	// truncated distribution.
+	total := -math.Expm1(float64(limit) * logx)
+	d := 1 + int64(math.Floor(math.Log1p(-r.Float64()*total)/logx))
+	return min(max(d, 1), limit)
+}
+
+// powerLawDistance returns a random distance between 1 and limit with
+// probability proportional to d^-a. If a > 1, it is drawn from a Zipf
+// distribution. Otherwise, it is the integer part of a continuous
+// power law in [1, limit+1), accepted with probability (x/2d)^a, which
+// turns the density of the continuous value x into the weight of d.
+func powerLawDistance(a float64, limit int64, r *rand.Rand) int64 {
+	if a > 1 {
+		return 1 + int64(rand.NewZipf(r, a, 1, uint64(limit-1)).Uint64())
+	}
+	b := float64(limit) + 1
+	for {
+		u := r.Float64()
+		var x float64
+		if a == 1 {
+			x = math.Pow(b, u)
+		} else {
+			x = math.Pow(1+u*(math.Pow(b, 1-a)-1), 1/(1-a))
+		}
+		d := int64(x)
+		if d < 1 || d > limit {
+			continue
+		}
+		if r.Float64() < math.Pow(x/float64(2*d), a) {
+			return d
+		}
+	}
+}
+
+// localEdges returns the edges of seq with their heads moved to a
+// distance of their tails drawn from decay, so every edge connects
+// vertices whose generation IDs are close, while the tails, and thus
+// the out-degrees, are kept. The heads are chosen among the n
+// vertices, on either side of the tail, with probability proportional
+// to the decay weight of their distance. Loops are kept. Unless
+// multiedges is true, heads that duplicate a previous edge of the same
+// tail are drawn again, assuming that the edges of every tail are
+// consecutive. After [maxLocalAttempts] attempts, the nearest vertex
+// that is not a head yet is chosen, and the edge is only discarded if
+// there is none. r is the source of randomness.
+func localEdges(seq iter.Seq[edge], decay decayFunc, n int64, multiedges bool, r *rand.Rand) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		last := int64(-1)
+		seen := make(map[int64]bool)
+		for e := range seq {
+			if e.tail == e.head {
+				if !yield(e) {
+					return
+				}
+				continue
+			}
+			if e.tail != last {
+				clear(seen)
+				last = e.tail
+			}
+			head := localHead(e.tail, n, decay, r)
+			for i := 1; !multiedges && seen[head] && i < maxLocalAttempts; i++ {
+				head = localHead(e.tail, n, decay, r)
+			}
+			if !multiedges && seen[head] {
+				var ok bool
+				if head, ok = nearestFree(e.tail, n, seen); !ok {
+					continue
+				}
+			}
+			seen[head] = true
+			e.head = head
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// nearestFree returns the nearest vertex to tail among the n vertices
+// that is not tail nor in seen. It returns false if there is none.
+func nearestFree(tail, n int64, seen map[int64]bool) (int64, bool) {
+	for d := int64(1); d <= max(tail, n-1-tail); d++ {
+		if v := tail - d; v >= 0 && !seen[v] {
+			return v, true
+		}
+		if v := tail + d; v < n && !seen[v] {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// localHead returns a random vertex other than tail among the n
+// vertices, with probability proportional to the decay weight of its
+// distance to tail. The distance is drawn up to the farthest vertex
+// and the side is chosen at random, retrying if there is no vertex at
+// that distance on that side, so every vertex gets the weight of its
+// distance. n must be greater than 1.
+func localHead(tail, n int64, decay decayFunc, r *rand.Rand) int64 {
+	left, right := tail, n-1-tail
+	for {
+		d := decay(r, max(left, right))
+		if r.IntN(2) == 0 {
+			if d <= left {
+				return tail - d
+			}
+		} else if d <= right {
+			return tail + d
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDecay(t *testing.T) {
+	const n = 100000
+
+	tests := []struct {
+		spec  string
+		limit int64
+		// ratio is the expected ratio between the frequencies
+		// of the distances 1 and d.
+		d     int64
+		ratio float64
+	}{
+		{spec: "geometric:0.5", limit: 1000, d: 3, ratio: 4},
+		{spec: "geometric:1", limit: 10, d: 7, ratio: 1},
+		{spec: "powerlaw:2", limit: 1000, d: 2, ratio: 4},
+		{spec: "powerlaw:1", limit: 1000, d: 4, ratio: 4},
+		{spec: "powerlaw:0.5", limit: 1000, d: 4, ratio: 2},
+		{spec: "powerlaw:0", limit: 10, d: 7, ratio: 1},
+	}
+	for _, tt := range tests {
+		decay, err := parseDecay(tt.spec)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.spec, err)
+			continue
+		}
+		r := newRNG(rngPCG, 1)
+		count := make(map[int64]int)
+		for range n {
+			d := decay(r, tt.limit)
+			if d < 1 || d > tt.limit {
+				t.Fatalf("%v: distance out of range: %v", tt.spec, d)
+			}
+			count[d]++
+		}
+		if got := float64(count[1]) / float64(count[tt.d]); math.Abs(got-tt.ratio) > 0.1*tt.ratio {
+			t.Errorf("%v: unexpected ratio between distances 1 and %v: got: %v want: %v", tt.spec, tt.d, got, tt.ratio)
+		}
+	}
+}
+
+func TestParseDecayInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"geometric",
+		"geometric:x",
+		"geometric:0",
+		"geometric:1.5",
+		"powerlaw:-1",
+		"exponential:0.5",
+	}
+	for _, spec := range specs {
+		if _, err := parseDecay(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestLocalEdges(t *testing.T) {
+	const n = 1000
+
+	var edges []edge
+	for tail := range int64(n) {
+		edges = append(edges, edge{tail: tail, head: tail})
+		for head := range int64(10) {
+			edges = append(edges, edge{tail: tail, head: (tail + 100*head + 1) % n})
+		}
+	}
+	decay, err := parseDecay("geometric:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := slices.Collect(localEdges(slices.Values(edges), decay, n, false, newRNG(rngPCG, 0)))
+	if len(got) != len(edges) {
+		t.Fatalf("unexpected number of edges: got: %v want: %v", len(got), len(edges))
+	}
+	seen := make(map[edge]bool)
+	var total int64
+	for i, e := range got {
+		if e.tail != edges[i].tail {
+			t.Fatalf("tail changed: got: %v want: %v", e, edges[i])
+		}
+		if e.head < 0 || e.head >= n {
+			t.Fatalf("head out of range: %v", e)
+		}
+		if (e.head == e.tail) != (edges[i].head == edges[i].tail) {
+			t.Errorf("unexpected loop: got: %v want: %v", e, edges[i])
+		}
+		if seen[e] {
+			t.Errorf("multiple edge: %v", e)
+		}
+		seen[e] = true
+		total += max(e.head-e.tail, e.tail-e.head)
+	}
+	// Every vertex has 10 edges, so the heads must be spread
+	// over at least 5 vertices at every side.
+	if mean := float64(total) / float64(len(got)); mean > 5 {
+		t.Errorf("edges are not local: mean distance: %v", mean)
+	}
+}
+
+func TestLocalityEdgeCount(t *testing.T) {
+	count := func(args ...string) int64 {
+		c, fs, err := parseGenerate(args)
+		if err != nil {
+			t.Fatalf("%v: parse error: %v", args, err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			t.Fatalf("%v: generation error: %v", args, err)
+		}
+		var buf bytes.Buffer
+		gen.write(context.Background(), &buf, func() {})
+		return int64(strings.Count(buf.String(), "E: "))
+	}
+
+	want := count("-n=10000", "-seed=3", "-meta=false")
+	for _, decay := range []string{"geometric:0.5", "powerlaw:1", "powerlaw:2.5"} {
+		got := count("-n=10000", "-seed=3", "-meta=false", "-locality="+decay)
+		if math.Abs(float64(got-want)) > 0.01*float64(want) {
+			t.Errorf("%v: unexpected number of edges: got: %v want: %v", decay, got, want)
+		}
+	}
+}
+
+func TestLocalityModels(t *testing.T) {
+	for _, args := range [][]string{
+		{"-locality=geometric:0.5", "-infinite"},
+		{"-locality=geometric:0.5", "-out-degree=const:2"},
+		{"-locality=geometric:0.5", "-triad-formation=0.5"},
+	} {
+		c, fs, err := parseGenerate(args)
+		if err != nil {
+			t.Fatalf("%v: parse error: %v", args, err)
+		}
+		if _, err := c.newGeneration(fs); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
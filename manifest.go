@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// A writtenFile is an output file written by a generation.
+type writtenFile struct {
+	name   string
+	format string
+
+	// vertices and edges are the number of vertices and edges
+	// written to the file.
+	vertices, edges int64
+
+	// size and sum are the size and the hex-encoded SHA-256
+	// checksum of the file, computed as it is written. sum is
+	// empty if checksums are not computed.
+	size int64
+	sum  string
+}
+
+// A manifest lists the output files written by a generation.
+type manifest struct {
+	Version    string         `json:"version"`
+	Parameters string         `json:"parameters"`
+	Range      string         `json:"vertex_range,omitempty"`
+	Complete   bool           `json:"complete"`
+	Files      []manifestFile `json:"files"`
+}
+
+// A manifestFile is an entry of a manifest.
+type manifestFile struct {
+	Name     string `json:"name"`
+	Format   string `json:"format"`
+	Vertices int64  `json:"vertices"`
+	Edges    int64  `json:"edges"`
+	Bytes    int64  `json:"bytes"`
+	SHA256   string `json:"sha256"`
+}
+
+// writeManifest writes the named manifest file listing files. The
+// names of the files are relative to the directory of the manifest.
+// The sizes and checksums of the files whose checksum has not been
+// computed are computed by reading them.
+func writeManifest(name string, m manifest, files []writtenFile) error {
+	m.Files = []manifestFile{}
+	for _, wf := range files {
+		size, sum := wf.size, wf.sum
+		if sum == "" {
+			var err error
+			if size, sum, err = hashFile(wf.name); err != nil {
+				return err
+			}
+		}
+		fname := wf.name
+		if rel, err := filepath.Rel(filepath.Dir(name), wf.name); err == nil {
+			fname = filepath.ToSlash(rel)
+		}
+		m.Files = append(m.Files, manifestFile{
+			Name:     fname,
+			Format:   wf.format,
+			Vertices: wf.vertices,
+			Edges:    wf.edges,
+			Bytes:    size,
+			SHA256:   sum,
+		})
+	}
+
+	out, err := createOutput(name, false, true)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(m); err != nil {
+		out.abort()
+		return err
+	}
+	return out.commit()
+}
+
+// hashFile returns the size and the hex-encoded SHA-256 checksum of
+// the named file.
+func hashFile(name string) (size int64, sum string, err error) {
+	h := sha256.New()
+	if size, err = hashFileTo(h, name, -1); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFileTo writes the first n bytes of the named file to h, or the
+// whole file if n is negative. It returns the number of bytes
+// written.
+func hashFileTo(h hash.Hash, name string, n int64) (int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if n < 0 {
+		return io.Copy(h, f)
+	}
+	return io.CopyN(h, f, n)
+}
+
+// writeChecksumFile writes the checksum of wf to a sidecar file named
+// after it with the ".sha256" extension, in the format of sha256sum.
+func writeChecksumFile(wf writtenFile) error {
+	out, err := createOutput(wf.name+".sha256", false, true)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(out, "%v  %v\n", wf.sum, filepath.Base(wf.name)); err != nil {
+		out.abort()
+		return err
+	}
+	return out.commit()
+}
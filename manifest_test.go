@@ -0,0 +1,80 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	graph := filepath.Join(dir, "out", "graph.txt")
+	if err := os.Mkdir(filepath.Dir(graph), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(graph, []byte("V: 0 0\nV: 1 1\nE: 0 1\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	name := filepath.Join(dir, "manifest.json")
+	files := []writtenFile{{name: graph, format: "simple", vertices: 2, edges: 1}}
+	if err := writeManifest(name, manifest{Parameters: "mkdigraph -n=2", Complete: true}, files); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := manifestFile{
+		Name:     "out/graph.txt",
+		Format:   "simple",
+		Vertices: 2,
+		Edges:    1,
+		Bytes:    21,
+		SHA256:   "fcb89203b1de4578d7e319045b9c109f83a6e1da3f58820ac27bebd3a1f135f9",
+	}
+	if len(m.Files) != 1 || m.Files[0] != want {
+		t.Errorf("unexpected files:\ngot: %+v\nwant: %+v", m.Files, []manifestFile{want})
+	}
+	if m.Parameters != "mkdigraph -n=2" || !m.Complete {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+}
+
+func TestWriteChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "graph.txt")
+	content := []byte("V: 0 0\nV: 1 1\nE: 0 1\n")
+	if err := os.WriteFile(name, content, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h := sha256.New()
+	h.Write(content)
+	wf := writtenFile{name: name, sum: hex.EncodeToString(h.Sum(nil))}
+	if err := writeChecksumFile(wf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(name + ".sha256")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "fcb89203b1de4578d7e319045b9c109f83a6e1da3f58820ac27bebd3a1f135f9  graph.txt\n"
+	if string(got) != want {
+		t.Errorf("unexpected checksum file: got: %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// Approximate sizes in bytes of the data structures held in memory by
+// the generation stages, including the overhead of maps and slice
+// growth.
+const (
+	stubBytes     = 8  // an element of a stub list
+	edgeBytes     = 32 // an edge
+	setEntryBytes = 64 // an entry of a set of edges
+	adjEntryBytes = 16 // an entry of an adjacency list
+	mapEntryBytes = 64 // an entry of a per-vertex map
+	wordBytes     = 16 // a string header
+)
+
+// meanSamples is the number of samples used to estimate the mean of a
+// degree distribution.
+const meanSamples = 10000
+
+// A byteSize is a number of bytes that can be set with a flag. Values
+// are integers with an optional K, M, G or T suffix, which are powers
+// of 1024, optionally followed by "B" or "iB".
+type byteSize int64
+
+// String returns the size in bytes.
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+// Set parses s and sets b.
+func (b *byteSize) Set(s string) error {
+	num := strings.TrimSuffix(strings.TrimSuffix(s, "B"), "i")
+	shift := 0
+	if i := len(num) - 1; i > 0 {
+		if j := strings.IndexByte("KMGT", num[i]); j >= 0 {
+			shift = 10 * (j + 1)
+			num = num[:i]
+		}
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64>>shift {
+		return fmt.Errorf("invalid size: %q", s)
+	}
+	*b = byteSize(n << shift)
+	return nil
+}
+
+// formatBytes returns a human-readable representation of n bytes.
+func formatBytes(n float64) string {
+	if math.IsInf(n, 1) {
+		return "unbounded"
+	}
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	i := 0
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f%v", n, units[i])
+	}
+	return fmt.Sprintf("%.1f%v", n, units[i])
+}
+
+// A memUse is the estimated memory used by a component of a
+// generation.
+type memUse struct {
+	what  string
+	bytes float64
+}
+
+// memUses returns the estimated memory used by the components of the
+// generation described by c that hold data proportional to the size
+// of the graph or of the input files. The rest of the generation uses
+// a small amount of memory that does not depend on the size of the
+// graph. trials, comms and words are the contents of the input files.
+func (c *genConfig) memUses(trials map[int64]degreeDist, comms map[int64]string, words []string) ([]memUse, error) {
+	var uses []memUse
+
+	if len(words) > 0 {
+		var size float64
+		for _, w := range words {
+			size += float64(len(w) + wordBytes)
+		}
+		uses = append(uses, memUse{"words", size})
+	}
+
+	if len(trials) > 0 {
+		uses = append(uses, memUse{"trials file", float64(len(trials)) * mapEntryBytes})
+	}
+
+	if len(comms) > 0 {
+		size := float64(len(comms)) * mapEntryBytes
+		for _, comm := range comms {
+			size += float64(len(comm))
+		}
+		uses = append(uses, memUse{"communities", size})
+	}
+
+	n := float64(c.vertices)
+	edges, err := c.expectedEdges()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.commSizes != "" || c.outDegree != "" || c.inDegree != "" {
+		out, in, err := degreeDists(c.outDegree, c.inDegree, c.trials, c.prob)
+		if err != nil {
+			return nil, err
+		}
+		kout, kin := meanDegree(out), meanDegree(in)
+		uses = append(uses, memUse{"stubs", n * (kout + kin) * stubBytes})
+		if c.assort != 0 {
+			uses = append(uses, memUse{"assortative pairing", n*(kout+kin)*6*stubBytes + n*mapEntryBytes})
+		}
+		if c.commSizes != "" {
+			uses = append(uses, memUse{"LFR vertices", n * 3 * stubBytes})
+			if !c.multiedges && !c.usesBloomFilter() {
+				uses = append(uses, memUse{"LFR edge set", edges * setEntryBytes})
+			}
+		}
+	}
+
+	if c.propensity != "" {
+		uses = append(uses, memUse{"propensities", n * 2 * stubBytes})
+	}
+
+	if c.churn > 0 {
+		uses = append(uses, memUse{"live edges", math.Inf(1)})
+	}
+
+	if c.workers > 1 {
+		batch := float64(trialBatch) * float64(c.trials) * c.prob * edgeBytes
+		uses = append(uses, memUse{"worker batches", 3 * float64(c.workers) * batch})
+	}
+
+	if c.triad > 0 {
+		uses = append(uses, memUse{"Holme-Kim adjacency", edges*(adjEntryBytes+stubBytes) + n*(mapEntryBytes+stubBytes)})
+	}
+
+	if c.closure > 0 {
+		uses = append(uses, memUse{"triangle closure", edges*adjEntryBytes + n*mapEntryBytes})
+	}
+
+	if c.verify && !c.bench {
+		size := n*3*mapEntryBytes + edges*setEntryBytes
+		if c.infinite {
+			size = math.Inf(1)
+		}
+		uses = append(uses, memUse{"verifier", size})
+	}
+
+	if c.usesBloomFilter() {
+		m, _ := bloomParams(int64(edges), c.dedupFP)
+		uses = append(uses, memUse{"Bloom filter", float64((m + 63) / 64 * 8)})
+	}
+
+	return uses, nil
+}
+
+// expectedEdges returns the expected number of edges generated by the
+// model of c, before the stages that add or remove edges.
+func (c *genConfig) expectedEdges() (float64, error) {
+	n := float64(c.vertices)
+	switch {
+	case c.profile == "graph500":
+		return n * float64(c.edgefactor), nil
+	case c.commSizes != "" || c.outDegree != "" || c.inDegree != "":
+		out, in, err := degreeDists(c.outDegree, c.inDegree, c.trials, c.prob)
+		if err != nil {
+			return 0, err
+		}
+		return n * min(meanDegree(out), meanDegree(in)), nil
+	}
+	return n * float64(c.trials) * c.prob, nil
+}
+
+// checkMem returns an error if the total memory of uses exceeds
+// limit.
+func checkMem(uses []memUse, limit int64) error {
+	var total float64
+	for _, u := range uses {
+		total += u.bytes
+	}
+	if total <= float64(limit) {
+		return nil
+	}
+
+	parts := make([]string, len(uses))
+	for i, u := range uses {
+		parts[i] = fmt.Sprintf("%v: %v", u.what, formatBytes(u.bytes))
+	}
+	return fmt.Errorf("estimated memory use exceeds -max-mem: %v > %v (%v)",
+		formatBytes(total), formatBytes(float64(limit)), strings.Join(parts, ", "))
+}
+
+// meanDegree estimates the mean of dist by sampling it.
+func meanDegree(dist degreeDist) float64 {
+	r := rand.New(rand.NewPCG(1, 2))
+	var sum float64
+	for range meanSamples {
+		sum += float64(dist(r))
+	}
+	return sum / meanSamples
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    byteSize
+		wantErr bool
+	}{
+		{s: "0", want: 0},
+		{s: "1024", want: 1024},
+		{s: "2K", want: 2 << 10},
+		{s: "3MB", want: 3 << 20},
+		{s: "4GiB", want: 4 << 30},
+		{s: "1T", want: 1 << 40},
+		{s: "", wantErr: true},
+		{s: "G", wantErr: true},
+		{s: "-1", wantErr: true},
+		{s: "1X", wantErr: true},
+		{s: "9000000T", wantErr: true},
+	}
+	for _, tt := range tests {
+		var got byteSize
+		err := got.Set(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.s, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%q: unexpected size: got: %v, want: %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestCheckMem(t *testing.T) {
+	uses := []memUse{{"a", 600}, {"b", 500}}
+	if err := checkMem(uses, 1100); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkMem(uses, 1000); err == nil {
+		t.Error("expected error")
+	}
+	if err := checkMem([]memUse{{"live edges", math.Inf(1)}}, math.MaxInt64); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMemUses(t *testing.T) {
+	c := genConfig{vertices: 1000, trials: 4, prob: 0.5, closure: 0.5, dedup: "bloom", dedupFP: 0.01}
+	uses, err := c.memUses(nil, nil, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]float64)
+	for _, u := range uses {
+		got[u.what] = u.bytes
+	}
+	if got["words"] != 2*(1+wordBytes) {
+		t.Errorf("unexpected words size: %v", got["words"])
+	}
+	if want := 2000.0*adjEntryBytes + 1000*mapEntryBytes; got["triangle closure"] != want {
+		t.Errorf("unexpected triangle closure size: got: %v, want: %v", got["triangle closure"], want)
+	}
+	// The binomial model removes multiple edges on its own.
+	if _, ok := got["Bloom filter"]; ok {
+		t.Errorf("unexpected Bloom filter size: %v", got["Bloom filter"])
+	}
+	if _, ok := got["verifier"]; ok {
+		t.Errorf("unexpected verifier size: %v", got["verifier"])
+	}
+}
+
+func TestMemUsesBloom(t *testing.T) {
+	c := genConfig{vertices: 1000, profile: "graph500", edgefactor: 2, dedup: "bloom", dedupFP: 0.01}
+	uses, err := c.memUses(nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m, _ := bloomParams(2000, 0.01)
+	want := float64((m + 63) / 64 * 8)
+	if len(uses) != 1 || uses[0].what != "Bloom filter" || uses[0].bytes != want {
+		t.Errorf("unexpected uses: got: %v, want: Bloom filter %v", uses, want)
+	}
+}
+
+func TestMemUsesVerify(t *testing.T) {
+	c := genConfig{vertices: 1000, trials: 4, prob: 0.5, verify: true}
+	uses, err := c.memUses(nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := 3000.0*mapEntryBytes + 2000*setEntryBytes
+	if len(uses) != 1 || uses[0].what != "verifier" || uses[0].bytes != want {
+		t.Errorf("unexpected uses: got: %v, want: verifier %v", uses, want)
+	}
+}
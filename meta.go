@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// nonGenerationFlags are the flags that do not affect the generated
+// graph. They are not recorded in the output metadata.
+var nonGenerationFlags = map[string]bool{
+	"o":                   true,
+	"append":              true,
+	"atomic":              true,
+	"version":             true,
+	"meta":                true,
+	"log":                 true,
+	"ipc":                 true,
+	"errors":              true,
+	"workers":             true,
+	"bench":               true,
+	"bench-json":          true,
+	"cpuprofile":          true,
+	"memprofile":          true,
+	"max-mem":             true,
+	"vertex-range":        true,
+	"manifest":            true,
+	"checksum":            true,
+	"tee":                 true,
+	"snapshot-dir":        true,
+	"deltas":              true,
+	"checkpoint":          true,
+	"checkpoint-interval": true,
+	"resume":              true,
+	"verify":              true,
+}
+
+// formatFlags are the flags that change how the graph is written, but
+// not the graph itself. They are not recorded in the output metadata,
+// but a resumed generation must use the same values.
+var formatFlags = map[string]bool{
+	"emit":             true,
+	"interleave":       true,
+	"rate":             true,
+	"burst":            true,
+	"drop-on-overflow": true,
+	"overflow-buffer":  true,
+	"z":                true,
+	"crlf":             true,
+	"edge-tuples":      true,
+	"dot":              true,
+	"dot-strict":       true,
+	"dot-name":         true,
+	"dot-tooltip":      true,
+	"dot-url":          true,
+	"strict":           true,
+	"golden":           true,
+}
+
+// metadata returns the mkdigraph command line that reproduces the
+// graph. It only records the flags set in fs that affect the graph,
+// so the metadata does not change when new flags are added to
+// mkdigraph. The flags for which inert returns true, because they
+// have no effect with the rest of the flags, are omitted too.
+func metadata(fs *flag.FlagSet, inert func(name string) bool) string {
+	return commandLine(fs.Visit, func(name string) bool {
+		return formatFlags[name] || inert(name)
+	})
+}
+
+// checkpointParams returns the parameters of the generation recorded
+// in checkpoints, which include the format flags, so a generation is
+// only resumed with the same output.
+func checkpointParams(fs *flag.FlagSet) string {
+	return commandLine(fs.VisitAll, func(string) bool { return false })
+}
+
+// commandLine returns the mkdigraph command line with the generation
+// flags visited by visit, except the ones for which skip returns
+// true.
+func commandLine(visit func(func(*flag.Flag)), skip func(name string) bool) string {
+	args := []string{"mkdigraph"}
+	visit(func(f *flag.Flag) {
+		if nonGenerationFlags[f.Name] || skip(f.Name) {
+			return
+		}
+		v := f.Value.String()
+		if v == "" || strings.ContainsAny(v, " \t\n\"'\\") {
+			v = strconv.Quote(v)
+		}
+		args = append(args, "-"+f.Name+"="+v)
+	})
+	return strings.Join(args, " ")
+}
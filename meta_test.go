@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	fs := flag.NewFlagSet("mkdigraph", flag.ContinueOnError)
+	fs.Int("n", 25, "")
+	fs.Bool("loops", false, "")
+	fs.String("words", "", "")
+	fs.String("o", "", "")
+	fs.Bool("crlf", false, "")
+	fs.Int("scale", 16, "")
+	if err := fs.Parse([]string{"-n", "10", "-words", "my words", "-o", "out", "-crlf", "-scale=4"}); err != nil {
+		t.Fatal(err)
+	}
+	inert := func(name string) bool { return name == "scale" }
+
+	want := `mkdigraph -n=10 -words="my words"`
+	if got := metadata(fs, inert); got != want {
+		t.Errorf("unexpected metadata: got: %q want: %q", got, want)
+	}
+
+	want = `mkdigraph -crlf=true -loops=false -n=10 -scale=4 -words="my words"`
+	if got := checkpointParams(fs); got != want {
+		t.Errorf("unexpected checkpoint parameters: got: %q want: %q", got, want)
+	}
+}
+
+func TestMetadataGenerate(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"-n=10", "-seed=3", "-crlf", "-strict", "-overflow-buffer=1M", "-drop-on-overflow"},
+			want: "mkdigraph -n=10 -seed=3",
+		},
+		{
+			args: []string{"-profile=graph500", "-scale=4", "-seed=3"},
+			want: "mkdigraph -profile=graph500 -scale=4 -seed=3",
+		},
+		{
+			args: []string{"-golden", "-n=10"},
+			want: "mkdigraph -n=10 -seed=1",
+		},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.args, err)
+		}
+		if gen.p.meta != tt.want {
+			t.Errorf("%v: unexpected metadata: got: %q want: %q", tt.args, gen.p.meta, tt.want)
+		}
+	}
+}
+
+func TestMetadataRandomSeed(t *testing.T) {
+	generate := func(args ...string) string {
+		var c genConfig
+		fs := c.flagSet("generate")
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var buf bytes.Buffer
+		gen.write(context.Background(), &buf, func() {})
+		return buf.String()
+	}
+
+	out := generate("-n=50")
+	header, _, _ := strings.Cut(out, "\n")
+	args := strings.Fields(strings.TrimPrefix(header, "# mkdigraph "))
+	if len(args) != 2 || args[0] != "-n=50" || !strings.HasPrefix(args[1], "-seed=") || args[1] == "-seed=0" {
+		t.Fatalf("unexpected metadata: %q", header)
+	}
+	if again := generate(args...); again != out {
+		t.Error("the metadata does not reproduce the graph")
+	}
+}
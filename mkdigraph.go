@@ -4,24 +4,119 @@
 
 // Mkdigraph generates random directed graphs. It uses a stream
 // generator, so the full graph is not stored in memory, which makes
-// it possible to generate graphs of arbitrary size.
+// it possible to generate graphs of arbitrary size. It also provides
+// commands to process graphs.
 //
 // Usage:
 //
-//	mkdigraph [flags]
+//	mkdigraph [generate] [flags]
+//	mkdigraph command [flags] [args]
 //
-// The flags are:
+// The commands are:
+//
+//	generate    generate a random digraph (default)
+//	convert     convert a graph to another format
+//	stats       print graph statistics
+//	validate    check that a graph is well-formed
+//	sample      extract a random induced subgraph
+//	induce      extract the subgraph induced by a set of vertices
+//	ego         extract the neighborhood of a vertex
+//	relabel     renumber the vertices of a graph
+//	filter      remove the edges not matching an expression
+//	head        keep the first edges of a graph
+//	complement  compute the complement of a graph
+//	project     project a bipartite graph onto one of its parts
+//	canon       write a graph in canonical order
+//	toposort    sort a graph topologically
+//	condense    compute the strongly connected components of a graph
+//	components  split a graph into its weakly connected components
+//	reach       check whether a vertex is reachable from another
+//	degdist     print the degree distributions of a graph
+//	fit         estimate the generation parameters of a graph
+//	render      draw a graph as an image
+//	words       preprocess a words file
+//	corpus      write a corpus of small graphs
+//	serve       serve generated graphs over HTTP
+//	completion  write a shell completion script
+//
+// If no command is specified, generate is run.
+//
+// # Generate
+//
+// The generate flags are:
 //
 //	-n n
 //		Number of vertices (default 25).
 //
+//	-seed n
+//		Seed of the random number generator. If 0, a random
+//		seed is drawn and recorded in the output metadata
+//		(default 0). See below.
+//
+//	-vertex-time start,interval
+//		Assign creation times to vertices and edges. See
+//		below.
+//
+//	-coords minlat,minlon,maxlat,maxlon
+//		Assign geographic coordinates within the bounding box
+//		to vertices. See below.
+//
+//	-vertex-types list
+//		Assign types to vertices from a comma-separated list of
+//		type:weight pairs. See below.
+//
+//	-type-edges list
+//		Only keep the edges between the types of a
+//		comma-separated list of tail>head pairs. See below.
+//
+//	-type-words list
+//		Choose the labels of the vertices of every type from the
+//		words files of a comma-separated list of type=file
+//		pairs. See below.
+//
+//	-edge-types list
+//		Assign types to edges from a comma-separated list of
+//		type:weight pairs. See below.
+//
+//	-rng algorithm
+//		Random number generator algorithm: pcg, chacha8 or
+//		mt19937 (default pcg). See below.
+//
+//	-vertex-range a:b
+//		Only generate the vertices with generation IDs in
+//		[a, b) and their edges. If b is omitted, it is the
+//		number of vertices. See below.
+//
+//	-workers n
+//		Number of goroutines generating edges of the binomial
+//		model (default 1). See below.
+//
+//	-infinite
+//		Generate a growing graph until interrupted. See below.
+//
+//	-churn p
+//		Probability of removing a random edge after adding an
+//		edge with -infinite (default 0). See below.
+//
 //	-trials n
 //		Number of edge creation trials per vertex (default 5).
 //
+//	-trials-file path
+//		Read per-vertex numbers of trials from a trials file.
+//		See below.
+//
 //	-prob p
 //		Success probability for each trial. p is a float value
 //		between 0 and 1 (default 0.5).
 //
+//	-strict
+//		Reject parameters that produce degenerate graphs. See
+//		below.
+//
+//	-verify
+//		Check that the written graph satisfies the constraints
+//		of the generation. See below.
+//
 //	-loops
 //		Allow loops.
 //
@@ -30,18 +125,304 @@
 //		the same tail vertex and the same head vertex are
 //		allowed.
 //
+//	-out-degree dist
+//		Out-degree distribution. See below (default
+//		"binomial:trials,prob").
+//
+//	-in-degree dist
+//		In-degree distribution. See below (default
+//		"binomial:trials,prob").
+//
+//	-assortativity r
+//		Degree assortativity bias of stub matching, between -1
+//		and 1 (default 0). See below.
+//
+//	-locality decay
+//		Edge locality decay. The heads of the edges are drawn
+//		with a probability that decreases with the distance to
+//		their tails. See below.
+//
+//	-triad-formation p
+//		Generate a Holme-Kim graph whose edges are created by
+//		triad formation with probability p. See below.
+//
+//	-plugin file
+//		Generate the edges with the model exported by a Go
+//		plugin. See below.
+//
+//	-profile name
+//		Generate a graph following a benchmark profile:
+//		graph500 or ldbc. See below.
+//
+//	-scale n
+//		Base 2 logarithm of the number of vertices of the
+//		graph500 profile (default 16).
+//
+//	-edgefactor n
+//		Ratio of edges to vertices of the graph500 profile
+//		(default 16).
+//
+//	-scale-factor x
+//		Scale factor of the ldbc profile (default 1).
+//
+//	-closure p
+//		Triangle closure probability. After adding an edge
+//		u -> v, an edge u -> w, where w is a random
+//		out-neighbor of v, is added with probability p
+//		(default 0).
+//
+//	-communities path
+//		Assign vertices to the communities listed in a
+//		communities file. See below.
+//
+//	-mixing p
+//		Probability of keeping an edge whose endpoints belong
+//		to different communities (default 1).
+//
+//	-community-sizes dist
+//		Generate an LFR benchmark graph whose community sizes
+//		are drawn from dist. See below.
+//
+//	-mu p
+//		Fraction of the edges of every vertex that point to
+//		other communities in the LFR benchmark (default 0.1).
+//
+//	-blocks file
+//		Generate a stochastic block model with the blocks and
+//		edge probabilities in file. See below.
+//
+//	-propensity dist
+//		Degree-correct the -blocks model with vertex
+//		propensities drawn from dist. See below.
+//
+//	-dedup mode
+//		Edge deduplication mode. If "bloom", duplicated edges
+//		are removed using a Bloom filter instead of an exact
+//		set. It requires -community-sizes, -profile=graph500
+//		or -plugin and cannot be combined with -multiedges.
+//		See below (default "none").
+//
+//	-dedup-fp p
+//		False positive rate of the Bloom filter used by
+//		-dedup=bloom (default 0.01).
+//
+//	-id-start n
+//		ID of the first vertex (default 0).
+//
+//	-id-stride n
+//		Difference between consecutive vertex IDs (default 1).
+//
+//	-id-width n
+//		Minimum width of vertex IDs. Shorter IDs are padded
+//		with zeros (default 0).
+//
 //	-words path
 //		Choose vertex labels from a words file.
 //
+//	-transliterate
+//		Transliterate the accented letters of words files
+//		instead of removing them. See below.
+//
+//	-words-encoding encoding
+//		Character encoding of words files: "utf-8" or
+//		"latin1" (default "utf-8").
+//
+//	-label-case case
+//		Case of word labels: "keep", "lower", "upper" or
+//		"title" (default "keep").
+//
+//	-labels mode
+//		Vertex label mode: "id" or "hash". See below
+//		(default "id").
+//
+//	-label-pools list
+//		Build dotted labels from the words files in a
+//		comma-separated list, one per level. See below.
+//
+//	-label-depth n
+//		Number of levels of the dotted labels built by
+//		-label-pools (default: one per pool).
+//
+//	-on-label-collision strategy
+//		Strategy when there are not enough words for every
+//		vertex: "suffix", "error" or "reuse". See below
+//		(default "suffix").
+//
+//	-emit records
+//		Records to emit: "vertices", "edges" or "both"
+//		(default "both").
+//
+//	-interleave
+//		Write every edge as soon as both of its endpoints have
+//		been written.
+//
+//	-rate r
+//		Maximum number of edges written per second. If 0, the
+//		rate is not limited (default 0).
+//
+//	-burst n
+//		Maximum number of edges written at once when the rate
+//		is limited (default 1).
+//
+//	-drop-on-overflow
+//		Drop edges instead of waiting while the output buffer
+//		is full, for best-effort live streams. By default,
+//		generation waits for slow consumers, so memory use
+//		stays bounded. With -drop-on-overflow, the output is
+//		written from a buffer of -overflow-buffer bytes, and
+//		the edges generated while it is full are not written.
+//		Vertices are never dropped, and the output is not
+//		corrupted. The number of dropped edges is reported as
+//		a warning. It cannot be combined with -snapshots,
+//		-checkpoint or -bench.
+//
+//	-overflow-buffer size
+//		Size of the output buffer of -drop-on-overflow, with
+//		an optional K, M, G or T suffix, between 4K and 1G
+//		(default 1M).
+//
+//	-z
+//		Terminate records with NUL instead of newline. Only
+//		valid for the simple format.
+//
+//	-crlf
+//		Terminate lines with CRLF instead of LF, like Windows
+//		text files. Not valid with -z or -edge-tuples.
+//
 //	-dot
 //		Emit DOT output.
 //
+//	-dot-strict
+//		Declare the DOT graph strict, so that Graphviz merges
+//		multiple edges. Requires -dot.
+//
+//	-dot-name name
+//		Identifier of the DOT graph. The graph is anonymous by
+//		default. Requires -dot.
+//
+//	-dot-tooltip template
+//		Template of the tooltip attribute of every DOT node.
+//		Requires -dot. See below.
+//
+//	-dot-url template
+//		Template of the URL attribute of every DOT node.
+//		Requires -dot. See below.
+//
+//	-edge-tuples
+//		Write edges as Graph500 binary edge tuples. See below.
+//
 //	-o output
 //		Output file. The default is the standard output.
 //
+//	-snapshots k
+//		Write k snapshots of a growing graph instead of a
+//		single graph. See below.
+//
+//	-snapshot-dir dir
+//		Directory where snapshots are written (default ".").
+//
+//	-deltas
+//		Write a delta file along with every snapshot. See
+//		below.
+//
+//	-append
+//		Append to the output file instead of truncating it.
+//
+//	-atomic
+//		Write to a temporary file that replaces the output
+//		file only if generation succeeds.
+//
+//	-tee format:file
+//		Also write the graph to the provided file in the
+//		provided format: simple, dot or edge-tuples. It can be
+//		repeated, so the graph is generated once and written
+//		in several formats at the same time. The additional
+//		files honor -append and -atomic, and are included in
+//		-checksum and -manifest. It cannot be combined with
+//		-snapshots, -checkpoint, -bench or -churn.
+//
+//	-checkpoint path
+//		Periodically write a checkpoint file that allows to
+//		resume the generation. See below.
+//
+//	-checkpoint-interval d
+//		Time between checkpoints (default 1m).
+//
+//	-resume
+//		Resume the generation from the -checkpoint file.
+//
+//	-checksum algorithm
+//		Write the checksum of every output file to a sidecar
+//		file. The only supported algorithm is sha256. See below.
+//
+//	-manifest file
+//		Write a JSON manifest of the output files to the file.
+//		See below.
+//
+//	-meta
+//		Record the generation parameters in the output
+//		(default true).
+//
+//	-golden
+//		Write byte-stable output for snapshot tests. See
+//		below.
+//
+//	-log format
+//		Format of the diagnostics written to the standard
+//		error: "text" or "json" (default "text").
+//
+//	-ipc
+//		Serve generation requests on the standard input and
+//		output. See below.
+//
+//	-bench
+//		Instead of writing the graph, generate it once per output
+//		format and report the throughput. See below.
+//
+//	-bench-json
+//		Report the benchmark results as JSON.
+//
+//	-max-mem size
+//		Fail if the estimated memory used by the generation
+//		exceeds size. See below.
+//
+//	-cpuprofile file
+//		Write a CPU profile to the file.
+//
+//	-memprofile file
+//		Write a memory profile to the file after generation.
+//
+//	-version
+//		Print the module version, VCS revision and Go version
+//		of mkdigraph and exit.
+//
+// Flags that would have no effect because of the rest of the flags are
+// rejected, like -mixing without -communities, -scale without
+// -profile=graph500 or -n with -profile, so misconfigured generations
+// fail instead of silently ignoring them.
+//
+// Flag values are validated before generating anything: -n must be at
+// least 1, -trials at least 0 and -prob between 0 and 1. Invalid flag
+// values and combinations are usage errors, see Exit status below.
+// Parameters guaranteed to produce degenerate graphs, like
+// -trials=0, -prob=0 or a single vertex without -loops, are reported
+// with a warning, or rejected with -strict.
+//
+// The -verify flag checks the graph while it is written against the
+// constraints of the generation: every vertex is written once, there
+// are no loops or multiple edges unless allowed, edges only join the
+// types allowed by -type-edges and, with stub matching, degrees do not
+// exceed the maximum of bounded degree distributions. If the whole
+// graph is written, the endpoints of every edge must be written too.
+// Violations are reported as errors and mkdigraph exits with status 1
+// before writing checksums or manifests. With -atomic, the graph is
+// verified before replacing the output file, which is left untouched
+// if there are violations. -verify keeps the whole graph in memory.
+//
 // Unless the -dot flag is specified, it prints the graph in the
 // format:
 //
+//	# mkdigraph -flag=value ...
 //	V: id label
 //	...
 //	E: tail head
@@ -51,138 +432,1456 @@
 // "V:" define vertices, followed by the ID and the label of the
 // vertex. Lines starting with "E:" define edges, followed by the IDs
 // of the tail and head vertices. All fields are separated by a single
-// space.
+// space. Each record is terminated by a newline or, if the -z flag is
+// specified, by a NUL character. Records may be followed by
+// attributes of the form key=value, escaped like labels. The type
+// attribute is written for typed vertices and edges, the community
+// attribute for vertices assigned to a community, the time attribute
+// for vertices and edges with a creation time, and the lat and lon
+// attributes for vertices with coordinates:
+//
+//	V: id label type=name community=name time=t lat=y lon=x
+//	E: tail head type=name time=t
+//
+// Unless -meta=false is specified, the first line is a comment
+// holding the mkdigraph command line that reproduces the graph, like
+// "# mkdigraph -n=100 -prob=0.3 -seed=42". It records the seed, even
+// if it was drawn randomly, and the flags that are set and affect the
+// graph. Defaults are not recorded, nor the flags that only change
+// how the graph is written, like -crlf, -z, -dot, -strict or
+// -overflow-buffer, nor the ones that have no effect with the rest of
+// the flags. In the simple format, comment lines start with "#" and
+// must be skipped by readers. In the DOT output, the parameters are
+// recorded in a C-style comment.
+//
+// With -golden, the output is meant to be committed as the reference
+// output of snapshot tests. The seed defaults to 1 instead of being
+// random, vertices are written in ID order and edges are sorted by
+// tail, head, type and time, so the output does not depend on -workers.
+// Manifests omit the version, which holds the time of the VCS
+// revision. The graph is kept in memory, and -golden cannot be
+// combined with -infinite, -snapshots, -interleave or -checkpoint.
+//
+// With -dot-tooltip and -dot-url, every DOT node gets a tooltip and a
+// URL attribute, which make the SVG images rendered by Graphviz
+// interactive. The templates are made of literal text and the fields
+// {id}, {label}, {type}, {community} and {time} of the vertex. The
+// fields are percent-encoded in URLs. For instance:
+//
+//	mkdigraph -n 10 -dot -dot-tooltip '{label} ({type})' -dot-url 'https://example.com/v/{id}'
+//
+// Labels are escaped, so they never contain field or record
+// separators. The escape sequences are "\\" for backslash, "\s" for
+// space, "\t" for tab, "\n" for newline, "\r" for carriage return and
+// "\0" for NUL.
+//
+// The -emit flag selects which records are written. Vertex IDs only
+// depend on -n, -id-start and -id-stride, so edges-only outputs can
+// be loaded against the vertices emitted by another run with the
+// same flags.
+//
+// By default, all the vertices are written before the first edge.
+// With -interleave, vertices are written in ID order and every edge
+// is written right after both of its endpoints, which lets streaming
+// consumers build the graph incrementally.
+//
+// With -rate, edges are written at a controlled pace, simulating a
+// live feed. The rate is enforced with a token bucket that holds up
+// to -burst edges, so short bursts can exceed the rate. The output is
+// flushed before waiting, so consumers receive every edge as soon as
+// it is written. Vertices are not rate limited.
+//
+// With -atomic, the output is written to a temporary file in the
+// same directory as the output file, which is renamed to the output
+// file once the graph has been written successfully and synced to
+// disk. Thus, the output file either holds a complete graph or is
+// left untouched, even after a crash. The temporary file is removed
+// if the generation fails.
+//
+// If mkdigraph receives SIGINT or SIGTERM, generation stops and the
+// output written so far is completed, so it remains a valid graph.
+// For instance, the closing brace of the DOT output is written. With
+// -atomic, the output file is left untouched instead. Then mkdigraph
+// exits with status 128 plus the signal number. A second signal
+// terminates the process immediately.
+//
+// With -log=json, warnings, statistics and errors are written to the
+// standard error as JSON records, one per line. When generation
+// finishes, a final record with message "summary" reports the number
+// of vertices and edges written and the elapsed time in seconds.
+//
+// The same parameters and seed generate the same graph. If -seed is 0,
+// a random seed is drawn before generating the graph and recorded in
+// the output metadata, so the graph can be generated again. The
+// binomial model uses a random number generator per vertex that only
+// depends on the seed and the ID of the vertex. Other models draw from
+// a single random number generator seeded with the seed.
+//
+// With -vertex-time, every vertex and edge is given a creation time,
+// which is written as a "time" attribute holding a Unix time in
+// seconds. start is an RFC 3339 time and interval a duration of at
+// least one second. Vertices are created in ID order, every one at a
+// random time within its own interval, the first one starting at
+// start. Every edge is created at a random time within the interval
+// that follows the creation of its last endpoint, so vertices always
+// exist before their edges. The times only depend on the seed and the
+// IDs, so they are preserved by -vertex-range and -workers. Note that
+// edges are not written in creation order. -vertex-time cannot be
+// combined with -infinite. For instance:
+//
+//	mkdigraph -n 1000 -vertex-time 2024-01-01T00:00:00Z,1m
+//
+// With -coords, every vertex is given random coordinates within the
+// provided bounding box, in degrees, which are written as the lat and
+// lon attributes with six decimal places. Coordinates are uniformly
+// distributed over the area of the bounding box. Like the creation
+// times, they only depend on the seed and the IDs. For instance:
+//
+//	mkdigraph -n 1000 -coords 40.31,-3.89,40.56,-3.52
+//
+// With -vertex-types, every vertex is given a random type, which is
+// written as a "type" attribute. The probability of every type is its
+// weight divided by the sum of all the weights. Like the creation
+// times, the types only depend on the seed and the IDs. -type-edges
+// restricts the edges to the listed pairs of types, discarding the
+// rest, and -type-words labels the vertices of a type with the words
+// of its own words file, like -words does. The vertices of the types
+// without a words file keep their labels. For instance, the following
+// command generates a bipartite graph of users and products:
+//
+//	mkdigraph -n 1000 -vertex-types user:0.8,product:0.2 \
+//		-type-edges 'user>product' -type-words user=users.txt,product=products.txt
+//
+// With -edge-types, every edge is given a random relationship type,
+// chosen with the probabilities of the weights like -vertex-types
+// does. It is written as a "type" attribute in the simple and patch
+// formats and as the label of the edge in the DOT format, where edges
+// are also colored by type. The type of an edge only depends on the
+// seed and the IDs of its endpoints, so parallel edges have the same
+// type. For instance:
+//
+//	mkdigraph -n 1000 -edge-types FOLLOWS:0.7,BLOCKS:0.3
+//
+// With -vertex-range, only a slice of the graph is generated: the
+// vertices whose generation IDs, the IDs before applying -id-start and
+// -id-stride, are in the provided range, and the edges whose tails are
+// those vertices. It allows to generate a graph in parallel on several
+// machines. Concatenating the outputs of consecutive ranges covering
+// all the vertices yields the output of a single run, because the
+// metadata and the DOT header are only written by the first range
+// and the DOT footer by the last one. -vertex-range requires -seed and
+// -emit=vertices or -emit=edges, only supports the binomial model, and
+// cannot be combined with -locality, -closure, -communities or -dedup.
+// For instance:
+//
+//	mkdigraph -n 1000000 -seed 42 -emit edges -vertex-range 0:500000 >a.txt
+//	mkdigraph -n 1000000 -seed 42 -emit edges -vertex-range 500000: >b.txt
+//
+// The -rng flag selects the pseudo-random number generator. PCG is
+// the fastest. ChaCha8 is a cryptographically strong generator with
+// better statistical quality at a small cost. MT19937 is the 32-bit
+// Mersenne Twister used by many other languages and libraries. With a
+// -seed lower than 2^32, the numbers drawn by the models that use a
+// single generator are those of the reference implementation, as
+// used by NumPy's RandomState, taken in pairs as 64-bit numbers.
+// MT19937 is the slowest, especially for the binomial model, which
+// seeds a generator per vertex. The algorithm is recorded in the
+// output metadata if -rng is set.
+//
+// With -workers, the edges of consecutive batches of vertices are
+// generated concurrently. Since the edges of every vertex only depend
+// on the seed and its ID, the graph does not depend on the number of
+// workers.
+//
+// With -checkpoint, a checkpoint file is written every
+// -checkpoint-interval while edges are generated. It records the
+// position of the generation and the size of the output. If the
+// generation is interrupted, even abruptly, running mkdigraph again
+// with the same flags plus -resume truncates the output file to the
+// recorded size and generates the rest of the graph, producing an
+// output identical to an uninterrupted run. The checkpoint file is
+// removed when generation finishes. -checkpoint requires -seed and -o,
+// only supports the binomial model, with or without -trials-file, and
+// cannot be combined with -append, -atomic, -interleave, -locality,
+// -closure, -communities or -dedup. For instance:
+//
+//	mkdigraph -n 100000000 -seed 42 -o graph.txt -checkpoint graph.ckpt
+//	mkdigraph -n 100000000 -seed 42 -o graph.txt -checkpoint graph.ckpt -resume
+//
+// With -manifest, a JSON file is written after generation listing
+// the output files: the -o file or the snapshot and delta files. It
+// records the mkdigraph version, the generation parameters as in the
+// output metadata, the -vertex-range if any, and whether the
+// generation completed or was interrupted. Every file is listed with
+// its name relative to the directory of the manifest, its format
+// (simple, dot or patch), the number of vertices and edges written,
+// and its size and SHA-256 checksum. -manifest requires -o or
+// -snapshots and cannot be combined with -append. For instance:
+//
+//	mkdigraph -n 1000 -snapshots 10 -snapshot-dir out -manifest out/manifest.json
+//
+// With -checksum=sha256, the SHA-256 checksum of every output file is
+// written along with it to a file with the same name plus the
+// ".sha256" extension, in the format of sha256sum, so the files can
+// be verified with "sha256sum -c". Like the checksums of the
+// manifest, they are computed as the data is written, without reading
+// the output files again. When a generation is resumed, only the part
+// of the output written before the interruption is read again.
+// -checksum requires -o or -snapshots and cannot be combined with
+// -append.
+//
+// With -bench, the graph is generated once per output format, simple
+// and DOT, and written to nowhere. A line is printed for every format
+// with the number of vertices, edges and bytes written, the elapsed
+// time, the throughput, and the number of allocations and allocated
+// bytes. With -bench-json, every line is a JSON object instead. The
+// graphs are generated independently, so they only match if -seed is
+// specified. For instance:
+//
+//	mkdigraph -n 1000000 -seed 1 -bench -bench-json
+//
+// With -ipc, mkdigraph serves generation requests with a binary
+// protocol on the standard input and output, so language bindings can
+// keep a single process instead of starting one per graph. Every frame
+// starts with its length as a 32-bit big-endian unsigned integer. A
+// request holds the generate flags of a graph separated by NUL
+// characters, which are appended to the rest of the flags of the
+// command line. The response is a sequence of frames whose first byte
+// is their type: "D" frames hold chunks of the output, written unless
+// the request has -o or -snapshots, and the last frame is either an
+// "S" frame holding a JSON object with the number of vertices and
+// edges, or an "E" frame holding the error like -errors=json does.
+// Requests are served in order until the standard input ends.
+// -version, -bench, profiles, -checksum, -manifest and -checkpoint are
+// not supported in requests. For instance, in Python:
+//
+//	req = b"\0".join([b"-n=10", b"-seed=1"])
+//	p.stdin.write(len(req).to_bytes(4, "big") + req)
 //
 // Note that even if -prob=1, if -loops=false the resulting digraph
 // will have a vertex with no outgoing edges.
 //
+// With -infinite, -n is ignored and the graph grows one vertex at a
+// time until mkdigraph is interrupted, the client of serve
+// disconnects or vertex IDs are exhausted. Every new vertex performs
+// -trials trials with success probability -prob, and every successful
+// trial creates an edge in a random direction between the new vertex
+// and a random vertex already in the graph. Vertices and edges are
+// always interleaved. It cannot be combined with -out-degree,
+// -in-degree, -community-sizes, -trials-file, -closure or -dedup.
+//
+// With -churn, the output of -infinite is a temporal stream of
+// additions and removals. Every added edge is followed with
+// probability p by the removal of a random edge of the graph, which
+// may be any edge added before, so only existing edges are removed.
+// The stream is written in the patch format, see below, and every
+// record holds the time of the event as an attribute. The time is the
+// ID, before applying -id-start and -id-stride, of the vertex being
+// added when the event happens:
+//
+//	+V: 3 3 time=3
+//	+E: 3 1 time=3
+//	-E: 0 2 time=3
+//
+// The edges of the graph are kept in memory. It cannot be combined
+// with -dot, -locality or -communities.
+//
+// With -snapshots, the graph grows one vertex at a time like with
+// -infinite, up to -n vertices, and k snapshots are written to the
+// files snapshot-001.txt to snapshot-k.txt in -snapshot-dir, or with
+// the .dot extension if -dot is specified. The i-th snapshot holds the
+// first i*n/k vertices and the edges between them, so every snapshot
+// is a superset of the previous one and the last one holds the whole
+// graph. All the snapshots are written in a single pass. It cannot be
+// combined with -o or -append, nor with the flags not supported by
+// -infinite.
+//
+// With -deltas, the files delta-001.txt to delta-k.txt are written
+// along with the snapshots. Every delta file holds the changes since
+// the previous snapshot, or since the empty graph for the first one,
+// in the patch format. The patch format is the simple format where
+// every record is prefixed with "+" if the vertex or edge is added, or
+// with "-" if it is removed:
+//
+//	+V: id label
+//	+E: tail head
+//	-E: tail head
+//	-V: id label
+//
+// Applying the delta files in order to the empty graph yields every
+// snapshot. Delta files are always written in the patch format, even
+// if -dot is specified.
+//
+// The -trials-file file lists one vertex per line, with its ID and
+// its number of edge creation trials separated by whitespace. The
+// number of trials is either an integer or a degree distribution, see
+// below, from which it is drawn. IDs refer to the output IDs. Vertices
+// missing from the file perform -trials trials. Every successful trial
+// creates an edge to a uniformly random vertex, and loops and multiple
+// edges are discarded unless allowed. It cannot be combined with
+// -out-degree, -in-degree or -community-sizes.
+//
+// If -out-degree or -in-degree are specified, the graph is generated
+// by stub matching instead. Every vertex gets a number of out-stubs
+// and in-stubs drawn from the out-degree and in-degree distributions,
+// and edges are created by pairing random out-stubs with random
+// in-stubs. Stubs left over are discarded, as well as loops and
+// multiple edges unless allowed. This makes it possible to generate
+// graphs whose in-degree and out-degree distributions differ. The
+// stubs are kept in memory. The supported distributions are:
+//
+//	const:k                constant degree k
+//	uniform:min,max        uniform in [min, max]
+//	binomial:n,p           binomial with n trials and probability p
+//	poisson:mean           Poisson with the given mean
+//	powerlaw:gamma,min,max power law with exponent gamma in [min, max]
+//
+// -assortativity biases stub matching so that tails of high
+// out-degree connect to heads of high in-degree, if positive, or of
+// low in-degree, if negative. With 1 or -1 the stubs are paired by
+// degree rank, and with 0, the default, they are paired at random.
+// Values in between mix both. It keeps several copies of the stubs
+// in memory. For instance:
+//
+//	mkdigraph -n 10000 -out-degree powerlaw:2,1,100 \
+//		-in-degree powerlaw:2,1,100 -assortativity 0.8
+//
+// If -triad-formation is greater than 0, the graph is a directed
+// Holme-Kim graph, which grows one vertex at a time. Every new vertex
+// performs -trials trials that succeed with probability -prob, and
+// every successful trial adds an edge from the new vertex to an older
+// one. The first edge points to a vertex chosen with probability
+// proportional to its in-degree plus one, by preferential attachment.
+// Every following edge points, with probability p, to a random
+// out-neighbor of the head of the last preferential attachment edge,
+// closing a transitive triangle, and is created by preferential
+// attachment otherwise. Higher values of p yield higher clustering
+// coefficients, which are reported by the stats command. Multiple
+// edges are discarded unless allowed, and the adjacency lists of the
+// graph are kept in memory. For instance:
+//
+//	mkdigraph -n 10000 -trials 4 -prob 1 -triad-formation 0.8
+//
+// Models that do not live in mkdigraph can be loaded from Go plugins
+// with -plugin. The plugin must be built with -buildmode=plugin by the
+// same Go toolchain as mkdigraph, and export a function, or a variable
+// holding it, named Model:
+//
+//	func Model(n int64, r *rand.Rand) iter.Seq2[int64, int64]
+//
+// Model yields the tail and head IDs of the edges of a graph with n
+// vertices, whose IDs are in [0, n), and must only use r, a
+// math/rand/v2 generator seeded by -seed, as its source of randomness.
+// The vertices and their attributes are generated by mkdigraph. The
+// loops and multiple edges yielded by Model are kept, and -verify
+// reports them unless -loops and -multiedges are specified. Plugins
+// are only supported on some platforms, like Linux and macOS. For
+// instance:
+//
+//	go build -buildmode=plugin -o ring.so ./ring
+//	mkdigraph -n 100 -plugin ring.so
+//
+// With -profile=graph500, the graph is generated like the Kronecker
+// generator of the Graph500 benchmark, with 2^scale vertices and
+// edgefactor*2^scale edges, and -n is ignored. Every edge is placed by
+// recursively choosing a quadrant of the adjacency matrix with the
+// Graph500 initiator probabilities 0.57, 0.19, 0.19 and 0.05, and the
+// vertex IDs are randomly permuted. Like in the reference generator,
+// loops and multiple edges are kept. The permutation is computed
+// instead of stored, so the profile uses a small amount of memory
+// that does not depend on the size of the graph.
+//
+// With -edge-tuples, only the edges are written, in the binary edge
+// tuple format read by the Graph500 reference implementations: the
+// IDs of the tail and the head of every edge as little-endian 64-bit
+// integers, without metadata. It cannot be combined with -dot, -z or
+// -churn. For instance:
+//
+//	mkdigraph -profile graph500 -scale 20 -edge-tuples -o graph.bin
+//
+// With -profile=ldbc, the graph is a lightweight approximation of the
+// social network of the LDBC Social Network Benchmark, and -n is
+// ignored. It has 10000 persons per unit of scale factor, every one
+// with 10 posts, written as vertices of types Person and Post. Every
+// person knows a number of persons drawn from a power law, most of
+// them with nearby IDs, which gives the network its community
+// structure. The knows edges link persons, the hasCreator edges link
+// every post with its creator, and the likes edges link the
+// acquaintances of every person with some of their posts. Every
+// vertex and edge has a creation time, and edges are created after
+// their endpoints. Only the acquaintances of one person are kept in
+// memory. It cannot be combined with -vertex-types, -edge-types or
+// -vertex-time. For instance:
+//
+//	mkdigraph -profile ldbc -scale-factor 0.1 -seed 1 -o snb.txt
+//
+// If -community-sizes is specified, the graph is a directed LFR
+// (Lancichinetti-Fortunato-Radicchi) benchmark. Out-degrees and
+// in-degrees are drawn from -out-degree and -in-degree, and the sizes
+// of the communities from -community-sizes, until they hold all the
+// vertices. The supported distributions are the same as for degrees.
+// The usual LFR parameters use power laws, for instance:
+//
+//	mkdigraph -n 10000 -out-degree powerlaw:2,10,100 \
+//		-in-degree powerlaw:2,10,100 \
+//		-community-sizes powerlaw:1.5,20,200 -mu 0.3
+//
+// Every vertex is assigned to a random community large enough to hold
+// its internal edges, if any. A fraction 1-mu of the stubs of every
+// vertex are matched inside its community and the rest with stubs of
+// other communities. Loops, multiple edges and external pairs that
+// fall in the same community are discarded. The ground-truth
+// community of every vertex is emitted as its community attribute.
+// The degrees, communities and the set of edges are kept in memory.
+//
+// If -blocks is specified, the graph is a directed stochastic block
+// model. The -blocks file lists one block per line, with its name, its
+// number of vertices and the probabilities of an edge from one of its
+// vertices to a vertex of every block, in the order of the file,
+// separated by whitespace. So the probabilities form a k×k matrix,
+// where k is the number of blocks, which does not need to be
+// symmetric, and a 0 forbids the edges from a block to another one.
+// Empty lines and lines starting with "#" are ignored. For instance:
+//
+//	# name  size  user  post  tag
+//	user    1000  0.01  0.02  0
+//	post    5000  0     0     0.001
+//	tag     100   0     0     0
+//
+// The number of vertices is the sum of the block sizes, so -n cannot
+// be specified. The vertices of every block have consecutive IDs, in
+// the order of the file, and the name of their block is emitted as
+// their community attribute. Loops are discarded unless allowed, and
+// multiple edges cannot happen. Only the matrix is kept in memory. It
+// cannot be combined with the other models nor with -communities.
+//
+// With -propensity, the block model is degree-corrected, so the
+// community structure coexists with heavy-tailed degrees instead of
+// the narrow degrees of the plain model. Every vertex gets a
+// propensity drawn from the -propensity distribution, which supports
+// the same distributions as degrees, scaled so the mean propensity of
+// every block is 1. The probability of an edge u -> v is the
+// probability given for their blocks multiplied by the propensities of
+// u and v, capped at 1. So the expected number of edges between every
+// pair of blocks is preserved, unless probabilities are capped, and
+// the degrees of every block follow the shape of the distribution.
+// For instance:
+//
+//	mkdigraph -blocks blocks.txt -propensity powerlaw:2.5,1,100
+//
+// The propensities are kept in memory, and the heads of every vertex
+// are not sorted by ID.
+//
+// If -locality is specified, the head of every generated edge u -> v
+// is drawn again among the vertices at either side of u, with a
+// probability proportional to a weight that decays with the distance
+// |u-v| between the generation IDs of the endpoints, which produces a
+// banded adjacency matrix. The tails are kept, so the number of edges
+// and the out-degrees are the ones of the model. Loops are always
+// kept. Unless -multiedges is specified, a head that duplicates an
+// edge is drawn again, and if that keeps happening, which only occurs
+// when the out-degree is close to the number of vertices within reach
+// of the decay, the nearest vertex that is not a head yet is chosen.
+// It only supports the binomial model. The supported decays are:
+//
+//	geometric:r  weight r^(|u-v|-1), with 0 < r <= 1
+//	powerlaw:a   weight |u-v|^-a, with a >= 0
+//
+// The -communities file lists one vertex per line, with its ID and
+// the label of its community separated by whitespace. Empty lines and
+// lines starting with "#" are ignored. IDs refer to the output IDs,
+// after applying -id-start and -id-stride. Every edge whose endpoints
+// belong to different communities, or that has an endpoint missing
+// from the file, is kept with probability -mixing, so lower values
+// produce stronger community structure. The community of each vertex
+// is emitted as a vertex attribute, and in the DOT output as a
+// community attribute.
+//
+// If -closure is greater than 0, the generated graph is kept in
+// memory in order to find the neighbors of each vertex. Closing
+// triangles increases the clustering coefficient of the graph.
+//
+// Most models remove multiple edges exactly while only keeping the
+// heads of the current vertex in memory, so -dedup=bloom is rejected
+// with them. With -community-sizes, which keeps the set of all the
+// edges, and with -profile=graph500 and -plugin, which keep multiple
+// edges, -dedup=bloom removes duplicated edges with a Bloom filter.
+// Its memory is fixed and depends only on the expected number of
+// edges and the false positive rate. The price is that some unique
+// edges may be suppressed. The number of suppressed edges is reported
+// on the standard error when generation finishes.
+//
+// The binomial model, the growing graph without -churn, and the -dot,
+// -locality, -snapshots, -deltas and -rate flags only keep a small
+// amount of memory that does not depend on the size of the graph.
+// The rest of the models and stages keep memory proportional to the
+// number of vertices or edges, as described above, as well as the
+// words, trials and communities files. With -max-mem, the memory used
+// by all of them is estimated before generating the graph, and the
+// generation fails with a breakdown of the estimate if it exceeds the
+// provided size. The size is a number of bytes, optionally followed
+// by K, M, G or T. The estimate is approximate, and it is unbounded
+// with -churn and with -verify and -infinite. Prefer -dedup=bloom to
+// the exact set of edges of -community-sizes when memory is scarce.
+// The size is also set as the soft memory limit of the Go runtime.
+// For instance:
+//
+//	mkdigraph -profile graph500 -scale 24 -dedup bloom -max-mem 512M
+//
+// Vertex IDs are assigned in generation order starting at -id-start
+// and separated by -id-stride. Default labels and label suffixes use
+// the assigned IDs. If -id-width is specified, IDs are zero-padded,
+// so lexicographic order matches numeric order up to that width.
+//
 // If the -words flag is specified, vertex labels are selected from
-// the provided words file. Each label is sanitized by removing any
-// characters that match the regular expression '[^a-zA-Z]'. If the
-// number of vertices exceeds the number of available labels, then
-// duplicated labels are suffixed with the vertex number.
+// the provided words file. Words files are UTF-8 encoded, and a
+// leading byte order mark is skipped, unless -words-encoding=latin1
+// is specified. Each label is sanitized by removing any characters
+// that match the regular expression '[^a-zA-Z]', so carriage returns
+// and non-ASCII characters are discarded. With -transliterate, the
+// accented and ligature letters of the Latin-1 Supplement and Latin
+// Extended-A Unicode blocks are replaced with ASCII letters before,
+// so "Müller" becomes "Muller" instead of "Mller" and "Straße"
+// becomes "Strasse", in both encodings. The -label-case flag changes
+// the case of the sanitized words, which are deduplicated again.
+// Lines can be up to 16 MiB long. If the number of vertices exceeds
+// the number of available labels, then duplicated labels are suffixed
+// with the vertex number. This can be changed with
+// -on-label-collision: "error" makes the generation fail before
+// writing anything if any label would be duplicated, and "reuse"
+// repeats the words without suffix. The words are shuffled with the
+// seed, so the labels of a generation only depend on the words in the
+// file and on -seed, not on their order.
+//
+// With -labels=hash, every vertex is labeled with a hash of the seed
+// and its ID, written as 13 characters in base 32. Hash labels are
+// opaque, but unique and reproducible with -seed. They cannot be
+// combined with -words nor -label-pools.
+//
+// The -label-pools flag builds hierarchical labels instead, like
+// "region.zone.host", taking the word of every level from its own
+// words file. With -label-depth, the pools are cycled, so a single
+// pool can provide every level. Vertex IDs are written in mixed radix
+// with the sizes of the pools as bases, the last level being the
+// least significant one, so consecutive vertices share the upper
+// levels. Labels are duplicated when the IDs exceed the number of
+// distinct dotted labels, which is handled like with -words. For
+// instance, the following command generates hosts grouped in zones
+// grouped in regions:
+//
+//	mkdigraph -n 1000 -label-pools regions.txt,zones.txt,hosts.txt
+//
+// # Convert
+//
+// Usage:
+//
+//	mkdigraph convert [-dot] [-z] [-xlsx] [-id-width n] [-o output] [file]
+//
+// Convert reads a graph in the simple format, in DOT or in GraphML
+// from file, or from the standard input if no file is specified, and
+// writes it in the selected format. The input format is detected from
+// its first keyword or tag. Records can be terminated by newline or
+// NUL, the terminator is detected from the input. Comments are
+// discarded.
+//
+// The supported subset of DOT covers the graphs written by mkdigraph
+// and most hand-written fixtures: a single, optionally strict,
+// digraph made of node statements and edge statements, including
+// chains like "0 -> 1 -> 2". Node IDs must be vertex IDs. The label,
+// type, community, time, lat and lon node attributes are read, as
+// well as the label and time edge attributes, the label of an edge
+// being its type. Nodes without a label are labeled with their IDs.
+// Other attributes and graph, node and edge default statements are
+// ignored. Undirected graphs, subgraphs and ports are not supported.
+// Like in the simple format, nodes are only declared by node
+// statements.
+//
+// GraphML documents, like the ones exported by Gephi or yEd, are read
+// from their first graph, which must be directed. If the first node
+// ID is a vertex ID, all of them must be vertex IDs. Otherwise, nodes
+// are numbered in order of appearance and labeled with their IDs. The
+// node attributes named label, type, community, time, lat and lon are
+// read, as well as the edge attributes named label or type, and time.
+// Nested graphs, hyperedges and ports are not supported.
+//
+// Like in generate, -dot-strict and -dot-name set the strictness and
+// the identifier of the DOT graph, and -dot-tooltip and -dot-url the
+// templates of the tooltip and URL node attributes. They are accepted
+// by every command that writes a graph with -dot. Likewise, -crlf
+// terminates the lines of the output with CRLF.
+//
+// With -xlsx, convert writes an Excel workbook with a "vertices" and an
+// "edges" worksheet, each of them with a header row. The type,
+// community, time and coordinates of the vertices, and the type and
+// time of the edges, only get a column if at least one record has
+// them. IDs that Excel cannot store exactly are written as text.
+// Graphs with more vertices or edges than fit in a worksheet
+// (1048575) are rejected, and the whole graph is kept in memory.
+//
+// # Stats
+//
+// Usage:
+//
+//	mkdigraph stats [file]
+//
+// Stats reads a graph in any of the formats supported by convert and
+// prints the number of vertices, edges, loops and multiple edges, the
+// number of vertices whose label is already used by a previous vertex,
+// the number of sources and sinks, the maximum degrees, the mean
+// out-degree, the density and the average clustering coefficient of
+// the graph. The clustering coefficient of a vertex is the fraction
+// of pairs of its neighbors that are adjacent, ignoring the direction
+// of the edges, loops and multiple edges. The degrees and labels of
+// every vertex and the set of edges are kept in memory.
+//
+// # Validate
+//
+// Usage:
+//
+//	mkdigraph validate [-simple] [file]
+//
+// Validate reads a graph in any of the formats supported by convert
+// and reports malformed records, vertices declared more than once
+// and edges with undeclared endpoints. With -simple, loops and
+// multiple edges are reported too. If any problem is found, validate
+// exits with status 4.
+//
+// # Sample
+//
+// Usage:
+//
+//	mkdigraph sample [-p p] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Sample reads a graph in any of the formats supported by convert and
+// writes the subgraph induced by a random sample of its vertices.
+// Every vertex is kept with probability p (default 0.1). Edges are
+// kept if both of their endpoints are kept, even if they precede them
+// in the input.
+//
+// # Induce
+//
+// Usage:
+//
+//	mkdigraph induce -vertices ids [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Induce reads a graph in any of the formats supported by convert and
+// writes the subgraph induced by the vertices listed in the ids file.
+// The file holds vertex IDs separated by whitespace, and empty lines
+// and lines starting with "#" are ignored. Like with sample, edges are
+// kept if both of their endpoints are listed, and the graph is
+// filtered as it is read, so only the listed IDs are kept in memory.
+//
+// # Ego
+//
+// Usage:
+//
+//	mkdigraph ego -root id [-hops k] [-direction dir] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Ego reads a graph in any of the formats supported by convert and
+// writes the subgraph induced by the vertices at most -hops edges
+// (default 1) away from the vertex -root, including the root. With
+// -direction out (default), edges are followed from tail to head, with
+// -direction in, from head to tail, and with -direction both, in both
+// directions. The root must be in the graph, as a declared vertex or
+// an edge endpoint. The whole graph is kept in memory.
+//
+// # Relabel
+//
+// Usage:
+//
+//	mkdigraph relabel [-shuffle] [-seed n] [-map file] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Relabel reads a graph in any of the formats supported by convert and
+// writes it with its vertices renumbered from 0 to n-1 in order of
+// first appearance, including the undeclared edge endpoints. Labels
+// are kept. The graph is renumbered as it is read, keeping the new ID
+// of every vertex in memory.
+//
+// With -shuffle, the new IDs are randomly permuted using -seed as the
+// seed (default 0, which means random). The whole graph is kept in
+// memory and written with the vertices first.
+//
+// With -map, the new IDs are read from a mapping file instead. Every
+// line of the file contains the old and the new ID of a vertex
+// separated by whitespace, and empty lines and lines starting with
+// "#" are ignored. Old and new IDs cannot be repeated, and relabel
+// fails if a vertex is not in the file.
+//
+// # Filter
+//
+// Usage:
+//
+//	mkdigraph filter -expr expression [-prune] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Filter reads a graph in any of the formats supported by convert and
+// writes it without the edges that do not match the expression. The
+// expression compares the attributes of an edge, combined with the &&
+// (and), || (or) and ! (not) operators and parentheses. The comparison
+// operators are ==, !=, <, <=, > and >=. The attributes are tail, head
+// and time, which are integers, and type, which is a string. Both
+// sides of a comparison must be of the same kind, and strings are
+// double-quoted. For instance, to keep the "knows" edges that are not
+// loops:
+//
+//	mkdigraph filter -expr 'type == "knows" && tail != head' graph.txt
+//
+// The graph is filtered as it is read. With -prune, the vertices left
+// without edges are removed too, which requires keeping the graph in
+// memory.
+//
+// # Head
+//
+// Usage:
+//
+//	mkdigraph head [-edges k] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Head reads a graph in any of the formats supported by convert and
+// writes its first -edges edges (default 1000) and the vertices they
+// reference, which is a valid graph, unlike a prefix of the input.
+// Vertices are written first. The whole input is read, because
+// vertices can be declared after their edges, but only the kept edges
+// and the vertices read before the last of them are kept in memory.
+//
+// # Complement
+//
+// Usage:
+//
+//	mkdigraph complement [-loops] [-max-vertices n] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Complement reads a graph in any of the formats supported by convert
+// and writes its directed complement, which has the same vertices and
+// an edge between every ordered pair of distinct vertices that are not
+// adjacent in that direction. With -loops, the missing loops are
+// added too. The vertices include the undeclared edge endpoints, and
+// the edges are sorted by tail and head. Since the complement of a
+// sparse graph with n vertices has about n^2 edges, graphs with more
+// than -max-vertices vertices (default 10000) are rejected. The whole
+// graph is kept in memory.
+//
+// # Project
+//
+// Usage:
+//
+//	mkdigraph project [-type type] [-weighted] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Project reads a bipartite graph in any of the formats supported by
+// convert and writes its projection onto one of its parts: the
+// vertices of the part, with an edge between every pair of them that
+// share a neighbor in the other part, regardless of the direction of
+// the edges. The part is made of the edge tails, like the users of a
+// graph of users rating items, or, with -type, of the vertices of that
+// type. Every pair of vertices is joined by a single edge from the
+// lower to the higher ID or, with -weighted, by one edge per shared
+// neighbor, so the multiplicity of the edges is their weight. Project
+// fails if an edge joins two vertices of the same part. The whole
+// graph is kept in memory.
+//
+// # Canon
+//
+// Usage:
+//
+//	mkdigraph canon [-exact] [-max-vertices n] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Canon reads a graph in any of the formats supported by convert and
+// writes it in a canonical order, so that equal graphs serialize
+// identically regardless of their vertex IDs and of the order of
+// their records. The vertices are renumbered from 0 to n-1 and written
+// in ID order, followed by the edges sorted by tail and head.
+//
+// By default, vertices are numbered in order of increasing out-degree,
+// then in-degree and then label, keeping their labels and attributes.
+// This is fast, but only canonical if the labels of the vertices with
+// the same degrees are unique.
+//
+// With -exact, vertices are numbered by a canonical labeling of the
+// structure of the graph, computed by individualization and
+// refinement, like nauty does, so isomorphic graphs are written
+// identically. Labels and attributes are ignored: every vertex,
+// including the undeclared edge endpoints, is declared with its new ID
+// as label. The search is exponential in the worst case, so graphs
+// with more than -max-vertices vertices (default 500) are rejected.
+// The whole graph is kept in memory.
+//
+// # Toposort
+//
+// Usage:
+//
+//	mkdigraph toposort [file]
+//
+// Toposort reads a graph in any of the formats supported by convert
+// and prints a topological order of its vertices, one vertex ID per
+// line. Undeclared edge endpoints are included. If the graph has a
+// cycle, including a loop, toposort reports the first cycle found
+// instead, like "cycle: 1 -> 2 -> 3 -> 1", and exits with status 1.
+// The order only depends on the input, and the adjacency lists of the
+// graph are kept in memory.
+//
+// # Condense
+//
+// Usage:
+//
+//	mkdigraph condense [-members file] [-dot] [-z] [-id-width n] [-o output] [file]
+//
+// Condense reads a graph in any of the formats supported by convert
+// and writes its condensation, the acyclic graph with a vertex for
+// every strongly connected component and an edge between two
+// components if any edge of the graph joins them. Components are
+// numbered in topological order and labeled with their IDs, so every
+// edge goes from a lower to a higher component. With -members, the
+// component of every vertex is written to file, one "vertex
+// component" pair per line, sorted by vertex ID. Undeclared edge
+// endpoints are included, and the adjacency lists of the graph are
+// kept in memory.
+//
+// # Components
+//
+// Usage:
+//
+//	mkdigraph components [-dir dir] [-min-size n] [-stats-only] [-dot] [-z] [-id-width n] [file]
+//
+// Components reads a graph in any of the formats supported by convert
+// and writes every weakly connected component to its own file, named
+// like snapshots: component-001.txt to component-k.txt in -dir
+// (default "."), or with the .dot extension if -dot is specified. Components are numbered by decreasing number of
+// vertices, and components of the same size by their lowest vertex
+// ID. Components with less than -min-size vertices (default 1) are
+// dropped. With -stats-only, nothing is written and the number,
+// vertices and edges of every component are printed instead, one
+// component per line. Undeclared edge endpoints are counted as
+// vertices. The whole graph is kept in memory.
+//
+// # Reach
+//
+// Usage:
+//
+//	mkdigraph reach -from id -to id [-path] [file]
+//
+// Reach reads a graph in any of the formats supported by convert and
+// prints "reachable" if the vertex -to is reachable from the vertex
+// -from, or "unreachable" and exits with status 1 otherwise. Every
+// vertex is reachable from itself. With -path, a shortest path like
+// "0 -> 2 -> 4" is printed instead of "reachable". Both vertices must
+// be in the graph, as declared vertices or edge endpoints. The
+// adjacency lists of the graph are kept in memory.
+//
+// # Degdist
+//
+// Usage:
+//
+//	mkdigraph degdist [-plot format] [-o output] [file]
+//
+// Degdist reads a graph in any of the formats supported by convert and
+// writes its out-degree and in-degree histograms as CSV. Every row
+// holds a degree and the number of vertices with that out-degree and
+// in-degree, and only the degrees of at least one vertex are written:
+//
+//	degree,out,in
+//	0,2,2
+//	1,2,2
+//	3,1,1
+//
+// With -plot, a gnuplot script or a Vega-Lite specification with the
+// histograms embedded is written instead, which plots them on
+// logarithmic axes. The formats are gnuplot and vega-lite. The graph
+// is read in a single pass, keeping the degrees of every vertex in
+// memory.
+//
+// # Fit
+//
+// Usage:
+//
+//	mkdigraph fit [-model model] [file]
+//
+// Fit reads a graph in any of the formats supported by convert,
+// estimates the parameters of a model from it and prints a generate
+// command line that produces graphs with similar gross statistics:
+// the number of vertices, the mean degree and, depending on the model,
+// the shape of the degree distributions or the community structure.
+// -loops and -multiedges are added if the graph has loops or multiple
+// edges. The models are:
+//
+//	gnp  the binomial model with the same mean out-degree (default)
+//	ba   stub matching with the constant out-degree and the power law
+//	     in-degree of a Barabási-Albert graph, with the exponent
+//	     estimated by maximum likelihood
+//	sbm  an LFR benchmark with the range of community sizes and the
+//	     fraction of edges between communities of the graph, which
+//	     requires community attributes
+//
+// For instance:
+//
+//	$ mkdigraph fit -model ba graph.txt
+//	mkdigraph -n 10000 -out-degree const:2 -in-degree powerlaw:2.325,1,264
+//
+// The degrees of every vertex and the set of edges are kept in memory.
+//
+// # Render
+//
+// Usage:
+//
+//	mkdigraph render [-layout layout] [-T format] [flags] [file]
+//
+// Render reads a graph in any of the formats supported by convert and
+// draws it as an image. By default, the graph is laid out by the
+// Graphviz program named by -layout (dot, neato, fdp, sfdp, circo or
+// twopi), which must be installed, and the image is written in the
+// format given by -T (default "svg"). For instance:
+//
+//	mkdigraph -n 10 -prob 0.2 | mkdigraph render -layout dot -T png -o graph.png
+//
+// The circle and force layouts are embedded and only write SVG images.
+// The circle layout places the vertices on a circle in ID order. The
+// force layout is a deterministic force-directed layout, in which
+// adjacent vertices attract each other and all vertices repel each
+// other, that handles graphs of a few thousand vertices. It is used
+// instead of a Graphviz layout if Graphviz is not installed and the
+// format is SVG. Graphs with more than -max-vertices vertices (default
+// 5000) are rejected by the embedded layouts. Vertices are drawn with
+// their labels, falling back to their IDs.
+//
+// With -T ascii, the graph is drawn as text, regardless of -layout,
+// which is handy to inspect tiny graphs in a terminal. Vertices are
+// drawn as boxes stacked in ID order and edges as arrows through lanes
+// to the right of the boxes:
+//
+//	$ printf 'E: 0 1\nE: 1 2\nE: 0 2\n' | mkdigraph render -T ascii
+//	+---+
+//	| 0 |---+
+//	|   |-+ |
+//	+---+ | |
+//	+---+ | |
+//	| 1 |<+ |
+//	|   |-+ |
+//	+---+ | |
+//	+---+ | |
+//	| 2 |<+ |
+//	|   |<--+
+//	+---+
+//
+// Graphs with more than -max-vertices vertices (default 30) are
+// rejected.
+//
+// With -T html, a standalone HTML page is written, which embeds the
+// graph and a viewer that runs in the browser without loading any
+// resource. The view can be dragged and zoomed with the mouse,
+// hovering over a vertex shows its attributes and degrees, and
+// clicking on it highlights its neighbors. Vertices are colored by
+// community and edges by type. The graph is laid out by the force
+// layout, unless -layout circle is specified. The whole graph is kept
+// in memory.
+//
+// # Words
+//
+// Usage:
+//
+//	mkdigraph words [-transliterate] [-encoding encoding] [-o output] file
+//
+// Words reads a words file, sanitizes and deduplicates its words like
+// -words does, decoding them from -encoding ("utf-8" or "latin1") and
+// transliterating them first with -transliterate, and
+// writes them as a words cache: a header that cannot appear in a text
+// file followed by the words sorted and separated by newlines. Words
+// caches are accepted wherever a words file is, and are loaded without
+// parsing nor sanitizing their words again, which saves most of the
+// startup time of the generations that use large dictionaries. Hence,
+// the -transliterate and -words-encoding flags of the generations have
+// no effect on caches.
+// For instance:
+//
+//	mkdigraph words -o words.cache /usr/share/dict/words
+//	mkdigraph -n 100 -words words.cache
+//
+// # Corpus
+//
+// Usage:
+//
+//	mkdigraph corpus -out dir [-count k] [-max-n m] [-seed s]
+//
+// Corpus writes k graphs (default 100) with up to m vertices (default
+// 16) to the directory dir, meant to seed the corpora of fuzzers of
+// programs that read graphs. Every graph is generated with a random
+// model among binomial, closure, locality, Holme-Kim, stub matching,
+// LFR and Graph500, with random parameters, including degenerate ones
+// like graphs without edges, and random attributes. A quarter of the
+// graphs are written in DOT and the rest in the simple format, without
+// metadata.
+//
+// The graphs are named after their index and model, like
+// "000042-holmekim.txt". Every graph only depends on its index, -max-n
+// and -seed (default 1), so the same parameters write the same corpus
+// and increasing -count only adds graphs. If -seed is 0, the corpus is
+// random. For instance:
+//
+//	mkdigraph corpus -out testdata/fuzz/corpus -count 500 -max-n 32
+//
+// # Serve
+//
+// Usage:
+//
+//	mkdigraph serve [-addr address] [-pprof address] [-jobs n] [-jobs-dir dir]
+//		[-tenants file | -basic-auth file]
+//		[-tls-cert file -tls-key file [-tls-client-ca file]]
+//		[-shutdown-delay duration] [-shutdown-timeout duration]
+//
+// Serve starts an HTTP server listening on the provided address
+// (default "localhost:8080"). Every GET request to "/" streams a newly
+// generated graph. The generate flags are passed as query parameters.
+// For instance:
+//
+//	curl 'http://localhost:8080/?n=100&prob=0.3&dot=true'
+//
+// The flags that refer to files or only make sense in the command
+// line, such as -o or -words, and -verify, which keeps the whole
+// graph in memory, are rejected. Generation stops if the
+// client disconnects.
+//
+// The server also provides a JSON API, described by the OpenAPI
+// document served at "/openapi.json". POST requests to "/graphs" take
+// a JSON object with the generate flags as "parameters", like
+// {"parameters": {"n": 100, "prob": 0.3}}, and stream the graph. If
+// "async" is true, the graph is generated in the background instead,
+// and the response is a job with an ID. "/graphs/{id}" reports the
+// status of the job, "/graphs/{id}/output" returns the graph once it
+// is done, and DELETE requests cancel and discard it. Jobs are queued
+// and at most -jobs of them (default 2) run at the same time. Their
+// status includes the number of bytes written so far. The graphs of
+// asynchronous generations are written to files in -jobs-dir (default
+// a temporary directory), and the oldest finished job is discarded
+// when there are 64. Downloads support HTTP range requests, so they
+// can be resumed. Errors are reported as JSON objects, like
+// -errors=json does. For instance:
+//
+//	curl -d '{"parameters": {"n": 100, "seed": 1}}' http://localhost:8080/graphs
+//
+// If -tenants is specified, every request, including those for the
+// profiling data, must authenticate with the API key of a tenant as a
+// bearer token, and asynchronous generations are only visible to the
+// tenant that started them. The file is a JSON object like:
+//
+//	{"tenants": [{"name": "team-a", "key": "secret", "rate": 2, "burst": 10,
+//		"max_vertices": 1000000, "max_edges": 100000000,
+//		"max_jobs": 1, "max_queued": 8}]}
+//
+// "rate" is the maximum number of requests per second of the tenant,
+// in bursts of up to "burst" requests, and "max_vertices" and
+// "max_edges" limit the size of its graphs. "max_jobs" is the maximum
+// number of asynchronous generations of the tenant running at the same
+// time, and "max_queued" the maximum number of them queued or running,
+// so that a tenant cannot hold all the jobs of the server. Zero means
+// unlimited. Requests exceeding the rate or "max_queued" are rejected
+// with status 429, and those for unbounded graphs, or whose -n or
+// expected number of edges exceeds the quotas, with status 403.
+// Generations exceeding the quotas anyway, for instance because of
+// -closure or randomness, are stopped. Asynchronous generations then
+// fail, and streamed graphs end with an "error:" comment, except for
+// -edge-tuples, which has no comments, and the error in the
+// Mkdigraph-Error trailer.
+//
+// If -basic-auth is specified, every request, including those for the
+// profiling data, must authenticate with HTTP basic authentication as
+// one of the users in the provided file, which has a "user:password"
+// line per user.
+//
+// If -tls-cert and -tls-key are specified, the server, including the
+// profiling data, is served over HTTPS with the certificate and
+// private key in the provided PEM files. If -tls-client-ca is also
+// specified, clients must present a certificate signed by one of the
+// CAs in the provided PEM file (mutual TLS).
+//
+// The health checks "/healthz" and "/readyz" do not require
+// authentication. The former reports whether the server is alive, and
+// the latter whether it accepts new requests. On SIGINT or SIGTERM,
+// the server stops being ready and, after -shutdown-delay (default 0),
+// stops accepting requests. The in-flight ones, including graph
+// streams, are given up to -shutdown-timeout (default 30s) to finish
+// before being interrupted. Then, asynchronous generations are
+// canceled. In Kubernetes, -shutdown-delay should be long enough for
+// the pod to be removed from its endpoints.
+//
+// If -pprof is specified, the runtime profiling data is served on the
+// provided address under "/debug/pprof/", in the format expected by
+// the pprof tool. For instance:
+//
+//	go tool pprof 'http://localhost:6060/debug/pprof/profile?seconds=30'
+//
+// # Completion
+//
+// Usage:
+//
+//	mkdigraph completion bash|zsh|fish
+//
+// Completion writes a completion script for the provided shell to the
+// standard output. For instance:
+//
+//	source <(mkdigraph completion bash)
+//
+// # WebAssembly
+//
+// Built with GOOS=js GOARCH=wasm, mkdigraph exposes the generate
+// command to JavaScript instead of running the command line interface,
+// so graphs can be generated in the browser. The js/mkdigraph.js
+// module loads the WebAssembly binary and provides:
+//
+//	generate(params, onVertex, onEdge)
+//
+// params maps generate flag names to their values, and onVertex and
+// onEdge are called with every vertex and edge as objects, in the
+// order they would be written. Returning false from them stops the
+// generation. Flags that need files or timers, like -o, -snapshots or
+// -rate, and -verify are not supported. For instance:
+//
+//	GOOS=js GOARCH=wasm go build -o mkdigraph.wasm
+//
+// and, in JavaScript:
+//
+//	const mkdigraph = await load("mkdigraph.wasm");
+//	mkdigraph.generate({n: 10, seed: 1}, (v) => console.log(v), (e) => console.log(e));
+//
+// # C library
+//
+// Built with the cshared tag and -buildmode=c-shared, mkdigraph is a C
+// shared library that other languages can load through their foreign
+// function interfaces, like ctypes in Python, to generate many graphs
+// without starting a process for each of them:
+//
+//	go build -tags cshared -buildmode=c-shared -o libmkdigraph.so
+//
+// The generated libmkdigraph.h header declares:
+//
+//	int mkdigraph_generate(int argc, char **argv,
+//		mkdigraph_vertex_func vfn, mkdigraph_edge_func efn, void *data,
+//		char *errbuf, size_t errlen);
+//	int mkdigraph_write(int argc, char **argv, char *errbuf, size_t errlen);
+//
+// Both take the generate flags in argv. mkdigraph_generate calls vfn
+// with a mkdigraph_vertex and efn with a mkdigraph_edge for every
+// vertex and edge, in the order they would be written, and stops if
+// they return nonzero. The strings of the vertices and edges are only
+// valid during the call. It rejects -o, -tee, -snapshots, -checkpoint,
+// -manifest, -checksum and -verify, which need mkdigraph_write.
+// mkdigraph_write writes the graph like the command line does. They
+// return 0 on success and, on failure, the exit status of the command
+// line, see Exit status below, and write the error message to errbuf.
+//
+// # Tracing
+//
+// If the OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variable is set, the
+// generate command records OpenTelemetry spans of its phases and
+// exports them when it finishes, using the OTLP/HTTP protocol with
+// JSON encoding. The root span, "generate", has the model, the number
+// of vertices and the seed as attributes, and the number of vertices
+// and edges written once it finishes. Its children are "setup",
+// "write" and, if enabled, "verify", "checksum" and "manifest". While
+// writing, the graph is generated, formatted and written to the output
+// at the same time, so the time spent in each of these stages is
+// reported as the attributes mkdigraph.model.seconds,
+// mkdigraph.format.seconds and mkdigraph.output.seconds of the "write"
+// span instead of as spans. OTEL_EXPORTER_OTLP_HEADERS,
+// OTEL_EXPORTER_OTLP_TRACES_HEADERS, OTEL_SERVICE_NAME and
+// OTEL_SDK_DISABLED are also supported. Only the "http/json" protocol
+// is supported. Failing to export the spans does not make the
+// generation fail.
+//
+// # Environment
+//
+// Every flag can be given a default value with an environment
+// variable named MKDIGRAPH_ followed by the flag name in upper case,
+// with dashes replaced by underscores. For instance, MKDIGRAPH_N sets
+// the default of -n and MKDIGRAPH_ID_START the default of -id-start.
+// Flags specified in the command line take precedence over
+// environment variables, which take precedence over the built-in
+// defaults. The variables apply to every command with a flag of that
+// name, but not to the query parameters of serve.
+//
+// # Exit status
+//
+// mkdigraph exits with status 0 on success and, on failure, with:
+//
+//	1  generation and other errors
+//	2  usage errors, like unknown flags and invalid flag values or
+//	   combinations
+//	3  input and output errors, like missing files or a full disk
+//	4  invalid input graphs, like malformed records or the problems
+//	   reported by validate
+//
+// Generations interrupted by a signal exit with status 128 plus the
+// signal number, and toposort and reach exit with status 1 when the
+// graph has a cycle or the target is unreachable.
+//
+// Every command accepts the -errors flag. With -errors=json, fatal
+// errors and the problems reported by validate are written to the
+// standard error as JSON objects, one per line, with the error
+// message, the kind and the value of the exit status and, for errors
+// on files, the operation and the path. For instance:
+//
+//	{"error":"open graph.txt: no such file or directory","kind":"io","status":3,"op":"open","path":"graph.txt"}
+//
+// Errors found while parsing the command line itself are written as
+// text.
 package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"maps"
+	"math"
 	"os"
 	"regexp"
 	"slices"
 	"strconv"
-
-	"github.com/jroimartin/randgraph"
+	"strings"
 )
 
-func main() {
-	var err error
+// A command is a mkdigraph command.
+type command struct {
+	// name is the name of the command.
+	name string
 
-	log.SetFlags(0)
-	log.SetPrefix("mkdigraph: ")
+	// short is a short description of the command.
+	short string
 
-	vertices := flag.Int("n", 25, "number of vertices")
-	trials := flag.Int("trials", 5, "number of edge creation trials per vertex")
-	prob := flag.Float64("prob", 0.5, "success probability for each trial")
-	loops := flag.Bool("loops", false, "allow loops")
-	multiedges := flag.Bool("multiedges", false, "allow multiple edges")
-	wordsFile := flag.String("words", "", "choose vertex labels from a words file")
-	emitDOT := flag.Bool("dot", false, "emit DOT output")
-	outFile := flag.String("o", "", "output file")
-	flag.Usage = usage
-	flag.Parse()
-
-	if flag.NArg() != 0 {
-		usage()
-		os.Exit(2)
-	}
-
-	var words []string
-	if *wordsFile != "" {
-		words, err = readWords(*wordsFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
+	// run runs the command with the provided arguments.
+	run func(args []string)
+}
 
-	b, err := randgraph.NewBinomial(*vertices, *trials, *prob)
-	if err != nil {
-		log.Fatal(err)
+// commands are the mkdigraph commands.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "generate", short: "generate a random digraph (default)", run: runGenerate},
+		{name: "convert", short: "convert a graph to another format", run: runConvert},
+		{name: "stats", short: "print graph statistics", run: runStats},
+		{name: "validate", short: "check that a graph is well-formed", run: runValidate},
+		{name: "sample", short: "extract a random induced subgraph", run: runSample},
+		{name: "induce", short: "extract the subgraph induced by a set of vertices", run: runInduce},
+		{name: "ego", short: "extract the neighborhood of a vertex", run: runEgo},
+		{name: "relabel", short: "renumber the vertices of a graph", run: runRelabel},
+		{name: "filter", short: "remove the edges not matching an expression", run: runFilter},
+		{name: "head", short: "keep the first edges of a graph", run: runHead},
+		{name: "complement", short: "compute the complement of a graph", run: runComplement},
+		{name: "project", short: "project a bipartite graph onto one of its parts", run: runProject},
+		{name: "canon", short: "write a graph in canonical order", run: runCanon},
+		{name: "toposort", short: "sort a graph topologically", run: runToposort},
+		{name: "condense", short: "compute the strongly connected components of a graph", run: runCondense},
+		{name: "components", short: "split a graph into its weakly connected components", run: runComponents},
+		{name: "reach", short: "check whether a vertex is reachable from another", run: runReach},
+		{name: "degdist", short: "print the degree distributions of a graph", run: runDegdist},
+		{name: "fit", short: "estimate the generation parameters of a graph", run: runFit},
+		{name: "render", short: "draw a graph as an image", run: runRender},
+		{name: "words", short: "preprocess a words file", run: runWords},
+		{name: "corpus", short: "write a corpus of small graphs", run: runCorpus},
+		{name: "serve", short: "serve generated graphs over HTTP", run: runServe},
+		{name: "completion", short: "write a shell completion script", run: runCompletion},
 	}
-	b.Loops = *loops
-	b.Multiedges = *multiedges
-	b.Directed = true
-	b.VertexLabel = func(id int) any {
-		return label(words, id)
+}
+
+// jsMain replaces the command line interface when mkdigraph runs in a
+// JavaScript environment. It is nil on other platforms.
+var jsMain func()
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("mkdigraph: ")
+
+	if jsMain != nil {
+		jsMain()
+		return
 	}
-	r := randgraph.New(b)
 
-	fout := os.Stdout
-	if *outFile != "" {
-		fout, err = os.Create(*outFile)
-		if err != nil {
-			log.Fatal(err)
+	args := os.Args[1:]
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if cmd.name == args[0] {
+				cmd.run(args[1:])
+				return
+			}
 		}
-		defer fout.Close()
 	}
+	runGenerate(args)
+}
 
-	if *emitDOT {
-		r.WriteDOT(fout)
-	} else {
-		writeSimple(fout, r)
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: mkdigraph [generate] [flags]")
+	fmt.Fprintln(os.Stderr, "       mkdigraph command [flags] [args]")
+	fmt.Fprintf(os.Stderr, "\nThe commands are:\n\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10v  %v\n", cmd.name, cmd.short)
 	}
+	fmt.Fprintf(os.Stderr, "\nUse \"mkdigraph command -h\" for more information about a command.\n")
 }
 
-func usage() {
-	fmt.Fprintln(os.Stderr, "usage: mkdigraph [flags]")
-	flag.PrintDefaults()
+// commandUsage returns a usage function for a command that prints
+// the synopsis and the flags defined in fs.
+func commandUsage(fs *flag.FlagSet, synopsis string) func() {
+	return func() {
+		fmt.Fprintf(os.Stderr, "usage: mkdigraph %v\n", synopsis)
+		fs.PrintDefaults()
+	}
 }
 
 var invalidChars = regexp.MustCompile(`[^a-zA-Z]`)
 
-func readWords(name string) ([]string, error) {
+// maxWordLine is the maximum length of the lines of a words file.
+const maxWordLine = 16 << 20
+
+// utf8BOM is the UTF-8 encoded byte order mark.
+const utf8BOM = "\xef\xbb\xbf"
+
+// readWords reads the words file with the provided name and returns
+// its sanitized words, sorted and deduplicated. The lines are decoded
+// from enc and, if translit is true, accented letters are
+// transliterated before sanitizing the words.
+func readWords(name string, enc wordsEncoding, translit bool) ([]string, error) {
 	f, err := os.Open(name)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	s := bufio.NewScanner(f)
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(len(wordsCacheMagic)); string(magic) == wordsCacheMagic {
+		return readWordsCache(name, br)
+	}
+	if enc == encodingUTF8 {
+		if bom, _ := br.Peek(len(utf8BOM)); string(bom) == utf8BOM {
+			br.Discard(len(bom))
+		}
+	}
+
+	s := bufio.NewScanner(br)
+	s.Buffer(nil, maxWordLine)
 
 	words := make(map[string]struct{})
+	n := 0
 	for s.Scan() {
-		word := invalidChars.ReplaceAllString(s.Text(), "")
+		n++
+		word := enc.decode(s.Text())
+		if translit {
+			word = transliterate(word)
+		}
+		word = invalidChars.ReplaceAllString(word, "")
 		if word != "" {
 			words[word] = struct{}{}
 		}
 	}
 
 	if err := s.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("%v:%v: line longer than %v bytes", name, n+1, maxWordLine)
+		}
 		return nil, err
 	}
 
-	return slices.Collect(maps.Keys(words)), nil
+	return slices.Sorted(maps.Keys(words)), nil
 }
 
-func writeSimple(w io.Writer, r *randgraph.RandGraph) {
-	for v := range r.Vertices() {
-		fmt.Fprintf(w, "V: %v %v\n", v.ID, v.Label)
+// checkSize returns an error if a graph with n vertices and the
+// provided number of edge creation trials per vertex cannot be
+// generated without overflowing vertex IDs or edge counts.
+func checkSize(n int64, trials int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid number of vertices: %v", n)
 	}
-	for e := range r.Edges() {
-		fmt.Fprintf(w, "E: %v %v\n", e.V0, e.V1)
+	if trials < 0 {
+		return fmt.Errorf("invalid number of trials: %v", trials)
 	}
+	if trials > 0 && n > math.MaxInt64/int64(trials) {
+		return fmt.Errorf("number of edges overflows: %v vertices * %v trials", n, trials)
+	}
+	return nil
+}
+
+// checkIDs returns an error if the IDs of n vertices starting at
+// start and separated by stride overflow.
+func checkIDs(n, start, stride int64) error {
+	if start < 0 {
+		return fmt.Errorf("invalid ID start: %v", start)
+	}
+	if stride < 1 {
+		return fmt.Errorf("invalid ID stride: %v", stride)
+	}
+	if n > 0 && (n-1) > (math.MaxInt64-start)/stride {
+		return fmt.Errorf("vertex IDs overflow: %v vertices starting at %v with stride %v", n, start, stride)
+	}
+	return nil
+}
+
+// degreeDists parses the out-degree and in-degree distributions. An
+// empty specification means a binomial distribution with the provided
+// number of trials and success probability.
+func degreeDists(outSpec, inSpec string, trials int, prob float64) (out, in degreeDist, err error) {
+	def := fmt.Sprintf("binomial:%v,%v", trials, prob)
+	if outSpec == "" {
+		outSpec = def
+	}
+	if inSpec == "" {
+		inSpec = def
+	}
+	if out, err = parseDegreeDist(outSpec); err != nil {
+		return nil, nil, err
+	}
+	if in, err = parseDegreeDist(inSpec); err != nil {
+		return nil, nil, err
+	}
+	return out, in, nil
+}
+
+// A collisionMode is the strategy of the generation when a word label
+// would be duplicated.
+type collisionMode int
+
+// Strategies of the -on-label-collision flag.
+const (
+	collisionSuffix collisionMode = iota
+	collisionError
+	collisionReuse
+)
+
+// parseCollisionMode parses the value of the -on-label-collision flag.
+func parseCollisionMode(s string) (collisionMode, error) {
+	switch s {
+	case "suffix":
+		return collisionSuffix, nil
+	case "error":
+		return collisionError, nil
+	case "reuse":
+		return collisionReuse, nil
+	}
+	return 0, fmt.Errorf("unknown label collision strategy: %q", s)
+}
+
+// wordLabel is like [label], but duplicated words are not suffixed if
+// mode is collisionReuse.
+func wordLabel(words []string, id int64, width int, mode collisionMode) string {
+	if mode == collisionReuse && len(words) > 0 {
+		return words[id%int64(len(words))]
+	}
+	return label(words, id, width)
+}
+
+// hashLabelWidth is the length of the labels returned by [hashLabel].
+const hashLabelWidth = 13
+
+// hashLabel returns an opaque label for the vertex with the provided
+// ID: its hash in base 32, zero-padded to hashLabelWidth. It only
+// depends on seed and id, and different IDs get different labels.
+func hashLabel(seed uint64, id int64) string {
+	s := strconv.FormatUint(vertexHash(seed, id, saltLabel), 32)
+	return strings.Repeat("0", hashLabelWidth-len(s)) + s
 }
 
-func label(labels []string, id int) string {
+func label(labels []string, id int64, width int) string {
 	if len(labels) == 0 {
-		return strconv.Itoa(id)
+		return formatID(id, width)
 	}
-	i := id % len(labels)
-	if id < len(labels) {
+	i := id % int64(len(labels))
+	if id < int64(len(labels)) {
 		return labels[i]
 	}
-	return labels[i] + strconv.Itoa(id)
+	return labels[i] + formatID(id, width)
 }
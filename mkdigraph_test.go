@@ -5,17 +5,17 @@
 package main
 
 import (
-	"bytes"
-	"regexp"
+	"math"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
-
-	"github.com/jroimartin/randgraph"
 )
 
 func TestReadWords(t *testing.T) {
 	want := []string{"FirstWord", "SecondWord"}
-	got, err := readWords("testdata/words")
+	got, err := readWords("testdata/words", encodingUTF8, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -26,30 +26,32 @@ func TestReadWords(t *testing.T) {
 	}
 }
 
-var validSimpleOutput = regexp.MustCompile(`(?m)^(V: \d+ .+\n)+(E: \d+ \d+\n)+$`)
+func TestReadWordsEncodings(t *testing.T) {
+	// A byte order mark, CRLF line endings, a Latin-1 word and a
+	// line longer than the default limit of bufio.Scanner.
+	long := strings.Repeat("x", 100000)
+	data := "\ufeffAlpha\r\nBeta\r\ncaf\xe9\r\n" + long + "\r\n"
+	name := filepath.Join(t.TempDir(), "words")
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
-func TestWriteSimple(t *testing.T) {
-	b, err := randgraph.NewBinomial(5, 2, 0.5)
+	got, err := readWords(name, encodingUTF8, false)
 	if err != nil {
 		t.Fatal(err)
 	}
-	b.Loops = true
-	b.Multiedges = true
-	b.Directed = true
-	r := randgraph.New(b)
-
-	buf := &bytes.Buffer{}
-	writeSimple(buf, r)
-	out := buf.String()
-	if !validSimpleOutput.MatchString(out) {
-		t.Errorf("malformed output:\n%v", out)
+	slices.Sort(got)
+	want := []string{"Alpha", "Beta", "caf", long}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected word list: got: %.20q want: %.20q", got, want)
 	}
 }
 
 func TestLabel(t *testing.T) {
 	tests := []struct {
 		labels []string
-		id     int
+		id     int64
+		width  int
 		want   string
 	}{
 		{
@@ -82,11 +84,137 @@ func TestLabel(t *testing.T) {
 			id:     5,
 			want:   "5",
 		},
+		{
+			labels: []string{"A", "B"},
+			id:     5,
+			width:  3,
+			want:   "B005",
+		},
+		{
+			labels: nil,
+			id:     5,
+			width:  3,
+			want:   "005",
+		},
 	}
 	for _, tt := range tests {
-		got := label(tt.labels, tt.id)
+		got := label(tt.labels, tt.id, tt.width)
 		if got != tt.want {
 			t.Errorf("unexpected label: got: %q, want: %q", got, tt.want)
 		}
 	}
 }
+
+func TestWordLabel(t *testing.T) {
+	words := []string{"A", "B"}
+	tests := []struct {
+		mode collisionMode
+		id   int64
+		want string
+	}{
+		{mode: collisionSuffix, id: 1, want: "B"},
+		{mode: collisionSuffix, id: 3, want: "B3"},
+		{mode: collisionError, id: 3, want: "B3"},
+		{mode: collisionReuse, id: 1, want: "B"},
+		{mode: collisionReuse, id: 3, want: "B"},
+	}
+	for _, tt := range tests {
+		if got := wordLabel(words, tt.id, 0, tt.mode); got != tt.want {
+			t.Errorf("mode %v, id %v: unexpected label: got: %q, want: %q", tt.mode, tt.id, got, tt.want)
+		}
+	}
+	if got := wordLabel(nil, 3, 2, collisionReuse); got != "03" {
+		t.Errorf("unexpected label without words: got: %q, want: %q", got, "03")
+	}
+
+	if _, err := parseCollisionMode("drop"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLabelCollisionError(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{args: []string{"-n=2"}, wantErr: false},
+		{args: []string{"-n=3"}, wantErr: true},
+		{args: []string{"-n=2", "-id-start=1"}, wantErr: true},
+		{args: []string{"-n=3", "-on-label-collision=reuse"}, wantErr: false},
+	}
+	for _, tt := range tests {
+		var c genConfig
+		fs := c.flagSet("generate")
+		args := append([]string{"-seed=1", "-words=testdata/words", "-on-label-collision=error"}, tt.args...)
+		if err := fs.Parse(args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := c.newGeneration(fs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.args, err)
+		}
+	}
+}
+
+func TestCheckSize(t *testing.T) {
+	tests := []struct {
+		n       int64
+		trials  int
+		wantErr bool
+	}{
+		{n: 25, trials: 5, wantErr: false},
+		{n: 1 << 40, trials: 1 << 20, wantErr: false},
+		{n: 0, trials: 0, wantErr: false},
+		{n: 1 << 40, trials: 1 << 30, wantErr: true},
+		{n: -1, trials: 5, wantErr: true},
+		{n: 25, trials: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := checkSize(tt.n, tt.trials)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkSize(%v, %v): unexpected error: %v", tt.n, tt.trials, err)
+		}
+	}
+}
+
+func TestCheckIDs(t *testing.T) {
+	tests := []struct {
+		n, start, stride int64
+		wantErr          bool
+	}{
+		{n: 25, start: 0, stride: 1, wantErr: false},
+		{n: 25, start: 1_000_000, stride: 10, wantErr: false},
+		{n: 0, start: math.MaxInt64, stride: 1, wantErr: false},
+		{n: 1, start: math.MaxInt64, stride: 1, wantErr: false},
+		{n: 2, start: math.MaxInt64, stride: 1, wantErr: true},
+		{n: 1 << 40, start: 0, stride: 1 << 30, wantErr: true},
+		{n: 25, start: -1, stride: 1, wantErr: true},
+		{n: 25, start: 0, stride: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := checkIDs(tt.n, tt.start, tt.stride)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkIDs(%v, %v, %v): unexpected error: %v", tt.n, tt.start, tt.stride, err)
+		}
+	}
+}
+
+func TestHashLabel(t *testing.T) {
+	seen := make(map[string]bool)
+	for id := range int64(1000) {
+		l := hashLabel(42, id)
+		if len(l) != hashLabelWidth {
+			t.Errorf("unexpected length of %q: got: %v, want: %v", l, len(l), hashLabelWidth)
+		}
+		if seen[l] {
+			t.Errorf("duplicated label: %q", l)
+		}
+		seen[l] = true
+	}
+	if hashLabel(42, 7) != hashLabel(42, 7) {
+		t.Error("labels are not reproducible")
+	}
+	if hashLabel(42, 7) == hashLabel(43, 7) {
+		t.Error("labels do not depend on the seed")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// pendingOutputs holds the atomic outputs that are neither committed
+// nor aborted, so [exit] can remove their temporary files.
+var pendingOutputs = struct {
+	mu      sync.Mutex
+	outputs map[*output]bool
+}{outputs: make(map[*output]bool)}
+
+// An output is the destination of a generated graph.
+type output struct {
+	f *os.File
+
+	// name is the name of the output file. It is empty for the
+	// standard output.
+	name string
+
+	// atomic reports whether f is a temporary file that is renamed
+	// to name on commit.
+	atomic bool
+}
+
+// createOutput creates the output file with the provided name. If
+// name is empty, the standard output is used. If append is true, the
+// output is appended to the file if it exists. If atomic is true, the
+// output is written to a temporary file in the same directory that
+// replaces the named file on commit.
+func createOutput(name string, append, atomic bool) (*output, error) {
+	if name == "" {
+		if append || atomic {
+			return nil, errors.New("append and atomic modes require an output file")
+		}
+		return &output{f: os.Stdout}, nil
+	}
+
+	if append && atomic {
+		return nil, errors.New("append and atomic modes are mutually exclusive")
+	}
+
+	if atomic {
+		f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+		if err != nil {
+			return nil, err
+		}
+		o := &output{f: f, name: name, atomic: true}
+		o.setPending(true)
+		if err := f.Chmod(outputMode(name)); err != nil {
+			o.abort()
+			return nil, err
+		}
+		return o, nil
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if append {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	f, err := os.OpenFile(name, flags, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	return &output{f: f, name: name}, nil
+}
+
+// outputMode returns the permissions of the file with the provided
+// name or 0644 if it does not exist.
+func outputMode(name string) fs.FileMode {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return 0o644
+	}
+	return fi.Mode().Perm()
+}
+
+// Write writes p to the output.
+func (o *output) Write(p []byte) (int, error) {
+	return o.f.Write(p)
+}
+
+// commit closes the output. In atomic mode, the temporary file is
+// synced to disk and replaces the output file, so a crash never
+// leaves a truncated output file.
+func (o *output) commit() error {
+	if o.name == "" {
+		return nil
+	}
+	if o.atomic {
+		if err := o.f.Sync(); err != nil {
+			o.abort()
+			return err
+		}
+	}
+	if err := o.f.Close(); err != nil {
+		o.abort()
+		return err
+	}
+	if !o.atomic {
+		return nil
+	}
+	defer o.setPending(false)
+	if err := os.Rename(o.f.Name(), o.name); err != nil {
+		os.Remove(o.f.Name())
+		return err
+	}
+	return nil
+}
+
+// abort closes the output. In atomic mode, the temporary file is
+// removed and the output file is left untouched.
+func (o *output) abort() {
+	if o.name == "" {
+		return
+	}
+	o.f.Close()
+	if o.atomic {
+		os.Remove(o.f.Name())
+		o.setPending(false)
+	}
+}
+
+// setPending adds the output to the pending outputs or removes it.
+func (o *output) setPending(pending bool) {
+	pendingOutputs.mu.Lock()
+	defer pendingOutputs.mu.Unlock()
+	if pending {
+		pendingOutputs.outputs[o] = true
+	} else {
+		delete(pendingOutputs.outputs, o)
+	}
+}
+
+// removePendingOutputs removes the temporary files of the pending
+// atomic outputs. It is called before exiting, so failed generations
+// do not leave temporary files behind.
+func removePendingOutputs() {
+	pendingOutputs.mu.Lock()
+	defer pendingOutputs.mu.Unlock()
+	for o := range pendingOutputs.outputs {
+		o.f.Close()
+		os.Remove(o.f.Name())
+		delete(pendingOutputs.outputs, o)
+	}
+}
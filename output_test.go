@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		append bool
+		atomic bool
+		abort  bool
+		want   string
+	}{
+		{name: "truncate", want: "new"},
+		{name: "append", append: true, want: "oldnew"},
+		{name: "atomic", atomic: true, want: "new"},
+		{name: "atomic abort", atomic: true, abort: true, want: "old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			name := filepath.Join(dir, "graph")
+			if err := os.WriteFile(name, []byte("old"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := createOutput(name, tt.append, tt.atomic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := out.Write([]byte("new")); err != nil {
+				t.Fatal(err)
+			}
+			if tt.abort {
+				out.abort()
+			} else if err := out.commit(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("unexpected content: got: %q want: %q", got, tt.want)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("unexpected number of files: got: %v want: 1", len(entries))
+			}
+		})
+	}
+}
+
+func TestCreateOutputInvalid(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "graph")
+	if _, err := createOutput(name, true, true); err == nil {
+		t.Error("expected error combining append and atomic modes")
+	}
+	if _, err := createOutput("", false, true); err == nil {
+		t.Error("expected error with atomic standard output")
+	}
+}
+
+func TestRemovePendingOutputs(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "graph")
+
+	committed, err := createOutput(name, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := committed.commit(); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := createOutput(filepath.Join(dir, "other"), false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pending.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+
+	removePendingOutputs()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "graph" {
+		t.Errorf("unexpected files: %v", entries)
+	}
+	if len(pendingOutputs.outputs) != 0 {
+		t.Errorf("unexpected pending outputs: %v", len(pendingOutputs.outputs))
+	}
+}
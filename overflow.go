@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// overflowChunk is the size of the chunks queued by an
+// overflowWriter.
+const overflowChunk = 4096
+
+// maxOverflowBuffer is the maximum size of the queue of an
+// overflowWriter.
+const maxOverflowBuffer = 1 << 30
+
+// An overflowWriter writes to an underlying writer from a separate
+// goroutine through a bounded queue, so the generation can find out
+// that the consumer is not keeping up and drop edges instead of
+// waiting for it. Writes only block while the queue is full. Queued
+// bytes are never discarded, so the output is not corrupted.
+type overflowWriter struct {
+	size int64
+	done chan struct{}
+
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// queue holds the chunks waiting to be written. A nil chunk
+	// is a flush request.
+	queue [][]byte
+
+	// queued is the number of bytes in queue.
+	queued int64
+
+	closed bool
+}
+
+// newOverflowWriter returns an overflowWriter that writes to w,
+// queuing up to size bytes. flush is called by the writing goroutine
+// when requested with [overflowWriter.flush]. Errors must be kept by
+// w, like [bufio.Writer] does, because they cannot be returned by
+// Write. The writer must be closed.
+func newOverflowWriter(w io.Writer, size int64, flush func()) *overflowWriter {
+	ow := &overflowWriter{
+		size: max(overflowChunk, size),
+		done: make(chan struct{}),
+	}
+	ow.cond = sync.NewCond(&ow.mu)
+	go func() {
+		defer close(ow.done)
+		for {
+			p, ok := ow.next()
+			if !ok {
+				return
+			}
+			if p == nil {
+				flush()
+				continue
+			}
+			w.Write(p)
+
+			ow.mu.Lock()
+			ow.queued -= int64(len(p))
+			ow.cond.Broadcast()
+			ow.mu.Unlock()
+		}
+	}()
+	return ow
+}
+
+// next waits for the next chunk of the queue. It returns false if the
+// writer is closed and the queue is empty.
+func (ow *overflowWriter) next() ([]byte, bool) {
+	ow.mu.Lock()
+	defer ow.mu.Unlock()
+	for len(ow.queue) == 0 && !ow.closed {
+		ow.cond.Wait()
+	}
+	if len(ow.queue) == 0 {
+		return nil, false
+	}
+	p := ow.queue[0]
+	ow.queue[0] = nil
+	ow.queue = ow.queue[1:]
+	return p, true
+}
+
+// push queues p, waiting while there is no room for it.
+func (ow *overflowWriter) push(p []byte) {
+	ow.mu.Lock()
+	defer ow.mu.Unlock()
+	for ow.queued+int64(len(p)) > ow.size {
+		ow.cond.Wait()
+	}
+	ow.queue = append(ow.queue, p)
+	ow.queued += int64(len(p))
+	ow.cond.Broadcast()
+}
+
+// Write queues p.
+func (ow *overflowWriter) Write(p []byte) (int, error) {
+	for chunk := range slices.Chunk(p, overflowChunk) {
+		ow.push(bytes.Clone(chunk))
+	}
+	return len(p), nil
+}
+
+// flush requests the writing goroutine to flush once the queued bytes
+// have been written.
+func (ow *overflowWriter) flush() {
+	ow.push(nil)
+}
+
+// full reports whether the queue has no room for another chunk.
+func (ow *overflowWriter) full() bool {
+	ow.mu.Lock()
+	defer ow.mu.Unlock()
+	return ow.queued+overflowChunk > ow.size
+}
+
+// close waits for the queued bytes to be written.
+func (ow *overflowWriter) close() {
+	ow.mu.Lock()
+	ow.closed = true
+	ow.cond.Broadcast()
+	ow.mu.Unlock()
+	<-ow.done
+}
+
+// dropOnOverflow returns a copy of seq without the edges found while
+// full reports true. dropped counts them.
+func dropOnOverflow(seq iter.Seq[edge], full func() bool, dropped *int64) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		for e := range seq {
+			if full() {
+				*dropped++
+				continue
+			}
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
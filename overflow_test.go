@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverflowWriter(t *testing.T) {
+	var buf bytes.Buffer
+	flushes := 0
+	ow := newOverflowWriter(&buf, 2*overflowChunk, func() { flushes++ })
+
+	var want []byte
+	for i := range 100 {
+		p := bytes.Repeat([]byte{byte('a' + i%26)}, i*100)
+		want = append(want, p...)
+		ow.Write(p)
+	}
+	ow.flush()
+	ow.close()
+
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("unexpected output: got %v bytes want %v bytes", buf.Len(), len(want))
+	}
+	if flushes != 1 {
+		t.Errorf("unexpected number of flushes: %v", flushes)
+	}
+}
+
+func TestDropOnOverflow(t *testing.T) {
+	edges := make([]edge, 10)
+	for i := range edges {
+		edges[i] = edge{tail: int64(i), head: int64(i)}
+	}
+
+	var dropped int64
+	full := func() bool { return dropped < 3 }
+	got := slices.Collect(dropOnOverflow(slices.Values(edges), full, &dropped))
+	if dropped != 3 || !slices.Equal(got, edges[3:]) {
+		t.Errorf("unexpected edges: got: %v (%v dropped)", got, dropped)
+	}
+}
+
+// slowWriter is a writer that sleeps before every write.
+type slowWriter struct {
+	buf   bytes.Buffer
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.buf.Write(p)
+}
+
+func TestGenerationDropOnOverflow(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantDropped bool
+	}{
+		{args: []string{"-n=2000", "-prob=0.01", "-seed=1", "-meta=false"}},
+		{args: []string{"-n=2000", "-prob=0.01", "-seed=1", "-meta=false", "-drop-on-overflow", "-overflow-buffer=4K"}, wantDropped: true},
+	}
+	for _, tt := range tests {
+		c, fs, err := parseGenerate(tt.args)
+		if err != nil {
+			t.Fatalf("%v: parse error: %v", tt.args, err)
+		}
+		gen, err := c.newGeneration(fs)
+		if err != nil {
+			t.Fatalf("%v: generation error: %v", tt.args, err)
+		}
+
+		sw := &slowWriter{delay: time.Millisecond}
+		bw := bufio.NewWriter(sw)
+		gen.write(context.Background(), bw, func() { bw.Flush() })
+		if err := bw.Flush(); err != nil {
+			t.Fatalf("%v: write error: %v", tt.args, err)
+		}
+
+		out := sw.buf.String()
+		// All the edges may be dropped.
+		if !validSimpleOutput.MatchString(out) && !regexp.MustCompile(`^(V: \d+ .+\n)+$`).MatchString(out) {
+			t.Errorf("%v: malformed output", tt.args)
+		}
+		if n := int64(strings.Count(out, "V: ")); n != 2000 {
+			t.Errorf("%v: unexpected number of vertices: %v", tt.args, n)
+		}
+		if n := int64(strings.Count(out, "E: ")); n != gen.counter.edges {
+			t.Errorf("%v: unexpected number of edges: got: %v want: %v", tt.args, n, gen.counter.edges)
+		}
+		if (gen.dropped > 0) != tt.wantDropped {
+			t.Errorf("%v: unexpected number of dropped edges: %v", tt.args, gen.dropped)
+		}
+	}
+}
+
+func TestDropOnOverflowFlags(t *testing.T) {
+	tests := [][]string{
+		{"-drop-on-overflow", "-overflow-buffer=1K"},
+		{"-drop-on-overflow", "-overflow-buffer=1T"},
+		{"-drop-on-overflow", "-snapshots=2"},
+		{"-drop-on-overflow", "-checkpoint=cp.json"},
+		{"-overflow-buffer=1M"},
+	}
+	for _, args := range tests {
+		c, fs, err := parseGenerate(args)
+		if err == nil {
+			_, err = c.newGeneration(fs)
+		}
+		if err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"plugin"
+)
+
+// A modelFunc generates the edges of a graph with n vertices, whose
+// IDs are in [0, n), as pairs of tail and head IDs. r is the source of
+// randomness.
+//
+// Generation plugins export a function of this type named Model, so
+// they only depend on the standard library.
+type modelFunc = func(n int64, r *rand.Rand) iter.Seq2[int64, int64]
+
+// loadModel loads the model exported by the named Go plugin.
+func loadModel(name string) (modelFunc, error) {
+	p, err := plugin.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup("Model")
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	switch model := sym.(type) {
+	case modelFunc:
+		return model, nil
+	case *modelFunc:
+		// Variables are looked up as pointers.
+		return *model, nil
+	}
+	return nil, fmt.Errorf("%v: Model has type %T, want %T", name, sym, modelFunc(nil))
+}
+
+// modelGraph returns a graph with n vertices whose edges are
+// generated by model. r is the source of randomness.
+func modelGraph(n int64, model modelFunc, r *rand.Rand, vlabel func(id int64) string) graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := range n {
+			if !yield(vertex{id: id, label: vlabel(id)}) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for tail, head := range model(n, r) {
+			if !yield(edge{tail: tail, head: head}) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
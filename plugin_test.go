@@ -0,0 +1,42 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"iter"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestModelGraph(t *testing.T) {
+	ring := func(n int64, r *rand.Rand) iter.Seq2[int64, int64] {
+		return func(yield func(int64, int64) bool) {
+			for i := range n {
+				if !yield(i, (i+1)%n) {
+					return
+				}
+			}
+		}
+	}
+	g := modelGraph(3, ring, newRNG(rngPCG, 1), func(id int64) string { return "v" + strconv.FormatInt(id, 10) })
+
+	wantVertices := []vertex{{id: 0, label: "v0"}, {id: 1, label: "v1"}, {id: 2, label: "v2"}}
+	if got := slices.Collect(g.vertices); !slices.Equal(got, wantVertices) {
+		t.Errorf("unexpected vertices: got: %v want: %v", got, wantVertices)
+	}
+	wantEdges := []edge{{tail: 0, head: 1}, {tail: 1, head: 2}, {tail: 2, head: 0}}
+	if got := slices.Collect(g.edges); !slices.Equal(got, wantEdges) {
+		t.Errorf("unexpected edges: got: %v want: %v", got, wantEdges)
+	}
+}
+
+func TestLoadModelMissing(t *testing.T) {
+	if _, err := loadModel(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,563 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxIDWidth is the maximum supported ID width. It is the number of
+// digits of the largest int64.
+const maxIDWidth = 19
+
+// An emitMode selects the records written by a printer.
+type emitMode int
+
+// Emit modes.
+const (
+	emitBoth emitMode = iota
+	emitVertices
+	emitEdges
+)
+
+// parseEmitMode parses the value of the -emit flag.
+func parseEmitMode(s string) (emitMode, error) {
+	switch s {
+	case "both":
+		return emitBoth, nil
+	case "vertices":
+		return emitVertices, nil
+	case "edges":
+		return emitEdges, nil
+	}
+	return 0, fmt.Errorf("unknown emit mode: %q", s)
+}
+
+// A printer writes graphs in the supported output formats.
+type printer struct {
+	// idWidth is the minimum width of vertex IDs. Shorter IDs are
+	// padded with zeros.
+	idWidth int
+
+	// emit selects the records to write.
+	emit emitMode
+
+	// interleave makes edges be written as soon as both of their
+	// endpoints have been written, instead of after all the
+	// vertices.
+	interleave bool
+
+	// nul makes simple format records be terminated by NUL
+	// instead of newline.
+	nul bool
+
+	// crlf makes lines be terminated by carriage return and line
+	// feed instead of line feed, like Windows text files. It is
+	// not valid with nul or edgeTuples.
+	crlf bool
+
+	// dotStrict makes DOT graphs be declared strict, so that
+	// Graphviz merges multiple edges.
+	dotStrict bool
+
+	// dotName is the identifier of DOT graphs. If it is empty,
+	// graphs are anonymous.
+	dotName string
+
+	// dotTooltip and dotURL are the templates of the tooltip and
+	// URL attributes of DOT nodes. They are not written if nil.
+	dotTooltip, dotURL nodeTemplate
+
+	// meta is written as a comment before the graph, unless it is
+	// empty.
+	meta string
+
+	// temporal makes the simple format be written as a temporal
+	// stream in the patch format, with the time of every vertex
+	// and edge.
+	temporal bool
+
+	// times makes the time of every vertex and edge be written as
+	// an attribute. It is implied by temporal in the simple
+	// format.
+	times bool
+
+	// coords makes the coordinates of every vertex be written as
+	// attributes.
+	coords bool
+
+	// edgeTuples makes the graph be written as Graph500 edge
+	// tuples instead of in the simple format.
+	edgeTuples bool
+
+	// skipBegin makes the data that precedes the first element
+	// be skipped, because it has already been written or it is
+	// written by another process. Likewise, skipEnd makes the data
+	// that follows the last element be skipped.
+	skipBegin, skipEnd bool
+}
+
+// An encoder writes the elements of a graph in an output format.
+type encoder interface {
+	// begin writes the data that precedes the first element.
+	begin()
+
+	// vertex writes a vertex.
+	vertex(v vertex)
+
+	// edge writes an edge.
+	edge(e edge)
+
+	// end writes the data that follows the last element.
+	end()
+}
+
+// newEncoder returns an encoder that writes to w in the simple format
+// or, if dot is true, in the DOT format.
+func (p printer) newEncoder(w io.Writer, dot bool) encoder {
+	if p.crlf {
+		w = &crlfWriter{w: w}
+	}
+	switch {
+	case dot:
+		return &dotEncoder{w: w, p: p}
+	case p.edgeTuples:
+		return &edgeTupleEncoder{w: w}
+	case p.temporal:
+		return p.newPatchEncoder(w)
+	}
+	return p.newSimpleEncoder(w)
+}
+
+// writeComment writes s to w as a comment in the simple format or, if
+// dot is true, in the DOT format. Nothing is written in formats
+// without comments.
+func (p printer) writeComment(w io.Writer, dot bool, s string) {
+	if p.crlf {
+		w = &crlfWriter{w: w}
+	}
+	switch {
+	case dot:
+		fmt.Fprintf(w, "/* %v */\n", strings.ReplaceAll(s, "*/", "* /"))
+	case p.edgeTuples:
+	case p.nul:
+		fmt.Fprintf(w, "# %v\x00", s)
+	default:
+		fmt.Fprintf(w, "# %v\n", s)
+	}
+}
+
+// newSimpleEncoder returns an encoder that writes to w in the simple
+// format.
+func (p printer) newSimpleEncoder(w io.Writer) *simpleEncoder {
+	eor := "\n"
+	if p.nul {
+		eor = "\x00"
+	}
+	return &simpleEncoder{w: w, p: p, eor: eor}
+}
+
+// write writes g using enc.
+func (p printer) write(enc encoder, g graph) {
+	if !p.skipBegin {
+		enc.begin()
+	}
+	p.walk(g, enc.vertex, enc.edge)
+	if !p.skipEnd {
+		enc.end()
+	}
+}
+
+func (p printer) writeSimple(w io.Writer, g graph) {
+	p.write(p.newEncoder(w, false), g)
+}
+
+func (p printer) writeDOT(w io.Writer, g graph) {
+	p.write(p.newEncoder(w, true), g)
+}
+
+// A simpleEncoder writes graphs in the simple format. Records are
+// formatted into a reusable buffer, so writing them does not
+// allocate.
+type simpleEncoder struct {
+	w   io.Writer
+	p   printer
+	eor string
+	buf []byte
+}
+
+func (enc *simpleEncoder) begin() {
+	if enc.p.meta != "" {
+		fmt.Fprintf(enc.w, "# %v%v", enc.p.meta, enc.eor)
+	}
+}
+
+func (enc *simpleEncoder) vertex(v vertex) {
+	enc.buf = enc.appendVertex(enc.buf[:0], v)
+	enc.w.Write(enc.buf)
+}
+
+func (enc *simpleEncoder) edge(e edge) {
+	enc.buf = enc.appendEdge(enc.buf[:0], e)
+	enc.w.Write(enc.buf)
+}
+
+func (enc *simpleEncoder) end() {}
+
+// appendVertex appends the record of v to b.
+func (enc *simpleEncoder) appendVertex(b []byte, v vertex) []byte {
+	b = append(b, "V: "...)
+	b = appendID(b, v.id, enc.p.idWidth)
+	b = append(b, ' ')
+	b = appendLabel(b, v.label)
+	if v.vtype != "" {
+		b = append(b, " type="...)
+		b = appendLabel(b, v.vtype)
+	}
+	if v.community != "" {
+		b = append(b, " community="...)
+		b = appendLabel(b, v.community)
+	}
+	if enc.p.temporal || enc.p.times {
+		b = append(b, " time="...)
+		b = strconv.AppendInt(b, v.time, 10)
+	}
+	if enc.p.coords {
+		b = append(b, " lat="...)
+		b = appendCoord(b, v.lat)
+		b = append(b, " lon="...)
+		b = appendCoord(b, v.lon)
+	}
+	return append(b, enc.eor...)
+}
+
+// appendEdge appends the record of e to b.
+func (enc *simpleEncoder) appendEdge(b []byte, e edge) []byte {
+	b = append(b, "E: "...)
+	b = appendID(b, e.tail, enc.p.idWidth)
+	b = append(b, ' ')
+	b = appendID(b, e.head, enc.p.idWidth)
+	if e.etype != "" {
+		b = append(b, " type="...)
+		b = appendLabel(b, e.etype)
+	}
+	if enc.p.temporal || enc.p.times {
+		b = append(b, " time="...)
+		b = strconv.AppendInt(b, e.time, 10)
+	}
+	return append(b, enc.eor...)
+}
+
+// newPatchEncoder returns an encoder that writes to w in the patch
+// format.
+func (p printer) newPatchEncoder(w io.Writer) encoder {
+	return patchEncoder{p.newSimpleEncoder(w)}
+}
+
+// A patchEncoder writes the vertices and edges added to or removed
+// from a graph in the patch format. Records are the records of the
+// simple format prefixed with "+" or "-".
+type patchEncoder struct {
+	*simpleEncoder
+}
+
+func (enc patchEncoder) vertex(v vertex) {
+	enc.buf = enc.appendVertex(append(enc.buf[:0], '+'), v)
+	enc.w.Write(enc.buf)
+}
+
+func (enc patchEncoder) edge(e edge) {
+	op := byte('+')
+	if e.removed {
+		op = '-'
+	}
+	enc.buf = enc.appendEdge(append(enc.buf[:0], op), e)
+	enc.w.Write(enc.buf)
+}
+
+// A dotEncoder writes graphs in the DOT format. Like
+// [simpleEncoder], it formats records into a reusable buffer.
+type dotEncoder struct {
+	w   io.Writer
+	p   printer
+	buf []byte
+}
+
+func (enc *dotEncoder) begin() {
+	if enc.p.meta != "" {
+		fmt.Fprintf(enc.w, "/* %v */\n", strings.ReplaceAll(enc.p.meta, "*/", "* /"))
+	}
+	b := enc.buf[:0]
+	if enc.p.dotStrict {
+		b = append(b, "strict "...)
+	}
+	b = append(b, "digraph "...)
+	if enc.p.dotName != "" {
+		b = appendDOTID(b, enc.p.dotName)
+		b = append(b, ' ')
+	}
+	b = append(b, "{\n"...)
+	enc.buf = b
+	enc.w.Write(b)
+}
+
+func (enc *dotEncoder) vertex(v vertex) {
+	b := append(enc.buf[:0], '\t')
+	b = appendID(b, v.id, enc.p.idWidth)
+	b = append(b, " [label="...)
+	b = appendDOTQuote(b, v.label)
+	if v.vtype != "" {
+		b = append(b, ", type="...)
+		b = appendDOTQuote(b, v.vtype)
+	}
+	if v.community != "" {
+		b = append(b, ", community="...)
+		b = appendDOTQuote(b, v.community)
+	}
+	if enc.p.times {
+		b = append(b, ", time="...)
+		b = strconv.AppendInt(b, v.time, 10)
+	}
+	if enc.p.coords {
+		b = append(b, ", lat="...)
+		b = appendCoord(b, v.lat)
+		b = append(b, ", lon="...)
+		b = appendCoord(b, v.lon)
+	}
+	if enc.p.dotTooltip != nil {
+		b = append(b, ", tooltip="...)
+		b = appendDOTQuote(b, string(enc.p.dotTooltip.expand(nil, v, enc.p.idWidth, false)))
+	}
+	if enc.p.dotURL != nil {
+		b = append(b, ", URL="...)
+		b = appendDOTQuote(b, string(enc.p.dotURL.expand(nil, v, enc.p.idWidth, true)))
+	}
+	b = append(b, "];\n"...)
+	enc.buf = b
+	enc.w.Write(b)
+}
+
+func (enc *dotEncoder) edge(e edge) {
+	b := append(enc.buf[:0], '\t')
+	b = appendID(b, e.tail, enc.p.idWidth)
+	b = append(b, " -> "...)
+	b = appendID(b, e.head, enc.p.idWidth)
+	sep := " ["
+	if e.etype != "" {
+		b = append(b, sep...)
+		b = append(b, "label="...)
+		b = appendDOTQuote(b, e.etype)
+		b = append(b, ", color=\""...)
+		b = append(b, typeColor(e.etype)...)
+		b = append(b, '"')
+		sep = ", "
+	}
+	if enc.p.times {
+		b = append(b, sep...)
+		b = append(b, "time="...)
+		b = strconv.AppendInt(b, e.time, 10)
+		sep = ", "
+	}
+	if sep == ", " {
+		b = append(b, ']')
+	}
+	b = append(b, ";\n"...)
+	enc.buf = b
+	enc.w.Write(b)
+}
+
+func (enc *dotEncoder) end() {
+	fmt.Fprintln(enc.w, "}")
+}
+
+// A crlfWriter writes to w replacing every line feed with a carriage
+// return and a line feed.
+type crlfWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (cw *crlfWriter) Write(p []byte) (int, error) {
+	b := cw.buf[:0]
+	for _, c := range p {
+		if c == '\n' {
+			b = append(b, '\r')
+		}
+		b = append(b, c)
+	}
+	cw.buf = b
+	if _, err := cw.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// An edgeTupleEncoder writes the edges of a graph as Graph500 edge
+// tuples: the IDs of the tail and head of every edge as little-endian
+// int64 values, without any header. Vertices are not written.
+type edgeTupleEncoder struct {
+	w   io.Writer
+	buf [16]byte
+}
+
+func (enc *edgeTupleEncoder) begin() {}
+
+func (enc *edgeTupleEncoder) vertex(vertex) {}
+
+func (enc *edgeTupleEncoder) edge(e edge) {
+	binary.LittleEndian.PutUint64(enc.buf[0:], uint64(e.tail))
+	binary.LittleEndian.PutUint64(enc.buf[8:], uint64(e.head))
+	enc.w.Write(enc.buf[:])
+}
+
+func (enc *edgeTupleEncoder) end() {}
+
+// walk calls vfn for every vertex and efn for every edge of g that
+// must be written, in the order they must be written. Interleaving
+// requires the vertices of g to be sorted by ID.
+func (p printer) walk(g graph, vfn func(vertex), efn func(edge)) {
+	switch {
+	case p.emit == emitVertices:
+		for v := range g.vertices {
+			vfn(v)
+		}
+	case p.emit == emitEdges:
+		for e := range g.edges {
+			efn(e)
+		}
+	case p.interleave:
+		next, stop := iter.Pull(g.vertices)
+		defer stop()
+
+		v, ok := next()
+		for e := range g.edges {
+			for ok && v.id <= max(e.tail, e.head) {
+				vfn(v)
+				v, ok = next()
+			}
+			efn(e)
+		}
+		for ok {
+			vfn(v)
+			v, ok = next()
+		}
+	default:
+		for v := range g.vertices {
+			vfn(v)
+		}
+		for e := range g.edges {
+			efn(e)
+		}
+	}
+}
+
+// labelEscaper escapes the characters that cannot appear verbatim in
+// the fields of the simple format.
+var labelEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	" ", `\s`,
+	"\t", `\t`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\x00", `\0`,
+)
+
+// escapeLabel escapes s so it can be used as a field of the simple
+// format.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// appendLabel appends the escaped s to b. See [escapeLabel].
+func appendLabel(b []byte, s string) []byte {
+	if !strings.ContainsAny(s, "\\ \t\n\r\x00") {
+		return append(b, s...)
+	}
+	return append(b, escapeLabel(s)...)
+}
+
+// appendDOTQuote appends s to b as a quoted DOT string. Quotes and
+// backslashes are escaped with a backslash.
+func appendDOTQuote(b []byte, s string) []byte {
+	b = append(b, '"')
+	for {
+		i := strings.IndexAny(s, `"\`)
+		if i < 0 {
+			break
+		}
+		b = append(b, s[:i]...)
+		b = append(b, '\\', s[i])
+		s = s[i+1:]
+	}
+	b = append(b, s...)
+	return append(b, '"')
+}
+
+// typeColors is the palette of the DOT edge colors. It is the Dark2
+// scheme of ColorBrewer, which is readable on a white background.
+var typeColors = []string{"#1b9e77", "#d95f02", "#7570b3", "#e7298a", "#66a61e", "#e6ab02", "#a6761d", "#666666"}
+
+// typeColor returns the color of the edges of type etype, chosen from
+// typeColors by the FNV-1a hash of etype, so that it does not depend
+// on the other types of the graph.
+func typeColor(etype string) string {
+	h := uint32(2166136261)
+	for i := range len(etype) {
+		h ^= uint32(etype[i])
+		h *= 16777619
+	}
+	return typeColors[h%uint32(len(typeColors))]
+}
+
+// appendDOTID appends s to b as a DOT ID. It is quoted unless it is
+// an identifier that is not a keyword.
+func appendDOTID(b []byte, s string) []byte {
+	bare := !slices.ContainsFunc(dotKeywords, func(kw string) bool { return strings.EqualFold(s, kw) })
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && (i == 0 || !('0' <= c && c <= '9')) {
+			bare = false
+			break
+		}
+	}
+	if !bare {
+		return appendDOTQuote(b, s)
+	}
+	return append(b, s...)
+}
+
+// dotKeywords are the keywords of the DOT language, which cannot be
+// used as bare IDs.
+var dotKeywords = []string{"node", "edge", "graph", "digraph", "subgraph", "strict"}
+
+// appendCoord appends a coordinate in degrees to b with six decimal
+// places, which is a precision of about ten centimeters.
+func appendCoord(b []byte, x float64) []byte {
+	return strconv.AppendFloat(b, x, 'f', 6, 64)
+}
+
+// formatID returns the decimal representation of id padded with
+// zeros up to width.
+func formatID(id int64, width int) string {
+	return string(appendID(nil, id, width))
+}
+
+// appendID appends the decimal representation of id padded with
+// zeros up to width to b.
+func appendID(b []byte, id int64, width int) []byte {
+	digits := 1
+	for x := id; x >= 10; x /= 10 {
+		digits++
+	}
+	for ; digits < width; digits++ {
+		b = append(b, '0')
+	}
+	return strconv.AppendInt(b, id, 10)
+}
@@ -0,0 +1,399 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// testGraph returns a small graph with a loop and multiple edges.
+func testGraph() graph {
+	return graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "0"}, {id: 1, label: "1"}, {id: 2, label: "2"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}, {tail: 0, head: 1}, {tail: 1, head: 1}, {tail: 2, head: 0}}),
+	}
+}
+
+var validSimpleOutput = regexp.MustCompile(`(?m)^(V: \d+ .+\n)+(E: \d+ \d+\n)+$`)
+
+func TestWriteSimple(t *testing.T) {
+	g := testGraph()
+
+	buf := &bytes.Buffer{}
+	printer{}.writeSimple(buf, g)
+	out := buf.String()
+	if !validSimpleOutput.MatchString(out) {
+		t.Errorf("malformed output:\n%v", out)
+	}
+}
+
+var validDOTOutput = regexp.MustCompile(`^digraph \{\n(\t\d+ \[label=".*"\];\n)+(\t\d+ -> \d+;\n)+\}\n$`)
+
+func TestWriteDOT(t *testing.T) {
+	g := testGraph()
+
+	buf := &bytes.Buffer{}
+	printer{}.writeDOT(buf, g)
+	out := buf.String()
+	if !validDOTOutput.MatchString(out) {
+		t.Errorf("malformed output:\n%v", out)
+	}
+}
+
+func TestFormatID(t *testing.T) {
+	tests := []struct {
+		id    int64
+		width int
+		want  string
+	}{
+		{id: 7, width: 0, want: "7"},
+		{id: 7, width: 3, want: "007"},
+		{id: 1234, width: 3, want: "1234"},
+		{id: 0, width: 2, want: "00"},
+	}
+	for _, tt := range tests {
+		got := formatID(tt.id, tt.width)
+		if got != tt.want {
+			t.Errorf("unexpected ID: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
+
+func TestAppendDOTQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "", want: `""`},
+		{s: "Word", want: `"Word"`},
+		{s: `say "hi"`, want: `"say \"hi\""`},
+		{s: `a\`, want: `"a\\"`},
+		{s: `a\"b`, want: `"a\\\"b"`},
+	}
+	for _, tt := range tests {
+		got := string(appendDOTQuote(nil, tt.s))
+		if got != tt.want {
+			t.Errorf("unexpected quoted string: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
+
+func TestAppendDOTID(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "G", want: "G"},
+		{s: "_graph2", want: "_graph2"},
+		{s: "2graph", want: `"2graph"`},
+		{s: "my graph", want: `"my graph"`},
+		{s: "Strict", want: `"Strict"`},
+	}
+	for _, tt := range tests {
+		got := string(appendDOTID(nil, tt.s))
+		if got != tt.want {
+			t.Errorf("unexpected ID: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
+
+func TestDOTHeader(t *testing.T) {
+	tests := []struct {
+		p    printer
+		want string
+	}{
+		{p: printer{}, want: "digraph {\n"},
+		{p: printer{dotStrict: true}, want: "strict digraph {\n"},
+		{p: printer{dotName: "G"}, want: "digraph G {\n"},
+		{p: printer{dotStrict: true, dotName: "my graph"}, want: "strict digraph \"my graph\" {\n"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		tt.p.newEncoder(buf, true).begin()
+		if got := buf.String(); got != tt.want {
+			t.Errorf("unexpected header: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
+
+func TestTypeColor(t *testing.T) {
+	if typeColor("FOLLOWS") != typeColor("FOLLOWS") {
+		t.Error("the color of a type is not stable")
+	}
+	colors := make(map[string]bool)
+	for _, etype := range []string{"FOLLOWS", "BLOCKS", "LIKES", "KNOWS", "OWNS"} {
+		c := typeColor(etype)
+		if !slices.Contains(typeColors, c) {
+			t.Errorf("color of %v not in the palette: %v", etype, c)
+		}
+		colors[c] = true
+	}
+	if len(colors) < 2 {
+		t.Errorf("all the types have the same color")
+	}
+}
+
+func TestPrinterCRLF(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}}),
+	}
+	tests := []struct {
+		dot  bool
+		want string
+	}{
+		{dot: false, want: "# meta\r\nV: 0 A\r\nV: 1 B\r\nE: 0 1\r\n"},
+		{dot: true, want: "/* meta */\r\ndigraph {\r\n\t0 [label=\"A\"];\r\n\t1 [label=\"B\"];\r\n\t0 -> 1;\r\n}\r\n"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		p := printer{crlf: true, meta: "meta"}
+		p.write(p.newEncoder(buf, tt.dot), g)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("dot=%v: unexpected output: got: %q want: %q", tt.dot, got, tt.want)
+		}
+	}
+}
+
+func TestEncoderAllocs(t *testing.T) {
+	p := printer{idWidth: 4}
+	v := vertex{id: 12, label: "Word", community: "c"}
+	e := edge{tail: 12, head: 345}
+
+	for _, dot := range []bool{false, true} {
+		enc := p.newEncoder(io.Discard, dot)
+		allocs := testing.AllocsPerRun(100, func() {
+			enc.vertex(v)
+			enc.edge(e)
+		})
+		if allocs != 0 {
+			t.Errorf("dot=%v: unexpected allocations: got: %v, want: 0", dot, allocs)
+		}
+	}
+}
+
+func TestPrinterEmit(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}}),
+	}
+
+	tests := []struct {
+		emit emitMode
+		want string
+	}{
+		{emit: emitBoth, want: "V: 0 A\nV: 1 B\nE: 0 1\n"},
+		{emit: emitVertices, want: "V: 0 A\nV: 1 B\n"},
+		{emit: emitEdges, want: "E: 0 1\n"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		printer{emit: tt.emit}.writeSimple(buf, g)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("unexpected output (emit=%v): got: %q want: %q", tt.emit, got, tt.want)
+		}
+	}
+}
+
+func TestPrinterInterleave(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}, {id: 2, label: "C"}, {id: 3, label: "D"}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0}, {tail: 0, head: 1}, {tail: 2, head: 1}, {tail: 1, head: 2}}),
+	}
+	want := "V: 0 A\nV: 1 B\nE: 1 0\nE: 0 1\nV: 2 C\nE: 2 1\nE: 1 2\nV: 3 D\n"
+
+	buf := &bytes.Buffer{}
+	printer{interleave: true}.writeSimple(buf, g)
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q want: %q", got, want)
+	}
+}
+
+func TestPrinterNUL(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}}),
+	}
+	want := "V: 0 A\x00V: 1 B\x00E: 0 1\x00"
+
+	buf := &bytes.Buffer{}
+	printer{nul: true}.writeSimple(buf, g)
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q want: %q", got, want)
+	}
+}
+
+func TestPrinterCommunity(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A", vtype: "user", community: "x y"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1}}),
+	}
+
+	tests := []struct {
+		dot  bool
+		want string
+	}{
+		{dot: false, want: "V: 0 A type=user community=x\\sy\nV: 1 B\nE: 0 1\n"},
+		{dot: true, want: "digraph {\n\t0 [label=\"A\", type=\"user\", community=\"x y\"];\n\t1 [label=\"B\"];\n\t0 -> 1;\n}\n"},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		p := printer{}
+		p.write(p.newEncoder(buf, tt.dot), g)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("unexpected output (dot=%v): got: %q want: %q", tt.dot, got, tt.want)
+		}
+	}
+}
+
+func TestPrinterTemporal(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A", time: 0}, {id: 1, label: "B", time: 1}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0, time: 1}, {tail: 1, head: 0, removed: true, time: 1}}),
+	}
+	want := "+V: 0 A time=0\n+V: 1 B time=1\n+E: 1 0 time=1\n-E: 1 0 time=1\n"
+
+	buf := &bytes.Buffer{}
+	p := printer{interleave: true, temporal: true}
+	p.write(p.newEncoder(buf, false), g)
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output: got: %q want: %q", got, want)
+	}
+}
+
+func TestPrinterTimes(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A", time: 10}, {id: 1, label: "B", time: 20}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0, time: 25}}),
+	}
+	tests := []struct {
+		dot  bool
+		want string
+	}{
+		{
+			dot:  false,
+			want: "V: 0 A time=10\nV: 1 B time=20\nE: 1 0 time=25\n",
+		},
+		{
+			dot:  true,
+			want: "digraph {\n\t0 [label=\"A\", time=10];\n\t1 [label=\"B\", time=20];\n\t1 -> 0 [time=25];\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		p := printer{times: true}
+		p.write(p.newEncoder(buf, tt.dot), g)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("dot=%v: unexpected output: got: %q want: %q", tt.dot, got, tt.want)
+		}
+	}
+}
+
+func TestPrinterEdgeTypes(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0, etype: "FOLLOWS", time: 25}, {tail: 0, head: 1, time: 30}}),
+	}
+	tests := []struct {
+		times bool
+		dot   bool
+		want  string
+	}{
+		{
+			dot:  false,
+			want: "V: 0 A\nV: 1 B\nE: 1 0 type=FOLLOWS\nE: 0 1\n",
+		},
+		{
+			dot:  true,
+			want: "digraph {\n\t0 [label=\"A\"];\n\t1 [label=\"B\"];\n\t1 -> 0 [label=\"FOLLOWS\", color=\"#d95f02\"];\n\t0 -> 1;\n}\n",
+		},
+		{
+			times: true,
+			dot:   false,
+			want:  "V: 0 A time=0\nV: 1 B time=0\nE: 1 0 type=FOLLOWS time=25\nE: 0 1 time=30\n",
+		},
+		{
+			times: true,
+			dot:   true,
+			want:  "digraph {\n\t0 [label=\"A\", time=0];\n\t1 [label=\"B\", time=0];\n\t1 -> 0 [label=\"FOLLOWS\", color=\"#d95f02\", time=25];\n\t0 -> 1 [time=30];\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		p := printer{times: tt.times}
+		p.write(p.newEncoder(buf, tt.dot), g)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("times=%v dot=%v: unexpected output: got: %q want: %q", tt.times, tt.dot, got, tt.want)
+		}
+	}
+}
+
+func TestPrinterEdgeTuples(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0}, {tail: 0, head: 258}}),
+	}
+	want := []byte{
+		1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 0, 0, 0, 0, 0, 0,
+	}
+
+	buf := &bytes.Buffer{}
+	p := printer{edgeTuples: true, meta: "ignored"}
+	p.write(p.newEncoder(buf, false), g)
+	if got := buf.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("unexpected output: got: %v want: %v", got, want)
+	}
+}
+
+func TestPrinterCoords(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A", lat: 40.416775, lon: -3.70379}}),
+		edges:    slices.Values([]edge{}),
+	}
+	tests := []struct {
+		dot  bool
+		want string
+	}{
+		{
+			dot:  false,
+			want: "V: 0 A lat=40.416775 lon=-3.703790\n",
+		},
+		{
+			dot:  true,
+			want: "digraph {\n\t0 [label=\"A\", lat=40.416775, lon=-3.703790];\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		p := printer{coords: true}
+		p.write(p.newEncoder(buf, tt.dot), g)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("dot=%v: unexpected output: got: %q want: %q", tt.dot, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{label: "Word", want: "Word"},
+		{label: "two words", want: `two\swords`},
+		{label: `back\slash`, want: `back\\slash`},
+		{label: "a\tb\nc\rd\x00e", want: `a\tb\nc\rd\0e`},
+	}
+	for _, tt := range tests {
+		got := escapeLabel(tt.label)
+		if got != tt.want {
+			t.Errorf("unexpected escaped label: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	cpu := filepath.Join(dir, "cpu.out")
+	mem := filepath.Join(dir, "mem.out")
+
+	stop, err := startCPUProfile(cpu)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := writeMemProfile(mem); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{cpu, mem} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fi.Size() == 0 {
+			t.Errorf("empty profile: %v", name)
+		}
+	}
+}
+
+func TestPprofHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/debug/pprof/heap?debug=1", nil)
+	pprofHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("unexpected status code: got: %v, want: %v", rec.Code, http.StatusOK)
+	}
+}
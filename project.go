@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"slices"
+)
+
+// project returns the projection of the bipartite graph d onto the
+// part of its vertices for which inPart returns true. Two vertices of
+// the part are adjacent in the projection if they share a neighbor in
+// the other part, regardless of the direction of the edges. Every pair
+// of adjacent vertices is joined by a single edge from the lower to
+// the higher ID or, if weighted is true, by one edge per shared
+// neighbor. The edges are sorted by tail and head.
+//
+// It returns an error if an edge joins two vertices of the same part.
+func project(d *graphData, inPart func(id int64) bool, weighted bool) (graph, error) {
+	// nbs holds the neighbors in the part of every vertex of the
+	// other part.
+	nbs := make(map[int64][]int64)
+	for _, e := range d.edges {
+		tailIn, headIn := inPart(e.tail), inPart(e.head)
+		switch {
+		case tailIn && !headIn:
+			nbs[e.head] = append(nbs[e.head], e.tail)
+		case !tailIn && headIn:
+			nbs[e.tail] = append(nbs[e.tail], e.head)
+		default:
+			return graph{}, fmt.Errorf("graph is not bipartite: %v -> %v", e.tail, e.head)
+		}
+	}
+
+	shared := make(map[edge]int)
+	for _, ids := range nbs {
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+		for i, u := range ids {
+			for _, v := range ids[i+1:] {
+				shared[edge{tail: u, head: v}]++
+			}
+		}
+	}
+	pairs := make([]edge, 0, len(shared))
+	for e := range shared {
+		pairs = append(pairs, e)
+	}
+	slices.SortFunc(pairs, func(a, b edge) int {
+		if c := cmp.Compare(a.tail, b.tail); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.head, b.head)
+	})
+
+	vertices := func(yield func(vertex) bool) {
+		for _, v := range d.vertices {
+			if !inPart(v.id) {
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for _, e := range pairs {
+			n := 1
+			if weighted {
+				n = shared[e]
+			}
+			for range n {
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}, nil
+}
+
+func runProject(args []string) {
+	fs := flag.NewFlagSet("project", flag.ExitOnError)
+	vtype := fs.String("type", "", "project onto the vertices of `type` instead of the edge tails")
+	weighted := fs.Bool("weighted", false, "write one edge per shared neighbor")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "project [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	d, err := loadGraph(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+
+	part := make(map[int64]struct{})
+	if *vtype != "" {
+		for _, v := range d.vertices {
+			if v.vtype == *vtype {
+				part[v.id] = struct{}{}
+			}
+		}
+	} else {
+		for _, e := range d.edges {
+			part[e.tail] = struct{}{}
+		}
+	}
+	inPart := func(id int64) bool {
+		_, ok := part[id]
+		return ok
+	}
+
+	g, err := project(d, inPart, *weighted)
+	if err != nil {
+		fatal(err)
+	}
+	err = of.write(func(enc encoder) error {
+		for v := range g.vertices {
+			enc.vertex(v)
+		}
+		for e := range g.edges {
+			enc.edge(e)
+		}
+		return nil
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
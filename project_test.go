@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	// Users 0, 1 and 2 rate items 10, 11 and 12.
+	const input = "V: 0 u0\nV: 1 u1\nV: 2 u2\nV: 10 i0\nV: 11 i1\nV: 12 i2\n" +
+		"E: 0 10\nE: 0 11\nE: 1 10\nE: 1 11\nE: 2 11\nE: 2 12\nE: 0 10\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := func(id int64) bool { return id < 10 }
+	items := func(id int64) bool { return id >= 10 }
+	tests := []struct {
+		inPart       func(id int64) bool
+		weighted     bool
+		wantVertices []int64
+		wantEdges    []edge
+	}{
+		{
+			inPart:       users,
+			weighted:     false,
+			wantVertices: []int64{0, 1, 2},
+			wantEdges:    []edge{{tail: 0, head: 1}, {tail: 0, head: 2}, {tail: 1, head: 2}},
+		},
+		{
+			inPart:       users,
+			weighted:     true,
+			wantVertices: []int64{0, 1, 2},
+			wantEdges:    []edge{{tail: 0, head: 1}, {tail: 0, head: 1}, {tail: 0, head: 2}, {tail: 1, head: 2}},
+		},
+		{
+			inPart:       items,
+			weighted:     true,
+			wantVertices: []int64{10, 11, 12},
+			wantEdges:    []edge{{tail: 10, head: 11}, {tail: 10, head: 11}, {tail: 11, head: 12}},
+		},
+	}
+	for _, tt := range tests {
+		g, err := project(d, tt.inPart, tt.weighted)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var ids []int64
+		for v := range g.vertices {
+			ids = append(ids, v.id)
+		}
+		if !slices.Equal(ids, tt.wantVertices) {
+			t.Errorf("unexpected vertices: got: %v want: %v", ids, tt.wantVertices)
+		}
+		if got := slices.Collect(g.edges); !slices.Equal(got, tt.wantEdges) {
+			t.Errorf("unexpected edges: got: %v want: %v", got, tt.wantEdges)
+		}
+	}
+
+	if _, err := project(d, func(id int64) bool { return id != 11 }, false); err == nil {
+		t.Error("expected error projecting a non-bipartite graph")
+	}
+}
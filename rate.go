@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// rateLimit returns a copy of seq that yields at most rate edges per
+// second on average, in bursts of up to burst edges. Before waiting,
+// flush is called, so the edges already yielded reach the consumer on
+// time. Iteration stops as soon as ctx is done.
+func rateLimit(ctx context.Context, seq iter.Seq[edge], rate float64, burst int, flush func()) iter.Seq[edge] {
+	return func(yield func(edge) bool) {
+		tokens := float64(burst)
+		last := time.Now()
+		for e := range seq {
+			now := time.Now()
+			tokens = min(float64(burst), tokens+now.Sub(last).Seconds()*rate)
+			last = now
+			if tokens < 1 {
+				flush()
+				wait := time.Duration((1 - tokens) / rate * float64(time.Second))
+				t := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return
+				case <-t.C:
+				}
+				now = time.Now()
+				tokens += now.Sub(last).Seconds() * rate
+				last = now
+			}
+			tokens--
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	edges := make([]edge, 30)
+	for i := range edges {
+		edges[i] = edge{tail: int64(i), head: int64(i)}
+	}
+
+	flushes := 0
+	start := time.Now()
+	got := slices.Collect(rateLimit(context.Background(), slices.Values(edges), 500, 10, func() { flushes++ }))
+	elapsed := time.Since(start)
+
+	if !slices.Equal(got, edges) {
+		t.Errorf("unexpected edges: got: %v want: %v", got, edges)
+	}
+	// The first 10 edges are a burst, the other 20 are paced at
+	// 500 edges/s.
+	if elapsed < 35*time.Millisecond {
+		t.Errorf("edges emitted too fast: %v", elapsed)
+	}
+	if flushes == 0 {
+		t.Error("flush was not called")
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	edges := make([]edge, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got := slices.Collect(rateLimit(ctx, slices.Values(edges), 0.001, 2, func() {}))
+	if len(got) != 2 {
+		t.Errorf("unexpected number of edges: got: %v want: 2", len(got))
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// shortestPath returns a shortest path from the vertex from to the
+// vertex to in the graph with the provided adjacency lists, including
+// both endpoints, or nil if to is not reachable from from. It performs
+// a breadth-first search that visits the out-neighbors of every vertex
+// in edge order, so the path only depends on the input.
+func shortestPath(out map[int64][]int64, from, to int64) []int64 {
+	parent := map[int64]int64{from: from}
+	queue := []int64{from}
+	for {
+		if _, ok := parent[to]; ok {
+			break
+		}
+		if len(queue) == 0 {
+			return nil
+		}
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range out[u] {
+			if _, ok := parent[v]; !ok {
+				parent[v] = u
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	path := []int64{to}
+	for id := to; id != from; {
+		id = parent[id]
+		path = append(path, id)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+func runReach(args []string) {
+	fs := flag.NewFlagSet("reach", flag.ExitOnError)
+	from := fs.Int64("from", -1, "`ID` of the source vertex")
+	to := fs.Int64("to", -1, "`ID` of the target vertex")
+	printPath := fs.Bool("path", false, "print a shortest path")
+	fs.Usage = commandUsage(fs, "reach -from id -to id [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *from < 0 || *to < 0 {
+		fatal(fmt.Errorf("-from and -to are required"))
+	}
+
+	_, out, err := readAdjacency(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+	for _, id := range []int64{*from, *to} {
+		if _, ok := out[id]; !ok {
+			fatal(fmt.Errorf("unknown vertex: %v", id))
+		}
+	}
+
+	path := shortestPath(out, *from, *to)
+	if path == nil {
+		fmt.Println("unreachable")
+		os.Exit(1)
+	}
+	if !*printPath {
+		fmt.Println("reachable")
+		return
+	}
+	ids := make([]string, len(path))
+	for i, id := range path {
+		ids[i] = fmt.Sprint(id)
+	}
+	fmt.Println(strings.Join(ids, " -> "))
+}
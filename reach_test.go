@@ -0,0 +1,37 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	out := map[int64][]int64{
+		0: {1, 2},
+		1: {3},
+		2: {3, 4},
+		3: {0},
+		4: {5},
+		5: nil,
+		6: {0},
+	}
+	tests := []struct {
+		from, to int64
+		want     []int64
+	}{
+		{from: 0, to: 0, want: []int64{0}},
+		{from: 0, to: 3, want: []int64{0, 1, 3}},
+		{from: 3, to: 5, want: []int64{3, 0, 2, 4, 5}},
+		{from: 0, to: 6, want: nil},
+		{from: 5, to: 0, want: nil},
+	}
+	for _, tt := range tests {
+		if got := shortestPath(out, tt.from, tt.to); !slices.Equal(got, tt.want) {
+			t.Errorf("shortestPath(%v, %v): got: %v want: %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxRecordSize is the maximum size of a record of the simple format.
+const maxRecordSize = 1 << 20
+
+// An element is either a vertex or an edge of a graph stream.
+type element struct {
+	isEdge bool
+	v      vertex
+	e      edge
+}
+
+// decodeSimple returns an iterator over the elements of the graph
+// read from r in the simple format. The record terminator, newline or
+// NUL, is detected from the input. Comments and empty records are
+// skipped. Iteration stops after the first error, which is yielded
+// along with a zero element.
+func decodeSimple(r io.Reader) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, maxRecordSize)
+		s.Split(splitRecords())
+
+		for n := 1; s.Scan(); n++ {
+			rec := strings.TrimSuffix(s.Text(), "\r")
+			if rec == "" || strings.HasPrefix(rec, "#") {
+				continue
+			}
+			elem, err := parseRecord(rec)
+			if err != nil {
+				yield(element{}, fmt.Errorf("record %v: %w", n, err))
+				return
+			}
+			if !yield(elem, nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			yield(element{}, err)
+		}
+	}
+}
+
+// splitRecords returns a [bufio.SplitFunc] that splits records
+// terminated by newline or NUL. The terminator is the first of them
+// found in the input.
+func splitRecords() bufio.SplitFunc {
+	var eor byte
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if eor == 0 {
+			switch i := bytes.IndexAny(data, "\n\x00"); {
+			case i >= 0:
+				eor = data[i]
+			case !atEOF:
+				return 0, nil, nil
+			default:
+				eor = '\n'
+			}
+		}
+		if i := bytes.IndexByte(data, eor); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// parseRecord parses a vertex or edge record of the simple format.
+func parseRecord(rec string) (element, error) {
+	switch {
+	case strings.HasPrefix(rec, "V: "):
+		id, label, ok := strings.Cut(rec[3:], " ")
+		if !ok {
+			return element{}, errors.New("missing vertex label")
+		}
+		v, err := parseID(id)
+		if err != nil {
+			return element{}, err
+		}
+		label, attrs, _ := strings.Cut(label, " ")
+		label, err = unescapeLabel(label)
+		if err != nil {
+			return element{}, err
+		}
+		elem := element{v: vertex{id: v, label: label}}
+		if attrs != "" {
+			if err := parseAttrs(&elem.v, attrs); err != nil {
+				return element{}, err
+			}
+		}
+		return elem, nil
+	case strings.HasPrefix(rec, "E: "):
+		tail, head, ok := strings.Cut(rec[3:], " ")
+		if !ok {
+			return element{}, errors.New("missing edge head")
+		}
+		t, err := parseID(tail)
+		if err != nil {
+			return element{}, err
+		}
+		head, attrs, _ := strings.Cut(head, " ")
+		h, err := parseID(head)
+		if err != nil {
+			return element{}, err
+		}
+		elem := element{isEdge: true, e: edge{tail: t, head: h}}
+		if attrs != "" {
+			if err := parseEdgeAttrs(&elem.e, attrs); err != nil {
+				return element{}, err
+			}
+		}
+		return elem, nil
+	}
+	return element{}, fmt.Errorf("malformed record: %q", rec)
+}
+
+// parseAttrs parses the space-separated key=value vertex attributes
+// in s into v. The supported attributes are type, community, time,
+// lat and lon.
+func parseAttrs(v *vertex, s string) error {
+	for attr := range strings.SplitSeq(s, " ") {
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok {
+			return fmt.Errorf("malformed vertex attribute: %q", attr)
+		}
+		value, err := unescapeLabel(value)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "type":
+			v.vtype = value
+		case "community":
+			v.community = value
+		case "time":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid time: %q", value)
+			}
+			v.time = t
+		case "lat", "lon":
+			x, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid coordinate: %q", value)
+			}
+			if key == "lat" {
+				v.lat = x
+			} else {
+				v.lon = x
+			}
+		default:
+			return fmt.Errorf("unknown vertex attribute: %q", key)
+		}
+	}
+	return nil
+}
+
+// parseEdgeAttrs parses the space-separated key=value edge attributes
+// in s into e. The supported attributes are type and time.
+func parseEdgeAttrs(e *edge, s string) error {
+	for attr := range strings.SplitSeq(s, " ") {
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok {
+			return fmt.Errorf("malformed edge attribute: %q", attr)
+		}
+		value, err := unescapeLabel(value)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "type":
+			e.etype = value
+		case "time":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid time: %q", value)
+			}
+			e.time = t
+		default:
+			return fmt.Errorf("unknown edge attribute: %q", key)
+		}
+	}
+	return nil
+}
+
+// parseID parses a vertex ID.
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid vertex ID: %q", s)
+	}
+	return id, nil
+}
+
+// unescapeLabel is the inverse of [escapeLabel].
+func unescapeLabel(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		if strings.ContainsAny(s, " \t\n\r\x00") {
+			return "", fmt.Errorf("unescaped separator in label: %q", s)
+		}
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 == len(s) {
+			return "", fmt.Errorf("trailing backslash in label: %q", s)
+		}
+		i++
+		switch s[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 's':
+			b.WriteByte(' ')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		default:
+			return "", fmt.Errorf("invalid escape sequence in label: %q", s)
+		}
+	}
+	return b.String(), nil
+}
+
+// A graphData is a graph stored in memory.
+type graphData struct {
+	vertices []vertex
+	edges    []edge
+}
+
+// loadGraph reads all the elements of seq into memory.
+func loadGraph(seq iter.Seq2[element, error]) (*graphData, error) {
+	d := &graphData{}
+	for elem, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		if elem.isEdge {
+			d.edges = append(d.edges, elem.e)
+		} else {
+			d.vertices = append(d.vertices, elem.v)
+		}
+	}
+	return d, nil
+}
+
+// hasVertex reports whether the vertex with the provided ID is
+// declared in d or is an edge endpoint.
+func (d *graphData) hasVertex(id int64) bool {
+	return slices.ContainsFunc(d.vertices, func(v vertex) bool { return v.id == id }) ||
+		slices.ContainsFunc(d.edges, func(e edge) bool { return e.tail == id || e.head == id })
+}
+
+// vertexIDs returns the sorted IDs of the vertices declared in d and
+// of the edge endpoints.
+func (d *graphData) vertexIDs() []int64 {
+	var ids []int64
+	for _, v := range d.vertices {
+		ids = append(ids, v.id)
+	}
+	for _, e := range d.edges {
+		ids = append(ids, e.tail, e.head)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// elements returns an iterator over the vertices and then the edges
+// stored in d.
+func (d *graphData) elements() iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		for _, v := range d.vertices {
+			if !yield(element{v: v}, nil) {
+				return
+			}
+		}
+		for _, e := range d.edges {
+			if !yield(element{isEdge: true, e: e}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// graph returns the graph stored in d.
+func (d *graphData) graph() graph {
+	return graph{
+		vertices: slices.Values(d.vertices),
+		edges:    slices.Values(d.edges),
+	}
+}
+
+// openInput opens the named input file. If name is empty or "-", the
+// standard input is returned.
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
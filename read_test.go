@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeSimple(t *testing.T) {
+	want := []element{
+		{v: vertex{id: 0, label: "A"}},
+		{v: vertex{id: 1, label: "two words"}},
+		{isEdge: true, e: edge{tail: 0, head: 1}},
+		{isEdge: true, e: edge{tail: 1, head: 1}},
+	}
+
+	inputs := []string{
+		"# comment\nV: 0 A\nV: 1 two\\swords\nE: 0 1\nE: 1 1\n",
+		"V: 0 A\r\nV: 1 two\\swords\r\n\r\nE: 0 1\r\nE: 1 1",
+		"# comment\x00V: 0 A\x00V: 1 two\\swords\x00E: 0 1\x00E: 1 1\x00",
+	}
+	for _, input := range inputs {
+		var got []element
+		for elem, err := range decodeSimple(strings.NewReader(input)) {
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %v", input, err)
+			}
+			got = append(got, elem)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%q: unexpected elements: got: %v want: %v", input, got, want)
+		}
+	}
+}
+
+func TestDecodeSimpleInvalid(t *testing.T) {
+	inputs := []string{
+		"V: 0\n",
+		"V: x A\n",
+		"V: -1 A\n",
+		"V: 0 A B\n",
+		"V: 0 A\\\n",
+		"V: 0 A\\x\n",
+		"E: 0\n",
+		"E: 0 y\n",
+		"X: 0 1\n",
+	}
+	for _, input := range inputs {
+		if _, err := loadGraph(decodeSimple(strings.NewReader(input))); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestDecodeSimpleAttrs(t *testing.T) {
+	input := "V: 0 A type=user community=x\\sy lat=1.5 lon=-2\nV: 1 B time=20\nE: 1 0 type=FOLLOWS time=25\n"
+	want := []element{
+		{v: vertex{id: 0, label: "A", vtype: "user", community: "x y", lat: 1.5, lon: -2}},
+		{v: vertex{id: 1, label: "B", time: 20}},
+		{isEdge: true, e: edge{tail: 1, head: 0, etype: "FOLLOWS", time: 25}},
+	}
+
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []element
+	for _, v := range d.vertices {
+		got = append(got, element{v: v})
+	}
+	for _, e := range d.edges {
+		got = append(got, element{isEdge: true, e: e})
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected elements: got: %v want: %v", got, want)
+	}
+
+	invalid := []string{
+		"V: 0 A color=red\n",
+		"V: 0 A community\n",
+		"V: 0 A time=x\n",
+		"V: 0 A lat=north\n",
+		"E: 0 1 color=red\n",
+		"E: 0 1 time\n",
+		"E: 0 1 time=x\n",
+	}
+	for _, input := range invalid {
+		if _, err := loadGraph(decodeSimple(strings.NewReader(input))); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestUnescapeLabel(t *testing.T) {
+	labels := []string{"Word", "two words", `back\slash`, "a\tb\nc\rd\x00e"}
+	for _, label := range labels {
+		got, err := unescapeLabel(escapeLabel(label))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", label, err)
+			continue
+		}
+		if got != label {
+			t.Errorf("unexpected label: got: %q, want: %q", got, label)
+		}
+	}
+}
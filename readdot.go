@@ -0,0 +1,579 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxSniffSize is the maximum number of bytes read to detect the
+// format of an input graph.
+const maxSniffSize = 1 << 16
+
+// decodeGraph returns an iterator over the elements of the graph read
+// from r, either in the simple format, in DOT or in GraphML. See
+// [decodeSimple], [decodeDOT] and [decodeGraphML]. Errors are returned
+// as input errors.
+func decodeGraph(r io.Reader) iter.Seq2[element, error] {
+	br := bufio.NewReaderSize(r, maxSniffSize)
+	var seq iter.Seq2[element, error]
+	switch sniffFormat(br) {
+	case "dot":
+		seq = decodeDOT(br)
+	case "graphml":
+		seq = decodeGraphML(br)
+	default:
+		seq = decodeSimple(br)
+	}
+	return func(yield func(element, error) bool) {
+		for elem, err := range seq {
+			if err != nil {
+				err = inputError{err}
+			}
+			if !yield(elem, err) {
+				return
+			}
+		}
+	}
+}
+
+// sniffFormat returns the format of the input buffered by br: dot,
+// graphml or simple. It skips the leading whitespace and comments and
+// checks the first keyword, or the first XML tag.
+func sniffFormat(br *bufio.Reader) string {
+	data, _ := br.Peek(maxSniffSize)
+	for {
+		data = bytes.TrimLeftFunc(data, unicode.IsSpace)
+		var end []byte
+		switch {
+		case bytes.HasPrefix(data, []byte("/*")):
+			end = []byte("*/")
+		case bytes.HasPrefix(data, []byte("//")), bytes.HasPrefix(data, []byte("#")):
+			end = []byte("\n")
+		}
+		if end == nil {
+			break
+		}
+		i := bytes.Index(data, end)
+		if i < 0 {
+			return "simple"
+		}
+		data = data[i+len(end):]
+	}
+	if bytes.HasPrefix(data, []byte("<")) {
+		return "graphml"
+	}
+	for _, kw := range []string{"digraph", "strict", "graph"} {
+		if len(data) > len(kw) && strings.EqualFold(string(data[:len(kw)]), kw) && !isDOTIDByte(data[len(kw)]) {
+			return "dot"
+		}
+	}
+	return "simple"
+}
+
+// decodeDOT returns an iterator over the elements of the graph read
+// from r in DOT. It supports the subset of DOT needed to read the
+// graphs written by mkdigraph and most hand-written fixtures: a
+// single, optionally strict, digraph made of node and edge
+// statements, including chains of edges. Node IDs must be vertex IDs.
+// The label, type, community, time, lat and lon node attributes and
+// the label and time edge attributes are read, the label of an edge
+// being its type. The rest of the attributes, as well as graph, node
+// and edge default statements, are ignored. Subgraphs and ports are
+// not supported. Iteration stops after the first error, which is
+// yielded along with a zero element.
+func decodeDOT(r io.Reader) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		p := &dotParser{lex: &dotLexer{r: bufio.NewReader(r), line: 1}}
+		if err := p.parse(yield); err != nil && err != errStop {
+			yield(element{}, fmt.Errorf("line %v: %w", p.lex.line, err))
+		}
+	}
+}
+
+// errStop is returned by a dotParser when the consumer stops the
+// iteration.
+var errStop = errors.New("stop")
+
+// A dotParser parses DOT graphs.
+type dotParser struct {
+	lex *dotLexer
+
+	// peeked is the token returned by the last call to peek, if
+	// it has not been consumed yet.
+	peeked *dotToken
+}
+
+// parse parses a graph and yields its elements.
+func (p *dotParser) parse(yield func(element, error) bool) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if tok.is("strict") {
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+	}
+	if !tok.is("digraph") {
+		return fmt.Errorf("expected digraph, got %v", tok)
+	}
+	if tok, err = p.next(); err != nil {
+		return err
+	}
+	if tok.kind == dotID {
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+	}
+	if tok.kind != '{' {
+		return fmt.Errorf("expected {, got %v", tok)
+	}
+
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case tok.kind == '}':
+			if tok, err := p.lex.next(); err != io.EOF {
+				if err != nil {
+					return err
+				}
+				return fmt.Errorf("unexpected %v after the graph", tok)
+			}
+			return nil
+		case tok.kind == ';':
+		case tok.is("graph"), tok.is("node"), tok.is("edge"):
+			if _, err := p.attrs(); err != nil {
+				return err
+			}
+		case tok.is("subgraph"), tok.kind == '{':
+			return errors.New("subgraphs are not supported")
+		case tok.kind == dotID:
+			if err := p.stmt(tok, yield); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected %v", tok)
+		}
+	}
+}
+
+// stmt parses the node, edge or attribute statement that starts with
+// the provided ID, and yields its elements.
+func (p *dotParser) stmt(first dotToken, yield func(element, error) bool) error {
+	tok, err := p.peek()
+	if err != nil {
+		return err
+	}
+	if tok.kind == '=' {
+		p.peeked = nil
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+		if tok.kind != dotID {
+			return fmt.Errorf("expected attribute value, got %v", tok)
+		}
+		return nil
+	}
+
+	ids := []string{first.text}
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok.kind != dotArrow {
+			break
+		}
+		p.peeked = nil
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+		if tok.kind != dotID {
+			return fmt.Errorf("expected node ID, got %v", tok)
+		}
+		ids = append(ids, tok.text)
+	}
+	attrs, err := p.attrs()
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]int64, len(ids))
+	for i, s := range ids {
+		if nodes[i], err = parseID(s); err != nil {
+			return err
+		}
+	}
+
+	if len(nodes) == 1 {
+		v := vertex{id: nodes[0], label: ids[0]}
+		for _, a := range attrs {
+			if err := setDOTNodeAttr(&v, a[0], a[1]); err != nil {
+				return err
+			}
+		}
+		if !yield(element{v: v}, nil) {
+			return errStop
+		}
+		return nil
+	}
+
+	var e edge
+	for _, a := range attrs {
+		if err := setDOTEdgeAttr(&e, a[0], a[1]); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < len(nodes); i++ {
+		e.tail, e.head = nodes[i-1], nodes[i]
+		if !yield(element{isEdge: true, e: e}, nil) {
+			return errStop
+		}
+	}
+	return nil
+}
+
+// attrs parses the optional attribute lists that follow a statement
+// and returns the key-value pairs.
+func (p *dotParser) attrs() ([][2]string, error) {
+	var attrs [][2]string
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind != '[' {
+			return attrs, nil
+		}
+		p.peeked = nil
+		for {
+			tok, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if tok.kind == ']' {
+				break
+			}
+			if tok.kind == ',' || tok.kind == ';' {
+				continue
+			}
+			if tok.kind != dotID {
+				return nil, fmt.Errorf("expected attribute name, got %v", tok)
+			}
+			eq, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if eq.kind != '=' {
+				return nil, fmt.Errorf("expected =, got %v", eq)
+			}
+			value, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			if value.kind != dotID {
+				return nil, fmt.Errorf("expected attribute value, got %v", value)
+			}
+			attrs = append(attrs, [2]string{tok.text, value.text})
+		}
+	}
+}
+
+// next returns the next token. It returns [io.ErrUnexpectedEOF] if
+// the input ends.
+func (p *dotParser) next() (dotToken, error) {
+	tok, err := p.peek()
+	p.peeked = nil
+	return tok, err
+}
+
+// peek returns the next token without consuming it. Like next, it
+// returns [io.ErrUnexpectedEOF] if the input ends.
+func (p *dotParser) peek() (dotToken, error) {
+	if p.peeked == nil {
+		tok, err := p.lex.next()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return dotToken{}, err
+		}
+		p.peeked = &tok
+	}
+	return *p.peeked, nil
+}
+
+// setDOTNodeAttr sets the node attribute key of v to value. Unknown
+// attributes are ignored.
+func setDOTNodeAttr(v *vertex, key, value string) error {
+	switch key {
+	case "label":
+		v.label = value
+	case "type":
+		v.vtype = value
+	case "community":
+		v.community = value
+	case "time":
+		t, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid time: %q", value)
+		}
+		v.time = t
+	case "lat", "lon":
+		x, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid coordinate: %q", value)
+		}
+		if key == "lat" {
+			v.lat = x
+		} else {
+			v.lon = x
+		}
+	}
+	return nil
+}
+
+// setDOTEdgeAttr sets the edge attribute key of e to value. Unknown
+// attributes are ignored.
+func setDOTEdgeAttr(e *edge, key, value string) error {
+	switch key {
+	case "label":
+		e.etype = value
+	case "time":
+		t, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid time: %q", value)
+		}
+		e.time = t
+	}
+	return nil
+}
+
+// Kinds of DOT tokens. Punctuation tokens are their own kind.
+const (
+	dotID    = -1 - iota // an ID, including keywords
+	dotArrow             // "->"
+)
+
+// A dotToken is a token of DOT.
+type dotToken struct {
+	kind int
+	text string
+}
+
+// is reports whether tok is the provided keyword. Keywords are case
+// insensitive.
+func (tok dotToken) is(kw string) bool {
+	return tok.kind == dotID && strings.EqualFold(tok.text, kw)
+}
+
+func (tok dotToken) String() string {
+	switch tok.kind {
+	case dotID:
+		return strconv.Quote(tok.text)
+	case dotArrow:
+		return "->"
+	}
+	return string(rune(tok.kind))
+}
+
+// A dotLexer splits DOT into tokens.
+type dotLexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+// next returns the next token. It returns [io.EOF] at the end of the
+// input.
+func (l *dotLexer) next() (dotToken, error) {
+	if err := l.skip(); err != nil {
+		return dotToken{}, err
+	}
+	c, err := l.r.ReadByte()
+	if err != nil {
+		return dotToken{}, err
+	}
+	switch {
+	case strings.IndexByte("{}[];,=", c) >= 0:
+		return dotToken{kind: int(c)}, nil
+	case c == '"':
+		return l.quoted()
+	case c == '<':
+		return l.html()
+	case c == '-':
+		next, err := l.r.ReadByte()
+		if err == nil && next == '>' {
+			return dotToken{kind: dotArrow}, nil
+		}
+		if err == nil && next == '-' {
+			return dotToken{}, errors.New("undirected edges are not supported")
+		}
+		if err == nil {
+			l.r.UnreadByte()
+		}
+	}
+	if !isDOTIDByte(c) && c != '-' {
+		return dotToken{}, fmt.Errorf("unexpected character: %q", c)
+	}
+	var sb strings.Builder
+	sb.WriteByte(c)
+	for {
+		c, err := l.r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return dotToken{}, err
+		}
+		if !isDOTIDByte(c) {
+			l.r.UnreadByte()
+			break
+		}
+		sb.WriteByte(c)
+	}
+	return dotToken{kind: dotID, text: sb.String()}, nil
+}
+
+// skip skips whitespace and comments.
+func (l *dotLexer) skip() error {
+	for {
+		c, err := l.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch {
+		case c == '\n':
+			l.line++
+		case c == ' ' || c == '\t' || c == '\r':
+		case c == '#':
+			if err := l.skipLine(); err != nil {
+				return err
+			}
+		case c == '/':
+			next, err := l.r.ReadByte()
+			if err != nil {
+				return fmt.Errorf("unexpected character: %q", c)
+			}
+			switch next {
+			case '/':
+				if err := l.skipLine(); err != nil {
+					return err
+				}
+			case '*':
+				if err := l.skipBlock(); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unexpected character: %q", c)
+			}
+		default:
+			return l.r.UnreadByte()
+		}
+	}
+}
+
+// skipLine skips the rest of the line.
+func (l *dotLexer) skipLine() error {
+	if _, err := l.r.ReadString('\n'); err != nil {
+		return err
+	}
+	l.line++
+	return nil
+}
+
+// skipBlock skips the rest of a block comment.
+func (l *dotLexer) skipBlock() error {
+	var prev byte
+	for {
+		c, err := l.r.ReadByte()
+		if err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		if c == '\n' {
+			l.line++
+		}
+		if prev == '*' && c == '/' {
+			return nil
+		}
+		prev = c
+	}
+}
+
+// quoted reads the rest of a quoted string. Escaped quotes and
+// backslashes are unescaped, escaped newlines are removed and other
+// escape sequences are kept, like Graphviz does.
+func (l *dotLexer) quoted() (dotToken, error) {
+	var sb strings.Builder
+	for {
+		c, err := l.r.ReadByte()
+		if err != nil {
+			return dotToken{}, io.ErrUnexpectedEOF
+		}
+		switch c {
+		case '"':
+			return dotToken{kind: dotID, text: sb.String()}, nil
+		case '\n':
+			l.line++
+		case '\\':
+			next, err := l.r.ReadByte()
+			if err != nil {
+				return dotToken{}, io.ErrUnexpectedEOF
+			}
+			switch next {
+			case '"', '\\':
+				c = next
+			case '\n':
+				l.line++
+				continue
+			default:
+				sb.WriteByte(c)
+				c = next
+			}
+		}
+		sb.WriteByte(c)
+	}
+}
+
+// html reads the rest of an HTML string, which is delimited by
+// balanced angle brackets.
+func (l *dotLexer) html() (dotToken, error) {
+	var sb strings.Builder
+	depth := 1
+	for {
+		c, err := l.r.ReadByte()
+		if err != nil {
+			return dotToken{}, io.ErrUnexpectedEOF
+		}
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			if depth--; depth == 0 {
+				return dotToken{kind: dotID, text: sb.String()}, nil
+			}
+		case '\n':
+			l.line++
+		}
+		sb.WriteByte(c)
+	}
+}
+
+// isDOTIDByte reports whether c can be part of an unquoted DOT ID.
+func isDOTIDByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 0x80 ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
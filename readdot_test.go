@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeDOT(t *testing.T) {
+	input := `/* mkdigraph -n=3 */
+// A comment.
+strict digraph "G" {
+	graph [rankdir=LR];
+	node [shape=box]
+	rankdir = LR
+	0 [label="A \"quoted\"", type="user", community="x y", time=10, lat=1.5, lon=-2];
+	1 [label=B color=red] [time=20]
+	2
+	# A preprocessor line.
+	0 -> 1 [label="FOLLOWS", time=25];
+	1 -> 2 -> 0
+}
+`
+	want := []element{
+		{v: vertex{id: 0, label: `A "quoted"`, vtype: "user", community: "x y", time: 10, lat: 1.5, lon: -2}},
+		{v: vertex{id: 1, label: "B", time: 20}},
+		{v: vertex{id: 2, label: "2"}},
+		{isEdge: true, e: edge{tail: 0, head: 1, etype: "FOLLOWS", time: 25}},
+		{isEdge: true, e: edge{tail: 1, head: 2}},
+		{isEdge: true, e: edge{tail: 2, head: 0}},
+	}
+
+	var got []element
+	for elem, err := range decodeGraph(strings.NewReader(input)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, elem)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected elements:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDecodeDOTRoundTrip(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A", vtype: "user", time: 1, lat: 40.5, lon: -3.5}, {id: 1, label: "two words", time: 2}}),
+		edges:    slices.Values([]edge{{tail: 0, head: 1, etype: "FOLLOWS", time: 3}, {tail: 1, head: 0, time: 4}}),
+	}
+	p := printer{meta: "mkdigraph -dot", times: true, coords: true}
+
+	dot := &bytes.Buffer{}
+	p.write(p.newEncoder(dot, true), g)
+
+	var elems []element
+	for elem, err := range decodeGraph(dot) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		elems = append(elems, elem)
+	}
+
+	want, got := &bytes.Buffer{}, &bytes.Buffer{}
+	p.meta = ""
+	p.write(p.newEncoder(want, false), g)
+	enc := p.newEncoder(got, false)
+	for _, elem := range elems {
+		if elem.isEdge {
+			enc.edge(elem.e)
+		} else {
+			enc.vertex(elem.v)
+		}
+	}
+	if got.String() != want.String() {
+		t.Errorf("unexpected output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestDecodeDOTBackslash(t *testing.T) {
+	labels := []string{`a\`, `\`, `a\"b`, `a\nb`}
+	var vertices []vertex
+	for i, label := range labels {
+		vertices = append(vertices, vertex{id: int64(i), label: label})
+	}
+	g := graph{vertices: slices.Values(vertices), edges: slices.Values([]edge(nil))}
+
+	dot := &bytes.Buffer{}
+	p := printer{}
+	p.write(p.newEncoder(dot, true), g)
+
+	var got []string
+	for elem, err := range decodeGraph(dot) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, elem.v.label)
+	}
+	if !slices.Equal(got, labels) {
+		t.Errorf("unexpected labels: got: %q, want: %q", got, labels)
+	}
+}
+
+func TestDecodeDOTInvalid(t *testing.T) {
+	invalid := []string{
+		"digraph {",
+		"digraph { 0 -> }",
+		"digraph { a }",
+		"digraph { 0 [label] }",
+		"digraph { 0 [time=x] }",
+		"digraph { subgraph { 0 } }",
+		"digraph { 0 -- 1 }",
+		"digraph { 0:p -> 1 }",
+		"digraph { \"0 }",
+		"digraph { /* 0 }",
+		"digraph { } 0",
+		"graph { 0 }",
+	}
+	for _, input := range invalid {
+		var err error
+		for _, err = range decodeGraph(strings.NewReader(input)) {
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestDecodeGraphSimple(t *testing.T) {
+	input := "# digraph {\nV: 0 digraph\nE: 0 0\n"
+	n := 0
+	for _, err := range decodeGraph(strings.NewReader(input)) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("unexpected number of elements: %v", n)
+	}
+}
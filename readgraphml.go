@@ -0,0 +1,252 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// decodeGraphML returns an iterator over the elements of the graph
+// read from r in GraphML. Only the first graph of the document is
+// read, and it must be directed. Nested graphs, hyperedges and ports
+// are not supported.
+//
+// If the first node ID of the document is a vertex ID, all of them must
+// be vertex IDs and they are kept. Otherwise, like the IDs written by
+// yEd, nodes are numbered in order of appearance, including the nodes
+// that are only referenced by edges, and labeled with their IDs. The
+// node attributes named label, type, community, time, lat and lon,
+// and the edge attributes named label or type, and time, are read.
+// Other attributes are ignored. Iteration stops after the first
+// error, which is yielded along with a zero element.
+func decodeGraphML(r io.Reader) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		d := &graphMLDecoder{
+			dec:  xml.NewDecoder(r),
+			keys: make(map[string]string),
+		}
+		if err := d.decode(yield); err != nil && err != errStop {
+			line, _ := d.dec.InputPos()
+			yield(element{}, fmt.Errorf("line %v: %w", line, err))
+		}
+	}
+}
+
+// A graphMLDecoder decodes GraphML documents.
+type graphMLDecoder struct {
+	dec *xml.Decoder
+
+	// keys maps the IDs of the keys of the document to the names
+	// of their attributes, prefixed by "node." or "edge.".
+	keys map[string]string
+
+	// ids maps the node IDs to vertex IDs if nodes are numbered in
+	// order of appearance.
+	ids map[string]int64
+
+	// numeric reports whether the node IDs are vertex IDs. It is
+	// set by the first node ID, after which seen is true.
+	numeric, seen bool
+}
+
+// graphMLKey is a key element.
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+}
+
+// graphMLData is a data element.
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLNode is a node element.
+type graphMLNode struct {
+	ID    string        `xml:"id,attr"`
+	Data  []graphMLData `xml:"data"`
+	Graph *struct{}     `xml:"graph"`
+	Port  *struct{}     `xml:"port"`
+}
+
+// graphMLEdge is an edge element.
+type graphMLEdge struct {
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed string        `xml:"directed,attr"`
+	Ports    string        `xml:"sourceport,attr"`
+	Data     []graphMLData `xml:"data"`
+}
+
+// decode decodes the document and yields the elements of its first
+// graph.
+func (d *graphMLDecoder) decode(yield func(element, error) bool) error {
+	var (
+		depth    int    // depth of the current element
+		graph    int    // depth of the graph, 0 if outside it
+		directed bool   // default directedness of the edges
+		done     bool   // whether the first graph has been read
+		root     string // name of the root element
+	)
+	for {
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			if root != "graphml" {
+				return errors.New("missing graphml element")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				root = t.Name.Local
+				if root != "graphml" {
+					return fmt.Errorf("unexpected root element: %q", root)
+				}
+				continue
+			}
+			switch {
+			case t.Name.Local == "key" && depth == 2:
+				var k graphMLKey
+				if err := d.dec.DecodeElement(&k, &t); err != nil {
+					return err
+				}
+				depth--
+				d.keys[k.ID] = k.For + "." + k.Name
+			case t.Name.Local == "graph" && graph == 0 && !done:
+				graph = depth
+				directed = attr(t, "edgedefault") == "directed"
+			case t.Name.Local == "node" && depth == graph+1:
+				var n graphMLNode
+				if err := d.dec.DecodeElement(&n, &t); err != nil {
+					return err
+				}
+				depth--
+				if n.Graph != nil || n.Port != nil {
+					return errors.New("nested graphs and ports are not supported")
+				}
+				if err := d.node(n, yield); err != nil {
+					return err
+				}
+			case t.Name.Local == "edge" && depth == graph+1:
+				var e graphMLEdge
+				if err := d.dec.DecodeElement(&e, &t); err != nil {
+					return err
+				}
+				depth--
+				if !(e.Directed == "true" || e.Directed == "" && directed) {
+					return errors.New("undirected edges are not supported")
+				}
+				if e.Ports != "" {
+					return errors.New("nested graphs and ports are not supported")
+				}
+				if err := d.edge(e, yield); err != nil {
+					return err
+				}
+			case t.Name.Local == "hyperedge" && depth == graph+1:
+				return errors.New("hyperedges are not supported")
+			}
+		case xml.EndElement:
+			if depth == graph {
+				graph, done = 0, true
+			}
+			depth--
+		}
+	}
+}
+
+// node yields the vertex of n.
+func (d *graphMLDecoder) node(n graphMLNode, yield func(element, error) bool) error {
+	id, err := d.vertexID(n.ID)
+	if err != nil {
+		return err
+	}
+	v := vertex{id: id, label: n.ID}
+	for _, data := range n.Data {
+		key, ok := strings.CutPrefix(d.keys[data.Key], "node.")
+		if !ok {
+			continue
+		}
+		if err := setDOTNodeAttr(&v, key, strings.TrimSpace(data.Value)); err != nil {
+			return err
+		}
+	}
+	if !yield(element{v: v}, nil) {
+		return errStop
+	}
+	return nil
+}
+
+// edge yields the edge of e.
+func (d *graphMLDecoder) edge(e graphMLEdge, yield func(element, error) bool) error {
+	tail, err := d.vertexID(e.Source)
+	if err != nil {
+		return err
+	}
+	head, err := d.vertexID(e.Target)
+	if err != nil {
+		return err
+	}
+	ed := edge{tail: tail, head: head}
+	for _, data := range e.Data {
+		key, ok := strings.CutPrefix(d.keys[data.Key], "edge.")
+		if !ok {
+			continue
+		}
+		if key == "type" {
+			key = "label"
+		}
+		if err := setDOTEdgeAttr(&ed, key, strings.TrimSpace(data.Value)); err != nil {
+			return err
+		}
+	}
+	if !yield(element{isEdge: true, e: ed}, nil) {
+		return errStop
+	}
+	return nil
+}
+
+// vertexID returns the vertex ID of the provided node ID.
+func (d *graphMLDecoder) vertexID(s string) (int64, error) {
+	if !d.seen {
+		d.seen = true
+		if _, err := parseID(s); err == nil {
+			d.numeric = true
+		} else {
+			d.ids = make(map[string]int64)
+		}
+	}
+	if d.numeric {
+		return parseID(s)
+	}
+	id, ok := d.ids[s]
+	if !ok {
+		id = int64(len(d.ids))
+		d.ids[s] = id
+	}
+	return id, nil
+}
+
+// attr returns the value of the attribute of t with the provided
+// name, or the empty string if it is missing.
+func attr(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeGraphML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []element
+	}{
+		{
+			name: "numeric",
+			input: `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"/>
+  <key id="d1" for="node" attr.name="time" attr.type="long"/>
+  <key id="d2" for="edge" attr.name="type" attr.type="string"/>
+  <key id="d3" for="node" attr.name="color" attr.type="string"/>
+  <graph id="G" edgedefault="directed">
+    <node id="0"><data key="d0">A</data><data key="d1">10</data></node>
+    <node id="1"><data key="d3">red</data></node>
+    <edge source="0" target="1"><data key="d2">FOLLOWS</data></edge>
+    <edge source="1" target="0" directed="true"/>
+  </graph>
+</graphml>
+`,
+			want: []element{
+				{v: vertex{id: 0, label: "A", time: 10}},
+				{v: vertex{id: 1, label: "1"}},
+				{isEdge: true, e: edge{tail: 0, head: 1, etype: "FOLLOWS"}},
+				{isEdge: true, e: edge{tail: 1, head: 0}},
+			},
+		},
+		{
+			name: "named",
+			input: `<graphml>
+  <graph edgedefault="undirected">
+    <node id="n0"/>
+    <edge source="n0" target="n2" directed="true"/>
+    <node id="n1"/>
+    <node id="n2"/>
+  </graph>
+  <graph edgedefault="directed">
+    <node id="n3"/>
+  </graph>
+</graphml>
+`,
+			want: []element{
+				{v: vertex{id: 0, label: "n0"}},
+				{isEdge: true, e: edge{tail: 0, head: 1}},
+				{v: vertex{id: 2, label: "n1"}},
+				{v: vertex{id: 1, label: "n2"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		var got []element
+		for elem, err := range decodeGraph(strings.NewReader(tt.input)) {
+			if err != nil {
+				t.Fatalf("%v: unexpected error: %v", tt.name, err)
+			}
+			got = append(got, elem)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%v: unexpected elements:\ngot:  %v\nwant: %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeGraphMLInvalid(t *testing.T) {
+	invalid := []string{
+		`<graph edgedefault="directed"/>`,
+		`<graphml><graph edgedefault="undirected"><node id="0"/><node id="1"/><edge source="0" target="1"/></graph></graphml>`,
+		`<graphml><graph edgedefault="directed"><node id="0"/><node id="a"/></graph></graphml>`,
+		`<graphml><graph edgedefault="directed"><node id="0"><graph/></node></graph></graphml>`,
+		`<graphml><graph edgedefault="directed"><hyperedge/></graph></graphml>`,
+		`<graphml><key id="d0" for="node" attr.name="time"/><graph edgedefault="directed"><node id="0"><data key="d0">x</data></node></graph></graphml>`,
+		`<graphml><graph edgedefault="directed"><node id="0">`,
+	}
+	for _, input := range invalid {
+		var err error
+		for _, err = range decodeGraph(strings.NewReader(input)) {
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
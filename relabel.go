@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+)
+
+// readMapping reads a vertex mapping file. Every line contains the
+// old and the new ID of a vertex separated by whitespace. Empty lines
+// and lines starting with "#" are ignored. Neither old nor new IDs can
+// be repeated.
+func readMapping(name string) (map[int64]int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	mapping := make(map[int64]int64)
+	used := make(map[int64]struct{})
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%v:%v: malformed line: %q", name, n, line)
+		}
+		from, err := parseID(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", name, n, err)
+		}
+		to, err := parseID(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", name, n, err)
+		}
+		if _, ok := mapping[from]; ok {
+			return nil, fmt.Errorf("%v:%v: duplicated vertex: %v", name, n, from)
+		}
+		if _, ok := used[to]; ok {
+			return nil, fmt.Errorf("%v:%v: duplicated new ID: %v", name, n, to)
+		}
+		mapping[from] = to
+		used[to] = struct{}{}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+// relabelElements returns the elements of seq with their vertex IDs
+// replaced by fn. Iteration stops after the first error returned by
+// fn, which is yielded along with a zero element.
+func relabelElements(seq iter.Seq2[element, error], fn func(id int64) (int64, error)) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		for elem, err := range seq {
+			if err != nil {
+				yield(elem, err)
+				return
+			}
+			var ids []*int64
+			if elem.isEdge {
+				ids = []*int64{&elem.e.tail, &elem.e.head}
+			} else {
+				ids = []*int64{&elem.v.id}
+			}
+			for _, id := range ids {
+				if *id, err = fn(*id); err != nil {
+					yield(element{}, err)
+					return
+				}
+			}
+			if !yield(elem, nil) {
+				return
+			}
+		}
+	}
+}
+
+// denseIDs returns a function that numbers vertices from 0 in order
+// of first appearance. If perm is not nil, the i-th vertex is
+// numbered perm[i] instead.
+func denseIDs(perm []int) func(id int64) (int64, error) {
+	ids := make(map[int64]int64)
+	return func(id int64) (int64, error) {
+		n, ok := ids[id]
+		if !ok {
+			n = int64(len(ids))
+			ids[id] = n
+		}
+		if perm != nil {
+			return int64(perm[n]), nil
+		}
+		return n, nil
+	}
+}
+
+// mappedIDs returns a function that renumbers vertices following
+// mapping. It fails for the vertices missing from mapping.
+func mappedIDs(mapping map[int64]int64) func(id int64) (int64, error) {
+	return func(id int64) (int64, error) {
+		n, ok := mapping[id]
+		if !ok {
+			return 0, fmt.Errorf("unmapped vertex: %v", id)
+		}
+		return n, nil
+	}
+}
+
+func runRelabel(args []string) {
+	fs := flag.NewFlagSet("relabel", flag.ExitOnError)
+	shuffle := fs.Bool("shuffle", false, "shuffle the new vertex IDs")
+	seed := fs.Uint64("seed", 0, "seed of the shuffle (0 means random)")
+	mapFile := fs.String("map", "", "read the new vertex IDs from a mapping `file`")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "relabel [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *shuffle && *mapFile != "" {
+		fatal(fmt.Errorf("-shuffle cannot be combined with -map"))
+	}
+
+	seq := decodeGraph(in)
+	var fn func(id int64) (int64, error)
+	switch {
+	case *mapFile != "":
+		mapping, err := readMapping(*mapFile)
+		if err != nil {
+			fatal(err)
+		}
+		fn = mappedIDs(mapping)
+	case *shuffle:
+		// The number of vertices must be known before
+		// shuffling them, so the graph is read into memory and
+		// written with the vertices first.
+		d, err := loadGraph(seq)
+		if err != nil {
+			fatal(err)
+		}
+		ids := make(map[int64]struct{})
+		for _, v := range d.vertices {
+			ids[v.id] = struct{}{}
+		}
+		for _, e := range d.edges {
+			ids[e.tail] = struct{}{}
+			ids[e.head] = struct{}{}
+		}
+		r := newRNG(rngPCG, *seed)
+		fn = denseIDs(r.Perm(len(ids)))
+		seq = d.elements()
+	default:
+		fn = denseIDs(nil)
+	}
+
+	err := of.write(func(enc encoder) error {
+		return encodeElements(enc, relabelElements(seq, fn))
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
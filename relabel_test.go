@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRelabelElements(t *testing.T) {
+	const input = "V: 10 A\nE: 10 30\nV: 30 B\nE: 30 20\n"
+	tests := []struct {
+		name string
+		fn   func(id int64) (int64, error)
+		want string
+	}{
+		{
+			name: "dense",
+			fn:   denseIDs(nil),
+			want: "V: 0 A\nE: 0 1\nV: 1 B\nE: 1 2\n",
+		},
+		{
+			name: "perm",
+			fn:   denseIDs([]int{2, 0, 1}),
+			want: "V: 2 A\nE: 2 0\nV: 0 B\nE: 0 1\n",
+		},
+		{
+			name: "mapping",
+			fn:   mappedIDs(map[int64]int64{10: 1, 20: 2, 30: 3}),
+			want: "V: 1 A\nE: 1 3\nV: 3 B\nE: 3 2\n",
+		},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		for elem, err := range relabelElements(decodeSimple(strings.NewReader(input)), tt.fn) {
+			if err != nil {
+				t.Fatalf("%v: unexpected error: %v", tt.name, err)
+			}
+			if elem.isEdge {
+				b.WriteString("E: " + formatID(elem.e.tail, 0) + " " + formatID(elem.e.head, 0) + "\n")
+			} else {
+				b.WriteString("V: " + formatID(elem.v.id, 0) + " " + elem.v.label + "\n")
+			}
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%v: got: %q want: %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRelabelElementsUnmapped(t *testing.T) {
+	seq := relabelElements(decodeSimple(strings.NewReader("V: 0 A\nE: 0 1\n")), mappedIDs(map[int64]int64{0: 5}))
+	var err error
+	for _, err = range seq {
+		if err != nil {
+			break
+		}
+	}
+	if err == nil || err.Error() != "unmapped vertex: 1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReadMapping(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr bool
+	}{
+		{data: "# old new\n0 2\n1 0\n\n2 1\n", wantErr: false},
+		{data: "0 1\n0 2\n", wantErr: true},
+		{data: "0 1\n2 1\n", wantErr: true},
+		{data: "0\n", wantErr: true},
+		{data: "0 x\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		name := filepath.Join(t.TempDir(), "map.txt")
+		if err := os.WriteFile(name, []byte(tt.data), 0o644); err != nil {
+			t.Fatalf("could not write file: %v", err)
+		}
+		mapping, err := readMapping(name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.data, err)
+		}
+		if err == nil && mapping[0] != 2 {
+			t.Errorf("%q: unexpected mapping: %v", tt.data, mapping)
+		}
+	}
+}
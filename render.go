@@ -0,0 +1,322 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+)
+
+// defaultMaxRender is the default maximum number of vertices of the
+// graphs drawn by the embedded layouts of the render command.
+const defaultMaxRender = 5000
+
+// forceIterations is the number of iterations of the force-directed
+// layout.
+const forceIterations = 200
+
+// graphvizLayouts are the Graphviz layout programs supported by the
+// render command.
+var graphvizLayouts = []string{"dot", "neato", "fdp", "sfdp", "circo", "twopi"}
+
+// embeddedLayouts are the layouts of the render command that do not
+// depend on Graphviz. They return the position of every vertex of d
+// in the order of ids.
+var embeddedLayouts = map[string]func(d *graphData, ids []int64) []point{
+	"circle": func(_ *graphData, ids []int64) []point { return circleLayout(len(ids)) },
+	"force":  forceLayout,
+}
+
+// Dimensions of the drawings of the embedded layouts, in pixels.
+const (
+	svgVertexRadius = 12
+	svgVertexGap    = 48
+	svgMargin       = 8
+)
+
+// A point is the position of a vertex in a drawing.
+type point struct {
+	x, y float64
+}
+
+// renderGraphviz draws d with the Graphviz layout program layout and
+// writes the image in format to w.
+func renderGraphviz(w io.Writer, d *graphData, layout, format string) error {
+	var dot bytes.Buffer
+	printer{}.writeDOT(&dot, d.graph())
+
+	cmd := exec.Command(layout, "-T"+format)
+	cmd.Stdin = &dot
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%v: %w", layout, err)
+	}
+	return nil
+}
+
+// circleLayout places n vertices evenly spaced on a circle, clockwise
+// from the top, leaving svgVertexGap pixels between the centers of
+// consecutive vertices.
+func circleLayout(n int) []point {
+	r := max(svgVertexGap*float64(n)/(2*math.Pi), svgVertexGap)
+	pos := make([]point, n)
+	for i := range pos {
+		a := 2*math.Pi*float64(i)/float64(n) - math.Pi/2
+		pos[i] = point{x: r * math.Cos(a), y: r * math.Sin(a)}
+	}
+	return pos
+}
+
+// forceLayout places the vertices of d, in the order of ids, with the
+// force-directed algorithm of Fruchterman and Reingold, ignoring the
+// direction of the edges. Adjacent vertices attract each other and
+// all vertices repel each other, so that adjacent vertices end up
+// about svgVertexGap pixels apart. Repulsion is only computed between
+// vertices closer than twice that distance, using a grid, which keeps
+// every iteration linear in the size of the graph for evenly spread
+// vertices. The layout is deterministic: vertices start on a spiral in
+// the order of ids.
+func forceLayout(d *graphData, ids []int64) []point {
+	const k = svgVertexGap
+
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	// The golden angle spreads the vertices evenly on a disk of
+	// about the final area of the drawing.
+	golden := math.Pi * (3 - math.Sqrt(5))
+	pos := make([]point, len(ids))
+	for i := range pos {
+		r := k * math.Sqrt(float64(i)+0.5)
+		a := golden * float64(i)
+		pos[i] = point{x: r * math.Cos(a), y: r * math.Sin(a)}
+	}
+
+	disp := make([]point, len(ids))
+	temp := k * math.Sqrt(float64(len(ids))) / 10
+	for it := range forceIterations {
+		clear(disp)
+
+		grid := make(map[[2]int][]int)
+		cell := func(p point) [2]int {
+			return [2]int{int(math.Floor(p.x / (2 * k))), int(math.Floor(p.y / (2 * k)))}
+		}
+		for i, p := range pos {
+			c := cell(p)
+			grid[c] = append(grid[c], i)
+		}
+		for i, p := range pos {
+			c := cell(p)
+			for cx := c[0] - 1; cx <= c[0]+1; cx++ {
+				for cy := c[1] - 1; cy <= c[1]+1; cy++ {
+					for _, j := range grid[[2]int{cx, cy}] {
+						if j == i {
+							continue
+						}
+						dx, dy := p.x-pos[j].x, p.y-pos[j].y
+						dist := math.Hypot(dx, dy)
+						if dist == 0 {
+							// Separate coincident vertices in a
+							// direction that depends on their
+							// order.
+							dx, dy, dist = float64(i-j), 0, math.Abs(float64(i-j))
+						}
+						if dist >= 2*k {
+							continue
+						}
+						f := k * k / dist / dist
+						disp[i].x += dx * f
+						disp[i].y += dy * f
+					}
+				}
+			}
+		}
+
+		for _, e := range d.edges {
+			t, h := index[e.tail], index[e.head]
+			if t == h {
+				continue
+			}
+			dx, dy := pos[t].x-pos[h].x, pos[t].y-pos[h].y
+			f := math.Hypot(dx, dy) / k
+			disp[t].x -= dx * f
+			disp[t].y -= dy * f
+			disp[h].x += dx * f
+			disp[h].y += dy * f
+		}
+
+		// The displacement is capped by a temperature that cools
+		// down linearly.
+		t := temp * float64(forceIterations-it) / forceIterations
+		for i, dp := range disp {
+			l := math.Hypot(dp.x, dp.y)
+			if l == 0 {
+				continue
+			}
+			s := min(l, t) / l
+			pos[i].x += dp.x * s
+			pos[i].y += dp.y * s
+		}
+	}
+	return pos
+}
+
+// writeSVG draws d as an SVG image and writes it to w. Vertex ids[i]
+// is placed at pos[i]. Vertices are drawn as labeled circles and
+// edges as arrows between them. Loops are drawn as circles above
+// their vertex and multiple edges overlap.
+func writeSVG(w io.Writer, d *graphData, ids []int64, pos []point) error {
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = strconv.FormatInt(id, 10)
+	}
+	for _, v := range d.vertices {
+		if v.label != "" {
+			labels[index[v.id]] = v.label
+		}
+	}
+
+	// Translate the drawing so that it starts at the margin, leaving
+	// room for the loops above the vertices.
+	minX, minY, maxX, maxY := 0.0, 0.0, 0.0, 0.0
+	if len(pos) > 0 {
+		minX, minY, maxX, maxY = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	}
+	for _, p := range pos {
+		minX, minY = min(minX, p.x), min(minY, p.y)
+		maxX, maxY = max(maxX, p.x), max(maxY, p.y)
+	}
+	pad := float64(svgMargin + 2*svgVertexRadius)
+	dx, dy := pad-minX, pad-minY
+	width, height := maxX-minX+2*pad, maxY-minY+2*pad
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n", width, height, width, height)
+	fmt.Fprintln(bw, "<defs>")
+	fmt.Fprintln(bw, "\t<marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"6\" markerHeight=\"6\" orient=\"auto\">")
+	fmt.Fprintln(bw, "\t\t<path d=\"M0,0 L10,5 L0,10 z\"/>")
+	fmt.Fprintln(bw, "\t</marker>")
+	fmt.Fprintln(bw, "</defs>")
+	fmt.Fprintln(bw, "<rect width=\"100%\" height=\"100%\" fill=\"white\"/>")
+
+	fmt.Fprintln(bw, "<g stroke=\"black\" fill=\"none\">")
+	for _, e := range d.edges {
+		t, h := pos[index[e.tail]], pos[index[e.head]]
+		if e.tail == e.head {
+			fmt.Fprintf(bw, "\t<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%v\"/>\n", t.x+dx, t.y+dy-svgVertexRadius, svgVertexRadius)
+			continue
+		}
+		// The arrow goes from border to border of the vertices.
+		l := math.Hypot(h.x-t.x, h.y-t.y)
+		if l <= 2*svgVertexRadius {
+			continue
+		}
+		ux, uy := (h.x-t.x)/l*svgVertexRadius, (h.y-t.y)/l*svgVertexRadius
+		fmt.Fprintf(bw, "\t<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" marker-end=\"url(#arrow)\"/>\n",
+			t.x+ux+dx, t.y+uy+dy, h.x-ux+dx, h.y-uy+dy)
+	}
+	fmt.Fprintln(bw, "</g>")
+
+	fmt.Fprintln(bw, "<g font-family=\"sans-serif\" font-size=\"10\" text-anchor=\"middle\" dominant-baseline=\"central\">")
+	for i, p := range pos {
+		fmt.Fprintf(bw, "\t<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%v\" fill=\"white\" stroke=\"black\"/>\n", p.x+dx, p.y+dy, svgVertexRadius)
+		fmt.Fprintf(bw, "\t<text x=\"%.1f\" y=\"%.1f\">%v</text>\n", p.x+dx, p.y+dy, html.EscapeString(labels[i]))
+	}
+	fmt.Fprintln(bw, "</g>")
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func runRender(args []string) {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	layout := fs.String("layout", "dot", "`layout` (dot, neato, fdp, sfdp, circo, twopi, circle, force)")
+	format := fs.String("T", "svg", "image `format`")
+	outFile := fs.String("o", "", "output file")
+	maxVertices := fs.Int("max-vertices", 0, "maximum number of `vertices` drawn by the embedded layouts (default 5000, or 30 with -T ascii)")
+	fs.Usage = commandUsage(fs, "render [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	ascii := *format == "ascii"
+	if *maxVertices == 0 {
+		*maxVertices = defaultMaxRender
+		if ascii {
+			*maxVertices = defaultMaxASCII
+		}
+	}
+
+	layoutFn, embedded := embeddedLayouts[*layout]
+	if !embedded && !slices.Contains(graphvizLayouts, *layout) {
+		fatal(fmt.Errorf("unknown layout: %v", *layout))
+	}
+	switch {
+	case ascii:
+		// ASCII drawings have a layout of their own.
+	case *format == "html":
+		// Graphviz does not write HTML pages, so the graph is
+		// laid out by the force layout unless another embedded
+		// layout is selected.
+		if !embedded {
+			layoutFn, embedded = forceLayout, true
+		}
+	case !embedded:
+		if _, err := exec.LookPath(*layout); err != nil {
+			if *format != "svg" {
+				fatal(fmt.Errorf("graphviz not found: %w", err))
+			}
+			warn("graphviz not found, using the force layout", "layout", *layout)
+			layoutFn, embedded = forceLayout, true
+		}
+	case *format != "svg":
+		fatal(fmt.Errorf("unsupported format for the %v layout: %v", *layout, *format))
+	}
+
+	d, err := loadGraph(decodeGraph(in))
+	if err != nil {
+		fatal(err)
+	}
+	ids := d.vertexIDs()
+	if (embedded || ascii) && len(ids) > *maxVertices {
+		fatal(fmt.Errorf("graph too large: %v vertices (max %v)", len(ids), *maxVertices))
+	}
+
+	out, err := createOutput(*outFile, false, false)
+	if err != nil {
+		fatal(err)
+	}
+	switch {
+	case ascii:
+		err = writeASCII(out, d, ids)
+	case *format == "html":
+		err = writeHTML(out, d, ids, layoutFn(d, ids))
+	case embedded:
+		err = writeSVG(out, d, ids, layoutFn(d, ids))
+	default:
+		err = renderGraphviz(out, d, *layout, *format)
+	}
+	if err != nil {
+		out.abort()
+		fatal(err)
+	}
+	if err := out.commit(); err != nil {
+		fatal(err)
+	}
+}
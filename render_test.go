@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCircleLayout(t *testing.T) {
+	pos := circleLayout(4)
+	if len(pos) != 4 {
+		t.Fatalf("unexpected number of positions: got: %v want: 4", len(pos))
+	}
+	r := math.Hypot(pos[0].x, pos[0].y)
+	for i, p := range pos {
+		if d := math.Hypot(p.x, p.y); math.Abs(d-r) > 1e-9 {
+			t.Errorf("position %v off the circle: got: %v want: %v", i, d, r)
+		}
+	}
+	if math.Abs(pos[0].x) > 1e-9 || pos[0].y >= 0 {
+		t.Errorf("first vertex not on top: %v", pos[0])
+	}
+	if pos[1].x <= 0 {
+		t.Errorf("vertices not placed clockwise: %v", pos[1])
+	}
+}
+
+func TestForceLayout(t *testing.T) {
+	// A path and an isolated vertex.
+	const input = "V: 4 A\nE: 0 1\nE: 1 2\nE: 2 3\nE: 3 3\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := d.vertexIDs()
+	pos := forceLayout(d, ids)
+	if len(pos) != len(ids) {
+		t.Fatalf("unexpected number of positions: got: %v want: %v", len(pos), len(ids))
+	}
+
+	dist := func(i, j int) float64 { return math.Hypot(pos[i].x-pos[j].x, pos[i].y-pos[j].y) }
+	for i := range pos {
+		for j := range i {
+			if d := dist(i, j); d < svgVertexGap/2 {
+				t.Errorf("vertices %v and %v too close: %v", ids[i], ids[j], d)
+			}
+		}
+	}
+	for i := range 3 {
+		if d := dist(i, i+1); d > 2*svgVertexGap {
+			t.Errorf("adjacent vertices %v and %v too far: %v", ids[i], ids[i+1], d)
+		}
+	}
+
+	if again := forceLayout(d, ids); !slices.Equal(again, pos) {
+		t.Errorf("layout is not deterministic: got: %v want: %v", again, pos)
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	const input = "V: 0 <A>\nV: 1 B\nE: 0 1\nE: 1 2\nE: 2 2\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := d.vertexIDs()
+
+	var buf bytes.Buffer
+	if err := writeSVG(&buf, d, ids, circleLayout(len(ids))); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	var texts []string
+	dec := xml.NewDecoder(&buf)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			counts[tok.Name.Local]++
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				texts = append(texts, s)
+			}
+		}
+	}
+
+	// A circle per vertex plus one for the loop.
+	if counts["circle"] != 4 {
+		t.Errorf("unexpected number of circles: got: %v want: 4", counts["circle"])
+	}
+	if counts["line"] != 2 {
+		t.Errorf("unexpected number of lines: got: %v want: 2", counts["line"])
+	}
+	if got, want := strings.Join(texts, " "), "<A> B 2"; got != want {
+		t.Errorf("unexpected labels: got: %q want: %q", got, want)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+)
+
+// An rngAlgorithm is a pseudo-random number generator algorithm.
+type rngAlgorithm int
+
+// Supported pseudo-random number generator algorithms.
+const (
+	rngPCG rngAlgorithm = iota
+	rngChaCha8
+	rngMT19937
+)
+
+// parseRNG parses the value of the -rng flag.
+func parseRNG(s string) (rngAlgorithm, error) {
+	switch s {
+	case "pcg":
+		return rngPCG, nil
+	case "chacha8":
+		return rngChaCha8, nil
+	case "mt19937":
+		return rngMT19937, nil
+	}
+	return 0, fmt.Errorf("unknown random number generator: %q", s)
+}
+
+// source returns a source of the algorithm seeded with seed and
+// stream. Different streams of the same seed are independent.
+//
+// The MT19937 source of stream 0 and a seed lower than 2^32 is
+// seeded like the reference implementation does with init_genrand.
+// Otherwise, it is seeded with init_by_array with the 32-bit halves of
+// seed and stream.
+func (a rngAlgorithm) source(seed, stream uint64) rand.Source {
+	switch a {
+	case rngChaCha8:
+		var b [32]byte
+		binary.LittleEndian.PutUint64(b[0:], seed)
+		binary.LittleEndian.PutUint64(b[8:], stream)
+		return rand.NewChaCha8(b)
+	case rngMT19937:
+		mt := &mt19937{}
+		if stream == 0 && seed <= 1<<32-1 {
+			mt.seed(uint32(seed))
+		} else {
+			mt.seedArray([]uint32{uint32(seed), uint32(seed >> 32), uint32(stream), uint32(stream >> 32)})
+		}
+		return mt
+	}
+	return rand.NewPCG(seed, stream)
+}
+
+// newRNG returns a pseudo-random number generator of the provided
+// algorithm seeded with seed. If seed is 0, it is randomly seeded.
+func newRNG(algo rngAlgorithm, seed uint64) *rand.Rand {
+	if seed == 0 {
+		return rand.New(algo.source(rand.Uint64(), rand.Uint64()))
+	}
+	return rand.New(algo.source(seed, 0))
+}
+
+// vertexRNG returns a pseudo-random number generator for the vertex
+// with the provided ID. It only depends on algo, seed and id, so the
+// randomness of a vertex does not depend on the previous vertices.
+func vertexRNG(algo rngAlgorithm, seed uint64, id int64) *rand.Rand {
+	return rand.New(algo.source(seed, mix64(uint64(id))))
+}
+
+// Salts of [vertexHash], which make the attributes of a vertex
+// independent of each other.
+const (
+	saltTime uint64 = iota + 1
+	saltCoords
+	saltType
+	saltEdgeType
+	saltWords
+	saltLabel
+)
+
+// vertexHash returns a pseudo-random number for the vertex with the
+// provided ID. It only depends on seed, id and salt. It is cheaper
+// than [vertexRNG] when a few random bits are needed. For a given seed
+// and salt, it is a permutation of the IDs.
+func vertexHash(seed uint64, id int64, salt uint64) uint64 {
+	return mix64(mix64(seed+salt) ^ mix64(uint64(id)))
+}
+
+// shuffleWords shuffles words with a pseudo-random number generator
+// that only depends on seed and stream. Every words file of a
+// generation uses its own stream.
+func shuffleWords(words []string, seed, stream uint64) {
+	r := rand.New(rand.NewPCG(mix64(seed+saltWords), stream))
+	r.Shuffle(len(words), func(i, j int) {
+		words[i], words[j] = words[j], words[i]
+	})
+}
+
+// mtN is the state size of MT19937 in 32-bit words.
+const mtN = 624
+
+// An mt19937 is a 32-bit Mersenne Twister. Its 64-bit outputs are
+// made of two consecutive 32-bit outputs, the first one being the
+// most significant, like NumPy does.
+type mt19937 struct {
+	mt [mtN]uint32
+	i  int
+}
+
+// seed implements init_genrand.
+func (m *mt19937) seed(s uint32) {
+	m.mt[0] = s
+	for i := 1; i < mtN; i++ {
+		m.mt[i] = 1812433253*(m.mt[i-1]^(m.mt[i-1]>>30)) + uint32(i)
+	}
+	m.i = mtN
+}
+
+// seedArray implements init_by_array.
+func (m *mt19937) seedArray(key []uint32) {
+	m.seed(19650218)
+	i, j := 1, 0
+	for k := max(mtN, len(key)); k > 0; k-- {
+		m.mt[i] = (m.mt[i] ^ ((m.mt[i-1] ^ (m.mt[i-1] >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= mtN {
+			m.mt[0] = m.mt[mtN-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k := mtN - 1; k > 0; k-- {
+		m.mt[i] = (m.mt[i] ^ ((m.mt[i-1] ^ (m.mt[i-1] >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= mtN {
+			m.mt[0] = m.mt[mtN-1]
+			i = 1
+		}
+	}
+	m.mt[0] = 0x80000000
+}
+
+// uint32 returns the next 32-bit output.
+func (m *mt19937) uint32() uint32 {
+	const (
+		mtM       = 397
+		matrixA   = 0x9908b0df
+		upperMask = 0x80000000
+		lowerMask = 0x7fffffff
+	)
+
+	if m.i >= mtN {
+		for k := range mtN {
+			y := m.mt[k]&upperMask | m.mt[(k+1)%mtN]&lowerMask
+			v := m.mt[(k+mtM)%mtN] ^ y>>1
+			if y&1 != 0 {
+				v ^= matrixA
+			}
+			m.mt[k] = v
+		}
+		m.i = 0
+	}
+
+	y := m.mt[m.i]
+	m.i++
+	y ^= y >> 11
+	y ^= y << 7 & 0x9d2c5680
+	y ^= y << 15 & 0xefc60000
+	y ^= y >> 18
+	return y
+}
+
+// Uint64 implements [rand.Source].
+func (m *mt19937) Uint64() uint64 {
+	return uint64(m.uint32())<<32 | uint64(m.uint32())
+}
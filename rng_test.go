@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMT19937(t *testing.T) {
+	// Reference outputs of mt19937ar.c.
+	m := &mt19937{}
+	m.seed(5489)
+	if got := m.uint32(); got != 3499211612 {
+		t.Errorf("unexpected init_genrand output: got: %v, want: 3499211612", got)
+	}
+
+	m.seedArray([]uint32{0x123, 0x234, 0x345, 0x456})
+	want := []uint32{1067595299, 955945823, 477289528, 4107218783, 4228976476}
+	got := make([]uint32, len(want))
+	for i := range got {
+		got[i] = m.uint32()
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected init_by_array outputs: got: %v, want: %v", got, want)
+	}
+}
+
+func TestRNGAlgorithms(t *testing.T) {
+	for _, name := range []string{"pcg", "chacha8", "mt19937"} {
+		algo, err := parseRNG(name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		a, b := newRNG(algo, 42), newRNG(algo, 42)
+		for range 1000 {
+			if a.Uint64() != b.Uint64() {
+				t.Fatalf("%v: same seed generates different numbers", name)
+			}
+		}
+
+		if vertexRNG(algo, 42, 1).Uint64() == vertexRNG(algo, 42, 2).Uint64() {
+			t.Errorf("%v: different vertices generate the same numbers", name)
+		}
+	}
+
+	if _, err := parseRNG("lcg"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestShuffleWords(t *testing.T) {
+	words := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	a, b := slices.Clone(words), slices.Clone(words)
+	shuffleWords(a, 42, 0)
+	shuffleWords(b, 42, 0)
+	if !slices.Equal(a, b) {
+		t.Errorf("same seed shuffles differently: %v %v", a, b)
+	}
+
+	c := slices.Clone(words)
+	shuffleWords(c, 43, 0)
+	if slices.Equal(a, c) {
+		t.Errorf("different seeds shuffle the same: %v", a)
+	}
+
+	slices.Sort(a)
+	if !slices.Equal(a, words) {
+		t.Errorf("shuffle changed the words: %v", a)
+	}
+}
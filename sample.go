@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+)
+
+// sampleElements returns the elements of the subgraph induced by a
+// random sample of the vertices of seq. Every vertex is kept with
+// probability p. The decision only depends on the vertex ID and key,
+// so edges can be filtered even if they precede their endpoints.
+func sampleElements(seq iter.Seq2[element, error], p float64, key uint64) iter.Seq2[element, error] {
+	return inducedElements(seq, func(id int64) bool {
+		return float64(mix64(key^uint64(id))>>11)/(1<<53) < p
+	})
+}
+
+// inducedElements returns the elements of the subgraph of seq induced
+// by the vertices for which keep returns true. Edges are kept if keep
+// returns true for both of their endpoints.
+func inducedElements(seq iter.Seq2[element, error], keep func(id int64) bool) iter.Seq2[element, error] {
+	return func(yield func(element, error) bool) {
+		for elem, err := range seq {
+			if err != nil {
+				yield(elem, err)
+				return
+			}
+			if elem.isEdge {
+				if !keep(elem.e.tail) || !keep(elem.e.head) {
+					continue
+				}
+			} else if !keep(elem.v.id) {
+				continue
+			}
+			if !yield(elem, nil) {
+				return
+			}
+		}
+	}
+}
+
+func runSample(args []string) {
+	fs := flag.NewFlagSet("sample", flag.ExitOnError)
+	p := fs.Float64("p", 0.1, "probability of keeping each vertex")
+	var of outputFlags
+	of.register(fs)
+	fs.Usage = commandUsage(fs, "sample [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	if *p < 0 || *p > 1 {
+		fatal(fmt.Errorf("invalid probability: %v", *p))
+	}
+
+	err := of.write(func(enc encoder) error {
+		return encodeElements(enc, sampleElements(decodeGraph(in), *p, rand.Uint64()))
+	})
+	if err != nil {
+		fatal(err)
+	}
+}
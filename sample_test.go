@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSampleElements(t *testing.T) {
+	input := "E: 0 1\nE: 1 2\nE: 2 3\nV: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\n"
+
+	tests := []struct {
+		p               float64
+		vertices, edges int
+	}{
+		{p: 0, vertices: 0, edges: 0},
+		{p: 1, vertices: 4, edges: 3},
+	}
+	for _, tt := range tests {
+		seq := sampleElements(decodeSimple(strings.NewReader(input)), tt.p, 1)
+		d, err := loadGraph(seq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.vertices) != tt.vertices || len(d.edges) != tt.edges {
+			t.Errorf("p=%v: unexpected sample size: got: (%v, %v) want: (%v, %v)",
+				tt.p, len(d.vertices), len(d.edges), tt.vertices, tt.edges)
+		}
+	}
+}
+
+func TestSampleElementsInduced(t *testing.T) {
+	var b strings.Builder
+	for i := range 100 {
+		b.WriteString("V: " + formatID(int64(i), 0) + " X\n")
+		b.WriteString("E: " + formatID(int64(i), 0) + " " + formatID(int64((i+1)%100), 0) + "\n")
+	}
+
+	d, err := loadGraph(sampleElements(decodeSimple(strings.NewReader(b.String())), 0.5, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := make(map[int64]bool)
+	for _, v := range d.vertices {
+		kept[v.id] = true
+	}
+	for _, e := range d.edges {
+		if !kept[e.tail] || !kept[e.head] {
+			t.Errorf("edge with dropped endpoint: %v -> %v", e.tail, e.head)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// serverFlags are the generate flags that can be set by clients of
+// the server. The rest of the flags refer to files in the server,
+// only make sense in the command line or let clients exhaust the
+// resources of the server, so new flags are rejected unless they are
+// added here.
+var serverFlags = map[string]bool{
+	"n":                  true,
+	"seed":               true,
+	"rng":                true,
+	"vertex-time":        true,
+	"coords":             true,
+	"vertex-types":       true,
+	"type-edges":         true,
+	"edge-types":         true,
+	"vertex-range":       true,
+	"infinite":           true,
+	"churn":              true,
+	"trials":             true,
+	"prob":               true,
+	"strict":             true,
+	"loops":              true,
+	"multiedges":         true,
+	"out-degree":         true,
+	"in-degree":          true,
+	"assortativity":      true,
+	"locality":           true,
+	"closure":            true,
+	"profile":            true,
+	"scale":              true,
+	"edgefactor":         true,
+	"scale-factor":       true,
+	"triad-formation":    true,
+	"mixing":             true,
+	"community-sizes":    true,
+	"mu":                 true,
+	"propensity":         true,
+	"dedup":              true,
+	"dedup-fp":           true,
+	"id-start":           true,
+	"id-stride":          true,
+	"id-width":           true,
+	"labels":             true,
+	"transliterate":      true,
+	"words-encoding":     true,
+	"label-case":         true,
+	"label-depth":        true,
+	"on-label-collision": true,
+	"emit":               true,
+	"interleave":         true,
+	"rate":               true,
+	"burst":              true,
+	"z":                  true,
+	"crlf":               true,
+	"dot":                true,
+	"dot-strict":         true,
+	"dot-name":           true,
+	"dot-tooltip":        true,
+	"dot-url":            true,
+	"meta":               true,
+	"golden":             true,
+	"max-mem":            true,
+}
+
+// handleGenerate streams a graph generated with the generate flags
+// passed as query parameters.
+func handleGenerate(w http.ResponseWriter, r *http.Request) {
+	c, gen, err := serverGeneration(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exceeded, err := tenantFrom(r.Context()).limit(c, gen)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	writeGraph(r.Context(), w, c, gen, exceeded)
+}
+
+// queryArgs converts query parameters into command line flags. The
+// flags are sorted by name.
+func queryArgs(query url.Values) ([]string, error) {
+	var args []string
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		if !serverFlags[name] {
+			return nil, fmt.Errorf("flag not allowed: -%v", name)
+		}
+		for _, v := range query[name] {
+			args = append(args, "-"+name+"="+v)
+		}
+	}
+	return args, nil
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "listen `address`")
+	pprofAddr := fs.String("pprof", "", "serve profiling data on `address`")
+	jobsDir := fs.String("jobs-dir", "", "write the graphs of asynchronous generations to `dir` (default temporary directory)")
+	jobs := fs.Int("jobs", 2, "run at most `n` asynchronous generations at the same time")
+	tenantsFile := fs.String("tenants", "", "read the API keys, rate limits and quotas of the tenants from `file`")
+	basicAuth := fs.String("basic-auth", "", "require HTTP basic authentication with the users in `file`")
+	tlsCert := fs.String("tls-cert", "", "serve HTTPS with the certificate in `file`")
+	tlsKey := fs.String("tls-key", "", "serve HTTPS with the private key in `file`")
+	tlsClientCA := fs.String("tls-client-ca", "", "require client certificates signed by the CAs in `file`")
+	shutdownDelay := fs.Duration("shutdown-delay", 0, "keep accepting requests for `duration` after being asked to shut down")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "wait up to `duration` for in-flight requests on shutdown")
+	fs.Usage = commandUsage(fs, "serve [flags]")
+	parseFlags(fs, args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *jobs < 1 {
+		fatal(usageError{errors.New("invalid number of jobs: must be at least 1")})
+	}
+
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fatal(usageError{errors.New("-tls-cert and -tls-key must be specified together")})
+	}
+	if *tlsClientCA != "" && *tlsCert == "" {
+		fatal(usageError{errors.New("-tls-client-ca requires -tls-cert and -tls-key")})
+	}
+	if *basicAuth != "" && *tenantsFile != "" {
+		fatal(usageError{errors.New("-basic-auth and -tenants are mutually exclusive")})
+	}
+
+	var ts tenants
+	if *tenantsFile != "" {
+		var err error
+		if ts, err = readTenants(*tenantsFile); err != nil {
+			fatal(err)
+		}
+	}
+	var users basicUsers
+	if *basicAuth != "" {
+		var err error
+		if users, err = readBasicUsers(*basicAuth); err != nil {
+			fatal(err)
+		}
+	}
+	var tlsConfig *tls.Config
+	if *tlsCert != "" {
+		var err error
+		if tlsConfig, err = serverTLSConfig(*tlsCert, *tlsKey, *tlsClientCA); err != nil {
+			fatal(err)
+		}
+	}
+
+	// listen serves srv, with TLS if it is configured.
+	listen := func(srv *http.Server) error {
+		srv.TLSConfig = tlsConfig
+		if tlsConfig != nil {
+			return srv.ListenAndServeTLS("", "")
+		}
+		return srv.ListenAndServe()
+	}
+
+	dir := *jobsDir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "mkdigraph-jobs-")
+		if err != nil {
+			fatal(err)
+		}
+		dir = tmp
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", handleGenerate)
+	api := newGraphAPI(dir, *jobs)
+	api.register(mux)
+
+	// The health checks are not authenticated, so they can be used
+	// by orchestrators.
+	var h health
+	root := http.NewServeMux()
+	h.register(root)
+	root.Handle("/", users.handler(ts.handler(mux)))
+
+	ctx, stop := notifyInterrupt()
+	defer stop()
+
+	errc := make(chan error, 2)
+	srv := &http.Server{Addr: *addr, Handler: root}
+	var pprofSrv *http.Server
+	if *pprofAddr != "" {
+		pprofSrv = &http.Server{Addr: *pprofAddr, Handler: users.handler(ts.handler(pprofHandler()))}
+		go func() {
+			log.Printf("serving profiling data on %v", *pprofAddr)
+			errc <- listen(pprofSrv)
+		}()
+	}
+	go func() {
+		log.Printf("listening on %v", *addr)
+		errc <- listen(srv)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		log.Printf("shutting down: %v", context.Cause(ctx))
+		// Not being ready while the requests are still accepted
+		// gives load balancers time to stop sending new ones.
+		h.draining.Store(true)
+		time.Sleep(*shutdownDelay)
+		err = shutdown(srv, *shutdownTimeout)
+		if pprofSrv != nil {
+			pprofSrv.Close()
+		}
+	}
+	api.close()
+	if *jobsDir == "" {
+		os.RemoveAll(dir)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// shutdown stops srv after waiting up to timeout for the in-flight
+// requests, including graph streams, to finish. Then, the remaining
+// ones are interrupted.
+func shutdown(srv *http.Server, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("shutdown: %v", err)
+		return srv.Close()
+	}
+	return nil
+}
+
+// A health reports the status of the server to orchestrators.
+type health struct {
+	// draining reports whether the server is shutting down.
+	draining atomic.Bool
+}
+
+// register registers the health check endpoints in mux. /healthz
+// reports whether the server is alive and /readyz whether it accepts
+// new requests.
+func (h *health) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if h.draining.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
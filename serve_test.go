@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerFlags(t *testing.T) {
+	var c genConfig
+	fs := c.flagSet("generate")
+	for name := range serverFlags {
+		if fs.Lookup(name) == nil {
+			t.Errorf("unknown flag: -%v", name)
+		}
+	}
+}
+
+func TestHandleGenerate(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantOutput *regexp.Regexp
+	}{
+		{query: "n=5&trials=2&meta=false", wantStatus: http.StatusOK, wantOutput: validSimpleOutput},
+		{query: "n=5&trials=2&meta=false&dot=true", wantStatus: http.StatusOK, wantOutput: validDOTOutput},
+		{query: "n=-1", wantStatus: http.StatusBadRequest},
+		{query: "unknown=1", wantStatus: http.StatusBadRequest},
+		{query: "words=/etc/passwd", wantStatus: http.StatusBadRequest},
+		{query: "o=out.txt", wantStatus: http.StatusBadRequest},
+		{query: "label-pools=/etc/passwd", wantStatus: http.StatusBadRequest},
+		{query: "plugin=/tmp/model.so", wantStatus: http.StatusBadRequest},
+		{query: "ipc=true", wantStatus: http.StatusBadRequest},
+		{query: "n=3&drop-on-overflow=true&overflow-buffer=1T", wantStatus: http.StatusBadRequest},
+		{query: "n=3&verify=true", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/?"+tt.query, nil)
+		rec := httptest.NewRecorder()
+		handleGenerate(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%q: unexpected status: got: %v want: %v", tt.query, rec.Code, tt.wantStatus)
+			continue
+		}
+		if tt.wantOutput != nil && !tt.wantOutput.MatchString(rec.Body.String()) {
+			t.Errorf("%q: malformed output:\n%v", tt.query, rec.Body.String())
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	var h health
+	mux := http.NewServeMux()
+	h.register(mux)
+
+	check := func(path string, want int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("%v: unexpected status: got: %v want: %v", path, rec.Code, want)
+		}
+	}
+	check("/healthz", http.StatusOK)
+	check("/readyz", http.StatusOK)
+
+	h.draining.Store(true)
+	check("/healthz", http.StatusOK)
+	check("/readyz", http.StatusServiceUnavailable)
+}
+
+func TestShutdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		wantBody string
+	}{
+		{name: "finished", timeout: time.Minute, wantBody: "done"},
+		{name: "interrupted", timeout: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		started, release := make(chan struct{}), make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("start "))
+			w.(http.Flusher).Flush()
+			close(started)
+			select {
+			case <-release:
+				w.Write([]byte("done"))
+			case <-r.Context().Done():
+			}
+		})
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen error: %v", err)
+		}
+		srv := &http.Server{Handler: handler}
+		go srv.Serve(ln)
+
+		bodyc := make(chan string)
+		go func() {
+			resp, err := http.Get("http://" + ln.Addr().String())
+			if err != nil {
+				bodyc <- ""
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			bodyc <- string(body)
+		}()
+		<-started
+
+		errc := make(chan error)
+		go func() { errc <- shutdown(srv, tt.timeout) }()
+		if tt.wantBody != "" {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("%v: shutdown error: %v", tt.name, err)
+		}
+		body := <-bodyc
+		if tt.wantBody != "" && !strings.HasSuffix(body, tt.wantBody) {
+			t.Errorf("%v: unexpected body: %q", tt.name, body)
+		}
+	}
+}
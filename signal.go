@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// A signalError is the cancellation cause of a context canceled by a
+// signal.
+type signalError struct {
+	sig syscall.Signal
+}
+
+func (err *signalError) Error() string {
+	return "interrupted: " + err.sig.String()
+}
+
+// exitCode returns the conventional exit code of a process
+// terminated by the signal.
+func (err *signalError) exitCode() int {
+	return 128 + int(err.sig)
+}
+
+// notifyInterrupt returns a context that is canceled when the process
+// receives SIGINT or SIGTERM. The cause of the cancellation is a
+// [*signalError]. Once the first signal is received, the default
+// behavior is restored, so a second signal terminates the process
+// immediately. The stop function stops listening for signals.
+func notifyInterrupt() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-c:
+			signal.Stop(c)
+			cancel(&signalError{sig: sig.(syscall.Signal)})
+		case <-done:
+		}
+	}()
+	stop = func() {
+		signal.Stop(c)
+		close(done)
+		cancel(nil)
+	}
+	return ctx, stop
+}
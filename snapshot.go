@@ -0,0 +1,249 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strconv"
+)
+
+// snapshotSizes returns the number of vertices of each of k evenly
+// spaced snapshots of a graph with n vertices. The last snapshot
+// holds the whole graph.
+func snapshotSizes(n int64, k int) []int64 {
+	if k == 0 {
+		return nil
+	}
+	sizes := make([]int64, k)
+	for i := range int64(k) {
+		sizes[i] = n/int64(k)*(i+1) + n%int64(k)*(i+1)/int64(k)
+	}
+	return sizes
+}
+
+// snapshotName returns the name of the i-th of k snapshots in dir.
+// Snapshots are numbered from 1.
+func snapshotName(dir string, i, k int, dot bool) string {
+	ext := ".txt"
+	if dot {
+		ext = ".dot"
+	}
+	width := max(3, len(strconv.Itoa(k)))
+	return filepath.Join(dir, fmt.Sprintf("snapshot-%0*d%v", width, i, ext))
+}
+
+// deltaName returns the name of the delta file of the i-th of k
+// snapshots in dir.
+func deltaName(dir string, i, k int) string {
+	width := max(3, len(strconv.Itoa(k)))
+	return filepath.Join(dir, fmt.Sprintf("delta-%0*d.txt", width, i))
+}
+
+// A snapshot is a snapshot or delta file being written.
+type snapshot struct {
+	out  *output
+	cw   *countingWriter
+	bw   *bufio.Writer
+	enc  encoder
+	size int64
+
+	// h computes the checksum of the snapshot. It is nil if
+	// checksums are not computed.
+	h hash.Hash
+
+	// format is the output format of the snapshot.
+	format string
+
+	// vertices and edges are the number of vertices and edges
+	// written to the snapshot.
+	vertices, edges int64
+
+	// delta is the delta file of the snapshot. It is nil if
+	// deltas are not written.
+	delta *snapshot
+}
+
+// createSnapshot creates the named snapshot file. If checksum is
+// true, its checksum is computed as it is written.
+func createSnapshot(name string, atomic, checksum bool, newEncoder func(w io.Writer) encoder) (*snapshot, error) {
+	out, err := createOutput(name, false, atomic)
+	if err != nil {
+		return nil, err
+	}
+	s := &snapshot{out: out, cw: &countingWriter{w: out}}
+	if checksum {
+		s.h = sha256.New()
+		s.cw.w = io.MultiWriter(out, s.h)
+	}
+	s.bw = bufio.NewWriter(s.cw)
+	s.enc = newEncoder(s.bw)
+	return s, nil
+}
+
+// flush flushes the snapshot and its delta file.
+func (s *snapshot) flush() {
+	s.bw.Flush()
+	if s.delta != nil {
+		s.delta.flush()
+	}
+}
+
+// finish completes the snapshot and its delta file and commits them.
+// The committed files are added to files.
+func (s *snapshot) finish(files *[]writtenFile) error {
+	if s.delta != nil {
+		if err := s.delta.finish(files); err != nil {
+			s.out.abort()
+			return err
+		}
+	}
+	s.enc.end()
+	if err := s.bw.Flush(); err != nil {
+		s.out.abort()
+		return err
+	}
+	if err := s.out.commit(); err != nil {
+		return err
+	}
+	wf := writtenFile{
+		name:     s.out.name,
+		format:   s.format,
+		vertices: s.vertices,
+		edges:    s.edges,
+		size:     s.cw.n,
+	}
+	if s.h != nil {
+		wf.sum = hex.EncodeToString(s.h.Sum(nil))
+	}
+	*files = append(*files, wf)
+	return nil
+}
+
+// abort aborts the snapshot and its delta file.
+func (s *snapshot) abort() {
+	if s.delta != nil {
+		s.delta.abort()
+	}
+	s.out.abort()
+}
+
+// writeSnapshots writes the snapshots of the graph to dir. Every
+// snapshot holds the first vertices of the graph, as many as its
+// size, and the edges between them. Thus, every snapshot is a
+// superset of the previous one. The vertices and edges of the graph
+// must be interleaved.
+//
+// If deltas are enabled, a delta file is written along with every
+// snapshot, holding only the vertices and edges added since the
+// previous snapshot in the patch format.
+//
+// If ctx is done before the graph is complete, the pending snapshots
+// are committed with the output written so far, unless atomic is
+// true.
+func (gen *generation) writeSnapshots(ctx context.Context, dir string, atomic bool) (err error) {
+	var snaps []*snapshot
+	defer func() {
+		if err != nil {
+			for _, s := range snaps {
+				s.abort()
+			}
+		}
+	}()
+
+	k := len(gen.snapshots)
+	for i, size := range gen.snapshots {
+		s, err := createSnapshot(snapshotName(dir, i+1, k, gen.emitDOT), atomic, gen.checksum, func(w io.Writer) encoder {
+			return gen.p.newEncoder(w, gen.emitDOT)
+		})
+		if err != nil {
+			return err
+		}
+		s.size, s.format = size, gen.format()
+		snaps = append(snaps, s)
+		if !gen.deltas {
+			continue
+		}
+		s.delta, err = createSnapshot(deltaName(dir, i+1, k), atomic, gen.checksum, gen.p.newPatchEncoder)
+		if err != nil {
+			return err
+		}
+		s.delta.format = "patch"
+	}
+
+	flush := func() {
+		for _, s := range snaps {
+			s.flush()
+		}
+	}
+	g := gen.stream(ctx, flush)
+
+	for _, s := range snaps {
+		s.enc.begin()
+		if s.delta != nil {
+			s.delta.enc.begin()
+		}
+	}
+
+	// Every vertex and edge is written to the pending snapshots.
+	// A snapshot is finished when the first vertex that does not
+	// belong to it is found, because all the edges between its
+	// vertices have already been written.
+	var nv int64
+	walker := gen.p
+	walker.emit = emitBoth
+	walker.walk(g, func(v vertex) {
+		for len(snaps) > 0 && snaps[0].size <= nv {
+			err = errors.Join(err, snaps[0].finish(&gen.files))
+			snaps = snaps[1:]
+		}
+		if gen.p.emit != emitEdges {
+			for _, s := range snaps {
+				s.enc.vertex(v)
+				s.vertices++
+			}
+			if len(snaps) > 0 && snaps[0].delta != nil {
+				snaps[0].delta.enc.vertex(v)
+				snaps[0].delta.vertices++
+			}
+		}
+		nv++
+	}, func(e edge) {
+		if gen.p.emit != emitVertices {
+			for _, s := range snaps {
+				s.enc.edge(e)
+				s.edges++
+			}
+			if len(snaps) > 0 && snaps[0].delta != nil {
+				snaps[0].delta.enc.edge(e)
+				snaps[0].delta.edges++
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil && atomic {
+		for _, s := range snaps {
+			s.abort()
+		}
+		return nil
+	}
+	for len(snaps) > 0 {
+		if err := snaps[0].finish(&gen.files); err != nil {
+			return err
+		}
+		snaps = snaps[1:]
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotSizes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		k    int
+		want []int64
+	}{
+		{n: 10, k: 0, want: nil},
+		{n: 10, k: 1, want: []int64{10}},
+		{n: 10, k: 2, want: []int64{5, 10}},
+		{n: 10, k: 3, want: []int64{3, 6, 10}},
+		{n: 3, k: 3, want: []int64{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		if got := snapshotSizes(tt.n, tt.k); !slices.Equal(got, tt.want) {
+			t.Errorf("unexpected sizes (n=%v, k=%v): got: %v want: %v", tt.n, tt.k, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotName(t *testing.T) {
+	tests := []struct {
+		i, k int
+		dot  bool
+		want string
+	}{
+		{i: 1, k: 5, dot: false, want: filepath.Join("dir", "snapshot-001.txt")},
+		{i: 12, k: 1000, dot: true, want: filepath.Join("dir", "snapshot-0012.dot")},
+	}
+	for _, tt := range tests {
+		if got := snapshotName("dir", tt.i, tt.k, tt.dot); got != tt.want {
+			t.Errorf("unexpected name: got: %q want: %q", got, tt.want)
+		}
+	}
+}
+
+func TestWriteSnapshots(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}, {id: 2, label: "C"}, {id: 3, label: "D"}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0}, {tail: 0, head: 1}, {tail: 2, head: 1}, {tail: 3, head: 3}}),
+	}
+	gen := &generation{
+		g:         g,
+		p:         printer{interleave: true},
+		snapshots: []int64{2, 3, 4},
+		counter:   &counter{},
+	}
+
+	dir := t.TempDir()
+	if err := gen.writeSnapshots(context.Background(), dir, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"V: 0 A\nV: 1 B\nE: 1 0\nE: 0 1\n",
+		"V: 0 A\nV: 1 B\nE: 1 0\nE: 0 1\nV: 2 C\nE: 2 1\n",
+		"V: 0 A\nV: 1 B\nE: 1 0\nE: 0 1\nV: 2 C\nE: 2 1\nV: 3 D\nE: 3 3\n",
+	}
+	for i, w := range want {
+		data, err := os.ReadFile(snapshotName(dir, i+1, len(want), false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != w {
+			t.Errorf("unexpected snapshot %v: got: %q want: %q", i+1, got, w)
+		}
+	}
+}
+
+func TestWriteSnapshotsEmit(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0}}),
+	}
+	gen := &generation{
+		g:         g,
+		p:         printer{interleave: true, emit: emitEdges},
+		snapshots: []int64{1, 2},
+		counter:   &counter{},
+	}
+
+	dir := t.TempDir()
+	if err := gen.writeSnapshots(context.Background(), dir, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for i := range 2 {
+		data, err := os.ReadFile(snapshotName(dir, i+1, 2, false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	if want := []string{"", "E: 1 0\n"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected snapshots: got: %q want: %q", got, want)
+	}
+}
+
+func TestWriteSnapshotsDeltas(t *testing.T) {
+	g := graph{
+		vertices: slices.Values([]vertex{{id: 0, label: "A"}, {id: 1, label: "B"}, {id: 2, label: "C"}}),
+		edges:    slices.Values([]edge{{tail: 1, head: 0}, {tail: 2, head: 0}, {tail: 2, head: 1}}),
+	}
+	gen := &generation{
+		g:         g,
+		p:         printer{interleave: true},
+		snapshots: []int64{2, 3},
+		deltas:    true,
+		counter:   &counter{},
+	}
+
+	dir := t.TempDir()
+	if err := gen.writeSnapshots(context.Background(), dir, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"+V: 0 A\n+V: 1 B\n+E: 1 0\n",
+		"+V: 2 C\n+E: 2 0\n+E: 2 1\n",
+	}
+	for i, w := range want {
+		data, err := os.ReadFile(deltaName(dir, i+1, len(want)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != w {
+			t.Errorf("unexpected delta %v: got: %q want: %q", i+1, got, w)
+		}
+	}
+}
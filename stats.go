@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// graphStats holds the statistics of a graph. They are computed in a
+// single pass, but the degrees and labels of every vertex and the set
+// of edges are kept in memory.
+type graphStats struct {
+	vertices   int64
+	edges      int64
+	loops      int64
+	multiedges int64
+
+	// collisions is the number of vertices whose label was already
+	// used by a previous vertex.
+	collisions int64
+
+	// labels is the set of vertex labels.
+	labels map[string]struct{}
+
+	// degrees holds the out-degree and in-degree of every vertex.
+	degrees map[int64]*[2]int64
+
+	// seen is the set of edges.
+	seen map[edge]struct{}
+}
+
+// newGraphStats returns an empty graphStats.
+func newGraphStats() *graphStats {
+	return &graphStats{
+		labels:  make(map[string]struct{}),
+		degrees: make(map[int64]*[2]int64),
+		seen:    make(map[edge]struct{}),
+	}
+}
+
+// degree returns the degrees of the vertex with the provided ID.
+func (st *graphStats) degree(id int64) *[2]int64 {
+	d, ok := st.degrees[id]
+	if !ok {
+		d = &[2]int64{}
+		st.degrees[id] = d
+	}
+	return d
+}
+
+// add adds elem to the statistics.
+func (st *graphStats) add(elem element) {
+	if !elem.isEdge {
+		st.vertices++
+		st.degree(elem.v.id)
+		if l := elem.v.label; l != "" {
+			if _, ok := st.labels[l]; ok {
+				st.collisions++
+			} else {
+				st.labels[l] = struct{}{}
+			}
+		}
+		return
+	}
+
+	e := elem.e
+	st.edges++
+	if e.tail == e.head {
+		st.loops++
+	}
+	// Parallel edges are multiple edges regardless of their
+	// attributes.
+	key := edge{tail: e.tail, head: e.head}
+	if _, ok := st.seen[key]; ok {
+		st.multiedges++
+	} else {
+		st.seen[key] = struct{}{}
+	}
+	st.degree(e.tail)[0]++
+	st.degree(e.head)[1]++
+}
+
+// write writes the statistics to w, one per line.
+func (st *graphStats) write(w io.Writer) {
+	var sources, sinks, maxOut, maxIn int64
+	for _, d := range st.degrees {
+		if d[1] == 0 {
+			sources++
+		}
+		if d[0] == 0 {
+			sinks++
+		}
+		maxOut = max(maxOut, d[0])
+		maxIn = max(maxIn, d[1])
+	}
+
+	var meanDegree, density float64
+	if st.vertices > 0 {
+		meanDegree = float64(st.edges) / float64(st.vertices)
+	}
+	if st.vertices > 1 {
+		density = float64(st.edges) / (float64(st.vertices) * float64(st.vertices-1))
+	}
+
+	fmt.Fprintf(w, "vertices: %v\n", st.vertices)
+	fmt.Fprintf(w, "edges: %v\n", st.edges)
+	fmt.Fprintf(w, "loops: %v\n", st.loops)
+	fmt.Fprintf(w, "multiedges: %v\n", st.multiedges)
+	fmt.Fprintf(w, "label-collisions: %v\n", st.collisions)
+	fmt.Fprintf(w, "sources: %v\n", sources)
+	fmt.Fprintf(w, "sinks: %v\n", sinks)
+	fmt.Fprintf(w, "max-out-degree: %v\n", maxOut)
+	fmt.Fprintf(w, "max-in-degree: %v\n", maxIn)
+	fmt.Fprintf(w, "mean-out-degree: %.4f\n", meanDegree)
+	fmt.Fprintf(w, "density: %.4f\n", density)
+	fmt.Fprintf(w, "clustering: %.4f\n", st.clustering())
+}
+
+// clustering returns the average local clustering coefficient of the
+// graph, ignoring the direction of the edges, loops and multiple
+// edges. The local clustering coefficient of a vertex is the fraction
+// of pairs of its neighbors that are adjacent, or 0 if it has less
+// than two neighbors.
+func (st *graphStats) clustering() float64 {
+	adj := make(map[int64]map[int64]struct{})
+	link := func(u, v int64) {
+		if adj[u] == nil {
+			adj[u] = make(map[int64]struct{})
+		}
+		adj[u][v] = struct{}{}
+	}
+	for e := range st.seen {
+		if e.tail != e.head {
+			link(e.tail, e.head)
+			link(e.head, e.tail)
+		}
+	}
+
+	// Every triangle is found once, from its vertex with the
+	// lowest ID.
+	triangles := make(map[int64]float64)
+	for u, nbs := range adj {
+		for v := range nbs {
+			if v < u {
+				continue
+			}
+			for w := range adj[v] {
+				if w <= v {
+					continue
+				}
+				if _, ok := nbs[w]; ok {
+					triangles[u]++
+					triangles[v]++
+					triangles[w]++
+				}
+			}
+		}
+	}
+
+	if len(st.degrees) == 0 {
+		return 0
+	}
+	var sum float64
+	for u, t := range triangles {
+		d := float64(len(adj[u]))
+		sum += t / (d * (d - 1) / 2)
+	}
+	return sum / float64(len(st.degrees))
+}
+
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "stats [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	st := newGraphStats()
+	for elem, err := range decodeGraph(in) {
+		if err != nil {
+			fatal(err)
+		}
+		st.add(elem)
+	}
+
+	bw := bufio.NewWriter(os.Stdout)
+	st.write(bw)
+	if err := bw.Flush(); err != nil {
+		fatal(err)
+	}
+}
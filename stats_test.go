@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGraphStats(t *testing.T) {
+	input := "V: 0 A\nV: 1 B\nV: 2 A\nE: 0 1\nE: 0 1\nE: 1 1\nE: 1 0\n"
+	want := `vertices: 3
+edges: 4
+loops: 1
+multiedges: 1
+label-collisions: 1
+sources: 1
+sinks: 1
+max-out-degree: 2
+max-in-degree: 3
+mean-out-degree: 1.3333
+density: 0.6667
+clustering: 0.0000
+`
+
+	st := newGraphStats()
+	for elem, err := range decodeSimple(strings.NewReader(input)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		st.add(elem)
+	}
+
+	buf := &bytes.Buffer{}
+	st.write(buf)
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected stats:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestGraphStatsClustering(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{input: "E: 0 1\nE: 1 2\nE: 0 2\n", want: 1},
+		{input: "E: 0 1\nE: 1 2\nE: 2 0\nE: 2 0\nE: 1 1\n", want: 1},
+		{input: "E: 0 1\nE: 0 2\nE: 0 3\n", want: 0},
+		{input: "E: 0 1\nE: 1 2\nE: 0 2\nE: 2 3\n", want: (1 + 1 + 1.0/3) / 4},
+		{input: "", want: 0},
+	}
+	for _, tt := range tests {
+		st := newGraphStats()
+		for elem, err := range decodeSimple(strings.NewReader(tt.input)) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			st.add(elem)
+		}
+		if got := st.clustering(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q: unexpected clustering: got: %v, want: %v", tt.input, got, tt.want)
+		}
+	}
+}
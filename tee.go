@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+)
+
+// teeFormats are the formats supported by -tee.
+var teeFormats = []string{"simple", "dot", "edge-tuples"}
+
+// A teeOutput is an additional output of the generation, set with
+// -tee.
+type teeOutput struct {
+	format string
+	name   string
+}
+
+// teeFlag is the value of the -tee flag, which can be repeated.
+type teeFlag []teeOutput
+
+// String returns the outputs as a comma-separated list.
+func (tf *teeFlag) String() string {
+	var outs []string
+	for _, to := range *tf {
+		outs = append(outs, to.format+":"+to.name)
+	}
+	return strings.Join(outs, ",")
+}
+
+// Set parses an output with the form format:file and adds it.
+func (tf *teeFlag) Set(s string) error {
+	format, name, ok := strings.Cut(s, ":")
+	if !ok || name == "" {
+		return fmt.Errorf("malformed output: %q (want format:file)", s)
+	}
+	if !slices.Contains(teeFormats, format) {
+		return fmt.Errorf("unknown format: %q (want %v)", format, strings.Join(teeFormats, ", "))
+	}
+	*tf = append(*tf, teeOutput{format: format, name: name})
+	return nil
+}
+
+// A teeFile is an open additional output.
+type teeFile struct {
+	teeOutput
+	out *output
+	cw  *countingWriter
+	h   hash.Hash
+	bw  *bufio.Writer
+	enc encoder
+}
+
+// openTees creates the additional outputs. The printer of the
+// generation is adapted to the format of every output. See
+// [createOutput] for the meaning of appendOut and atomic.
+func (gen *generation) openTees(outs []teeOutput, appendOut, atomic bool) ([]*teeFile, error) {
+	var tees []*teeFile
+	for _, to := range outs {
+		out, err := createOutput(to.name, appendOut, atomic)
+		if err != nil {
+			for _, tf := range tees {
+				tf.out.abort()
+			}
+			return nil, err
+		}
+		tf := &teeFile{teeOutput: to, out: out, cw: &countingWriter{w: out}}
+		if gen.checksum {
+			tf.h = sha256.New()
+			tf.cw.w = io.MultiWriter(out, tf.h)
+		}
+		tf.bw = bufio.NewWriter(tf.cw)
+
+		p := gen.p
+		p.edgeTuples = to.format == "edge-tuples"
+		if to.format != "simple" {
+			p.nul = false
+		}
+		if p.edgeTuples {
+			p.crlf = false
+		}
+		tf.enc = p.newEncoder(tf.bw, to.format == "dot")
+		tees = append(tees, tf)
+	}
+	return tees, nil
+}
+
+// close flushes the output and commits it, unless commit is false,
+// in which case it is discarded. It returns the written file.
+func (tf *teeFile) close(gen *generation, commit bool) (writtenFile, error) {
+	if err := tf.bw.Flush(); err != nil {
+		tf.out.abort()
+		return writtenFile{}, err
+	}
+	if !commit {
+		tf.out.abort()
+		return writtenFile{}, nil
+	}
+	if err := tf.out.commit(); err != nil {
+		return writtenFile{}, err
+	}
+	wf := writtenFile{
+		name:     tf.name,
+		format:   tf.format,
+		vertices: gen.counter.vertices,
+		edges:    gen.counter.edges,
+		size:     tf.cw.n,
+	}
+	if tf.h != nil {
+		wf.sum = hex.EncodeToString(tf.h.Sum(nil))
+	}
+	return wf, nil
+}
+
+// closeTees closes the additional outputs, committing them if commit
+// is true, and records the committed files. It returns the first
+// error found.
+func (gen *generation) closeTees(tees []*teeFile, commit bool) error {
+	var first error
+	for _, tf := range tees {
+		if first != nil {
+			tf.out.abort()
+			continue
+		}
+		wf, err := tf.close(gen, commit)
+		if err != nil {
+			first = err
+			continue
+		}
+		if commit {
+			gen.files = append(gen.files, wf)
+		}
+	}
+	return first
+}
+
+// A multiEncoder writes the graph with several encoders.
+type multiEncoder []encoder
+
+func (encs multiEncoder) begin() {
+	for _, enc := range encs {
+		enc.begin()
+	}
+}
+
+func (encs multiEncoder) vertex(v vertex) {
+	for _, enc := range encs {
+		enc.vertex(v)
+	}
+}
+
+func (encs multiEncoder) edge(e edge) {
+	for _, enc := range encs {
+		enc.edge(e)
+	}
+}
+
+func (encs multiEncoder) end() {
+	for _, enc := range encs {
+		enc.end()
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestTeeFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    teeOutput
+		wantErr bool
+	}{
+		{value: "dot:g.dot", want: teeOutput{format: "dot", name: "g.dot"}},
+		{value: "edge-tuples:dir/g:1.bin", want: teeOutput{format: "edge-tuples", name: "dir/g:1.bin"}},
+		{value: "g.dot", wantErr: true},
+		{value: "dot:", wantErr: true},
+		{value: "csv:g.csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		var tf teeFlag
+		err := tf.Set(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if err == nil && (len(tf) != 1 || tf[0] != tt.want) {
+			t.Errorf("%q: unexpected outputs: %v", tt.value, tf)
+		}
+	}
+}
+
+func TestGenerationTee(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "g.txt")
+	dot := filepath.Join(dir, "g.dot")
+	tuples := filepath.Join(dir, "g.bin")
+
+	c, fs, err := parseGenerate([]string{
+		"-n=20", "-prob=0.2", "-seed=1", "-meta=false", "-o=" + out, "-checksum=sha256",
+		"-tee=dot:" + dot, "-tee=edge-tuples:" + tuples,
+	})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		t.Fatalf("generation error: %v", err)
+	}
+	if err := gen.writeFile(context.Background(), c.outFile, false, false); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	simple, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	var want []string
+	for _, m := range regexp.MustCompile(`(?m)^E: (\d+) (\d+)$`).FindAllStringSubmatch(string(simple), -1) {
+		want = append(want, m[1]+"->"+m[2])
+	}
+	if len(want) == 0 || int64(len(want)) != gen.counter.edges {
+		t.Fatalf("unexpected number of edges: %v", len(want))
+	}
+
+	b, err := os.ReadFile(dot)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if !validDOTOutput.Match(b) {
+		t.Errorf("malformed DOT output:\n%s", b)
+	}
+	var got []string
+	for _, m := range regexp.MustCompile(`(\d+) -> (\d+)`).FindAllStringSubmatch(string(b), -1) {
+		got = append(got, m[1]+"->"+m[2])
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected DOT edges: got: %v want: %v", got, want)
+	}
+
+	b, err = os.ReadFile(tuples)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	got = nil
+	for tuple := range slices.Chunk(b, 16) {
+		got = append(got, fmt.Sprintf("%v->%v", binary.LittleEndian.Uint64(tuple), binary.LittleEndian.Uint64(tuple[8:])))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected edge tuples: got: %v want: %v", got, want)
+	}
+
+	if len(gen.files) != 3 {
+		t.Fatalf("unexpected written files: %v", gen.files)
+	}
+	for i, format := range []string{"simple", "dot", "edge-tuples"} {
+		if wf := gen.files[i]; wf.format != format || wf.sum == "" || wf.edges != gen.counter.edges {
+			t.Errorf("unexpected written file: %+v", wf)
+		}
+	}
+}
+
+func TestTeeFlags(t *testing.T) {
+	tests := [][]string{
+		{"-tee=dot:g.dot", "-snapshots=2"},
+		{"-tee=dot:g.dot", "-churn=0.1"},
+		{"-tee=dot:g.dot", "-tee=simple:g.dot"},
+		{"-o=g.dot", "-tee=dot:g.dot"},
+	}
+	for _, args := range tests {
+		c, fs, err := parseGenerate(args)
+		if err == nil {
+			_, err = c.newGeneration(fs)
+		}
+		if err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A tenant is a client of the server identified by an API key.
+type tenant struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+
+	// Rate is the maximum number of requests per second on
+	// average, in bursts of up to Burst requests. If 0, the rate
+	// is not limited.
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+
+	// MaxVertices and MaxEdges are the maximum number of vertices
+	// and edges of every generated graph. If 0, they are not
+	// limited.
+	MaxVertices int64 `json:"max_vertices"`
+	MaxEdges    int64 `json:"max_edges"`
+
+	// MaxJobs is the maximum number of asynchronous generations
+	// of the tenant running at the same time, and MaxQueued the
+	// maximum number of them queued or running. If 0, they are
+	// not limited.
+	MaxJobs   int `json:"max_jobs"`
+	MaxQueued int `json:"max_queued"`
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	// sem limits the number of jobs of the tenant running at the
+	// same time. It is created on first use.
+	sem chan struct{}
+}
+
+// A tenantConfig is the file of the -tenants flag.
+type tenantConfig struct {
+	Tenants []*tenant `json:"tenants"`
+}
+
+// tenants maps API keys to their tenants.
+type tenants map[string]*tenant
+
+// readTenants reads the named tenants file.
+func readTenants(name string) (tenants, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cfg tenantConfig
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, inputError{fmt.Errorf("%v: %w", name, err)}
+	}
+
+	ts := make(tenants)
+	for i, t := range cfg.Tenants {
+		switch {
+		case t == nil || t.Name == "":
+			return nil, inputError{fmt.Errorf("%v: tenant %v: missing name", name, i)}
+		case t.Key == "":
+			return nil, inputError{fmt.Errorf("%v: tenant %v: missing key", name, t.Name)}
+		case ts[t.Key] != nil:
+			return nil, inputError{fmt.Errorf("%v: tenant %v: duplicated key", name, t.Name)}
+		case t.Rate < 0 || math.IsInf(t.Rate, 0) || t.Burst < 0 || t.MaxVertices < 0 || t.MaxEdges < 0 || t.MaxJobs < 0 || t.MaxQueued < 0:
+			return nil, inputError{fmt.Errorf("%v: tenant %v: negative limit", name, t.Name)}
+		}
+		ts[t.Key] = t
+	}
+	return ts, nil
+}
+
+// allow reports whether the tenant can make a request at time now
+// without exceeding its rate. If not, it also returns how long it has
+// to wait.
+func (t *tenant) allow(now time.Time) (bool, time.Duration) {
+	if t.Rate == 0 {
+		return true, 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	burst := float64(max(t.Burst, 1))
+	if t.last.IsZero() {
+		t.tokens = burst
+	} else {
+		t.tokens = min(burst, t.tokens+now.Sub(t.last).Seconds()*t.Rate)
+	}
+	t.last = now
+	if t.tokens < 1 {
+		return false, time.Duration((1 - t.tokens) / t.Rate * float64(time.Second))
+	}
+	t.tokens--
+	return true, 0
+}
+
+// jobSlots returns the semaphore that limits the number of jobs of
+// the tenant running at the same time, or nil if it is not limited.
+// t can be nil.
+func (t *tenant) jobSlots() chan struct{} {
+	if t == nil || t.MaxJobs == 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.sem == nil {
+		t.sem = make(chan struct{}, t.MaxJobs)
+	}
+	return t.sem
+}
+
+// tenantKey is the context key of the tenant of a request.
+type tenantKey struct{}
+
+// tenantFrom returns the tenant of the request with context ctx, or
+// nil if the server has no tenants.
+func tenantFrom(ctx context.Context) *tenant {
+	t, _ := ctx.Value(tenantKey{}).(*tenant)
+	return t
+}
+
+// handler returns a handler that authenticates the requests by their
+// API key, sent as a bearer token, rejects them if their tenant
+// exceeds its rate and passes the rest to next. If ts is nil, all the
+// requests are passed to next.
+func (ts tenants) handler(next http.Handler) http.Handler {
+	if ts == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		t := ts[key]
+		if !ok || t == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, errors.New("invalid API key"))
+			return
+		}
+		if ok, wait := t.allow(time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			writeAPIError(w, http.StatusTooManyRequests, fmt.Errorf("rate limit of tenant %v exceeded", t.Name))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, t)))
+	})
+}
+
+// errQuota is returned when a generation exceeds the quotas of its
+// tenant.
+var errQuota = errors.New("quota exceeded")
+
+// limit makes gen stop once it exceeds the quotas of t. It returns an
+// error if c exceeds them beforehand, that is, if the graph is
+// unbounded or its number of vertices or expected number of edges is
+// over the quotas. Otherwise, it returns a function that reports
+// whether they were exceeded anyway, to be called once the graph has
+// been written. t can be nil.
+func (t *tenant) limit(c *genConfig, gen *generation) (func() error, error) {
+	var exceeded error
+	check := func() error { return exceeded }
+	if t == nil {
+		return check, nil
+	}
+
+	if c.infinite && (t.MaxVertices > 0 || t.MaxEdges > 0) {
+		return nil, fmt.Errorf("%w: unbounded graph", errQuota)
+	}
+	if t.MaxVertices > 0 && c.vertices > t.MaxVertices {
+		return nil, fmt.Errorf("%w: %v vertices > %v", errQuota, c.vertices, t.MaxVertices)
+	}
+	if t.MaxEdges > 0 {
+		edges, err := c.expectedEdges()
+		if err != nil {
+			return nil, err
+		}
+		if edges > float64(t.MaxEdges) {
+			return nil, fmt.Errorf("%w: %.0f expected edges > %v", errQuota, edges, t.MaxEdges)
+		}
+	}
+
+	g := gen.g
+	if t.MaxVertices > 0 {
+		vertices := g.vertices
+		g.vertices = func(yield func(vertex) bool) {
+			var n int64
+			for v := range vertices {
+				if n++; n > t.MaxVertices {
+					exceeded = fmt.Errorf("%w: more than %v vertices", errQuota, t.MaxVertices)
+					return
+				}
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+	if t.MaxEdges > 0 {
+		edges := g.edges
+		g.edges = func(yield func(edge) bool) {
+			var n int64
+			for e := range edges {
+				if n++; n > t.MaxEdges {
+					exceeded = fmt.Errorf("%w: more than %v edges", errQuota, t.MaxEdges)
+					return
+				}
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+	gen.g = g
+	return check, nil
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTenants(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr bool
+	}{
+		{data: `{"tenants": [{"name": "a", "key": "k1", "rate": 1}, {"name": "b", "key": "k2", "max_edges": 10, "max_jobs": 1, "max_queued": 2}]}`},
+		{data: `{"tenants": []}`},
+		{data: `{"tenants": [{"key": "k1"}]}`, wantErr: true},
+		{data: `{"tenants": [{"name": "a"}]}`, wantErr: true},
+		{data: `{"tenants": [{"name": "a", "key": "k"}, {"name": "b", "key": "k"}]}`, wantErr: true},
+		{data: `{"tenants": [{"name": "a", "key": "k", "rate": -1}]}`, wantErr: true},
+		{data: `{"tenants": [{"name": "a", "key": "k", "max_queued": -1}]}`, wantErr: true},
+		{data: `{"tenants": [{"name": "a", "key": "k", "unknown": 1}]}`, wantErr: true},
+		{data: `{"tenants": [null]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		name := filepath.Join(t.TempDir(), "tenants.json")
+		if err := os.WriteFile(name, []byte(tt.data), 0o644); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		_, err := readTenants(name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.data, err)
+		}
+	}
+}
+
+func TestTenantAllow(t *testing.T) {
+	tn := &tenant{Rate: 2, Burst: 3}
+	now := time.Now()
+	for i := range 3 {
+		if ok, _ := tn.allow(now); !ok {
+			t.Fatalf("request %v of burst rejected", i)
+		}
+	}
+	ok, wait := tn.allow(now)
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("unexpected result after burst: %v %v", ok, wait)
+	}
+	if ok, _ := tn.allow(now.Add(wait)); !ok {
+		t.Fatalf("request rejected after waiting")
+	}
+
+	unlimited := &tenant{}
+	for range 100 {
+		if ok, _ := unlimited.allow(now); !ok {
+			t.Fatalf("unlimited tenant rejected")
+		}
+	}
+}
+
+// newTenantServer returns a test server serving the JSON API for the
+// provided tenants.
+func newTenantServer(t *testing.T, ts tenants) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", handleGenerate)
+	newGraphAPI(t.TempDir(), 2).register(mux)
+	srv := httptest.NewServer(ts.handler(mux))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTenantsHandler(t *testing.T) {
+	srv := newTenantServer(t, tenants{
+		"k1": {Name: "a", Key: "k1", Rate: 0.001, Burst: 2},
+		"k2": {Name: "b", Key: "k2", MaxVertices: 10, MaxEdges: 5},
+	})
+
+	do := func(key, method, path, body string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp
+	}
+
+	tests := []struct {
+		key, method, path, body string
+		wantStatus              int
+	}{
+		{method: "GET", path: "/?n=5", wantStatus: http.StatusUnauthorized},
+		{key: "unknown", method: "GET", path: "/?n=5", wantStatus: http.StatusUnauthorized},
+		{key: "k1", method: "GET", path: "/?n=5", wantStatus: http.StatusOK},
+		{key: "k1", method: "GET", path: "/openapi.json", wantStatus: http.StatusOK},
+		{key: "k1", method: "GET", path: "/?n=5", wantStatus: http.StatusTooManyRequests},
+		{key: "k2", method: "GET", path: "/?n=11", wantStatus: http.StatusForbidden},
+		{key: "k2", method: "POST", path: "/graphs", body: `{"parameters": {"n": 11}}`, wantStatus: http.StatusForbidden},
+		{key: "k2", method: "GET", path: "/?n=10", wantStatus: http.StatusForbidden},
+		{key: "k2", method: "GET", path: "/?infinite=true", wantStatus: http.StatusForbidden},
+		{key: "k2", method: "POST", path: "/graphs", body: `{"parameters": {"n": 10, "prob": 0.1}}`, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := do(tt.key, tt.method, tt.path, tt.body)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%v %v %v: unexpected status: got: %v want: %v", tt.key, tt.method, tt.path, resp.StatusCode, tt.wantStatus)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Errorf("%v %v %v: missing Retry-After", tt.key, tt.method, tt.path)
+		}
+	}
+}
+
+func TestTenantQuota(t *testing.T) {
+	srv := newTenantServer(t, tenants{
+		"k1": {Name: "a", Key: "k1", MaxEdges: 5},
+		"k2": {Name: "b", Key: "k2"},
+	})
+
+	post := func(key, body string) jobStatus {
+		req, _ := http.NewRequest("POST", srv.URL+"/graphs", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		defer resp.Body.Close()
+		var job jobStatus
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("invalid job: %v", err)
+		}
+		return job
+	}
+	get := func(key, path string) *http.Response {
+		req, _ := http.NewRequest("GET", srv.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		return resp
+	}
+
+	// Graphs expected to exceed the quota are rejected up front.
+	resp := get("k1", "/?n=100&prob=0.5")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("unexpected status: %v", resp.StatusCode)
+	}
+
+	// The expected number of edges is within the quota, but
+	// triangle closure adds more.
+	job := post("k1", `{"parameters": {"n": 4, "trials": 5, "prob": 0.25, "closure": 1, "seed": 1}, "async": true}`)
+	for job.Status == jobQueued || job.Status == jobRunning {
+		time.Sleep(10 * time.Millisecond)
+		resp := get("k1", "/graphs/"+job.ID)
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("invalid job: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if job.Status != jobFailed || !strings.Contains(job.Error, errQuota.Error()) || job.Edges != 5 {
+		t.Errorf("unexpected job: %+v", job)
+	}
+
+	// Jobs are not visible to other tenants.
+	resp = get("k2", "/graphs/"+job.ID)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("job visible to another tenant: %v", resp.StatusCode)
+	}
+
+	// Streamed graphs report the error in a trailer.
+	resp = get("k1", "/?n=4&trials=5&prob=0.25&closure=1&seed=1&meta=false")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if n := strings.Count(string(body), "E: "); n != 5 {
+		t.Errorf("unexpected number of edges: %v", n)
+	}
+	if !strings.HasSuffix(string(body), "# error: "+errQuota.Error()+": more than 5 edges\n") {
+		t.Errorf("missing error record: %q", body)
+	}
+	if !strings.Contains(resp.Trailer.Get("Mkdigraph-Error"), errQuota.Error()) {
+		t.Errorf("unexpected trailer: %q", resp.Trailer.Get("Mkdigraph-Error"))
+	}
+}
+
+func TestTenantJobs(t *testing.T) {
+	api := newGraphAPI(t.TempDir(), 2)
+	defer api.close()
+	a := &tenant{Name: "a", MaxJobs: 1, MaxQueued: 2}
+	b := &tenant{Name: "b"}
+
+	start := func(tn *tenant, params url.Values) (*graphJob, error) {
+		c, gen, err := serverGeneration(params)
+		if err != nil {
+			t.Fatalf("generation error: %v", err)
+		}
+		exceeded, err := tn.limit(c, gen)
+		if err != nil {
+			t.Fatalf("limit error: %v", err)
+		}
+		return api.start(c, gen, tn, exceeded)
+	}
+	wait := func(job *graphJob, status string) {
+		for st := job.jsonStatus(); st.Status != status; st = job.jsonStatus() {
+			if st.Status != jobQueued && st.Status != jobRunning {
+				t.Fatalf("unexpected job: %+v", st)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// The first job of a takes its only slot, so the second one is
+	// queued and the third one is rejected.
+	slow := url.Values{"n": {"1000"}, "prob": {"1"}, "rate": {"1"}}
+	running, err := start(a, slow)
+	if err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	wait(running, jobRunning)
+	queued, err := start(a, slow)
+	if err != nil {
+		t.Fatalf("start error: %v", err)
+	}
+	if _, err := start(a, slow); !errors.Is(err, errJobQuota) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The queued job of a does not hold the other slot of the
+	// server, so the jobs of b still run.
+	for range 3 {
+		job, err := start(b, url.Values{"n": {"10"}, "prob": {"0.5"}})
+		if err != nil {
+			t.Fatalf("start error: %v", err)
+		}
+		wait(job, jobDone)
+	}
+	if st := queued.jsonStatus(); st.Status != jobQueued {
+		t.Errorf("unexpected job: %+v", st)
+	}
+
+	// Once a job of a finishes, the queued one runs and a can
+	// queue another one.
+	running.cancel()
+	wait(running, jobCanceled)
+	wait(queued, jobRunning)
+	if _, err := start(a, slow); err != nil {
+		t.Errorf("start error: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// serverTLSConfig returns the TLS configuration of a server with the
+// certificate and key in the provided PEM files. If clientCA is not
+// empty, clients must present a certificate signed by one of the CAs
+// in that PEM file.
+func serverTLSConfig(certFile, keyFile, clientCA string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCA != "" {
+		data, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, inputError{fmt.Errorf("%v: no certificates", clientCA)}
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// basicUsers maps user names to their passwords.
+type basicUsers map[string]string
+
+// readBasicUsers reads the named users file, which has a "user:password"
+// line per user. Empty lines and lines starting with "#" are ignored.
+func readBasicUsers(name string) (basicUsers, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	users := make(basicUsers)
+	s := bufio.NewScanner(f)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		user, password, ok := strings.Cut(line, ":")
+		if !ok || user == "" || password == "" {
+			return nil, inputError{fmt.Errorf("%v:%v: malformed user", name, lineno)}
+		}
+		if _, ok := users[user]; ok {
+			return nil, inputError{fmt.Errorf("%v:%v: duplicated user: %v", name, lineno, user)}
+		}
+		users[user] = password
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, inputError{fmt.Errorf("%v: no users", name)}
+	}
+	return users, nil
+}
+
+// handler returns a handler that passes the requests authenticated
+// with HTTP basic authentication to next. If users is nil, all the
+// requests are passed to next.
+func (users basicUsers) handler(next http.Handler) http.Handler {
+	if users == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		want, found := users[user]
+		if !ok || !found || subtle.ConstantTimeCompare([]byte(password), []byte(want)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mkdigraph", charset="UTF-8"`)
+			writeAPIError(w, http.StatusUnauthorized, errors.New("invalid user or password"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a self-signed certificate for 127.0.0.1, which can
+// also sign other certificates, and its private key as PEM files to
+// dir. It returns the names of the files.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key error: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mkdigraph"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("certificate error: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("key error: %v", err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir())
+	cfg, err := serverTLSConfig(certFile, keyFile, certFile)
+	if err != nil {
+		t.Fatalf("config error: %v", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(handleGenerate))
+	srv.TLS = cfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cfg.Certificates[0].Leaf)
+	clientCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("certificate error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		certs   []tls.Certificate
+		wantErr bool
+	}{
+		{name: "client certificate", certs: []tls.Certificate{clientCert}},
+		{name: "no client certificate", wantErr: true},
+	}
+	for _, tt := range tests {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: tt.certs},
+		}}
+		resp, err := client.Get(srv.URL + "/?n=5")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+		}
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%v: unexpected status: %v", tt.name, resp.StatusCode)
+			}
+		}
+	}
+
+	if _, err := serverTLSConfig(certFile, keyFile, keyFile); err == nil {
+		t.Errorf("client CA without certificates accepted")
+	}
+	if _, err := serverTLSConfig(keyFile, certFile, ""); err == nil {
+		t.Errorf("swapped certificate and key accepted")
+	}
+}
+
+func TestReadBasicUsers(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    basicUsers
+		wantErr bool
+	}{
+		{data: "# users\nalice:secret\n\nbob:pass:word\n", want: basicUsers{"alice": "secret", "bob": "pass:word"}},
+		{data: "alice\n", wantErr: true},
+		{data: "alice:\n", wantErr: true},
+		{data: ":secret\n", wantErr: true},
+		{data: "alice:a\nalice:b\n", wantErr: true},
+		{data: "# no users\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		name := filepath.Join(t.TempDir(), "users")
+		if err := os.WriteFile(name, []byte(tt.data), 0o600); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		got, err := readBasicUsers(name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.data, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%q: unexpected users: got: %v want: %v", tt.data, got, tt.want)
+			continue
+		}
+		for user, password := range tt.want {
+			if got[user] != password {
+				t.Errorf("%q: unexpected password of %v: %q", tt.data, user, got[user])
+			}
+		}
+	}
+}
+
+func TestBasicUsersHandler(t *testing.T) {
+	users := basicUsers{"alice": "secret"}
+	h := users.handler(http.HandlerFunc(handleGenerate))
+
+	tests := []struct {
+		user, password string
+		auth           bool
+		wantStatus     int
+	}{
+		{user: "alice", password: "secret", auth: true, wantStatus: http.StatusOK},
+		{user: "alice", password: "wrong", auth: true, wantStatus: http.StatusUnauthorized},
+		{user: "bob", password: "secret", auth: true, wantStatus: http.StatusUnauthorized},
+		{wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/?n=5", nil)
+		if tt.auth {
+			req.SetBasicAuth(tt.user, tt.password)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%v:%v: unexpected status: got: %v want: %v", tt.user, tt.password, rec.Code, tt.wantStatus)
+		}
+		if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%v:%v: missing WWW-Authenticate", tt.user, tt.password)
+		}
+	}
+}
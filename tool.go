@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+)
+
+// outputFlags are the output flags shared by the commands that read a
+// graph and write another one.
+type outputFlags struct {
+	outFile    string
+	emitDOT    bool
+	dotStrict  bool
+	dotName    string
+	dotTooltip string
+	dotURL     string
+	nul        bool
+	crlf       bool
+	idWidth    int
+}
+
+// register defines the output flags in fs.
+func (of *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&of.outFile, "o", "", "output file")
+	fs.BoolVar(&of.emitDOT, "dot", false, "emit DOT output")
+	fs.BoolVar(&of.dotStrict, "dot-strict", false, "declare the DOT graph strict")
+	fs.StringVar(&of.dotName, "dot-name", "", "identifier of the DOT graph")
+	fs.StringVar(&of.dotTooltip, "dot-tooltip", "", "`template` of the tooltips of the DOT nodes")
+	fs.StringVar(&of.dotURL, "dot-url", "", "`template` of the URLs of the DOT nodes")
+	fs.BoolVar(&of.nul, "z", false, "terminate records with NUL instead of newline")
+	fs.BoolVar(&of.crlf, "crlf", false, "terminate lines with CRLF instead of LF")
+	fs.IntVar(&of.idWidth, "id-width", 0, "minimum `width` of vertex IDs")
+}
+
+// write creates the output file and calls fn with an encoder that
+// writes to it. If fn returns an error, the output is discarded.
+func (of *outputFlags) write(fn func(enc encoder) error) error {
+	if of.nul && of.emitDOT {
+		return fmt.Errorf("-z cannot be combined with -dot")
+	}
+	if of.nul && of.crlf {
+		return fmt.Errorf("-z cannot be combined with -crlf")
+	}
+	if (of.dotStrict || of.dotName != "" || of.dotTooltip != "" || of.dotURL != "") && !of.emitDOT {
+		return fmt.Errorf("-dot-strict, -dot-name, -dot-tooltip and -dot-url require -dot")
+	}
+	dotTooltip, dotURL, err := parseNodeTemplates(of.dotTooltip, of.dotURL)
+	if err != nil {
+		return err
+	}
+	if of.idWidth < 0 || of.idWidth > maxIDWidth {
+		return fmt.Errorf("invalid ID width: %v", of.idWidth)
+	}
+
+	out, err := createOutput(of.outFile, false, false)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(out)
+
+	p := printer{idWidth: of.idWidth, nul: of.nul, crlf: of.crlf, dotStrict: of.dotStrict, dotName: of.dotName, dotTooltip: dotTooltip, dotURL: dotURL}
+	enc := p.newEncoder(bw, of.emitDOT)
+	enc.begin()
+	if err := fn(enc); err != nil {
+		out.abort()
+		return err
+	}
+	enc.end()
+
+	if err := bw.Flush(); err != nil {
+		out.abort()
+		return err
+	}
+	return out.commit()
+}
+
+// encodeElements writes the elements of seq using enc.
+func encodeElements(enc encoder, seq iter.Seq2[element, error]) error {
+	for elem, err := range seq {
+		if err != nil {
+			return err
+		}
+		if elem.isEdge {
+			enc.edge(elem.e)
+		} else {
+			enc.vertex(elem.v)
+		}
+	}
+	return nil
+}
+
+// parseToolFlags parses the flags of a command that reads a graph
+// from an optional input file and returns the opened input.
+func parseToolFlags(fs *flag.FlagSet, args []string) io.ReadCloser {
+	parseFlags(fs, args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	return in
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"iter"
+	"log"
+	"os"
+	"slices"
+	"strings"
+)
+
+// A cycleError reports a cycle of a graph.
+type cycleError struct {
+	// cycle are the vertices of the cycle, starting and ending
+	// with the same vertex.
+	cycle []int64
+}
+
+func (err *cycleError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("cycle: ")
+	for i, id := range err.cycle {
+		if i > 0 {
+			sb.WriteString(" -> ")
+		}
+		fmt.Fprint(&sb, id)
+	}
+	return sb.String()
+}
+
+// topoSort returns a topological order of the vertices of the graph
+// read from seq, or a *cycleError with the first cycle found by a
+// depth-first search. The vertices include the undeclared edge
+// endpoints. The search visits the vertices in order of appearance
+// and their out-neighbors in edge order, so the result only depends
+// on the input. The adjacency lists of the graph are kept in memory.
+func topoSort(seq iter.Seq2[element, error]) ([]int64, error) {
+	order, out, err := readAdjacency(seq)
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make(map[int64]int, len(out))
+
+	// frame is a vertex on the search path and the index of its
+	// next out-neighbor.
+	type frame struct {
+		id   int64
+		next int
+	}
+
+	var (
+		post  []int64
+		stack []frame
+	)
+	for _, root := range order {
+		if state[root] != unvisited {
+			continue
+		}
+		state[root] = active
+		stack = append(stack[:0], frame{id: root})
+		for len(stack) > 0 {
+			f := &stack[len(stack)-1]
+			if f.next == len(out[f.id]) {
+				state[f.id] = done
+				post = append(post, f.id)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			head := out[f.id][f.next]
+			f.next++
+			switch state[head] {
+			case unvisited:
+				state[head] = active
+				stack = append(stack, frame{id: head})
+			case active:
+				i := slices.IndexFunc(stack, func(f frame) bool { return f.id == head })
+				var cycle []int64
+				for _, f := range stack[i:] {
+					cycle = append(cycle, f.id)
+				}
+				return nil, &cycleError{cycle: append(cycle, head)}
+			}
+		}
+	}
+	slices.Reverse(post)
+	return post, nil
+}
+
+// readAdjacency reads the graph from seq and returns its vertices, in
+// order of appearance, and its adjacency lists. The vertices include
+// the undeclared edge endpoints, and the out-neighbors of every
+// vertex are in edge order.
+func readAdjacency(seq iter.Seq2[element, error]) (order []int64, out map[int64][]int64, err error) {
+	out = make(map[int64][]int64)
+	add := func(id int64) {
+		if _, ok := out[id]; !ok {
+			out[id] = nil
+			order = append(order, id)
+		}
+	}
+	for elem, err := range seq {
+		if err != nil {
+			return nil, nil, err
+		}
+		if !elem.isEdge {
+			add(elem.v.id)
+			continue
+		}
+		add(elem.e.tail)
+		add(elem.e.head)
+		out[elem.e.tail] = append(out[elem.e.tail], elem.e.head)
+	}
+	return order, out, nil
+}
+
+func runToposort(args []string) {
+	fs := flag.NewFlagSet("toposort", flag.ExitOnError)
+	fs.Usage = commandUsage(fs, "toposort [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	order, err := topoSort(decodeGraph(in))
+	var cerr *cycleError
+	if errors.As(err, &cerr) {
+		log.Print(cerr)
+		os.Exit(1)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	bw := bufio.NewWriter(os.Stdout)
+	for _, id := range order {
+		fmt.Fprintln(bw, id)
+	}
+	if err := bw.Flush(); err != nil {
+		fatal(err)
+	}
+}
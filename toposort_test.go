@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTopoSort(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int64
+		wantErr string
+	}{
+		{
+			input: "V: 0 A\nV: 1 B\nV: 2 C\nE: 0 1\nE: 1 2\nE: 0 2\n",
+			want:  []int64{0, 1, 2},
+		},
+		{
+			input: "V: 2 C\nV: 1 B\nV: 0 A\nV: 3 D\nE: 0 1\nE: 1 2\n",
+			want:  []int64{3, 0, 1, 2},
+		},
+		{
+			input: "E: 5 4\n",
+			want:  []int64{5, 4},
+		},
+		{
+			input:   "V: 0 A\nV: 1 B\nV: 2 C\nV: 3 D\nE: 0 1\nE: 1 2\nE: 2 3\nE: 3 1\n",
+			wantErr: "cycle: 1 -> 2 -> 3 -> 1",
+		},
+		{
+			input:   "V: 0 A\nE: 0 0\n",
+			wantErr: "cycle: 0 -> 0",
+		},
+		{
+			input:   "V: 0 A\nbad\n",
+			wantErr: `record 2: malformed record: "bad"`,
+		},
+	}
+	for _, tt := range tests {
+		got, err := topoSort(decodeSimple(strings.NewReader(tt.input)))
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%q: unexpected error: got: %v want: %v", tt.input, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.input, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q: unexpected order: got: %v want: %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tracing is the tracer of the generation. It is nil if tracing is
+// not configured.
+var tracing *tracer
+
+// exportTimeout is the maximum time spent exporting the spans.
+const exportTimeout = 10 * time.Second
+
+// A tracer records the spans of a generation and exports them to an
+// OpenTelemetry collector with the OTLP/HTTP protocol, JSON encoded.
+// The methods of a nil tracer and its nil spans do nothing.
+type tracer struct {
+	endpoint string
+	headers  map[string]string
+	service  string
+	traceID  [16]byte
+
+	mu    sync.Mutex
+	spans []*span
+}
+
+// newTracer returns a tracer configured by the standard OpenTelemetry
+// environment variables. It returns nil if no OTLP endpoint is set.
+func newTracer() (*tracer, error) {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return nil, nil
+	}
+
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil, nil
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+
+	protocol := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if protocol == "" {
+		protocol = os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	if protocol != "" && protocol != "http/json" {
+		return nil, usageError{fmt.Errorf("unsupported OTLP protocol: %q", protocol)}
+	}
+
+	headers := make(map[string]string)
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS"} {
+		for kv := range strings.SplitSeq(os.Getenv(env), ",") {
+			if strings.TrimSpace(kv) == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, usageError{fmt.Errorf("malformed %v: %q", env, kv)}
+			}
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	service := os.Getenv("OTEL_SERVICE_NAME")
+	if service == "" {
+		service = "mkdigraph"
+	}
+
+	t := &tracer{endpoint: endpoint, headers: headers, service: service}
+	rand.Read(t.traceID[:])
+	return t, nil
+}
+
+// A span is an operation of the generation.
+type span struct {
+	t      *tracer
+	name   string
+	id     [8]byte
+	parent *span
+	start  time.Time
+	end    time.Time
+	attrs  []any
+	err    error
+}
+
+// start starts a span with the provided name and attributes, given as
+// alternating keys and values. parent can be nil.
+func (t *tracer) start(name string, parent *span, attrs ...any) *span {
+	if t == nil {
+		return nil
+	}
+	s := &span{t: t, name: name, parent: parent, start: time.Now(), attrs: attrs}
+	rand.Read(s.id[:])
+
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return s
+}
+
+// set adds attributes to the span, given as alternating keys and
+// values.
+func (s *span) set(attrs ...any) {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.t.mu.Unlock()
+}
+
+// finish ends the span. If err is not nil, the span has failed.
+func (s *span) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	if s.end.IsZero() {
+		s.end, s.err = time.Now(), err
+	}
+}
+
+// abort ends the spans that are still open with err and exports all
+// of them. It is called before exiting with an error.
+func (t *tracer) abort(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	for _, s := range t.spans {
+		if s.end.IsZero() {
+			s.end, s.err = time.Now(), err
+		}
+	}
+	t.mu.Unlock()
+	t.flush()
+}
+
+// flush exports the finished spans, warning about errors, because
+// failing to export them must not fail the generation.
+func (t *tracer) flush() {
+	if t == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	if err := t.export(ctx); err != nil {
+		warn("trace export", "error", err)
+	}
+}
+
+// export sends the finished spans to the OTLP endpoint.
+func (t *tracer) export(ctx context.Context) error {
+	body, err := json.Marshal(t.request())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%v: %v", t.endpoint, resp.Status)
+	}
+	return nil
+}
+
+// OTLP status codes.
+const (
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+// otlpSpanInternal is the OTLP kind of the spans.
+const otlpSpanInternal = 1
+
+// request returns the OTLP export request of the finished spans.
+func (t *tracer) request() map[string]any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var spans []map[string]any
+	for _, s := range t.spans {
+		if s.end.IsZero() {
+			continue
+		}
+		o := map[string]any{
+			"traceId":           hex.EncodeToString(t.traceID[:]),
+			"spanId":            hex.EncodeToString(s.id[:]),
+			"name":              s.name,
+			"kind":              otlpSpanInternal,
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+			"attributes":        otlpAttrs(s.attrs),
+			"status":            map[string]any{"code": otlpStatusOK},
+		}
+		if s.parent != nil {
+			o["parentSpanId"] = hex.EncodeToString(s.parent.id[:])
+		}
+		if s.err != nil {
+			o["status"] = map[string]any{"code": otlpStatusError, "message": s.err.Error()}
+		}
+		spans = append(spans, o)
+	}
+
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": otlpAttrs([]any{"service.name", t.service, "service.version", version()}),
+			},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "mkdigraph"},
+				"spans": spans,
+			}},
+		}},
+	}
+}
+
+// otlpAttrs returns the OTLP representation of attributes given as
+// alternating keys and values.
+func otlpAttrs(attrs []any) []map[string]any {
+	kvs := make([]map[string]any, 0, len(attrs)/2)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		var v map[string]any
+		switch x := attrs[i+1].(type) {
+		case bool:
+			v = map[string]any{"boolValue": x}
+		case int:
+			v = map[string]any{"intValue": strconv.Itoa(x)}
+		case int64:
+			v = map[string]any{"intValue": strconv.FormatInt(x, 10)}
+		case float64:
+			v = map[string]any{"doubleValue": x}
+		case time.Duration:
+			v = map[string]any{"doubleValue": x.Seconds()}
+		default:
+			v = map[string]any{"stringValue": fmt.Sprint(x)}
+		}
+		kvs = append(kvs, map[string]any{"key": fmt.Sprint(attrs[i]), "value": v})
+	}
+	return kvs
+}
+
+// stageTimes accumulates the time spent in the stages of the
+// generation pipeline, which are interleaved because the graph is
+// streamed.
+type stageTimes struct {
+	// model is the time spent generating vertices and edges.
+	model time.Duration
+
+	// output is the time spent writing to the output file.
+	output time.Duration
+}
+
+// timeGraph returns a copy of g that accumulates the time spent
+// generating its vertices and edges.
+func (st *stageTimes) timeGraph(g graph) graph {
+	vertices := func(yield func(vertex) bool) {
+		t := time.Now()
+		for v := range g.vertices {
+			st.model += time.Since(t)
+			if !yield(v) {
+				return
+			}
+			t = time.Now()
+		}
+		st.model += time.Since(t)
+	}
+	edges := func(yield func(edge) bool) {
+		t := time.Now()
+		for e := range g.edges {
+			st.model += time.Since(t)
+			if !yield(e) {
+				return
+			}
+			t = time.Now()
+		}
+		st.model += time.Since(t)
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// timeWriter returns a writer that writes to w and accumulates the
+// time spent writing.
+func (st *stageTimes) timeWriter(w io.Writer) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		t := time.Now()
+		n, err := w.Write(p)
+		st.output += time.Since(t)
+		return n, err
+	})
+}
+
+// modelName returns the name of the model used to generate the graph.
+func (c *genConfig) modelName() string {
+	switch {
+	case c.plugin != "":
+		return "plugin"
+	case c.blocksFile != "" && c.propensity != "":
+		return "degree-corrected blocks"
+	case c.blocksFile != "":
+		return "blocks"
+	case c.commSizes != "":
+		return "lfr"
+	case c.infinite || c.snapshots > 0:
+		return "growing"
+	case c.profile != "":
+		return c.profile
+	case c.triad > 0:
+		return "holme-kim"
+	case c.outDegree != "" || c.inDegree != "":
+		return "configuration"
+	}
+	return "binomial"
+}
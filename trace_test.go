@@ -0,0 +1,211 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewTracer(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantNil      bool
+		wantErr      bool
+		wantEndpoint string
+		wantHeaders  map[string]string
+	}{
+		{
+			name:    "not configured",
+			wantNil: true,
+		},
+		{
+			name:         "base endpoint",
+			env:          map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/"},
+			wantEndpoint: "http://collector:4318/v1/traces",
+		},
+		{
+			name: "traces endpoint",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://traces:4318/custom",
+			},
+			wantEndpoint: "http://traces:4318/custom",
+		},
+		{
+			name: "headers",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":       "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_HEADERS":        "a=1, b=2",
+				"OTEL_EXPORTER_OTLP_TRACES_HEADERS": "b=3",
+			},
+			wantEndpoint: "http://collector:4318/v1/traces",
+			wantHeaders:  map[string]string{"a": "1", "b": "3"},
+		},
+		{
+			name: "disabled",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+				"OTEL_SDK_DISABLED":           "true",
+			},
+			wantNil: true,
+		},
+		{
+			name: "unsupported protocol",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed headers",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_HEADERS":  "a",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{
+				"OTEL_SDK_DISABLED",
+				"OTEL_EXPORTER_OTLP_ENDPOINT",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+				"OTEL_EXPORTER_OTLP_PROTOCOL",
+				"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL",
+				"OTEL_EXPORTER_OTLP_HEADERS",
+				"OTEL_EXPORTER_OTLP_TRACES_HEADERS",
+			} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			tr, err := newTracer()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if (tr == nil) != tt.wantNil {
+				t.Fatalf("unexpected tracer: %+v", tr)
+			}
+			if tr == nil {
+				return
+			}
+			if tr.endpoint != tt.wantEndpoint {
+				t.Errorf("unexpected endpoint: got: %v want: %v", tr.endpoint, tt.wantEndpoint)
+			}
+			for k, v := range tt.wantHeaders {
+				if tr.headers[k] != v {
+					t.Errorf("unexpected header %v: got: %q want: %q", k, tr.headers[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestTracerExport(t *testing.T) {
+	var (
+		got    map[string]any
+		header string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Token")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	tr := &tracer{endpoint: srv.URL, headers: map[string]string{"X-Token": "secret"}, service: "test"}
+	root := tr.start("generate", nil, "mkdigraph.n", int64(10))
+	child := tr.start("write", root)
+	child.set("mkdigraph.model.seconds", 1500*time.Millisecond)
+	child.finish(errors.New("write failed"))
+	tr.start("open", root)
+	root.finish(nil)
+	tr.flush()
+
+	if header != "secret" {
+		t.Errorf("unexpected header: %q", header)
+	}
+
+	scope := got["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)
+	spans := scope["spans"].([]any)
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of spans: %v", len(spans))
+	}
+	gen, write := spans[0].(map[string]any), spans[1].(map[string]any)
+	if gen["name"] != "generate" || write["name"] != "write" {
+		t.Errorf("unexpected spans: %v %v", gen["name"], write["name"])
+	}
+	if write["parentSpanId"] != gen["spanId"] || write["traceId"] != gen["traceId"] {
+		t.Errorf("unexpected parent: %v", write)
+	}
+	if _, ok := gen["parentSpanId"]; ok {
+		t.Errorf("root span with parent: %v", gen)
+	}
+	if status := write["status"].(map[string]any); status["code"] != float64(otlpStatusError) || status["message"] != "write failed" {
+		t.Errorf("unexpected status: %v", status)
+	}
+	attr := gen["attributes"].([]any)[0].(map[string]any)
+	if attr["key"] != "mkdigraph.n" || attr["value"].(map[string]any)["intValue"] != "10" {
+		t.Errorf("unexpected attribute: %v", attr)
+	}
+	attr = write["attributes"].([]any)[0].(map[string]any)
+	if attr["value"].(map[string]any)["doubleValue"] != 1.5 {
+		t.Errorf("unexpected attribute: %v", attr)
+	}
+
+	// Open spans are exported when aborting.
+	tr.abort(errors.New("fatal"))
+	spans = got["resourceSpans"].([]any)[0].(map[string]any)["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	if len(spans) != 3 {
+		t.Errorf("unexpected number of spans after abort: %v", len(spans))
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tr *tracer
+	s := tr.start("generate", nil)
+	s.set("k", "v")
+	s.finish(nil)
+	tr.abort(nil)
+	tr.flush()
+}
+
+func TestStageTimes(t *testing.T) {
+	var st stageTimes
+	g := graph{
+		vertices: func(yield func(vertex) bool) {
+			time.Sleep(10 * time.Millisecond)
+			yield(vertex{id: 0})
+		},
+		edges: func(yield func(edge) bool) {},
+	}
+	w := st.timeWriter(writerFunc(func(p []byte) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return len(p), nil
+	}))
+
+	for range st.timeGraph(g).vertices {
+		w.Write([]byte("v"))
+	}
+	if st.model < 10*time.Millisecond {
+		t.Errorf("unexpected model time: %v", st.model)
+	}
+	if st.output < 10*time.Millisecond {
+		t.Errorf("unexpected output time: %v", st.output)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// translitTable maps the letters of the Latin-1 Supplement and Latin
+// Extended-A blocks to their ASCII transliterations.
+var translitTable = func() map[rune]string {
+	groups := []struct{ ascii, letters string }{
+		{"A", "ÀÁÂÃÄÅĀĂĄ"}, {"a", "àáâãäåāăą"},
+		{"C", "ÇĆĈĊČ"}, {"c", "çćĉċč"},
+		{"D", "ÐĎĐ"}, {"d", "ðďđ"},
+		{"E", "ÈÉÊËĒĔĖĘĚ"}, {"e", "èéêëēĕėęě"},
+		{"G", "ĜĞĠĢ"}, {"g", "ĝğġģ"},
+		{"H", "ĤĦ"}, {"h", "ĥħ"},
+		{"I", "ÌÍÎÏĨĪĬĮİ"}, {"i", "ìíîïĩīĭįı"},
+		{"J", "Ĵ"}, {"j", "ĵ"},
+		{"K", "Ķ"}, {"k", "ķĸ"},
+		{"L", "ĹĻĽĿŁ"}, {"l", "ĺļľŀł"},
+		{"N", "ÑŃŅŇŊ"}, {"n", "ñńņňŉŋ"},
+		{"O", "ÒÓÔÕÖØŌŎŐ"}, {"o", "òóôõöøōŏő"},
+		{"R", "ŔŖŘ"}, {"r", "ŕŗř"},
+		{"S", "ŚŜŞŠ"}, {"s", "śŝşšſ"},
+		{"T", "ŢŤŦ"}, {"t", "ţťŧ"},
+		{"U", "ÙÚÛÜŨŪŬŮŰŲ"}, {"u", "ùúûüũūŭůűų"},
+		{"W", "Ŵ"}, {"w", "ŵ"},
+		{"Y", "ÝŶŸ"}, {"y", "ýÿŷ"},
+		{"Z", "ŹŻŽ"}, {"z", "źżž"},
+		{"AE", "Æ"}, {"ae", "æ"},
+		{"OE", "Œ"}, {"oe", "œ"},
+		{"IJ", "Ĳ"}, {"ij", "ĳ"},
+		{"TH", "Þ"}, {"th", "þ"},
+		{"ss", "ß"},
+	}
+	table := make(map[rune]string)
+	for _, g := range groups {
+		for _, r := range g.letters {
+			table[r] = g.ascii
+		}
+	}
+	return table
+}()
+
+// transliterate replaces the accented and ligature letters of s with
+// their ASCII transliterations, like "ü" with "u" and "ß" with "ss".
+// Other characters are kept.
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if t, ok := translitTable[r]; ok {
+			b.WriteString(t)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// A wordsEncoding is the character encoding of a words file.
+type wordsEncoding int
+
+// Encodings of the -words-encoding flag.
+const (
+	encodingUTF8 wordsEncoding = iota
+	encodingLatin1
+)
+
+// parseWordsEncoding parses the value of the -words-encoding flag.
+func parseWordsEncoding(s string) (wordsEncoding, error) {
+	switch s {
+	case "utf-8":
+		return encodingUTF8, nil
+	case "latin1":
+		return encodingLatin1, nil
+	}
+	return 0, fmt.Errorf("unknown words encoding: %q", s)
+}
+
+// decode returns s decoded from enc as UTF-8. Every Latin-1 byte is
+// the code point with the same value.
+func (enc wordsEncoding) decode(s string) string {
+	if enc != encodingLatin1 {
+		return s
+	}
+	r := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		r[i] = rune(s[i])
+	}
+	return string(r)
+}
+
+// A letterCase is the case of the word labels.
+type letterCase int
+
+// Cases of the -label-case flag.
+const (
+	caseKeep letterCase = iota
+	caseLower
+	caseUpper
+	caseTitle
+)
+
+// parseLetterCase parses the value of the -label-case flag.
+func parseLetterCase(s string) (letterCase, error) {
+	switch s {
+	case "keep":
+		return caseKeep, nil
+	case "lower":
+		return caseLower, nil
+	case "upper":
+		return caseUpper, nil
+	case "title":
+		return caseTitle, nil
+	}
+	return 0, fmt.Errorf("unknown label case: %q", s)
+}
+
+// apply returns the sorted words with their case changed to lc. Since
+// words that only differ in case become equal, they are deduplicated
+// again.
+func (lc letterCase) apply(words []string) []string {
+	if lc == caseKeep {
+		return words
+	}
+	for i, w := range words {
+		switch lc {
+		case caseLower:
+			words[i] = strings.ToLower(w)
+		case caseUpper:
+			words[i] = strings.ToUpper(w)
+		case caseTitle:
+			words[i] = strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+		}
+	}
+	slices.Sort(words)
+	return slices.Compact(words)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "Müller", want: "Muller"},
+		{s: "Straße", want: "Strasse"},
+		{s: "Æsir", want: "AEsir"},
+		{s: "Łódź", want: "Lodz"},
+		{s: "Dvořák", want: "Dvorak"},
+		{s: "日本", want: "日本"},
+	}
+	for _, tt := range tests {
+		if got := transliterate(tt.s); got != tt.want {
+			t.Errorf("%q: got: %q, want: %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestReadWordsTransliterate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "words")
+	if err := os.WriteFile(name, []byte("Müller\nMuller\nCrème\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readWords(name, encodingUTF8, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Creme", "Muller"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected words: got: %v, want: %v", got, want)
+	}
+
+	got, err = readWords(name, encodingUTF8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Crme", "Mller", "Muller"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected words: got: %v, want: %v", got, want)
+	}
+}
+
+func TestReadWordsLatin1(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "words")
+	if err := os.WriteFile(name, []byte("M\xfcller\nStra\xdfe\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readWords(name, encodingLatin1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Muller", "Strasse"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected words: got: %v, want: %v", got, want)
+	}
+
+	got, err = readWords(name, encodingUTF8, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Mller", "Strae"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected words: got: %v, want: %v", got, want)
+	}
+}
+
+func TestParseWordsEncoding(t *testing.T) {
+	if enc, err := parseWordsEncoding("latin1"); err != nil || enc != encodingLatin1 {
+		t.Errorf("unexpected encoding: %v, %v", enc, err)
+	}
+	if _, err := parseWordsEncoding("latin-1"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestLetterCase(t *testing.T) {
+	words := []string{"Apple", "aPPLE", "banana"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "keep", want: []string{"Apple", "aPPLE", "banana"}},
+		{name: "lower", want: []string{"apple", "banana"}},
+		{name: "upper", want: []string{"APPLE", "BANANA"}},
+		{name: "title", want: []string{"Apple", "Banana"}},
+	}
+	for _, tt := range tests {
+		lc, err := parseLetterCase(tt.name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := lc.apply(slices.Clone(words)); !slices.Equal(got, tt.want) {
+			t.Errorf("%v: got: %v, want: %v", tt.name, got, tt.want)
+		}
+	}
+	if _, err := parseLetterCase("turkish"); err == nil {
+		t.Error("expected error")
+	}
+}
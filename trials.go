@@ -0,0 +1,239 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"iter"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// readTrials reads a trials file. Every line contains a vertex ID
+// and its number of edge creation trials, either as an integer or as
+// a degree distribution, separated by whitespace. Empty lines and
+// lines starting with "#" are ignored. The IDs are translated to
+// generation IDs using start and stride, and must belong to one of
+// the n generated vertices.
+func readTrials(name string, n, start, stride int64) (map[int64]degreeDist, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	trials := make(map[int64]degreeDist)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%v:%v: malformed line: %q", name, lineno, line)
+		}
+		id, err := parseID(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", name, lineno, err)
+		}
+		if id < start || (id-start)%stride != 0 || (id-start)/stride >= n {
+			return nil, fmt.Errorf("%v:%v: vertex is not generated: %v", name, lineno, id)
+		}
+		gid := (id - start) / stride
+		if _, ok := trials[gid]; ok {
+			return nil, fmt.Errorf("%v:%v: duplicated vertex: %v", name, lineno, id)
+		}
+		dist, err := parseTrials(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", name, lineno, err)
+		}
+		trials[gid] = dist
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return trials, nil
+}
+
+// parseTrials parses a number of trials, which is either an integer
+// or a degree distribution.
+func parseTrials(s string) (degreeDist, error) {
+	if !strings.Contains(s, ":") {
+		k, err := strconv.Atoi(s)
+		if err != nil || k < 0 {
+			return nil, fmt.Errorf("invalid number of trials: %q", s)
+		}
+		return func(*rand.Rand) int { return k }, nil
+	}
+	return parseDegreeDist(s)
+}
+
+// parseVertexRange parses a range of vertices of a graph with n
+// vertices. It has the form "a:b" and comprises the vertices with
+// generation IDs in [a, b). If b is omitted, it is n.
+func parseVertexRange(s string, n int64) (start, end int64, err error) {
+	a, b, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed vertex range: %q", s)
+	}
+	if start, err = strconv.ParseInt(a, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed vertex range: %q", s)
+	}
+	end = n
+	if b != "" {
+		if end, err = strconv.ParseInt(b, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("malformed vertex range: %q", s)
+		}
+	}
+	if start < 0 || start >= end || end > n {
+		return 0, 0, fmt.Errorf("invalid vertex range: %q", s)
+	}
+	return start, end, nil
+}
+
+// trialBatch is the number of consecutive vertices whose edges are
+// generated together by a worker of [trialGraph].
+const trialBatch = 1024
+
+// trialGraph returns the vertices with IDs in [start, end) of a
+// binomial graph with n vertices, and the edges whose tails are these
+// vertices. Every vertex performs a number of edge creation trials
+// given by trials, or def if it is not in trials. Every trial
+// succeeds with probability p and creates an edge to a random vertex.
+// Loops and multiple edges are discarded unless allowed.
+//
+// The edges of every vertex are drawn from [vertexRNG], so they only
+// depend on algo, seed and the ID of the vertex. Edges are generated
+// in tail order, so the graph can be generated in disjoint ranges of
+// vertices, or resumed from the vertex where it was interrupted. For
+// the same reason, the edges of batches of vertices can be generated
+// concurrently by the provided number of workers without changing the
+// graph.
+func trialGraph(n int64, trials map[int64]degreeDist, def int, p float64, loops, multiedges bool, algo rngAlgorithm, seed uint64, start, end int64, workers int, vlabel func(id int64) string) graph {
+	vertices := func(yield func(vertex) bool) {
+		for id := start; id < end; id++ {
+			if !yield(vertex{id: id, label: vlabel(id)}) {
+				return
+			}
+		}
+	}
+
+	// batch appends the edges of the vertices in [lo, hi) to
+	// dst.
+	batch := func(dst []edge, lo, hi int64) []edge {
+		seen := make(map[int64]struct{})
+		for tail := lo; tail < hi; tail++ {
+			r := vertexRNG(algo, seed, tail)
+			t := def
+			if dist, ok := trials[tail]; ok {
+				t = dist(r)
+			}
+			clear(seen)
+			for range t {
+				if r.Float64() >= p {
+					continue
+				}
+				head := r.Int64N(n)
+				if head == tail && !loops {
+					continue
+				}
+				if !multiedges {
+					if _, ok := seen[head]; ok {
+						continue
+					}
+					seen[head] = struct{}{}
+				}
+				dst = append(dst, edge{tail: tail, head: head})
+			}
+		}
+		return dst
+	}
+
+	edges := func(yield func(edge) bool) {
+		var buf []edge
+		for lo := start; lo < end; {
+			hi := lo + min(trialBatch, end-lo)
+			buf = batch(buf[:0], lo, hi)
+			for _, e := range buf {
+				if !yield(e) {
+					return
+				}
+			}
+			lo = hi
+		}
+	}
+	if workers > 1 {
+		edges = parallelBatches(start, end, workers, batch)
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// parallelBatches returns an iterator over the edges of the vertices
+// in [start, end). The edges of every batch of [trialBatch] vertices
+// are appended by batch to an empty slice. Batches are generated
+// concurrently by the provided number of workers, but their edges are
+// yielded in order.
+func parallelBatches(start, end int64, workers int, batch func(dst []edge, lo, hi int64) []edge) iter.Seq[edge] {
+	type job struct {
+		lo, hi int64
+		res    chan []edge
+	}
+
+	return func(yield func(edge) bool) {
+		done := make(chan struct{})
+		jobs := make(chan job)
+
+		// results holds the pending batches in order. Its
+		// capacity bounds the number of batches held in
+		// memory.
+		results := make(chan chan []edge, 2*workers)
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Go(func() {
+				for j := range jobs {
+					j.res <- batch(nil, j.lo, j.hi)
+				}
+			})
+		}
+		wg.Go(func() {
+			defer close(jobs)
+			defer close(results)
+			for lo := start; lo < end; {
+				hi := lo + min(trialBatch, end-lo)
+				j := job{lo: lo, hi: hi, res: make(chan []edge, 1)}
+				select {
+				case results <- j.res:
+				case <-done:
+					return
+				}
+				select {
+				case jobs <- j:
+				case <-done:
+					return
+				}
+				lo = hi
+			}
+		})
+		defer wg.Wait()
+		defer close(done)
+
+		for res := range results {
+			for _, e := range <-res {
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestReadTrials(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "trials")
+	data := "# vertex trials\n10 3\n\n14\tconst:7\n"
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	trials, err := readTrials(name, 5, 10, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trials) != 2 {
+		t.Fatalf("unexpected number of entries: got: %v want: 2", len(trials))
+	}
+	if got := trials[0](nil); got != 3 {
+		t.Errorf("unexpected trials of vertex 10: got: %v want: 3", got)
+	}
+	if got := trials[2](nil); got != 7 {
+		t.Errorf("unexpected trials of vertex 14: got: %v want: 7", got)
+	}
+}
+
+func TestReadTrialsInvalid(t *testing.T) {
+	inputs := []string{
+		"10\n",
+		"10 3 4\n",
+		"x 3\n",
+		"10 -1\n",
+		"10 x\n",
+		"10 const\n",
+		"9 3\n",
+		"11 3\n",
+		"20 3\n",
+		"10 3\n10 4\n",
+	}
+	for _, input := range inputs {
+		name := filepath.Join(t.TempDir(), "trials")
+		if err := os.WriteFile(name, []byte(input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readTrials(name, 5, 10, 2); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+}
+
+func TestTrialGraph(t *testing.T) {
+	const n = 50
+
+	trials := map[int64]degreeDist{
+		0: func(*rand.Rand) int { return 40 },
+		1: func(*rand.Rand) int { return 0 },
+	}
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	g := trialGraph(n, trials, 2, 1, false, false, rngPCG, 1, 0, n, 1, vlabel)
+
+	out := make(map[int64]int)
+	seen := make(map[edge]bool)
+	for e := range g.edges {
+		if e.tail == e.head {
+			t.Errorf("unexpected loop: %v", e)
+		}
+		if seen[e] {
+			t.Errorf("unexpected multiple edge: %v", e)
+		}
+		seen[e] = true
+		out[e.tail]++
+	}
+	if out[0] <= 2 || out[0] > 40 {
+		t.Errorf("unexpected out-degree of vertex 0: %v", out[0])
+	}
+	if out[1] != 0 {
+		t.Errorf("unexpected out-degree of vertex 1: got: %v want: 0", out[1])
+	}
+	for id := int64(2); id < n; id++ {
+		if out[id] > 2 {
+			t.Errorf("unexpected out-degree of vertex %v: got: %v want: <= 2", id, out[id])
+		}
+	}
+}
+
+func TestTrialGraphStart(t *testing.T) {
+	const n = 50
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	full := slices.Collect(trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, 0, n, 1, vlabel).edges)
+	i := slices.IndexFunc(full, func(e edge) bool { return e.tail >= 20 })
+	got := slices.Collect(trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, 20, n, 1, vlabel).edges)
+	if !slices.Equal(got, full[i:]) {
+		t.Errorf("resumed edges differ:\ngot: %v\nwant: %v", got, full[i:])
+	}
+
+	other := slices.Collect(trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 43, 0, n, 1, vlabel).edges)
+	if slices.Equal(other, full) {
+		t.Error("different seeds generate the same graph")
+	}
+}
+
+func TestTrialGraphWorkers(t *testing.T) {
+	const n = 3*trialBatch + 100
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	for _, start := range []int64{0, trialBatch + 7} {
+		want := slices.Collect(trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, start, n, 1, vlabel).edges)
+		for _, workers := range []int{2, 3, 8} {
+			got := slices.Collect(trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, start, n, workers, vlabel).edges)
+			if !slices.Equal(got, want) {
+				t.Errorf("start=%v workers=%v: edges differ from a single worker", start, workers)
+			}
+		}
+	}
+
+	// Stopping the iteration early must not leak or block the
+	// workers.
+	var got []edge
+	for e := range trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, 0, n, 4, vlabel).edges {
+		if len(got) == 10 {
+			break
+		}
+		got = append(got, e)
+	}
+	want := slices.Collect(trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, 0, n, 1, vlabel).edges)[:10]
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected edges:\ngot: %v\nwant: %v", got, want)
+	}
+}
+
+func TestTrialGraphRanges(t *testing.T) {
+	const n = 100
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	full := trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, 0, n, 1, vlabel)
+	wantVertices := slices.Collect(full.vertices)
+	wantEdges := slices.Collect(full.edges)
+
+	var gotVertices []vertex
+	var gotEdges []edge
+	for _, r := range [][2]int64{{0, 10}, {10, 11}, {11, 64}, {64, n}} {
+		g := trialGraph(n, nil, 5, 0.5, false, false, rngPCG, 42, r[0], r[1], 1, vlabel)
+		gotVertices = slices.AppendSeq(gotVertices, g.vertices)
+		gotEdges = slices.AppendSeq(gotEdges, g.edges)
+	}
+	if !slices.Equal(gotVertices, wantVertices) {
+		t.Errorf("vertices of the ranges differ:\ngot: %v\nwant: %v", gotVertices, wantVertices)
+	}
+	if !slices.Equal(gotEdges, wantEdges) {
+		t.Errorf("edges of the ranges differ:\ngot: %v\nwant: %v", gotEdges, wantEdges)
+	}
+}
+
+func TestParseVertexRange(t *testing.T) {
+	tests := []struct {
+		s          string
+		start, end int64
+		wantErr    bool
+	}{
+		{s: "0:10", start: 0, end: 10},
+		{s: "5:", start: 5, end: 100},
+		{s: "99:100", start: 99, end: 100},
+		{s: "10", wantErr: true},
+		{s: "a:b", wantErr: true},
+		{s: "5:5", wantErr: true},
+		{s: "-1:5", wantErr: true},
+		{s: "0:101", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := parseVertexRange(tt.s, 100)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.s, err)
+			continue
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("%q: unexpected range: got: %v:%v, want: %v:%v", tt.s, start, end, tt.start, tt.end)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A weightedNames is a list of names with probabilities.
+type weightedNames struct {
+	names []string
+
+	// cum holds the cumulative probability of every name and the
+	// names that precede it.
+	cum []float64
+}
+
+// parseWeightedNames parses a comma-separated list of name:weight
+// pairs. Weights are normalized to probabilities. what is the kind of
+// the names, used in error messages.
+func parseWeightedNames(s, what string) (weightedNames, error) {
+	var (
+		wn    weightedNames
+		total float64
+	)
+	seen := make(map[string]bool)
+	for field := range strings.SplitSeq(s, ",") {
+		name, weight, ok := strings.Cut(field, ":")
+		if !ok || name == "" {
+			return weightedNames{}, fmt.Errorf("malformed %v: %q", what, field)
+		}
+		if seen[name] {
+			return weightedNames{}, fmt.Errorf("duplicated %v: %q", what, name)
+		}
+		seen[name] = true
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil || w <= 0 {
+			return weightedNames{}, fmt.Errorf("invalid weight of %v %q: %q", what, name, weight)
+		}
+		total += w
+		wn.names = append(wn.names, name)
+		wn.cum = append(wn.cum, total)
+	}
+	for i := range wn.cum {
+		wn.cum[i] /= total
+	}
+	wn.cum[len(wn.cum)-1] = 1
+	return wn, nil
+}
+
+// pick returns the index of the name chosen by u, which is a float64
+// in [0, 1).
+func (wn weightedNames) pick(u float64) int {
+	for i, c := range wn.cum {
+		if u < c {
+			return i
+		}
+	}
+	return len(wn.cum) - 1
+}
+
+// index returns the index of the provided name. It returns -1 if the
+// name is not in wn.
+func (wn weightedNames) index(name string) int {
+	for i, n := range wn.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// A typeSchema assigns types to vertices and restricts the edges
+// between them.
+type typeSchema struct {
+	types weightedNames
+
+	// labels returns the label of a vertex of every type given its
+	// generation ID, indexed by type. If the function of a type is
+	// nil, the label of the vertex is kept.
+	labels []func(id int64) string
+
+	// allowed is the set of allowed pairs of tail and head types,
+	// indexed by type. If nil, all edges are allowed.
+	allowed map[[2]int]bool
+
+	// seed determines the types of the vertices.
+	seed uint64
+}
+
+// parseVertexTypes parses the value of the -vertex-types flag, which
+// is a comma-separated list of type:weight pairs.
+func parseVertexTypes(s string) (weightedNames, error) {
+	return parseWeightedNames(s, "vertex type")
+}
+
+// parseTypeEdges parses the value of the -type-edges flag, which is a
+// comma-separated list of tail>head pairs of types. It returns the
+// set of allowed pairs indexed by type.
+func parseTypeEdges(s string, types weightedNames) (map[[2]int]bool, error) {
+	index := func(name string) (int, error) {
+		if i := types.index(name); i >= 0 {
+			return i, nil
+		}
+		return 0, fmt.Errorf("unknown vertex type: %q", name)
+	}
+
+	allowed := make(map[[2]int]bool)
+	for field := range strings.SplitSeq(s, ",") {
+		tail, head, ok := strings.Cut(field, ">")
+		if !ok {
+			return nil, fmt.Errorf("malformed type edge: %q", field)
+		}
+		t, err := index(tail)
+		if err != nil {
+			return nil, err
+		}
+		h, err := index(head)
+		if err != nil {
+			return nil, err
+		}
+		allowed[[2]int{t, h}] = true
+	}
+	return allowed, nil
+}
+
+// parseTypeWords parses the value of the -type-words flag, which is a
+// comma-separated list of type=file pairs. It returns the words file
+// of every type, indexed by type.
+func parseTypeWords(s string, types weightedNames) (map[int]string, error) {
+	files := make(map[int]string)
+	for field := range strings.SplitSeq(s, ",") {
+		name, file, ok := strings.Cut(field, "=")
+		if !ok || file == "" {
+			return nil, fmt.Errorf("malformed type words: %q", field)
+		}
+		i := types.index(name)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown vertex type: %q", name)
+		}
+		files[i] = file
+	}
+	return files, nil
+}
+
+// typeOf returns the index of the type of the vertex with the
+// provided ID. It only depends on the seed of ts and id.
+func (ts typeSchema) typeOf(id int64) int {
+	return ts.types.pick(unitFloat(vertexHash(ts.seed, id, saltType)))
+}
+
+// withTypes returns a copy of g where every vertex is assigned a type
+// of ts and labeled accordingly, and the edges whose pair of types is
+// not allowed are discarded.
+func withTypes(g graph, ts typeSchema) graph {
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			i := ts.typeOf(v.id)
+			v.vtype = ts.types.names[i]
+			if ts.labels[i] != nil {
+				v.label = ts.labels[i](v.id)
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := g.edges
+	if ts.allowed != nil {
+		edges = func(yield func(edge) bool) {
+			for e := range g.edges {
+				if !ts.allowed[[2]int{ts.typeOf(e.tail), ts.typeOf(e.head)}] {
+					continue
+				}
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// edgeTypes assigns relationship types to edges.
+type edgeTypes struct {
+	types weightedNames
+
+	// seed determines the types of the edges.
+	seed uint64
+}
+
+// parseEdgeTypes parses the value of the -edge-types flag, which is a
+// comma-separated list of type:weight pairs.
+func parseEdgeTypes(s string, seed uint64) (edgeTypes, error) {
+	types, err := parseWeightedNames(s, "edge type")
+	if err != nil {
+		return edgeTypes{}, err
+	}
+	return edgeTypes{types: types, seed: seed}, nil
+}
+
+// typeOf returns the type of e. It only depends on the seed of et and
+// the endpoints of e, so parallel edges have the same type and a
+// removed edge has the type it was added with.
+func (et edgeTypes) typeOf(e edge) string {
+	h := mix64(vertexHash(et.seed, e.tail, saltEdgeType) ^ uint64(e.head))
+	return et.types.names[et.types.pick(unitFloat(h))]
+}
+
+// withEdgeTypes returns a copy of g where every edge is assigned a
+// type of et.
+func withEdgeTypes(g graph, et edgeTypes) graph {
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			e.etype = et.typeOf(e)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: g.vertices, edges: edges}
+}
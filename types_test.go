@@ -0,0 +1,194 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseWeightedNames(t *testing.T) {
+	wn, err := parseWeightedNames("user:3,product:1", "vertex type")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"user", "product"}; !slices.Equal(wn.names, want) {
+		t.Errorf("unexpected names: got: %v, want: %v", wn.names, want)
+	}
+	if want := []float64{0.75, 1}; !slices.Equal(wn.cum, want) {
+		t.Errorf("unexpected probabilities: got: %v, want: %v", wn.cum, want)
+	}
+	if i := wn.pick(0.8); i != 1 {
+		t.Errorf("unexpected pick: got: %v, want: 1", i)
+	}
+	if i := wn.index("product"); i != 1 {
+		t.Errorf("unexpected index: got: %v, want: 1", i)
+	}
+	if i := wn.index("shop"); i != -1 {
+		t.Errorf("unexpected index: got: %v, want: -1", i)
+	}
+
+	invalid := []string{
+		"",
+		"user",
+		":1",
+		"user:0",
+		"user:-1",
+		"user:x",
+		"user:1,user:2",
+	}
+	for _, s := range invalid {
+		if _, err := parseWeightedNames(s, "vertex type"); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestParseTypeEdges(t *testing.T) {
+	types := weightedNames{names: []string{"user", "product"}, cum: []float64{0.5, 1}}
+
+	allowed, err := parseTypeEdges("user>product,user>user", types)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[[2]int]bool{{0, 1}: true, {0, 0}: true}
+	if len(allowed) != len(want) {
+		t.Fatalf("unexpected allowed pairs: got: %v, want: %v", allowed, want)
+	}
+	for k := range want {
+		if !allowed[k] {
+			t.Errorf("pair %v is not allowed", k)
+		}
+	}
+
+	invalid := []string{"", "user", "user>shop", "shop>user"}
+	for _, s := range invalid {
+		if _, err := parseTypeEdges(s, types); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestParseTypeWords(t *testing.T) {
+	types := weightedNames{names: []string{"user", "product"}, cum: []float64{0.5, 1}}
+
+	files, err := parseTypeWords("product=products.txt", types)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[1] != "products.txt" {
+		t.Errorf("unexpected files: %v", files)
+	}
+
+	invalid := []string{"", "user", "user=", "shop=shops.txt"}
+	for _, s := range invalid {
+		if _, err := parseTypeWords(s, types); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestTypeSchemaTypeOf(t *testing.T) {
+	types, err := parseVertexTypes("user:0.8,product:0.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ts := typeSchema{types: types, seed: 1}
+
+	const n = 10000
+	var users int
+	for id := range int64(n) {
+		if ts.typeOf(id) == 0 {
+			users++
+		}
+	}
+	if frac := float64(users) / n; math.Abs(frac-0.8) > 0.02 {
+		t.Errorf("unexpected fraction of users: %v", frac)
+	}
+}
+
+func TestWithTypes(t *testing.T) {
+	types, err := parseVertexTypes("user:1,product:1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ts := typeSchema{
+		types:   types,
+		labels:  []func(int64) string{nil, func(int64) string { return "p" }},
+		allowed: map[[2]int]bool{{0, 1}: true},
+		seed:    1,
+	}
+
+	var (
+		vs []vertex
+		es []edge
+	)
+	for id := range int64(20) {
+		vs = append(vs, vertex{id: id, label: "v"})
+		for head := range int64(20) {
+			es = append(es, edge{tail: id, head: head})
+		}
+	}
+	g := withTypes(graph{vertices: slices.Values(vs), edges: slices.Values(es)}, ts)
+
+	for v := range g.vertices {
+		i := ts.typeOf(v.id)
+		if v.vtype != types.names[i] {
+			t.Errorf("vertex %v: unexpected type: got: %q, want: %q", v.id, v.vtype, types.names[i])
+		}
+		if want := []string{"v", "p"}[i]; v.label != want {
+			t.Errorf("vertex %v: unexpected label: got: %q, want: %q", v.id, v.label, want)
+		}
+	}
+
+	var n int
+	for e := range g.edges {
+		if ts.typeOf(e.tail) != 0 || ts.typeOf(e.head) != 1 {
+			t.Errorf("edge %v -> %v is not allowed", e.tail, e.head)
+		}
+		n++
+	}
+	if n == 0 {
+		t.Error("all edges were discarded")
+	}
+}
+
+func TestWithEdgeTypes(t *testing.T) {
+	et, err := parseEdgeTypes("FOLLOWS:0.7,BLOCKS:0.3", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var es []edge
+	for tail := range int64(100) {
+		for head := range int64(100) {
+			es = append(es, edge{tail: tail, head: head})
+		}
+	}
+	g := withEdgeTypes(graph{vertices: slices.Values([]vertex{}), edges: slices.Values(es)}, et)
+
+	var follows, n int
+	for e := range g.edges {
+		switch e.etype {
+		case "FOLLOWS":
+			follows++
+		case "BLOCKS":
+		default:
+			t.Fatalf("edge %v -> %v: unexpected type: %q", e.tail, e.head, e.etype)
+		}
+		if got := et.typeOf(edge{tail: e.tail, head: e.head, removed: true}); got != e.etype {
+			t.Errorf("edge %v -> %v: type of removed edge: got: %q, want: %q", e.tail, e.head, got, e.etype)
+		}
+		n++
+	}
+	if frac := float64(follows) / float64(n); math.Abs(frac-0.7) > 0.02 {
+		t.Errorf("unexpected fraction of follows: %v", frac)
+	}
+
+	if _, err := parseEdgeTypes("FOLLOWS", 1); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"iter"
+	"os"
+	"slices"
+)
+
+// validateGraph returns the problems found in the graph read from
+// seq. A graph is well-formed if every vertex is declared once and
+// every edge endpoint is declared. If simple is true, loops and
+// multiple edges are reported too. Reading stops at the first decoding
+// error.
+func validateGraph(seq iter.Seq2[element, error], simple bool) []error {
+	var problems []error
+
+	declared := make(map[int64]struct{})
+	referenced := make(map[int64]struct{})
+	seen := make(map[edge]struct{})
+	for elem, err := range seq {
+		if err != nil {
+			problems = append(problems, err)
+			break
+		}
+
+		if !elem.isEdge {
+			id := elem.v.id
+			if _, ok := declared[id]; ok {
+				problems = append(problems, fmt.Errorf("duplicated vertex: %v", id))
+			}
+			declared[id] = struct{}{}
+			continue
+		}
+
+		e := elem.e
+		referenced[e.tail] = struct{}{}
+		referenced[e.head] = struct{}{}
+		if !simple {
+			continue
+		}
+		if e.tail == e.head {
+			problems = append(problems, fmt.Errorf("loop: %v -> %v", e.tail, e.head))
+		}
+		key := edge{tail: e.tail, head: e.head}
+		if _, ok := seen[key]; ok {
+			problems = append(problems, fmt.Errorf("multiple edge: %v -> %v", e.tail, e.head))
+		}
+		seen[key] = struct{}{}
+	}
+
+	var undeclared []int64
+	for id := range referenced {
+		if _, ok := declared[id]; !ok {
+			undeclared = append(undeclared, id)
+		}
+	}
+	slices.Sort(undeclared)
+	for _, id := range undeclared {
+		problems = append(problems, fmt.Errorf("undeclared vertex: %v", id))
+	}
+
+	return problems
+}
+
+func runValidate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	simple := fs.Bool("simple", false, "report loops and multiple edges")
+	fs.Usage = commandUsage(fs, "validate [flags] [file]")
+	in := parseToolFlags(fs, args)
+	defer in.Close()
+
+	problems := validateGraph(decodeGraph(in), *simple)
+	for _, err := range problems {
+		reportError(exitInvalid, err)
+	}
+	if len(problems) > 0 {
+		os.Exit(exitInvalid)
+	}
+}
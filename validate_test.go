@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGraph(t *testing.T) {
+	tests := []struct {
+		input  string
+		simple bool
+		want   []string
+	}{
+		{
+			input:  "V: 0 A\nV: 1 B\nE: 0 1\nE: 1 0\n",
+			simple: true,
+			want:   nil,
+		},
+		{
+			input:  "E: 0 1\nV: 0 A\nV: 1 B\n",
+			simple: false,
+			want:   nil,
+		},
+		{
+			input:  "V: 0 A\nV: 0 B\nE: 0 2\nE: 0 0\nE: 0 0\n",
+			simple: false,
+			want:   []string{"duplicated vertex: 0", "undeclared vertex: 2"},
+		},
+		{
+			input:  "V: 0 A\nE: 0 0\nE: 0 0\n",
+			simple: true,
+			want:   []string{"loop: 0 -> 0", "loop: 0 -> 0", "multiple edge: 0 -> 0"},
+		},
+		{
+			input:  "V: 0 A\nbad\n",
+			simple: false,
+			want:   []string{`record 2: malformed record: "bad"`},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, err := range validateGraph(decodeSimple(strings.NewReader(tt.input)), tt.simple) {
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%q: unexpected problems: got: %q want: %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// verifyLimit is the maximum number of problems recorded by a
+// verifier. The rest are only counted.
+const verifyLimit = 20
+
+// A verifier checks that a graph satisfies the constraints of its
+// generation while it is written, so bugs in the generators are not
+// silently written. It keeps every vertex and edge in memory.
+type verifier struct {
+	// loops and multiedges report whether loops and multiple edges
+	// are allowed.
+	loops      bool
+	multiedges bool
+
+	// endpoints reports whether the endpoints of every edge must be
+	// written.
+	endpoints bool
+
+	// allowed is the set of allowed pairs of tail and head types.
+	// If nil, all edges are allowed.
+	allowed map[[2]string]bool
+
+	// maxOut and maxIn are the maximum out-degree and in-degree of
+	// the vertices. If negative, degrees are not bounded.
+	maxOut, maxIn int
+
+	types    map[int64]string
+	edges    map[[2]int64]int
+	out, in  map[int64]int
+	problems []error
+	dropped  int
+}
+
+// newVerifier returns a verifier with unbounded degrees that allows
+// loops and multiple edges as specified.
+func newVerifier(loops, multiedges bool) *verifier {
+	return &verifier{
+		loops:      loops,
+		multiedges: multiedges,
+		maxOut:     -1,
+		maxIn:      -1,
+		types:      make(map[int64]string),
+		edges:      make(map[[2]int64]int),
+		out:        make(map[int64]int),
+		in:         make(map[int64]int),
+	}
+}
+
+// report records a problem.
+func (vf *verifier) report(format string, a ...any) {
+	if len(vf.problems) >= verifyLimit {
+		vf.dropped++
+		return
+	}
+	vf.problems = append(vf.problems, fmt.Errorf(format, a...))
+}
+
+// check returns g checking every vertex and edge as they are
+// consumed.
+func (vf *verifier) check(g graph) graph {
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			if _, ok := vf.types[v.id]; ok {
+				vf.report("duplicated vertex: %v", v.id)
+			}
+			vf.types[v.id] = v.vtype
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			vf.edge(e)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
+
+// edge checks the edge e.
+func (vf *verifier) edge(e edge) {
+	key := [2]int64{e.tail, e.head}
+	if e.removed {
+		if vf.edges[key] == 0 {
+			vf.report("removed edge does not exist: %v -> %v", e.tail, e.head)
+			return
+		}
+		vf.edges[key]--
+		vf.out[e.tail]--
+		vf.in[e.head]--
+		return
+	}
+
+	if e.tail == e.head && !vf.loops {
+		vf.report("loop: %v -> %v", e.tail, e.head)
+	}
+	if vf.edges[key] > 0 && !vf.multiedges {
+		vf.report("multiple edge: %v -> %v", e.tail, e.head)
+	}
+	vf.edges[key]++
+
+	vf.out[e.tail]++
+	if vf.maxOut >= 0 && vf.out[e.tail] == vf.maxOut+1 {
+		vf.report("out-degree of %v exceeds %v", e.tail, vf.maxOut)
+	}
+	vf.in[e.head]++
+	if vf.maxIn >= 0 && vf.in[e.head] == vf.maxIn+1 {
+		vf.report("in-degree of %v exceeds %v", e.head, vf.maxIn)
+	}
+
+	if vf.allowed != nil {
+		// The types of the endpoints that are not written are
+		// unknown.
+		ttype, tok := vf.types[e.tail]
+		htype, hok := vf.types[e.head]
+		if tok && hok && !vf.allowed[[2]string{ttype, htype}] {
+			vf.report("edge between disallowed types: %v -> %v (%v -> %v)", e.tail, e.head, ttype, htype)
+		}
+	}
+}
+
+// finish returns the problems found. If complete is true, the whole
+// graph has been written and the endpoints of the edges are checked
+// too.
+func (vf *verifier) finish(complete bool) []error {
+	if complete && vf.endpoints {
+		var undeclared []int64
+		for key := range vf.edges {
+			for _, id := range key {
+				if _, ok := vf.types[id]; !ok {
+					undeclared = append(undeclared, id)
+				}
+			}
+		}
+		slices.Sort(undeclared)
+		for _, id := range slices.Compact(undeclared) {
+			vf.report("undeclared vertex: %v", id)
+		}
+	}
+
+	problems := vf.problems
+	if vf.dropped > 0 {
+		problems = append(problems, fmt.Errorf("and %v more problems", vf.dropped))
+	}
+	return problems
+}
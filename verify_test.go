@@ -0,0 +1,162 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		vf       func() *verifier
+		vertices []vertex
+		edges    []edge
+		want     []string
+	}{
+		{
+			name:     "valid",
+			vf:       func() *verifier { return newVerifier(false, false) },
+			vertices: []vertex{{id: 0}, {id: 1}},
+			edges:    []edge{{tail: 0, head: 1}, {tail: 1, head: 0}},
+		},
+		{
+			name:     "loop",
+			vf:       func() *verifier { return newVerifier(false, true) },
+			vertices: []vertex{{id: 0}},
+			edges:    []edge{{tail: 0, head: 0}},
+			want:     []string{"loop: 0 -> 0"},
+		},
+		{
+			name:     "multiple edge",
+			vf:       func() *verifier { return newVerifier(true, false) },
+			vertices: []vertex{{id: 0}, {id: 1}},
+			edges:    []edge{{tail: 0, head: 1}, {tail: 0, head: 1, etype: "a"}},
+			want:     []string{"multiple edge: 0 -> 1"},
+		},
+		{
+			name:     "removed edge",
+			vf:       func() *verifier { return newVerifier(false, false) },
+			vertices: []vertex{{id: 0}, {id: 1}},
+			edges:    []edge{{tail: 0, head: 1}, {tail: 0, head: 1, removed: true}, {tail: 0, head: 1}, {tail: 1, head: 0, removed: true}},
+			want:     []string{"removed edge does not exist: 1 -> 0"},
+		},
+		{
+			name:     "duplicated vertex",
+			vf:       func() *verifier { return newVerifier(false, false) },
+			vertices: []vertex{{id: 0}, {id: 0}},
+			want:     []string{"duplicated vertex: 0"},
+		},
+		{
+			name: "undeclared vertex",
+			vf: func() *verifier {
+				vf := newVerifier(false, false)
+				vf.endpoints = true
+				return vf
+			},
+			vertices: []vertex{{id: 0}},
+			edges:    []edge{{tail: 0, head: 2}, {tail: 1, head: 2}},
+			want:     []string{"undeclared vertex: 1", "undeclared vertex: 2"},
+		},
+		{
+			name: "degrees",
+			vf: func() *verifier {
+				vf := newVerifier(false, false)
+				vf.maxOut, vf.maxIn = 1, 1
+				return vf
+			},
+			vertices: []vertex{{id: 0}, {id: 1}, {id: 2}},
+			edges:    []edge{{tail: 0, head: 1}, {tail: 0, head: 2}, {tail: 2, head: 1}},
+			want:     []string{"out-degree of 0 exceeds 1", "in-degree of 1 exceeds 1"},
+		},
+		{
+			name: "types",
+			vf: func() *verifier {
+				vf := newVerifier(false, false)
+				vf.allowed = map[[2]string]bool{{"a", "b"}: true}
+				return vf
+			},
+			vertices: []vertex{{id: 0, vtype: "a"}, {id: 1, vtype: "b"}},
+			edges:    []edge{{tail: 0, head: 1}, {tail: 1, head: 0}, {tail: 1, head: 5}},
+			want:     []string{"edge between disallowed types: 1 -> 0 (b -> a)"},
+		},
+	}
+	for _, tt := range tests {
+		vf := tt.vf()
+		g := vf.check(graph{vertices: slices.Values(tt.vertices), edges: slices.Values(tt.edges)})
+		for range g.vertices {
+		}
+		for range g.edges {
+		}
+
+		var got []string
+		for _, err := range vf.finish(true) {
+			got = append(got, err.Error())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%v: unexpected problems: got: %q want: %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerifierLimit(t *testing.T) {
+	vf := newVerifier(false, false)
+	for i := range verifyLimit + 5 {
+		vf.edge(edge{tail: int64(i), head: int64(i)})
+	}
+	problems := vf.finish(true)
+	if len(problems) != verifyLimit+1 {
+		t.Fatalf("unexpected number of problems: %v", len(problems))
+	}
+	if got, want := problems[verifyLimit].Error(), "and 5 more problems"; got != want {
+		t.Errorf("unexpected last problem: got: %q want: %q", got, want)
+	}
+}
+
+func TestVerifyAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "graph")
+	if err := os.WriteFile(name, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var c genConfig
+	fs := c.flagSet("generate")
+	if err := fs.Parse([]string{"-n=3", "-seed=1", "-verify"}); err != nil {
+		t.Fatal(err)
+	}
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Loops are not allowed.
+	gen.g.edges = slices.Values([]edge{{tail: 1, head: 1}})
+
+	if err := gen.writeFile(context.Background(), name, false, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if problems := gen.verifyProblems(true); len(problems) != 1 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "old" {
+		t.Errorf("output file replaced: %q", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("unexpected number of files: got: %v want: 1", len(entries))
+	}
+}
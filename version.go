@@ -0,0 +1,51 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// version returns the version of mkdigraph as reported by the build
+// information embedded in the binary.
+func version() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "mkdigraph (unknown)"
+	}
+	return formatBuildInfo(bi)
+}
+
+// formatBuildInfo returns a single line describing the module
+// version, the VCS revision and time, and the Go version of bi.
+func formatBuildInfo(bi *debug.BuildInfo) string {
+	fields := []string{"mkdigraph", bi.Main.Version}
+
+	var rev, vcsTime string
+	modified := false
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.time":
+			vcsTime = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if rev != "" {
+		if modified {
+			rev += "+dirty"
+		}
+		fields = append(fields, rev)
+	}
+	if vcsTime != "" {
+		fields = append(fields, vcsTime)
+	}
+	fields = append(fields, bi.GoVersion)
+
+	return strings.Join(fields, " ")
+}
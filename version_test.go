@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFormatBuildInfo(t *testing.T) {
+	tests := []struct {
+		bi   *debug.BuildInfo
+		want string
+	}{
+		{
+			bi: &debug.BuildInfo{
+				GoVersion: "go1.25.0",
+				Main:      debug.Module{Version: "v1.2.3"},
+			},
+			want: "mkdigraph v1.2.3 go1.25.0",
+		},
+		{
+			bi: &debug.BuildInfo{
+				GoVersion: "go1.25.0",
+				Main:      debug.Module{Version: "(devel)"},
+				Settings: []debug.BuildSetting{
+					{Key: "vcs.revision", Value: "0123abcd"},
+					{Key: "vcs.time", Value: "2025-01-02T03:04:05Z"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			},
+			want: "mkdigraph (devel) 0123abcd+dirty 2025-01-02T03:04:05Z go1.25.0",
+		},
+	}
+	for _, tt := range tests {
+		got := formatBuildInfo(tt.bi)
+		if got != tt.want {
+			t.Errorf("unexpected version: got: %q, want: %q", got, tt.want)
+		}
+	}
+}
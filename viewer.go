@@ -0,0 +1,270 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A viewerNode is a vertex of the graph embedded in the HTML viewer.
+type viewerNode struct {
+	ID        string  `json:"id"`
+	Label     string  `json:"label"`
+	Type      string  `json:"type,omitempty"`
+	Community string  `json:"community,omitempty"`
+	Color     string  `json:"color,omitempty"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+}
+
+// A viewerEdge is an edge of the graph embedded in the HTML viewer.
+// Its endpoints are indexes of the nodes.
+type viewerEdge struct {
+	Source int    `json:"source"`
+	Target int    `json:"target"`
+	Type   string `json:"type,omitempty"`
+	Color  string `json:"color,omitempty"`
+}
+
+// A viewerGraph is the graph embedded in the HTML viewer.
+type viewerGraph struct {
+	Nodes []viewerNode `json:"nodes"`
+	Edges []viewerEdge `json:"edges"`
+}
+
+// writeHTML writes a standalone HTML page that draws d, with vertex
+// ids[i] placed at pos[i], and lets the user explore it: the view can
+// be dragged and zoomed, hovering over a vertex shows its attributes
+// and clicking on it highlights its neighbors. Vertices are colored by
+// community and edges by type. The page does not load any resource.
+func writeHTML(w io.Writer, d *graphData, ids []int64, pos []point) error {
+	vg := viewerGraph{Nodes: make([]viewerNode, len(ids)), Edges: make([]viewerEdge, 0, len(d.edges))}
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+		s := strconv.FormatInt(id, 10)
+		vg.Nodes[i] = viewerNode{ID: s, Label: s, X: pos[i].x, Y: pos[i].y}
+	}
+	for _, v := range d.vertices {
+		n := &vg.Nodes[index[v.id]]
+		if v.label != "" {
+			n.Label = v.label
+		}
+		n.Type, n.Community = v.vtype, v.community
+		if v.community != "" {
+			n.Color = typeColor(v.community)
+		}
+	}
+	for _, e := range d.edges {
+		ve := viewerEdge{Source: index[e.tail], Target: index[e.head], Type: e.etype}
+		if e.etype != "" {
+			ve.Color = typeColor(e.etype)
+		}
+		vg.Edges = append(vg.Edges, ve)
+	}
+
+	// The JSON encoder escapes "<", ">" and "&", so the data cannot
+	// close the script element.
+	data, err := json.Marshal(vg)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, strings.Replace(viewerPage, "/*GRAPH*/null", string(data), 1))
+	return err
+}
+
+// viewerPage is the HTML viewer. The graph replaces the /*GRAPH*/null
+// placeholder.
+const viewerPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>mkdigraph</title>
+<style>
+html, body { margin: 0; height: 100%; overflow: hidden; font-family: sans-serif; }
+canvas { display: block; cursor: grab; }
+#info { position: absolute; top: 8px; left: 8px; padding: 4px 8px; background: rgba(255, 255, 255, 0.9); border: 1px solid #ccc; font-size: 12px; white-space: pre; }
+</style>
+</head>
+<body>
+<canvas id="view"></canvas>
+<div id="info"></div>
+<script>
+"use strict";
+const graph = /*GRAPH*/null;
+const nodes = graph.nodes, edges = graph.edges;
+const canvas = document.getElementById("view");
+const info = document.getElementById("info");
+const ctx = canvas.getContext("2d");
+const radius = 12;
+
+const out = nodes.map(() => []), inc = nodes.map(() => []);
+for (const e of edges) {
+	out[e.source].push(e.target);
+	inc[e.target].push(e.source);
+}
+
+let scale = 1, tx = 0, ty = 0, selected = -1, hovered = -1, drag = null;
+
+function fit() {
+	canvas.width = window.innerWidth;
+	canvas.height = window.innerHeight;
+	if (nodes.length === 0) {
+		return;
+	}
+	let minX = Infinity, minY = Infinity, maxX = -Infinity, maxY = -Infinity;
+	for (const n of nodes) {
+		minX = Math.min(minX, n.x);
+		minY = Math.min(minY, n.y);
+		maxX = Math.max(maxX, n.x);
+		maxY = Math.max(maxY, n.y);
+	}
+	const w = maxX - minX + 4 * radius, h = maxY - minY + 4 * radius;
+	scale = Math.min(canvas.width / w, canvas.height / h, 2);
+	tx = canvas.width / 2 - scale * (minX + maxX) / 2;
+	ty = canvas.height / 2 - scale * (minY + maxY) / 2;
+}
+
+function draw() {
+	ctx.setTransform(1, 0, 0, 1, 0, 0);
+	ctx.fillStyle = "white";
+	ctx.fillRect(0, 0, canvas.width, canvas.height);
+	ctx.setTransform(scale, 0, 0, scale, tx, ty);
+	ctx.lineWidth = 1 / scale;
+
+	const near = new Set();
+	if (selected >= 0) {
+		near.add(selected);
+		out[selected].forEach(v => near.add(v));
+		inc[selected].forEach(v => near.add(v));
+	}
+
+	for (const e of edges) {
+		const a = nodes[e.source], b = nodes[e.target];
+		ctx.globalAlpha = selected >= 0 && e.source !== selected && e.target !== selected ? 0.1 : 1;
+		ctx.strokeStyle = ctx.fillStyle = e.color || "#999";
+		if (e.source === e.target) {
+			ctx.beginPath();
+			ctx.arc(a.x, a.y - radius, radius, 0, 2 * Math.PI);
+			ctx.stroke();
+			continue;
+		}
+		const dx = b.x - a.x, dy = b.y - a.y, l = Math.hypot(dx, dy);
+		if (l <= 2 * radius) {
+			continue;
+		}
+		const ux = dx / l, uy = dy / l;
+		const x2 = b.x - ux * radius, y2 = b.y - uy * radius;
+		ctx.beginPath();
+		ctx.moveTo(a.x + ux * radius, a.y + uy * radius);
+		ctx.lineTo(x2, y2);
+		ctx.stroke();
+		ctx.beginPath();
+		ctx.moveTo(x2, y2);
+		ctx.lineTo(x2 - ux * 8 - uy * 4, y2 - uy * 8 + ux * 4);
+		ctx.lineTo(x2 - ux * 8 + uy * 4, y2 - uy * 8 - ux * 4);
+		ctx.closePath();
+		ctx.fill();
+	}
+
+	ctx.textAlign = "center";
+	ctx.textBaseline = "middle";
+	ctx.font = "10px sans-serif";
+	nodes.forEach((n, i) => {
+		ctx.globalAlpha = selected >= 0 && !near.has(i) ? 0.1 : 1;
+		ctx.beginPath();
+		ctx.arc(n.x, n.y, radius, 0, 2 * Math.PI);
+		ctx.fillStyle = n.color || "white";
+		ctx.fill();
+		ctx.lineWidth = (i === hovered || i === selected ? 3 : 1) / scale;
+		ctx.strokeStyle = "black";
+		ctx.stroke();
+		if (scale * radius >= 6) {
+			ctx.fillStyle = "black";
+			ctx.fillText(n.label, n.x, n.y, 2 * radius);
+		}
+	});
+	ctx.globalAlpha = 1;
+}
+
+function describe(i) {
+	if (i < 0) {
+		info.textContent = nodes.length + " vertices, " + edges.length + " edges";
+		return;
+	}
+	const n = nodes[i];
+	let s = n.id + " " + n.label;
+	if (n.type) {
+		s += "\ntype: " + n.type;
+	}
+	if (n.community) {
+		s += "\ncommunity: " + n.community;
+	}
+	s += "\nout-degree: " + out[i].length + "\nin-degree: " + inc[i].length;
+	info.textContent = s;
+}
+
+function nodeAt(px, py) {
+	const x = (px - tx) / scale, y = (py - ty) / scale;
+	for (let i = nodes.length - 1; i >= 0; i--) {
+		if (Math.hypot(nodes[i].x - x, nodes[i].y - y) <= radius) {
+			return i;
+		}
+	}
+	return -1;
+}
+
+canvas.addEventListener("mousedown", e => {
+	drag = {x: e.clientX, y: e.clientY, tx: tx, ty: ty, moved: false};
+});
+window.addEventListener("mouseup", e => {
+	if (drag && !drag.moved) {
+		selected = nodeAt(e.clientX, e.clientY);
+		describe(selected);
+		draw();
+	}
+	drag = null;
+});
+canvas.addEventListener("mousemove", e => {
+	if (drag) {
+		const dx = e.clientX - drag.x, dy = e.clientY - drag.y;
+		if (Math.abs(dx) + Math.abs(dy) > 3) {
+			drag.moved = true;
+		}
+		tx = drag.tx + dx;
+		ty = drag.ty + dy;
+		draw();
+		return;
+	}
+	const i = nodeAt(e.clientX, e.clientY);
+	if (i !== hovered) {
+		hovered = i;
+		describe(i >= 0 ? i : selected);
+		draw();
+	}
+});
+canvas.addEventListener("wheel", e => {
+	e.preventDefault();
+	const f = Math.exp(-e.deltaY * 0.001);
+	tx = e.clientX - (e.clientX - tx) * f;
+	ty = e.clientY - (e.clientY - ty) * f;
+	scale *= f;
+	draw();
+}, {passive: false});
+window.addEventListener("resize", () => {
+	fit();
+	draw();
+});
+
+fit();
+describe(-1);
+draw();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	const input = "V: 0 </script> community=c\nV: 1 B\nE: 0 1 type=KNOWS\nE: 1 2\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := d.vertexIDs()
+
+	var b strings.Builder
+	if err := writeHTML(&b, d, ids, circleLayout(len(ids))); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+	if n := strings.Count(page, "</script>"); n != 1 {
+		t.Errorf("unexpected number of script end tags: %v", n)
+	}
+
+	const prefix = "const graph = "
+	i := strings.Index(page, prefix)
+	if i < 0 {
+		t.Fatal("graph data not found")
+	}
+	data, _, _ := strings.Cut(page[i+len(prefix):], ";\n")
+	var vg viewerGraph
+	if err := json.Unmarshal([]byte(data), &vg); err != nil {
+		t.Fatalf("invalid graph data: %v", err)
+	}
+
+	if len(vg.Nodes) != 3 {
+		t.Fatalf("unexpected number of nodes: got: %v want: 3", len(vg.Nodes))
+	}
+	if n := vg.Nodes[0]; n.Label != "</script>" || n.Community != "c" || n.Color != typeColor("c") {
+		t.Errorf("unexpected node: %+v", n)
+	}
+	if n := vg.Nodes[2]; n.ID != "2" || n.Label != "2" || n.Color != "" {
+		t.Errorf("unexpected undeclared node: %+v", n)
+	}
+	want := []viewerEdge{{Source: 0, Target: 1, Type: "KNOWS", Color: typeColor("KNOWS")}, {Source: 1, Target: 2}}
+	if len(vg.Edges) != len(want) || vg.Edges[0] != want[0] || vg.Edges[1] != want[1] {
+		t.Errorf("unexpected edges: got: %+v want: %+v", vg.Edges, want)
+	}
+}
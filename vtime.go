@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// A timeline assigns creation times to the vertices and edges of a
+// graph. Times are Unix times in seconds.
+type timeline struct {
+	// start is the earliest creation time.
+	start int64
+
+	// interval is the interval between the creation of
+	// consecutive vertices. Every vertex is created at a random
+	// time within its interval.
+	interval int64
+
+	// seed determines the random times.
+	seed uint64
+}
+
+// parseTimeline parses the value of the -vertex-time flag, which has
+// the form "start,interval". start is an RFC 3339 time and interval
+// a duration of at least one second.
+func parseTimeline(s string, seed uint64) (timeline, error) {
+	start, interval, ok := strings.Cut(s, ",")
+	if !ok {
+		return timeline{}, fmt.Errorf("malformed vertex time: %q", s)
+	}
+	t, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return timeline{}, fmt.Errorf("invalid vertex time %q: %w", s, err)
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return timeline{}, fmt.Errorf("invalid vertex time %q: %w", s, err)
+	}
+	if d < time.Second {
+		return timeline{}, fmt.Errorf("invalid vertex time %q: %w", s, errors.New("interval is shorter than one second"))
+	}
+	return timeline{start: t.Unix(), interval: int64(d / time.Second), seed: seed}, nil
+}
+
+// check returns an error if the times of a graph with n vertices
+// overflow.
+func (tl timeline) check(n int64) error {
+	if float64(tl.start)+float64(tl.interval)*float64(n+2) >= math.MaxInt64 {
+		return fmt.Errorf("vertex times overflow with %v vertices", n)
+	}
+	return nil
+}
+
+// vertexTime returns the creation time of the vertex with the
+// provided ID. Vertices are created in ID order.
+func (tl timeline) vertexTime(id int64) int64 {
+	jitter := int64(vertexHash(tl.seed, id, saltTime) % uint64(tl.interval))
+	return tl.start + id*tl.interval + jitter
+}
+
+// edgeTime returns the creation time of e, which is a random time
+// within the interval that follows the creation of its last
+// endpoint.
+func (tl timeline) edgeTime(e edge) int64 {
+	jitter := int64(mix64(vertexHash(tl.seed, e.tail, saltTime)^uint64(e.head)) % uint64(tl.interval))
+	return max(tl.vertexTime(e.tail), tl.vertexTime(e.head)) + 1 + jitter
+}
+
+// withTimes returns a copy of g where every vertex and edge has its
+// creation time according to tl. Thus, every vertex is created
+// before its edges. The times only depend on tl and the IDs of the
+// vertices, so they do not change if the graph is generated in
+// parts.
+func withTimes(g graph, tl timeline) graph {
+	vertices := func(yield func(vertex) bool) {
+		for v := range g.vertices {
+			v.time = tl.vertexTime(v.id)
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	edges := func(yield func(edge) bool) {
+		for e := range g.edges {
+			e.time = tl.edgeTime(e)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+	return graph{vertices: vertices, edges: edges}
+}
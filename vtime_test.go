@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestParseTimeline(t *testing.T) {
+	tl, err := parseTimeline("2024-01-01T00:00:00Z,1h", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tl.start != 1704067200 || tl.interval != 3600 {
+		t.Errorf("unexpected timeline: %+v", tl)
+	}
+
+	for _, s := range []string{"", "2024-01-01T00:00:00Z", "yesterday,1h", "2024-01-01T00:00:00Z,1ms", "2024-01-01T00:00:00Z,soon"} {
+		if _, err := parseTimeline(s, 1); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func TestWithTimes(t *testing.T) {
+	const n = 200
+
+	vlabel := func(id int64) string { return strconv.FormatInt(id, 10) }
+
+	tl := timeline{start: 1000, interval: 60, seed: 7}
+	g := withTimes(trialGraph(n, nil, 5, 0.5, true, false, rngPCG, 3, 0, n, 1, vlabel), tl)
+
+	times := make(map[int64]int64)
+	prev := int64(-1)
+	for v := range g.vertices {
+		if v.time <= prev {
+			t.Errorf("vertex %v is not created after the previous one: %v <= %v", v.id, v.time, prev)
+		}
+		times[v.id] = v.time
+		prev = v.time
+	}
+	for e := range g.edges {
+		if e.time <= times[e.tail] || e.time <= times[e.head] {
+			t.Errorf("edge %v is created before its endpoints", e)
+		}
+	}
+
+	// Times do not depend on the part of the graph being
+	// generated.
+	part := withTimes(trialGraph(n, nil, 5, 0.5, true, false, rngPCG, 3, 100, n, 1, vlabel), tl)
+	full := slices.Collect(g.edges)
+	i := slices.IndexFunc(full, func(e edge) bool { return e.tail >= 100 })
+	if got := slices.Collect(part.edges); !slices.Equal(got, full[i:]) {
+		t.Error("edge times depend on the generated part of the graph")
+	}
+
+	if err := tl.check(n); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (timeline{start: 1000, interval: 1 << 40}).check(1 << 30); err == nil {
+		t.Error("expected error")
+	}
+}
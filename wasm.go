@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+//go:build js && wasm
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+func init() {
+	jsMain = serveJS
+}
+
+// serveJS exposes the generate function to JavaScript as
+// mkdigraph.generate and waits forever, so it can be called once the
+// program has started.
+func serveJS() {
+	js.Global().Set("mkdigraph", js.ValueOf(map[string]any{
+		"generate": js.FuncOf(jsGenerate),
+	}))
+	select {}
+}
+
+// jsGenerate implements mkdigraph.generate(params, onVertex, onEdge).
+// params is an object that maps generate flag names to their values.
+// onVertex and onEdge are called with every vertex and edge, in the
+// order they would be written, and stop the generation if they return
+// false. It returns an object with the number of vertices and edges,
+// or with the error message if the generation fails.
+func jsGenerate(this js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		return jsError(errors.New("usage: generate(params, onVertex, onEdge)"))
+	}
+	params, onVertex, onEdge := args[0], args[1], args[2]
+
+	c, fs, err := parseGenerate(jsFlags(params))
+	if err != nil {
+		return jsError(err)
+	}
+
+	// The browser has no files, and waiting for timers or
+	// interruptions would block its event loop.
+	switch {
+	case c.outFile != "" || len(c.tees) > 0 || c.snapshots > 0 || c.checkpoint != "" || c.manifest != "" || c.checksum != "" || c.verify:
+		return jsError(errors.New("-o, -tee, -snapshots, -checkpoint, -manifest, -checksum and -verify are not supported"))
+	case c.infinite || c.rate > 0 || c.bench:
+		return jsError(errors.New("-infinite, -rate and -bench are not supported"))
+	}
+
+	gen, err := c.newGeneration(fs)
+	if err != nil {
+		return jsError(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	call := func(fn js.Value, obj map[string]any) {
+		if fn.Type() != js.TypeFunction {
+			return
+		}
+		if ret := fn.Invoke(obj); ret.Type() == js.TypeBoolean && !ret.Bool() {
+			cancel()
+		}
+	}
+	p := gen.p
+	vfn := func(v vertex) {
+		obj := map[string]any{"id": v.id, "label": v.label}
+		if v.vtype != "" {
+			obj["type"] = v.vtype
+		}
+		if v.community != "" {
+			obj["community"] = v.community
+		}
+		if p.times || p.temporal {
+			obj["time"] = v.time
+		}
+		if p.coords {
+			obj["lat"], obj["lon"] = v.lat, v.lon
+		}
+		call(onVertex, obj)
+	}
+	efn := func(e edge) {
+		obj := map[string]any{"tail": e.tail, "head": e.head}
+		if e.etype != "" {
+			obj["type"] = e.etype
+		}
+		if p.times || p.temporal {
+			obj["time"] = e.time
+		}
+		if p.temporal {
+			obj["removed"] = e.removed
+		}
+		call(onEdge, obj)
+	}
+	if err := gen.walk(ctx, vfn, efn); err != nil {
+		return jsError(err)
+	}
+
+	return map[string]any{"vertices": gen.counter.vertices, "edges": gen.counter.edges}
+}
+
+// jsFlags returns the generate flags of the params object of
+// mkdigraph.generate. If params is not an object, there are no flags.
+func jsFlags(params js.Value) []string {
+	if params.Type() != js.TypeObject {
+		return nil
+	}
+
+	// Numbers and booleans are converted to strings by JavaScript,
+	// so they are formatted like flag values.
+	var flags []string
+	str := js.Global().Get("String")
+	keys := js.Global().Get("Object").Call("keys", params)
+	for i := range keys.Length() {
+		name := keys.Index(i).String()
+		flags = append(flags, fmt.Sprintf("-%v=%v", name, str.Invoke(params.Get(name)).String()))
+	}
+	return flags
+}
+
+// jsError returns the result of a failed call from JavaScript.
+func jsError(err error) any {
+	return map[string]any{"error": err.Error()}
+}
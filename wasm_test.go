@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+//go:build js && wasm
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestJSFlags(t *testing.T) {
+	params := js.ValueOf(map[string]any{"n": 10, "prob": 0.5, "loops": true, "vertex-types": "a,b"})
+	got := jsFlags(params)
+	slices.Sort(got)
+	want := []string{"-loops=true", "-n=10", "-prob=0.5", "-vertex-types=a,b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected flags: got: %q want: %q", got, want)
+	}
+
+	if got := jsFlags(js.Undefined()); got != nil {
+		t.Errorf("unexpected flags: %q", got)
+	}
+}
+
+func TestJSGenerate(t *testing.T) {
+	var vertices, edges int
+	onVertex := js.FuncOf(func(this js.Value, args []js.Value) any {
+		vertices++
+		return nil
+	})
+	defer onVertex.Release()
+	onEdge := js.FuncOf(func(this js.Value, args []js.Value) any {
+		edges++
+		return edges < 3
+	})
+	defer onEdge.Release()
+
+	params := js.ValueOf(map[string]any{"n": 10, "prob": 1, "seed": 1})
+	ret := js.ValueOf(jsGenerate(js.Undefined(), []js.Value{params, onVertex.Value, onEdge.Value}))
+	if err := ret.Get("error"); !err.IsUndefined() {
+		t.Fatalf("generation error: %v", err)
+	}
+	if vertices != 10 || edges != 3 {
+		t.Errorf("generation not stopped: %v vertices, %v edges", vertices, edges)
+	}
+}
+
+func TestJSGenerateUnsupported(t *testing.T) {
+	for _, name := range []string{"o", "checkpoint", "verify"} {
+		params := js.ValueOf(map[string]any{"n": 10, name: "x"})
+		if name == "verify" {
+			params.Set(name, true)
+		}
+		ret := js.ValueOf(jsGenerate(js.Undefined(), []js.Value{params, js.Null(), js.Null()}))
+		if err := ret.Get("error"); err.IsUndefined() || !strings.Contains(err.String(), "not supported") {
+			t.Errorf("-%v: unexpected result: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// wordsCacheMagic is the header of the words cache files. The NUL
+// byte cannot appear in a words file meant to be read as text.
+const wordsCacheMagic = "\x00mkdigraph words\n"
+
+// readWordsCache reads the words of the words cache file with the
+// provided name from r, which is positioned at the start of the
+// header. The words are returned in the order of the file.
+func readWordsCache(name string, r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	body, ok := strings.CutPrefix(string(data), wordsCacheMagic)
+	if !ok {
+		return nil, fmt.Errorf("%v: invalid words cache header", name)
+	}
+	if body == "" {
+		return nil, nil
+	}
+	body, ok = strings.CutSuffix(body, "\n")
+	if !ok {
+		return nil, fmt.Errorf("%v: truncated words cache", name)
+	}
+	words := strings.Split(body, "\n")
+	for i, w := range words {
+		if !isSanitized(w) {
+			return nil, fmt.Errorf("%v: invalid word %v in words cache", name, i+1)
+		}
+	}
+	return words, nil
+}
+
+// isSanitized reports whether word is a non-empty word that does not
+// contain any of the characters removed by readWords. It is much
+// faster than matching invalidChars.
+func isSanitized(word string) bool {
+	for i := range len(word) {
+		if c := word[i]; !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// writeWordsCache writes words to w as a words cache: the header
+// followed by the words sorted and separated by newlines. The words
+// must be sanitized and unique, like the ones returned by readWords.
+func writeWordsCache(w io.Writer, words []string) error {
+	words = slices.Clone(words)
+	slices.Sort(words)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(wordsCacheMagic)
+	for _, word := range words {
+		bw.WriteString(word)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+func runWords(args []string) {
+	fs := flag.NewFlagSet("words", flag.ExitOnError)
+	outFile := fs.String("o", "", "output file")
+	translit := fs.Bool("transliterate", false, "transliterate accented letters instead of removing them")
+	encoding := fs.String("encoding", "utf-8", "character `encoding` of the words file (utf-8, latin1)")
+	fs.Usage = commandUsage(fs, "words [-transliterate] [-encoding encoding] [-o output] file")
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	enc, err := parseWordsEncoding(*encoding)
+	if err != nil {
+		fatal(usageError{err})
+	}
+	words, err := readWords(fs.Arg(0), enc, *translit)
+	if err != nil {
+		fatal(err)
+	}
+
+	out, err := createOutput(*outFile, false, false)
+	if err != nil {
+		fatal(err)
+	}
+	if err := writeWordsCache(out, words); err != nil {
+		out.abort()
+		fatal(err)
+	}
+	if err := out.commit(); err != nil {
+		fatal(err)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestWordsCache(t *testing.T) {
+	words, err := readWords("testdata/words", encodingUTF8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeWordsCache(&buf, words); err != nil {
+		t.Fatal(err)
+	}
+	if want := wordsCacheMagic + "FirstWord\nSecondWord\n"; buf.String() != want {
+		t.Errorf("unexpected cache: got: %q want: %q", buf.String(), want)
+	}
+
+	name := filepath.Join(t.TempDir(), "words.cache")
+	if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readWords(name, encodingUTF8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"FirstWord", "SecondWord"}; !slices.Equal(got, want) {
+		t.Errorf("unexpected words: got: %v want: %v", got, want)
+	}
+}
+
+func TestWordsCacheErrors(t *testing.T) {
+	tests := []string{
+		wordsCacheMagic + "Word",
+		wordsCacheMagic + "Word\n\nOther\n",
+		wordsCacheMagic + "Wörd\n",
+	}
+	dir := t.TempDir()
+	for i, data := range tests {
+		name := filepath.Join(dir, "words.cache")
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readWords(name, encodingUTF8, false); err == nil {
+			t.Errorf("%v: expected error", i)
+		}
+	}
+}
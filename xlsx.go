@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// xlsxMaxRows is the maximum number of rows of an Excel worksheet,
+// including the header.
+const xlsxMaxRows = 1 << 20
+
+// xlsxMaxExact is the bound of the integers that Excel stores exactly.
+// Larger IDs are written as text.
+const xlsxMaxExact = 1e15
+
+// An xlsxCell is a cell of a worksheet. It holds a number if isNum is
+// true and text otherwise.
+type xlsxCell struct {
+	isNum bool
+	num   string
+	text  string
+}
+
+// numCell returns a cell that holds n, as text if Excel cannot store
+// it exactly.
+func numCell(n int64) xlsxCell {
+	if n >= xlsxMaxExact || n <= -xlsxMaxExact {
+		return xlsxCell{text: strconv.FormatInt(n, 10)}
+	}
+	return xlsxCell{isNum: true, num: strconv.FormatInt(n, 10)}
+}
+
+// writeXLSX writes the vertices and the edges of d as the "vertices"
+// and "edges" worksheets of an Excel workbook. Every sheet starts with
+// a header row, and the optional attributes only get a column if at
+// least one vertex or edge has them.
+func writeXLSX(w io.Writer, d *graphData) error {
+	if len(d.vertices) >= xlsxMaxRows || len(d.edges) >= xlsxMaxRows {
+		return fmt.Errorf("graph too large for a worksheet: %v vertices and %v edges (max %v)", len(d.vertices), len(d.edges), xlsxMaxRows-1)
+	}
+
+	var vtype, community, vtime, coords bool
+	for _, v := range d.vertices {
+		vtype = vtype || v.vtype != ""
+		community = community || v.community != ""
+		vtime = vtime || v.time != 0
+		coords = coords || v.lat != 0 || v.lon != 0
+	}
+	vheader := []string{"id", "label"}
+	for _, col := range []struct {
+		name string
+		used bool
+	}{{"type", vtype}, {"community", community}, {"time", vtime}, {"lat", coords}, {"lon", coords}} {
+		if col.used {
+			vheader = append(vheader, col.name)
+		}
+	}
+	vrow := func(v vertex) []xlsxCell {
+		row := []xlsxCell{numCell(v.id), {text: v.label}}
+		if vtype {
+			row = append(row, xlsxCell{text: v.vtype})
+		}
+		if community {
+			row = append(row, xlsxCell{text: v.community})
+		}
+		if vtime {
+			row = append(row, numCell(v.time))
+		}
+		if coords {
+			row = append(row,
+				xlsxCell{isNum: true, num: string(appendCoord(nil, v.lat))},
+				xlsxCell{isNum: true, num: string(appendCoord(nil, v.lon))},
+			)
+		}
+		return row
+	}
+
+	var etype, etime bool
+	for _, e := range d.edges {
+		etype = etype || e.etype != ""
+		etime = etime || e.time != 0
+	}
+	eheader := []string{"tail", "head"}
+	if etype {
+		eheader = append(eheader, "type")
+	}
+	if etime {
+		eheader = append(eheader, "time")
+	}
+	erow := func(e edge) []xlsxCell {
+		row := []xlsxCell{numCell(e.tail), numCell(e.head)}
+		if etype {
+			row = append(row, xlsxCell{text: e.etype})
+		}
+		if etime {
+			row = append(row, numCell(e.time))
+		}
+		return row
+	}
+
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, data string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.data); err != nil {
+			return err
+		}
+	}
+	if err := writeSheet(zw, "xl/worksheets/sheet1.xml", vheader, len(d.vertices), func(i int) []xlsxCell { return vrow(d.vertices[i]) }); err != nil {
+		return err
+	}
+	if err := writeSheet(zw, "xl/worksheets/sheet2.xml", eheader, len(d.edges), func(i int) []xlsxCell { return erow(d.edges[i]) }); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeSheet writes a worksheet with the provided header followed by
+// n rows to the named file of zw. Text is written as inline strings.
+func writeSheet(zw *zip.Writer, name string, header []string, n int, row func(i int) []xlsxCell) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	writeRow := func(r int, cells []xlsxCell) {
+		fmt.Fprintf(bw, `<row r="%v">`, r)
+		for c, cell := range cells {
+			ref := string(rune('A'+c)) + strconv.Itoa(r)
+			if cell.isNum {
+				fmt.Fprintf(bw, `<c r="%v"><v>%v</v></c>`, ref, cell.num)
+				continue
+			}
+			fmt.Fprintf(bw, `<c r="%v" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(bw, []byte(cell.text))
+			bw.WriteString(`</t></is></c>`)
+		}
+		bw.WriteString("</row>")
+	}
+
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	cells := make([]xlsxCell, len(header))
+	for i, h := range header {
+		cells[i] = xlsxCell{text: h}
+	}
+	writeRow(1, cells)
+	for i := range n {
+		writeRow(i+2, row(i))
+	}
+	bw.WriteString("</sheetData></worksheet>")
+	return bw.Flush()
+}
+
+// Fixed parts of the workbooks written by writeXLSX.
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets>` +
+		`<sheet name="vertices" sheetId="1" r:id="rId1"/>` +
+		`<sheet name="edges" sheetId="2" r:id="rId2"/>` +
+		`</sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>` +
+		`</Relationships>`
+)
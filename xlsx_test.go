@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Roi Martin
+// Use of this source code is governed by the MIT license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readSheet returns the text of the cells of every row of the named
+// worksheet of the workbook in zr. It fails if the file is not
+// well-formed XML, so it also checks the other parts.
+func readSheet(t *testing.T, zr *zip.Reader, name string) [][]string {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var rows [][]string
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%v: invalid XML: %v", name, err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "row":
+				rows = append(rows, nil)
+			case "c":
+				rows[len(rows)-1] = append(rows[len(rows)-1], "")
+			}
+		case xml.CharData:
+			if len(rows) > 0 && len(rows[len(rows)-1]) > 0 {
+				row := rows[len(rows)-1]
+				row[len(row)-1] += string(tok)
+			}
+		}
+	}
+	return rows
+}
+
+func TestWriteXLSX(t *testing.T) {
+	const input = "V: 0 A&B type=user\nV: 1 B\nE: 0 1 type=KNOWS\nE: 1 1000000000000000\n"
+	d, err := loadGraph(decodeSimple(strings.NewReader(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, d); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		readSheet(t, zr, name)
+	}
+
+	vertices := readSheet(t, zr, "xl/worksheets/sheet1.xml")
+	wantVertices := [][]string{{"id", "label", "type"}, {"0", "A&B", "user"}, {"1", "B", ""}}
+	if !slices.EqualFunc(vertices, wantVertices, slices.Equal) {
+		t.Errorf("unexpected vertices: got: %q want: %q", vertices, wantVertices)
+	}
+	edges := readSheet(t, zr, "xl/worksheets/sheet2.xml")
+	wantEdges := [][]string{{"tail", "head", "type"}, {"0", "1", "KNOWS"}, {"1", "1000000000000000", ""}}
+	if !slices.EqualFunc(edges, wantEdges, slices.Equal) {
+		t.Errorf("unexpected edges: got: %q want: %q", edges, wantEdges)
+	}
+
+	// The large ID is written as text.
+	if !bytes.Contains(readPart(t, zr, "xl/worksheets/sheet2.xml"), []byte(`t="inlineStr"><is><t xml:space="preserve">1000000000000000<`)) {
+		t.Error("large ID not written as text")
+	}
+}
+
+// readPart returns the contents of the named file of zr.
+func readPart(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}